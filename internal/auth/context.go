@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+)
+
+// Principal is the authenticated caller of a request. Callers signed in
+// with a password have a SessionID; callers using an API token have a
+// TokenID and the Scopes the token is limited to. The caller acts in
+// OrganizationID, and User.Role is its role there; both are empty for users
+// who belong to no organization.
+type Principal struct {
+	User           *domain.User
+	SessionID      uuid.UUID
+	TokenID        uuid.UUID
+	Scopes         []Permission
+	OrganizationID uuid.UUID
+}
+
+// In returns a copy of the caller acting in another organization, in which
+// it has role.
+func (p *Principal) In(organizationID uuid.UUID, role domain.Role) *Principal {
+	user := *p.User
+	user.Role = role
+	in := *p
+	in.User = &user
+	in.OrganizationID = organizationID
+	return &in
+}
+
+// Grants returns what the caller may do: what its role allows, narrowed to
+// the token's scopes for API tokens. The result must not be modified.
+func (p *Principal) Grants() Grants {
+	grants := GrantsOf(p.User.Role)
+	if p.Scopes == nil {
+		return grants
+	}
+	scoped := make(Grants, len(p.Scopes))
+	for _, permission := range p.Scopes {
+		if scopes, ok := grants[permission]; ok {
+			scoped[permission] = scopes
+		}
+	}
+	return scoped
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFrom returns the caller attached by WithPrincipal, or nil for
+// anonymous requests.
+func PrincipalFrom(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey{}).(*Principal)
+	return principal
+}
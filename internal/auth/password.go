@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"errors"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Passwords are limited to what bcrypt can hash: it only looks at the first
+// 72 bytes, so longer passwords are refused rather than silently truncated.
+const (
+	MinPasswordLength = 8
+	MaxPasswordLength = 72
+)
+
+// passwordCost is the bcrypt work factor.
+const passwordCost = 12
+
+var ErrPasswordLength = errors.New("password must be between 8 and 72 bytes long")
+
+func HashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLength || len(password) > MaxPasswordLength {
+		return "", ErrPasswordLength
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), passwordCost)
+	return string(hash), err
+}
+
+// dummyHash is compared against when a login names an unknown user, so that
+// such logins take as long as ones with a wrong password.
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("not a real password"), passwordCost)
+	return hash
+})
+
+// CheckPassword reports whether password matches hash. An empty hash, as
+// stored for users who cannot log in, never matches.
+func CheckPassword(hash, password string) bool {
+	if hash == "" {
+		bcrypt.CompareHashAndPassword(dummyHash(), []byte(password))
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
@@ -0,0 +1,253 @@
+package auth
+
+import (
+	"context"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+)
+
+// Permission is an action on a kind of resource.
+type Permission string
+
+const (
+	UsersRead       Permission = "users:read"
+	UsersCreate     Permission = "users:create"
+	UsersUpdate     Permission = "users:update"
+	UsersDelete     Permission = "users:delete"
+	ProjectsRead    Permission = "projects:read"
+	ProjectsCreate  Permission = "projects:create"
+	ProjectsUpdate  Permission = "projects:update"
+	ProjectsDelete  Permission = "projects:delete"
+	TasksRead       Permission = "tasks:read"
+	TasksCreate     Permission = "tasks:create"
+	TasksUpdate     Permission = "tasks:update"
+	TasksDelete     Permission = "tasks:delete"
+	WorkflowsRead   Permission = "workflows:read"
+	WorkflowsUpdate Permission = "workflows:update"
+	// OrganizationsUpdate allows renaming the caller's organization. Its
+	// members are managed with the users:* permissions.
+	OrganizationsUpdate Permission = "organizations:update"
+	// Comments are read along with their task, with tasks:read.
+	CommentsCreate Permission = "comments:create"
+	CommentsUpdate Permission = "comments:update"
+	CommentsDelete Permission = "comments:delete"
+	// Attachments are read along with their task or project.
+	AttachmentsCreate Permission = "attachments:create"
+	AttachmentsDelete Permission = "attachments:delete"
+	// Worklogs are read along with their task, and timesheets along with
+	// their project or user.
+	WorklogsCreate Permission = "worklogs:create"
+	WorklogsUpdate Permission = "worklogs:update"
+	WorklogsDelete Permission = "worklogs:delete"
+)
+
+// Permissions lists every permission.
+func Permissions() []Permission {
+	return []Permission{
+		UsersRead, UsersCreate, UsersUpdate, UsersDelete,
+		ProjectsRead, ProjectsCreate, ProjectsUpdate, ProjectsDelete,
+		TasksRead, TasksCreate, TasksUpdate, TasksDelete,
+		WorkflowsRead, WorkflowsUpdate,
+		OrganizationsUpdate,
+		CommentsCreate, CommentsUpdate, CommentsDelete,
+		AttachmentsCreate, AttachmentsDelete,
+		WorklogsCreate, WorklogsUpdate, WorklogsDelete,
+	}
+}
+
+// Scope narrows a permission to some of the resources it is about.
+type Scope string
+
+const (
+	// ScopeAny grants the permission on every resource.
+	ScopeAny Scope = "any"
+	// ScopeSelf grants it on the caller's own user and on what the caller
+	// wrote, uploaded or logged.
+	ScopeSelf Scope = "self"
+	// ScopeManaged grants it on the projects the caller manages or owns
+	// and on their tasks.
+	ScopeManaged Scope = "managed"
+	// ScopeAssigned grants it on the tasks assigned to the caller.
+	ScopeAssigned Scope = "assigned"
+	// ScopeContributed grants it on the projects the caller owns or
+	// contributes to, and on their tasks. Observers are left out.
+	ScopeContributed Scope = "contributed"
+)
+
+// Grants maps permissions to the scopes they are granted in.
+type Grants map[Permission][]Scope
+
+var readAll = Grants{
+	UsersRead:     {ScopeAny},
+	ProjectsRead:  {ScopeAny},
+	TasksRead:     {ScopeAny},
+	WorkflowsRead: {ScopeAny},
+	UsersUpdate:   {ScopeSelf},
+}
+
+var roleGrants = map[domain.Role]Grants{
+	domain.RoleAdmin: grantAll(),
+	domain.RoleManager: merge(readAll, Grants{
+		ProjectsCreate:  {ScopeAny},
+		ProjectsUpdate:  {ScopeManaged},
+		ProjectsDelete:  {ScopeManaged},
+		TasksCreate:     {ScopeManaged, ScopeContributed},
+		TasksUpdate:     {ScopeManaged, ScopeAssigned},
+		TasksDelete:     {ScopeManaged},
+		WorkflowsUpdate: {ScopeManaged},
+		CommentsCreate:  {ScopeAny},
+		CommentsUpdate:  {ScopeSelf},
+		CommentsDelete:  {ScopeSelf, ScopeManaged},
+
+		AttachmentsCreate: {ScopeAny},
+		AttachmentsDelete: {ScopeSelf, ScopeManaged},
+
+		WorklogsCreate: {ScopeAny},
+		WorklogsUpdate: {ScopeSelf, ScopeManaged},
+		WorklogsDelete: {ScopeSelf, ScopeManaged},
+	}),
+	domain.RoleMember: merge(readAll, Grants{
+		TasksCreate:    {ScopeContributed},
+		TasksUpdate:    {ScopeAssigned},
+		CommentsCreate: {ScopeAny},
+		CommentsUpdate: {ScopeSelf},
+		CommentsDelete: {ScopeSelf},
+
+		AttachmentsCreate: {ScopeAny},
+		AttachmentsDelete: {ScopeSelf},
+
+		WorklogsCreate: {ScopeAny},
+		WorklogsUpdate: {ScopeSelf},
+		WorklogsDelete: {ScopeSelf},
+	}),
+	domain.RoleViewer: readAll,
+}
+
+func grantAll() Grants {
+	grants := make(Grants)
+	for _, permission := range Permissions() {
+		grants[permission] = []Scope{ScopeAny}
+	}
+	return grants
+}
+
+func merge(base, extra Grants) Grants {
+	grants := make(Grants, len(base)+len(extra))
+	for permission, scopes := range base {
+		grants[permission] = scopes
+	}
+	for permission, scopes := range extra {
+		grants[permission] = scopes
+	}
+	return grants
+}
+
+// GrantsOf returns what a role may do. Unknown roles may do nothing. The
+// result is shared and must not be modified.
+func GrantsOf(role domain.Role) Grants {
+	grants := roleGrants[role]
+	if grants == nil {
+		return Grants{}
+	}
+	return grants
+}
+
+// Target is the resource an action is about, described by the relations
+// that scopes test. UserID is set for users and to the author of comments,
+// attachments and worklogs; ManagerID and ProjectRole, the caller's role on
+// the project, for projects and what belongs to them; Assignee for tasks.
+type Target struct {
+	UserID      uuid.UUID
+	ManagerID   uuid.UUID
+	ProjectRole domain.ProjectRole
+	Assignee    uuid.UUID
+}
+
+// Allows reports whether user may take permission on target.
+func Allows(user *domain.User, permission Permission, target Target) bool {
+	return GrantsOf(user.Role).allows(user.ID, permission, target)
+}
+
+// Has reports whether user holds permission in any scope, that is whether
+// it may take it on at least some resources.
+func Has(user *domain.User, permission Permission) bool {
+	return len(GrantsOf(user.Role)[permission]) > 0
+}
+
+func (g Grants) allows(userID uuid.UUID, permission Permission, target Target) bool {
+	for _, scope := range g[permission] {
+		switch scope {
+		case ScopeAny:
+			return true
+		case ScopeSelf:
+			if target.UserID == userID {
+				return true
+			}
+		case ScopeManaged:
+			if target.ManagerID == userID || target.ProjectRole == domain.ProjectOwner {
+				return true
+			}
+		case ScopeAssigned:
+			if target.Assignee == userID {
+				return true
+			}
+		case ScopeContributed:
+			if target.ProjectRole.Assignable() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Authorize checks that the caller attached to ctx may take permission on
+// target.
+func Authorize(ctx context.Context, permission Permission, target Target) error {
+	principal := PrincipalFrom(ctx)
+	if principal == nil {
+		return domain.Unauthorized("missing_token", "authentication is required")
+	}
+	if !principal.Grants().allows(principal.User.ID, permission, target) {
+		return principal.forbidden(permission)
+	}
+	return nil
+}
+
+// AuthorizeAny checks that the caller attached to ctx holds permission in
+// some scope. Whether it applies to a particular resource is left to
+// Authorize.
+func AuthorizeAny(ctx context.Context, permission Permission) error {
+	principal := PrincipalFrom(ctx)
+	if principal == nil {
+		return domain.Unauthorized("missing_token", "authentication is required")
+	}
+	if len(principal.Grants()[permission]) == 0 {
+		return principal.forbidden(permission)
+	}
+	return nil
+}
+
+// forbidden explains a denial. Callers using an API token whose role would
+// allow the action are told that the token's scopes are what is missing.
+func (p *Principal) forbidden(permission Permission) error {
+	user := p.User
+	if p.OrganizationID == uuid.Nil {
+		return domain.Forbidden("no_organization", "you do not belong to an organization, create one or ask to be added")
+	}
+	if p.Scopes != nil && !slices.Contains(p.Scopes, permission) && Has(user, permission) {
+		return domain.Forbidden("insufficient_scope", "the API token is not scoped for %s", permission).
+			With("permission", permission).
+			With("token_scopes", p.Scopes)
+	}
+	scopes := slices.Clone(GrantsOf(user.Role)[permission])
+	if len(scopes) == 0 {
+		return domain.Forbidden("forbidden", "role %q does not allow %s", user.Role, permission).
+			With("permission", permission).
+			With("scopes", []Scope{})
+	}
+	return domain.Forbidden("forbidden", "role %q allows %s only in scope %v", user.Role, permission, scopes).
+		With("permission", permission).
+		With("scopes", scopes)
+}
@@ -0,0 +1,190 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/auth"
+	"github.com/yelnar0112/project-management/internal/domain"
+)
+
+var me, someone = uuid.New(), uuid.New()
+
+// targets relate a resource to the caller, me, in each way scopes test.
+var targets = []struct {
+	name   string
+	target auth.Target
+}{
+	{"self", auth.Target{UserID: me, ManagerID: someone, Assignee: someone}},
+	{"managed", auth.Target{UserID: someone, ManagerID: me, Assignee: someone}},
+	{"owned", auth.Target{UserID: someone, ManagerID: someone, ProjectRole: domain.ProjectOwner, Assignee: someone}},
+	{"assigned", auth.Target{UserID: someone, ManagerID: someone, Assignee: me}},
+	{"contributed", auth.Target{UserID: someone, ManagerID: someone, ProjectRole: domain.ProjectContributor,
+		Assignee: someone}},
+	{"observed", auth.Target{UserID: someone, ManagerID: someone, ProjectRole: domain.ProjectObserver,
+		Assignee: someone}},
+	{"unrelated", auth.Target{UserID: someone, ManagerID: someone, Assignee: someone}},
+}
+
+var (
+	everywhere   = []string{"self", "managed", "owned", "assigned", "contributed", "observed", "unrelated"}
+	reads        = []auth.Permission{auth.UsersRead, auth.ProjectsRead, auth.TasksRead, auth.WorkflowsRead}
+	contribution = []auth.Permission{auth.CommentsCreate, auth.AttachmentsCreate, auth.WorklogsCreate}
+)
+
+// allowed lists, for each role, the targets on which it may take each
+// permission; every other permission and target is denied.
+var allowed = map[domain.Role]map[auth.Permission][]string{
+	domain.RoleManager: with(readAll(), map[auth.Permission][]string{
+		auth.ProjectsCreate:    everywhere,
+		auth.ProjectsUpdate:    {"managed", "owned"},
+		auth.ProjectsDelete:    {"managed", "owned"},
+		auth.TasksCreate:       {"managed", "owned", "contributed"},
+		auth.TasksUpdate:       {"managed", "owned", "assigned"},
+		auth.TasksDelete:       {"managed", "owned"},
+		auth.WorkflowsUpdate:   {"managed", "owned"},
+		auth.CommentsUpdate:    {"self"},
+		auth.CommentsDelete:    {"self", "managed", "owned"},
+		auth.AttachmentsDelete: {"self", "managed", "owned"},
+		auth.WorklogsUpdate:    {"self", "managed", "owned"},
+		auth.WorklogsDelete:    {"self", "managed", "owned"},
+	}, contribution),
+	domain.RoleMember: with(readAll(), map[auth.Permission][]string{
+		auth.TasksCreate:       {"owned", "contributed"},
+		auth.TasksUpdate:       {"assigned"},
+		auth.CommentsUpdate:    {"self"},
+		auth.CommentsDelete:    {"self"},
+		auth.AttachmentsDelete: {"self"},
+		auth.WorklogsUpdate:    {"self"},
+		auth.WorklogsDelete:    {"self"},
+	}, contribution),
+	domain.RoleViewer: readAll(),
+	domain.RoleAdmin:  with(nil, nil, auth.Permissions()),
+	"unknown":         {},
+}
+
+// readAll is what every role may do: read everything and update its own
+// user.
+func readAll() map[auth.Permission][]string {
+	return with(map[auth.Permission][]string{auth.UsersUpdate: {"self"}}, nil, reads)
+}
+
+// with merges grants and grants everywhere into base.
+func with(base, grants map[auth.Permission][]string, everywhereFor []auth.Permission) map[auth.Permission][]string {
+	merged := make(map[auth.Permission][]string)
+	for permission, targets := range base {
+		merged[permission] = targets
+	}
+	for permission, targets := range grants {
+		merged[permission] = targets
+	}
+	for _, permission := range everywhereFor {
+		merged[permission] = everywhere
+	}
+	return merged
+}
+
+// TestAllows checks every role against every permission on targets related
+// to the caller in each way, denials included.
+func TestAllows(t *testing.T) {
+	for role, permissions := range allowed {
+		user := &domain.User{ID: me, Role: role}
+		for _, permission := range auth.Permissions() {
+			allowedOn := make(map[string]bool)
+			for _, target := range permissions[permission] {
+				allowedOn[target] = true
+			}
+			for _, target := range targets {
+				t.Run(fmt.Sprintf("%s/%s/%s", role, permission, target.name), func(t *testing.T) {
+					if got, want := auth.Allows(user, permission, target.target), allowedOn[target.name]; got != want {
+						t.Errorf("Allows = %v, want %v", got, want)
+					}
+				})
+			}
+			if got, want := auth.Has(user, permission), len(permissions[permission]) > 0; got != want {
+				t.Errorf("%s: Has(%s) = %v, want %v", role, permission, got, want)
+			}
+		}
+	}
+}
+
+// TestAuthorize checks how callers are refused, and that API tokens narrow
+// their role's grants to their scopes without widening them.
+func TestAuthorize(t *testing.T) {
+	organizationID := uuid.New()
+	member := func(scopes ...auth.Permission) context.Context {
+		return auth.WithPrincipal(context.Background(), &auth.Principal{
+			User:           &domain.User{ID: me, Role: domain.RoleMember},
+			SessionID:      uuid.New(),
+			Scopes:         scopes,
+			OrganizationID: organizationID,
+		})
+	}
+	session := member()
+	token := member(auth.TasksRead, auth.ProjectsDelete)
+	homeless := auth.WithPrincipal(context.Background(), &auth.Principal{
+		User: &domain.User{ID: me}, SessionID: uuid.New()})
+	self := auth.Target{UserID: me}
+	assigned := auth.Target{Assignee: me}
+
+	cases := []struct {
+		name       string
+		ctx        context.Context
+		permission auth.Permission
+		target     auth.Target
+		kind       error
+		code       string
+		scopes     []auth.Scope
+	}{
+		{"allowed", session, auth.TasksUpdate, assigned, nil, "", nil},
+		{"anonymous", context.Background(), auth.TasksRead, self, domain.ErrUnauthorized, "missing_token", nil},
+		{"outside of the scope", session, auth.TasksUpdate, self, domain.ErrForbidden, "forbidden",
+			[]auth.Scope{auth.ScopeAssigned}},
+		{"not granted to the role", session, auth.UsersDelete, self, domain.ErrForbidden, "forbidden",
+			[]auth.Scope{}},
+		{"in no organization", homeless, auth.TasksRead, self, domain.ErrForbidden, "no_organization", nil},
+		{"in the token's scopes", token, auth.TasksRead, self, nil, "", nil},
+		{"out of the token's scopes", token, auth.TasksUpdate, assigned, domain.ErrForbidden, "insufficient_scope", nil},
+		{"in the token's scopes but not the role's", token, auth.ProjectsDelete, self, domain.ErrForbidden,
+			"forbidden", []auth.Scope{}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := auth.Authorize(c.ctx, c.permission, c.target)
+			if c.kind == nil {
+				if err != nil {
+					t.Fatalf("want allowed, got %v", err)
+				}
+				return
+			}
+			var problem *domain.Error
+			if !errors.As(err, &problem) || !errors.Is(err, c.kind) || problem.Code != c.code {
+				t.Fatalf("want a %v error with code %s, got %v", c.kind, c.code, err)
+			}
+			if c.scopes != nil && fmt.Sprint(problem.Fields["scopes"]) != fmt.Sprint(c.scopes) {
+				t.Errorf("want scopes %v, got %v", c.scopes, problem.Fields["scopes"])
+			}
+		})
+	}
+}
+
+// TestAuthorizeAny checks that holding a permission in any scope is enough.
+func TestAuthorizeAny(t *testing.T) {
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{
+		User:           &domain.User{ID: me, Role: domain.RoleMember},
+		SessionID:      uuid.New(),
+		OrganizationID: uuid.New(),
+	})
+	if err := auth.AuthorizeAny(ctx, auth.TasksUpdate); err != nil {
+		t.Errorf("members update the tasks assigned to them, got %v", err)
+	}
+	if err := auth.AuthorizeAny(ctx, auth.ProjectsUpdate); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("members update no projects, got %v", err)
+	}
+	if err := auth.AuthorizeAny(context.Background(), auth.TasksRead); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("anonymous callers hold no permission, got %v", err)
+	}
+}
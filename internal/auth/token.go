@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const issuer = "project-management"
+
+var ErrInvalidToken = errors.New("invalid access token")
+
+// Claims are the contents of an access token. The subject is the user ID.
+type Claims struct {
+	SessionID uuid.UUID `json:"sid"`
+	jwt.RegisteredClaims
+}
+
+// Tokens signs and verifies access tokens with HMAC-SHA256.
+type Tokens struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+func NewTokens(secret []byte, ttl time.Duration) *Tokens {
+	return &Tokens{secret: secret, ttl: ttl}
+}
+
+func (t *Tokens) TTL() time.Duration {
+	return t.ttl
+}
+
+// Issue returns a signed access token for a user's session.
+func (t *Tokens) Issue(userID, sessionID uuid.UUID, now time.Time) (string, error) {
+	claims := Claims{
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			Subject:   userID.String(),
+			ID:        uuid.NewString(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(t.ttl)),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(t.secret)
+}
+
+// Verify checks the signature and expiry of an access token and returns the
+// user and session it was issued for.
+func (t *Tokens) Verify(token string) (userID, sessionID uuid.UUID, err error) {
+	var claims Claims
+	_, err = jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (any, error) {
+		return t.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithIssuer(issuer), jwt.WithExpirationRequired())
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.Join(ErrInvalidToken, err)
+	}
+
+	userID, err = uuid.Parse(claims.Subject)
+	if err != nil || claims.SessionID == uuid.Nil {
+		return uuid.Nil, uuid.Nil, ErrInvalidToken
+	}
+	return userID, claims.SessionID, nil
+}
+
+// NewOpaqueToken returns a random token for the client and the hash under
+// which it is stored.
+func NewOpaqueToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, HashToken(token), nil
+}
+
+// APITokenPrefix starts every API token, which tells them apart from access
+// tokens and makes leaked ones easy to search for.
+const APITokenPrefix = "pmpat_"
+
+// NewAPIToken returns a random API token and the hash under which it is
+// stored.
+func NewAPIToken() (token, hash string, err error) {
+	token, _, err = NewOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	token = APITokenPrefix + token
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the stored form of an opaque token. The tokens carry 256
+// random bits, so a fast hash is enough.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,16 +1,221 @@
 package config
 
 import (
+	"crypto/rand"
 	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
-func LoadConfig() {
+const (
+	// StorageDatabase keeps data in the SQL database configured by DB_*.
+	StorageDatabase = "database"
+	// StorageMemory keeps data in process memory; everything is lost on exit.
+	StorageMemory = "memory"
+)
+
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+const (
+	// BlobLocal keeps attachment contents in files under BlobDir.
+	BlobLocal = "local"
+	// BlobS3 keeps attachment contents in an S3 or S3-compatible bucket.
+	BlobS3 = "s3"
+)
+
+// DefaultAttachmentTypes are accepted for upload unless ATTACHMENT_TYPES
+// says otherwise: images, documents, logs and archives.
+var DefaultAttachmentTypes = []string{
+	"image/png", "image/jpeg", "image/gif", "image/webp",
+	"application/pdf", "text/plain", "text/csv", "text/markdown", "application/json",
+	"application/zip", "application/gzip",
+}
+
+type Config struct {
+	Storage string
+
+	// DBDriver is either DriverPostgres or DriverSQLite. For SQLite, DBName
+	// is the path of the database file and the other DB_* settings are unused.
+	DBDriver   string
+	DBHost     string
+	DBPort     string
+	DBUser     string
+	DBPassword string
+	DBName     string
+	DBSSLMode  string
+
+	// JWTSecret signs access tokens. When it is not set a random secret is
+	// generated, so tokens do not survive a restart.
+	JWTSecret       []byte
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	// AdminEmail and AdminPassword, when set, create an admin user on
+	// startup unless one with that email exists.
+	AdminEmail    string
+	AdminPassword string
+
+	// BlobStorage is either BlobLocal or BlobS3. The S3* settings are only
+	// used by BlobS3; S3Insecure talks to the endpoint over plain HTTP.
+	BlobStorage       string
+	BlobDir           string
+	S3Endpoint        string
+	S3Region          string
+	S3Bucket          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	S3Insecure        bool
+	// AttachmentMaxSize is the largest file accepted for upload, in bytes.
+	AttachmentMaxSize int64
+	// AttachmentTypes are the content types accepted for upload. An entry
+	// such as "image/*" accepts every subtype.
+	AttachmentTypes []string
+
+	// ReminderInterval is how often due dates are checked for reminders.
+	// ReminderLead is how long before its due date a task's assignee is
+	// reminded of it.
+	ReminderInterval time.Duration
+	ReminderLead     time.Duration
+}
+
+func LoadConfig() *Config {
 	err := godotenv.Load()
 	if err != nil {
 		log.Println("No .env file found, using environment variables")
 	} else {
 		log.Println("Configuration loaded from .env file")
 	}
+
+	cfg := &Config{
+		Storage:    getEnv("STORAGE", StorageDatabase),
+		DBDriver:   getEnv("DB_DRIVER", DriverPostgres),
+		DBHost:     os.Getenv("DB_HOST"),
+		DBPort:     os.Getenv("DB_PORT"),
+		DBUser:     os.Getenv("DB_USER"),
+		DBPassword: os.Getenv("DB_PASSWORD"),
+		DBName:     os.Getenv("DB_NAME"),
+		DBSSLMode:  getEnv("DB_SSLMODE", "disable"),
+
+		JWTSecret:       []byte(os.Getenv("JWT_SECRET")),
+		AccessTokenTTL:  getDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL: getDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+		AdminEmail:      os.Getenv("ADMIN_EMAIL"),
+		AdminPassword:   os.Getenv("ADMIN_PASSWORD"),
+
+		BlobStorage:       getEnv("BLOB_STORAGE", BlobLocal),
+		BlobDir:           getEnv("BLOB_DIR", "data/attachments"),
+		S3Endpoint:        os.Getenv("S3_ENDPOINT"),
+		S3Region:          os.Getenv("S3_REGION"),
+		S3Bucket:          os.Getenv("S3_BUCKET"),
+		S3AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+		S3SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		S3Insecure:        getBool("S3_INSECURE"),
+		AttachmentMaxSize: getSize("ATTACHMENT_MAX_SIZE", 25<<20),
+		AttachmentTypes:   getList("ATTACHMENT_TYPES", DefaultAttachmentTypes),
+
+		ReminderInterval: getDuration("REMINDER_INTERVAL", 5*time.Minute),
+		ReminderLead:     getDuration("REMINDER_LEAD", 24*time.Hour),
+	}
+
+	switch cfg.Storage {
+	case StorageDatabase, StorageMemory:
+	default:
+		log.Fatalf("Unknown STORAGE %q, expected %q or %q", cfg.Storage, StorageDatabase, StorageMemory)
+	}
+
+	switch cfg.DBDriver {
+	case DriverPostgres, DriverSQLite:
+	default:
+		log.Fatalf("Unknown DB_DRIVER %q, expected %q or %q", cfg.DBDriver, DriverPostgres, DriverSQLite)
+	}
+	// Without a file name, SQLite would give each connection a temporary
+	// database of its own.
+	if cfg.Storage == StorageDatabase && cfg.DBDriver == DriverSQLite && cfg.DBName == "" {
+		log.Fatal("DB_DRIVER=sqlite requires DB_NAME, the path of the database file")
+	}
+
+	switch cfg.BlobStorage {
+	case BlobLocal:
+	case BlobS3:
+		if cfg.S3Endpoint == "" || cfg.S3Bucket == "" {
+			log.Fatal("BLOB_STORAGE=s3 requires S3_ENDPOINT and S3_BUCKET")
+		}
+	default:
+		log.Fatalf("Unknown BLOB_STORAGE %q, expected %q or %q", cfg.BlobStorage, BlobLocal, BlobS3)
+	}
+
+	if len(cfg.JWTSecret) == 0 {
+		log.Println("JWT_SECRET is not set, using a random secret; tokens will not survive a restart")
+		cfg.JWTSecret = make([]byte, 32)
+		if _, err := rand.Read(cfg.JWTSecret); err != nil {
+			log.Fatalf("Could not generate a JWT secret: %v", err)
+		}
+	} else if len(cfg.JWTSecret) < 32 {
+		log.Fatal("JWT_SECRET must be at least 32 bytes long")
+	}
+
+	return cfg
+}
+
+func getDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Fatalf("Invalid %s %q, expected a positive duration such as 15m", key, value)
+	}
+	return d
+}
+
+func getSize(key string, fallback int64) int64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n <= 0 {
+		log.Fatalf("Invalid %s %q, expected a positive number of bytes", key, value)
+	}
+	return n
+}
+
+func getBool(key string) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Fatalf("Invalid %s %q, expected true or false", key, value)
+	}
+	return b
+}
+
+// getList splits a comma-separated value, dropping empty entries.
+func getList(key string, fallback []string) []string {
+	var list []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, strings.ToLower(item))
+		}
+	}
+	if len(list) == 0 {
+		return fallback
+	}
+	return list
+}
+
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return fallback
 }
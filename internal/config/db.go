@@ -4,32 +4,39 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
+	"net/url"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
 	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
 )
 
-var DB *sql.DB
-
-func ConnectDB() {
-	connStr := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		os.Getenv("DB_HOST"), os.Getenv("DB_PORT"), os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"), os.Getenv("DB_NAME"),
-	)
+// ConnectDB opens the database selected by DB_DRIVER, retrying with
+// exponential backoff until it is reachable.
+func ConnectDB(cfg *Config) *sql.DB {
+	var connStr string
+	switch cfg.DBDriver {
+	case DriverSQLite:
+		connStr = SQLiteDSN(cfg.DBName)
+	default:
+		connStr = fmt.Sprintf(
+			"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+			cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBSSLMode,
+		)
+	}
 
+	var db *sql.DB
 	operation := func() error {
-		db, err := sql.Open("postgres", connStr)
+		conn, err := sql.Open(cfg.DBDriver, connStr)
 		if err != nil {
 			return fmt.Errorf("error opening database: %w", err)
 		}
-		if err = db.Ping(); err != nil {
-			db.Close()
+		if err = conn.Ping(); err != nil {
+			conn.Close()
 			return fmt.Errorf("error connecting to the database: %w", err)
 		}
-		DB = db
+		db = conn
 		return nil
 	}
 
@@ -45,4 +52,18 @@ func ConnectDB() {
 	}
 
 	log.Println("Successfully connected to the database")
+	return db
+}
+
+// SQLiteDSN builds the connection string for the SQLite database file at
+// path, enabling foreign keys and the timestamp format the repositories
+// expect.
+func SQLiteDSN(path string) string {
+	query := url.Values{}
+	query.Add("_pragma", "foreign_keys(1)")
+	query.Add("_pragma", "journal_mode(WAL)")
+	query.Add("_pragma", "busy_timeout(5000)")
+	query.Set("_time_format", "sqlite")
+	query.Set("_txlock", "immediate")
+	return "file:" + path + "?" + query.Encode()
 }
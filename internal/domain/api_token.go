@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIToken is a personal access token that lets scripts act as a user
+// without logging in. Only a hash of the token is kept; the token itself is
+// shown once, when it is created.
+type APIToken struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+	Name   string    `json:"name"`
+	Hash   string    `json:"-"`
+	// Scopes are the permissions the token is limited to, on top of what
+	// the user's role allows.
+	Scopes    []string  `json:"scopes"`
+	CreatedAt time.Time `json:"created_at"`
+	// ExpiresAt is zero for tokens that do not expire.
+	ExpiresAt time.Time `json:"expires_at"`
+	// LastUsedAt is zero until the token is first used.
+	LastUsedAt time.Time `json:"last_used_at"`
+	// RevokedAt is zero while the token is active.
+	RevokedAt time.Time `json:"revoked_at"`
+}
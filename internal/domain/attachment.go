@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Attachment is a file attached to a task or a project. Its content is kept
+// in blob storage under Key; the database only holds what describes it.
+type Attachment struct {
+	ID uuid.UUID `json:"id"`
+	// Exactly one of ProjectID and TaskID is set.
+	ProjectID   uuid.UUID `json:"project_id"`
+	TaskID      uuid.UUID `json:"task_id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	// Checksum is the hex-encoded SHA-256 of the content.
+	Checksum string `json:"checksum"`
+	Key      string `json:"-"`
+	// UploaderID is uuid.Nil once the uploader's account has been deleted.
+	UploaderID uuid.UUID `json:"uploader_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// AttachmentOwner is what files are attached to: a task when TaskID is set,
+// otherwise a project.
+type AttachmentOwner struct {
+	ProjectID uuid.UUID
+	TaskID    uuid.UUID
+}
+
+// Owner returns what the attachment belongs to.
+func (a *Attachment) Owner() AttachmentOwner {
+	return AttachmentOwner{ProjectID: a.ProjectID, TaskID: a.TaskID}
+}
@@ -0,0 +1,176 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// WIPPolicy is what happens when a task enters a column that is at its WIP
+// limit: the move is reported with a warning, or refused.
+type WIPPolicy string
+
+const (
+	WIPWarn   WIPPolicy = "warn"
+	WIPReject WIPPolicy = "reject"
+)
+
+// BoardColumn shows the tasks in any of its workflow states. WIPLimit caps
+// the tasks in the column, 0 meaning no limit.
+type BoardColumn struct {
+	Name      string    `json:"name"`
+	States    []string  `json:"states"`
+	WIPLimit  int       `json:"wip_limit"`
+	WIPPolicy WIPPolicy `json:"wip_policy"`
+}
+
+// Board lays a project's tasks out in columns, left to right. Tasks in
+// states that no column maps are left off the board.
+type Board struct {
+	ProjectID uuid.UUID     `json:"project_id"`
+	Columns   []BoardColumn `json:"columns"`
+}
+
+// MaxBoardColumnNameLength is the longest column name accepted, in
+// characters.
+const MaxBoardColumnNameLength = 50
+
+// DefaultBoard is used by projects that have not set up their own: one
+// column per state of their workflow, without limits.
+func DefaultBoard(workflow *Workflow) *Board {
+	board := &Board{ProjectID: workflow.ProjectID, Columns: []BoardColumn{}}
+	for _, state := range workflow.States {
+		board.Columns = append(board.Columns, BoardColumn{
+			Name:      state.Name,
+			States:    []string{state.Name},
+			WIPPolicy: WIPWarn,
+		})
+	}
+	return board
+}
+
+// Validate trims the column names, warns by default and checks the columns
+// against the workflow of the board's project: each maps states of it, and
+// no state is in two columns.
+func (b *Board) Validate(workflow *Workflow) error {
+	if len(b.Columns) == 0 {
+		return errors.New("board must have at least one column")
+	}
+	names := make(map[string]bool, len(b.Columns))
+	mapped := make(map[string]string)
+	for i := range b.Columns {
+		column := &b.Columns[i]
+		column.Name = strings.TrimSpace(column.Name)
+		if column.WIPPolicy == "" {
+			column.WIPPolicy = WIPWarn
+		}
+		switch {
+		case column.Name == "":
+			return errors.New("board column names must not be empty")
+		case utf8.RuneCountInString(column.Name) > MaxBoardColumnNameLength:
+			return fmt.Errorf("board column names must be at most %d characters", MaxBoardColumnNameLength)
+		case names[strings.ToLower(column.Name)]:
+			return fmt.Errorf("board column %q is defined twice", column.Name)
+		case len(column.States) == 0:
+			return fmt.Errorf("board column %q must map at least one state", column.Name)
+		case column.WIPLimit < 0:
+			return fmt.Errorf("board column %q has a negative WIP limit", column.Name)
+		case column.WIPPolicy != WIPWarn && column.WIPPolicy != WIPReject:
+			return fmt.Errorf("board column %q has WIP policy %q, expected %q or %q",
+				column.Name, column.WIPPolicy, WIPWarn, WIPReject)
+		}
+		names[strings.ToLower(column.Name)] = true
+		for _, state := range column.States {
+			if !workflow.HasState(state) {
+				return fmt.Errorf("board column %q maps %q, which is not a workflow state", column.Name, state)
+			}
+			if other, ok := mapped[state]; ok {
+				return fmt.Errorf("state %q is mapped by both column %q and column %q", state, other, column.Name)
+			}
+			mapped[state] = column.Name
+		}
+	}
+	return nil
+}
+
+// Column returns the column with the given name, in any letter case, or nil.
+func (b *Board) Column(name string) *BoardColumn {
+	for i := range b.Columns {
+		if strings.EqualFold(b.Columns[i].Name, strings.TrimSpace(name)) {
+			return &b.Columns[i]
+		}
+	}
+	return nil
+}
+
+// ColumnOf returns the column that maps state, or nil.
+func (b *Board) ColumnOf(state string) *BoardColumn {
+	for i := range b.Columns {
+		if b.Columns[i].Maps(state) {
+			return &b.Columns[i]
+		}
+	}
+	return nil
+}
+
+func (c *BoardColumn) Maps(state string) bool {
+	for _, s := range c.States {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+// BoardView is a board with the tasks in each of its columns.
+type BoardView struct {
+	ProjectID uuid.UUID         `json:"project_id"`
+	Columns   []BoardColumnView `json:"columns"`
+}
+
+// BoardColumnView holds a column's tasks in board order. OverLimit tells
+// whether they are more than its WIP limit.
+type BoardColumnView struct {
+	BoardColumn
+	Count     int    `json:"count"`
+	OverLimit bool   `json:"over_limit"`
+	Tasks     []Task `json:"tasks"`
+}
+
+// WIPWarning reports a task moved into a column with a "warn" WIP policy
+// that now holds more tasks than its limit.
+type WIPWarning struct {
+	Code     string `json:"code"`
+	Column   string `json:"column"`
+	WIPLimit int    `json:"wip_limit"`
+	Count    int    `json:"count"`
+}
+
+// TaskMove is a task moved on its project's board, with the warnings the
+// move raised.
+type TaskMove struct {
+	Task     Task         `json:"task"`
+	Warnings []WIPWarning `json:"warnings"`
+}
+
+// SortBoard puts tasks in board order: by rank, then, for tasks that have
+// none yet, below all others by creation.
+func SortBoard(tasks []Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := &tasks[i], &tasks[j]
+		if (a.Rank == "") != (b.Rank == "") {
+			return b.Rank == ""
+		}
+		if a.Rank != b.Rank {
+			return a.Rank < b.Rank
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID.String() < b.ID.String()
+	})
+}
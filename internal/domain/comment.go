@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Comment is part of the discussion on a task, written in Markdown. Threads
+// are one level deep: ParentID names the comment that started the thread,
+// and is uuid.Nil for that comment itself.
+type Comment struct {
+	ID       uuid.UUID `json:"id"`
+	TaskID   uuid.UUID `json:"task_id"`
+	ParentID uuid.UUID `json:"parent_id"`
+	// AuthorID is uuid.Nil once the author's account has been deleted.
+	AuthorID uuid.UUID `json:"author_id"`
+	Body     string    `json:"body"`
+	// HTML is Body rendered and sanitized, ready to be shown.
+	HTML string `json:"html"`
+	// Mentions are the users mentioned in Body, ordered by ID.
+	Mentions  []uuid.UUID `json:"mentions"`
+	CreatedAt time.Time   `json:"created_at"`
+	// EditedAt is zero for comments that were never edited.
+	EditedAt time.Time `json:"edited_at"`
+	// DeletedAt is zero until the comment is deleted. Deleted comments
+	// keep their place in the thread with an empty body.
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// CommentRevision is an earlier version of a comment: the body EditorID
+// replaced at EditedAt.
+type CommentRevision struct {
+	ID        uuid.UUID `json:"id"`
+	CommentID uuid.UUID `json:"comment_id"`
+	Body      string    `json:"body"`
+	// EditorID is uuid.Nil once the editor's account has been deleted.
+	EditorID uuid.UUID `json:"editor_id"`
+	EditedAt time.Time `json:"edited_at"`
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Dependency records that a task cannot be completed before its blocker
+// is. Both tasks belong to the same organization but may be in different
+// projects.
+type Dependency struct {
+	TaskID    uuid.UUID `json:"task_id"`
+	BlockerID uuid.UUID `json:"blocker_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DependencyGraph is everything a task waits on and everything waiting on
+// it, directly or through other tasks.
+type DependencyGraph struct {
+	TaskID uuid.UUID `json:"task_id"`
+	// BlockedBy and Blocks list the tasks linked to the task directly.
+	BlockedBy []uuid.UUID `json:"blocked_by"`
+	Blocks    []uuid.UUID `json:"blocks"`
+	// OpenBlockers are the tasks it waits on, directly or not, that are not
+	// in a terminal state yet, which keep the task from being completed.
+	OpenBlockers []uuid.UUID `json:"open_blockers"`
+	// Tasks are the other tasks of the graph.
+	Tasks        []Task       `json:"tasks"`
+	Dependencies []Dependency `json:"dependencies"`
+}
@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The kinds of Error. Callers test for them with errors.Is.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrValidation   = errors.New("validation failed")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrTooLarge     = errors.New("too large")
+	ErrUnsupported  = errors.New("unsupported media type")
+)
+
+// Error is an error that clients are expected to handle. Code is a stable,
+// machine-readable identifier such as "task_not_found"; Message is meant for
+// people and may change. Fields carries any further data worth reporting,
+// such as the states a task may move to.
+type Error struct {
+	Kind    error
+	Code    string
+	Message string
+	Fields  map[string]any
+	cause   error
+}
+
+func newError(kind error, code, format string, args ...any) *Error {
+	return &Error{Kind: kind, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func NotFound(code, format string, args ...any) *Error {
+	return newError(ErrNotFound, code, format, args...)
+}
+
+func Conflict(code, format string, args ...any) *Error {
+	return newError(ErrConflict, code, format, args...)
+}
+
+func Validation(code, format string, args ...any) *Error {
+	return newError(ErrValidation, code, format, args...)
+}
+
+func Unauthorized(code, format string, args ...any) *Error {
+	return newError(ErrUnauthorized, code, format, args...)
+}
+
+func Forbidden(code, format string, args ...any) *Error {
+	return newError(ErrForbidden, code, format, args...)
+}
+
+func TooLarge(code, format string, args ...any) *Error {
+	return newError(ErrTooLarge, code, format, args...)
+}
+
+func Unsupported(code, format string, args ...any) *Error {
+	return newError(ErrUnsupported, code, format, args...)
+}
+
+// Wrap records the error that caused e, so that errors.Is also matches it.
+func (e *Error) Wrap(cause error) *Error {
+	e.cause = cause
+	return e
+}
+
+// With adds a field to report along with e.
+func (e *Error) With(key string, value any) *Error {
+	if e.Fields == nil {
+		e.Fields = make(map[string]any)
+	}
+	e.Fields[key] = value
+	return e
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() []error {
+	if e.cause == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.cause}
+}
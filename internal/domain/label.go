@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Label categorizes tasks. Each project keeps its own catalog of labels,
+// whose names are unique in it regardless of letter case.
+type Label struct {
+	ID        uuid.UUID `json:"id"`
+	ProjectID uuid.UUID `json:"project_id"`
+	Name      string    `json:"name"`
+	// Color is a hex RGB color such as "#d73a4a".
+	Color       string    `json:"color"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// DefaultLabelColor is given to labels created without a color.
+const DefaultLabelColor = "#6b7280"
+
+// MaxLabelNameLength is the longest label name accepted, in characters.
+const MaxLabelNameLength = 50
+
+var labelColor = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+// Normalize trims the label's name, lowercases its color and checks both.
+// Names cannot contain commas, which separate them in task filters.
+func (l *Label) Normalize() error {
+	l.Name = strings.TrimSpace(l.Name)
+	l.Color = strings.ToLower(strings.TrimSpace(l.Color))
+	switch {
+	case l.Name == "":
+		return errors.New("label name must not be empty")
+	case utf8.RuneCountInString(l.Name) > MaxLabelNameLength:
+		return fmt.Errorf("label name must be at most %d characters", MaxLabelNameLength)
+	case strings.Contains(l.Name, ","):
+		return errors.New("label name must not contain commas")
+	case !labelColor.MatchString(l.Color):
+		return fmt.Errorf("label color %q is not a hex color such as #d73a4a", l.Color)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProjectRole is what a member does on a project.
+type ProjectRole string
+
+const (
+	// ProjectOwner runs the project alongside its manager.
+	ProjectOwner ProjectRole = "owner"
+	// ProjectContributor works on the project's tasks.
+	ProjectContributor ProjectRole = "contributor"
+	// ProjectObserver follows the project but is not assigned tasks.
+	ProjectObserver ProjectRole = "observer"
+)
+
+// DefaultProjectRole is given to members added without one.
+const DefaultProjectRole = ProjectContributor
+
+// ProjectRoles lists every project role from most to least involved.
+func ProjectRoles() []ProjectRole {
+	return []ProjectRole{ProjectOwner, ProjectContributor, ProjectObserver}
+}
+
+// ParseProjectRole accepts a project role name in any letter case.
+func ParseProjectRole(s string) (ProjectRole, error) {
+	r := ProjectRole(strings.ToLower(strings.TrimSpace(s)))
+	if !r.Valid() {
+		names := make([]string, 0, len(ProjectRoles()))
+		for _, known := range ProjectRoles() {
+			names = append(names, string(known))
+		}
+		return "", fmt.Errorf("unknown project role %q, expected one of: %s", s, strings.Join(names, ", "))
+	}
+	return r, nil
+}
+
+func (r ProjectRole) Valid() bool {
+	for _, known := range ProjectRoles() {
+		if r == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Assignable reports whether members with the role may be assigned tasks.
+func (r ProjectRole) Assignable() bool {
+	return r == ProjectOwner || r == ProjectContributor
+}
+
+// Member is a user's place on a project.
+type Member struct {
+	ProjectID uuid.UUID   `json:"project_id"`
+	UserID    uuid.UUID   `json:"user_id"`
+	Role      ProjectRole `json:"role"`
+	JoinedAt  time.Time   `json:"joined_at"`
+}
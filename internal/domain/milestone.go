@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Milestone is a checkpoint of a project that the tasks linked to it are to
+// be completed by.
+type Milestone struct {
+	ID          uuid.UUID `json:"id"`
+	ProjectID   uuid.UUID `json:"project_id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	TargetDate  time.Time `json:"target_date"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// MaxMilestoneTitleLength and MaxMilestoneDescriptionLength are the longest
+// milestone title and description accepted, in characters.
+const (
+	MaxMilestoneTitleLength       = 200
+	MaxMilestoneDescriptionLength = 2000
+)
+
+// Normalize trims the milestone's title and description, keeps its target
+// date in UTC and checks them.
+func (m *Milestone) Normalize() error {
+	m.Title = strings.TrimSpace(m.Title)
+	m.Description = strings.TrimSpace(m.Description)
+	m.TargetDate = m.TargetDate.UTC()
+	switch {
+	case m.Title == "":
+		return errors.New("milestone title must not be empty")
+	case utf8.RuneCountInString(m.Title) > MaxMilestoneTitleLength:
+		return fmt.Errorf("milestone title must be at most %d characters", MaxMilestoneTitleLength)
+	case utf8.RuneCountInString(m.Description) > MaxMilestoneDescriptionLength:
+		return fmt.Errorf("milestone description must be at most %d characters", MaxMilestoneDescriptionLength)
+	case m.TargetDate.IsZero():
+		return errors.New("target_date is required")
+	}
+	return nil
+}
+
+// MilestoneStatus tells how a milestone is doing.
+type MilestoneStatus string
+
+const (
+	MilestoneOnTrack   MilestoneStatus = "on_track"
+	MilestoneAtRisk    MilestoneStatus = "at_risk"
+	MilestoneOverdue   MilestoneStatus = "overdue"
+	MilestoneCompleted MilestoneStatus = "completed"
+)
+
+// MilestoneProgress is a milestone with the progress of its tasks.
+type MilestoneProgress struct {
+	Milestone
+	Progress Progress `json:"progress"`
+	// Elapsed is the part of the time from the milestone's creation to its
+	// target date that has passed, in percent, rounded down and at most 100.
+	Elapsed int             `json:"elapsed"`
+	Status  MilestoneStatus `json:"status"`
+}
+
+// Track tells how a milestone is doing at now, from the number of its
+// tasks and of those still open. A milestone is completed once it has
+// tasks and all of them are, and overdue when its target date passes before
+// that. Until then, it is at risk when the part of its tasks completed is
+// smaller than the part of its time elapsed, both in whole percents.
+func (m *Milestone) Track(total, open int, now time.Time) MilestoneProgress {
+	progress := MilestoneProgress{
+		Milestone: *m,
+		Progress:  Progress{Total: total, Completed: total - open},
+		Status:    MilestoneOnTrack,
+	}
+	if total > 0 {
+		progress.Progress.Percent = progress.Progress.Completed * 100 / total
+	}
+
+	span, left := m.TargetDate.Sub(m.CreatedAt), m.TargetDate.Sub(now)
+	switch {
+	case left <= 0 || span <= 0:
+		progress.Elapsed = 100
+	case left < span:
+		progress.Elapsed = int(float64(span-left) * 100 / float64(span))
+	}
+
+	switch {
+	case total > 0 && open == 0:
+		progress.Status = MilestoneCompleted
+	case left <= 0:
+		progress.Status = MilestoneOverdue
+	case progress.Progress.Percent < progress.Elapsed:
+		progress.Status = MilestoneAtRisk
+	}
+	return progress
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationKind tells what a notification is about.
+type NotificationKind string
+
+const (
+	// NotificationDueSoon reminds the assignee of a task that is due soon.
+	NotificationDueSoon NotificationKind = "task_due_soon"
+	// NotificationOverdue tells the assignee that a task is past its due
+	// date.
+	NotificationOverdue NotificationKind = "task_overdue"
+)
+
+// Notification tells a user about one of its tasks. Tasks without an
+// assignee notify the manager of their project.
+type Notification struct {
+	ID     uuid.UUID        `json:"id"`
+	UserID uuid.UUID        `json:"user_id"`
+	TaskID uuid.UUID        `json:"task_id"`
+	Kind   NotificationKind `json:"kind"`
+	// DueDate is the due date of the task the notification was sent for.
+	DueDate   time.Time `json:"due_date"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+	// ReadAt is zero until the user marks the notification as read.
+	ReadAt time.Time `json:"read_at"`
+}
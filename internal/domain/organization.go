@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Organization is a tenant: one team's users, projects and tasks, kept
+// apart from those of every other organization.
+type Organization struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OrganizationMember is a user's place in an organization. Users may belong
+// to several organizations, with a different role in each.
+type OrganizationMember struct {
+	OrganizationID uuid.UUID `json:"organization_id"`
+	UserID         uuid.UUID `json:"user_id"`
+	Role           Role      `json:"role"`
+	JoinedAt       time.Time `json:"joined_at"`
+}
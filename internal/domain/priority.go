@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Priority is how urgent a task is. Priorities are ordered by Rank, from
+// PriorityLowest to PriorityCritical.
+type Priority string
+
+const (
+	PriorityLowest   Priority = "lowest"
+	PriorityLow      Priority = "low"
+	PriorityMedium   Priority = "medium"
+	PriorityHigh     Priority = "high"
+	PriorityCritical Priority = "critical"
+)
+
+// DefaultPriority is given to tasks created without one.
+const DefaultPriority = PriorityMedium
+
+var priorityRanks = map[Priority]int{
+	PriorityLowest:   1,
+	PriorityLow:      2,
+	PriorityMedium:   3,
+	PriorityHigh:     4,
+	PriorityCritical: 5,
+}
+
+// Priorities lists every priority from least to most urgent.
+func Priorities() []Priority {
+	return []Priority{PriorityLowest, PriorityLow, PriorityMedium, PriorityHigh, PriorityCritical}
+}
+
+// ParsePriority accepts a priority name in any letter case.
+func ParsePriority(s string) (Priority, error) {
+	p := Priority(strings.ToLower(strings.TrimSpace(s)))
+	if !p.Valid() {
+		names := make([]string, 0, len(priorityRanks))
+		for _, known := range Priorities() {
+			names = append(names, string(known))
+		}
+		return "", fmt.Errorf("unknown priority %q, expected one of: %s", s, strings.Join(names, ", "))
+	}
+	return p, nil
+}
+
+func (p Priority) Valid() bool {
+	_, ok := priorityRanks[p]
+	return ok
+}
+
+// Rank orders priorities numerically; higher is more urgent. Unknown
+// priorities rank 0.
+func (p Priority) Rank() int {
+	return priorityRanks[p]
+}
@@ -13,4 +13,7 @@ type Entity struct {
 	StartDate   time.Time `json:"start_date"`
 	EndDate     time.Time `json:"end_date"`
 	ManagerID   uuid.UUID `json:"manager_id"`
+	// OrganizationID is set from the caller's organization when the project
+	// is created and never changes.
+	OrganizationID uuid.UUID `json:"organization_id"`
 }
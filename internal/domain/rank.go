@@ -0,0 +1,102 @@
+package domain
+
+import "strings"
+
+// Ranks order tasks on a board. They are strings of base-36 digits without
+// trailing zeros and sort as strings, so there always is another rank
+// between two of them: moving a task mostly changes only its own rank.
+
+const rankDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// MaxRankLength is the longest rank accepted. Ranks get longer as tasks are
+// moved between the same two; once there is no room left, the column is
+// ranked anew.
+const MaxRankLength = 32
+
+// ValidRank tells whether rank is a rank.
+func ValidRank(rank string) bool {
+	if rank == "" || len(rank) > MaxRankLength || rank[len(rank)-1] == '0' {
+		return false
+	}
+	for i := 0; i < len(rank); i++ {
+		if strings.IndexByte(rankDigits, rank[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// RankBetween returns a rank that sorts after a and before b. An empty a
+// or b leaves that side open. It fails when a is not before b, or when
+// the rank would be longer than MaxRankLength.
+func RankBetween(a, b string) (string, bool) {
+	if a != "" && !ValidRank(a) || b != "" && !ValidRank(b) || a != "" && b != "" && a >= b {
+		return "", false
+	}
+	rank := midRank(a, b)
+	return rank, len(rank) <= MaxRankLength
+}
+
+// midRank returns a rank between a and b, which sort in that order, b being
+// open when empty.
+func midRank(a, b string) string {
+	if b != "" {
+		// Keep the prefix that a, padded with zeros, shares with b.
+		n := 0
+		for n < len(b) && rankDigit(a, n) == strings.IndexByte(rankDigits, b[n]) {
+			n++
+		}
+		if n > 0 {
+			return b[:n] + midRank(rankTail(a, n), b[n:])
+		}
+	}
+	low, high := rankDigit(a, 0), len(rankDigits)
+	if b != "" {
+		high = strings.IndexByte(rankDigits, b[0])
+	}
+	if high-low > 1 {
+		return string(rankDigits[(low+high)/2])
+	}
+	// The first digits are adjacent: b's first digit alone sorts between
+	// them when b goes on, or else a's is extended.
+	if len(b) > 1 {
+		return b[:1]
+	}
+	return string(rankDigits[low]) + midRank(rankTail(a, 1), "")
+}
+
+// rankDigit returns the value of the i-th digit of rank, 0 past its end.
+func rankDigit(rank string, i int) int {
+	if i >= len(rank) {
+		return 0
+	}
+	return strings.IndexByte(rankDigits, rank[i])
+}
+
+func rankTail(rank string, i int) string {
+	if i >= len(rank) {
+		return ""
+	}
+	return rank[i:]
+}
+
+// SpreadRanks returns n ranks in ascending order, evenly spaced so that
+// tasks can be moved between them for long before one gets long.
+func SpreadRanks(n int) []string {
+	width, space := 1, int64(len(rankDigits))
+	for space < 2*int64(n+1) {
+		width++
+		space *= int64(len(rankDigits))
+	}
+	ranks := make([]string, n)
+	for i := range ranks {
+		value := int64(i+1) * space / int64(n+1)
+		digits := make([]byte, width)
+		for j := width - 1; j >= 0; j-- {
+			digits[j] = rankDigits[value%int64(len(rankDigits))]
+			value /= int64(len(rankDigits))
+		}
+		ranks[i] = strings.TrimRight(string(digits), "0")
+	}
+	return ranks
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Role decides what a user may do; see package auth for the permissions of
+// each role.
+type Role string
+
+const (
+	RoleAdmin   Role = "admin"
+	RoleManager Role = "manager"
+	RoleMember  Role = "member"
+	RoleViewer  Role = "viewer"
+)
+
+// DefaultRole is given to users created without one.
+const DefaultRole = RoleMember
+
+// Roles lists every role from most to least privileged.
+func Roles() []Role {
+	return []Role{RoleAdmin, RoleManager, RoleMember, RoleViewer}
+}
+
+// ParseRole accepts a role name in any letter case.
+func ParseRole(s string) (Role, error) {
+	r := Role(strings.ToLower(strings.TrimSpace(s)))
+	if !r.Valid() {
+		names := make([]string, 0, len(Roles()))
+		for _, known := range Roles() {
+			names = append(names, string(known))
+		}
+		return "", fmt.Errorf("unknown role %q, expected one of: %s", s, strings.Join(names, ", "))
+	}
+	return r, nil
+}
+
+func (r Role) Valid() bool {
+	for _, known := range Roles() {
+		if r == known {
+			return true
+		}
+	}
+	return false
+}
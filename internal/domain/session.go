@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session is one login of a user. Access tokens name the session they were
+// issued for, so revoking it signs the user out everywhere the session's
+// tokens are held.
+type Session struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	CreatedAt time.Time
+	// RevokedAt is zero while the session is active.
+	RevokedAt time.Time
+}
+
+// RefreshToken is a single-use token that renews a session. Only a hash of
+// the token is kept.
+type RefreshToken struct {
+	Hash      string
+	SessionID uuid.UUID
+	ExpiresAt time.Time
+	// UsedAt is zero until the token has been exchanged for a new one.
+	UsedAt time.Time
+}
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// SprintState is where a sprint is in its life: planned sprints are
+// started, at most one per project at a time, and then closed.
+type SprintState string
+
+const (
+	SprintPlanned SprintState = "planned"
+	SprintActive  SprintState = "active"
+	SprintClosed  SprintState = "closed"
+)
+
+// Sprint is a time box in which a project's team works through the tasks
+// put in it. Tasks in no sprint are in their project's backlog.
+type Sprint struct {
+	ID        uuid.UUID   `json:"id"`
+	ProjectID uuid.UUID   `json:"project_id"`
+	Name      string      `json:"name"`
+	Goal      string      `json:"goal"`
+	StartDate time.Time   `json:"start_date"`
+	EndDate   time.Time   `json:"end_date"`
+	State     SprintState `json:"state"`
+	// Committed is the work in the sprint when it started.
+	Committed SprintWork `json:"committed"`
+	// Completed and Unfinished are the work completed in the sprint and
+	// rolled over out of it when it closed.
+	Completed  SprintWork `json:"completed"`
+	Unfinished SprintWork `json:"unfinished"`
+	// RolledOverTo is the sprint the unfinished tasks moved to, uuid.Nil
+	// when they went to the backlog.
+	RolledOverTo uuid.UUID `json:"rolled_over_to"`
+	// StartedAt and ClosedAt are zero until the sprint starts and closes.
+	StartedAt time.Time `json:"started_at"`
+	ClosedAt  time.Time `json:"closed_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SprintWork counts tasks and totals their original estimates, in seconds.
+type SprintWork struct {
+	Tasks    int   `json:"tasks"`
+	Estimate int64 `json:"estimate"`
+}
+
+// Add counts a task in the work.
+func (w *SprintWork) Add(task *Task) {
+	w.Tasks++
+	w.Estimate += task.OriginalEstimate
+}
+
+// MaxSprintNameLength and MaxSprintGoalLength are the longest sprint name
+// and goal accepted, in characters.
+const (
+	MaxSprintNameLength = 100
+	MaxSprintGoalLength = 2000
+)
+
+// Normalize trims the sprint's name and goal, keeps its dates in UTC and
+// checks them.
+func (s *Sprint) Normalize() error {
+	s.Name = strings.TrimSpace(s.Name)
+	s.Goal = strings.TrimSpace(s.Goal)
+	s.StartDate = s.StartDate.UTC()
+	s.EndDate = s.EndDate.UTC()
+	switch {
+	case s.Name == "":
+		return errors.New("sprint name must not be empty")
+	case utf8.RuneCountInString(s.Name) > MaxSprintNameLength:
+		return fmt.Errorf("sprint name must be at most %d characters", MaxSprintNameLength)
+	case utf8.RuneCountInString(s.Goal) > MaxSprintGoalLength:
+		return fmt.Errorf("sprint goal must be at most %d characters", MaxSprintGoalLength)
+	case s.StartDate.IsZero() || s.EndDate.IsZero():
+		return errors.New("start_date and end_date are required")
+	case !s.EndDate.After(s.StartDate):
+		return errors.New("end_date must be after start_date")
+	}
+	return nil
+}
+
+// SprintReport compares the work committed to a sprint with the work
+// completed in it. Until the sprint starts, the work in it counts as
+// committed; until it closes, Completed and Unfinished are of the tasks in
+// it now.
+type SprintReport struct {
+	Sprint     Sprint     `json:"sprint"`
+	Committed  SprintWork `json:"committed"`
+	Completed  SprintWork `json:"completed"`
+	Unfinished SprintWork `json:"unfinished"`
+	// Percent is the part of the committed estimate that was completed, or
+	// of the committed tasks when none was estimated, rounded down. Work
+	// added to a started sprint can take it over 100.
+	Percent int `json:"percent"`
+	// Tasks are those in the sprint, ordered by creation. Once it closed,
+	// only the completed ones are left.
+	Tasks []Task `json:"tasks"`
+}
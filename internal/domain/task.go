@@ -10,10 +10,56 @@ type Task struct {
 	ID          uuid.UUID `json:"id"`
 	Title       string    `json:"title"`
 	Description string    `json:"description"`
-	Priority    string    `json:"priority"`
+	Priority    Priority  `json:"priority"`
 	State       string    `json:"state"`
 	Assignee    uuid.UUID `json:"assignee"`
 	ProjectID   uuid.UUID `json:"project_id"`
-	CreatedAt   time.Time `json:"created_at"`
-	CompletedAt time.Time `json:"completed_at"`
+	// ParentID is the task this one is a subtask of, in the same project.
+	ParentID uuid.UUID `json:"parent_id"`
+	// SprintID is the sprint of the task's project it is planned in, or
+	// uuid.Nil for tasks in the backlog.
+	SprintID uuid.UUID `json:"sprint_id"`
+	// MilestoneID is the milestone of the task's project it counts toward,
+	// or uuid.Nil.
+	MilestoneID uuid.UUID `json:"milestone_id"`
+	// Rank orders the task in its column of the project's board; tasks
+	// that were never moved there have none. Updating a task without a
+	// rank keeps the one it has.
+	Rank string `json:"rank"`
+	// Labels are from the catalog of the task's project, ordered by name.
+	// Updating a task without labels keeps the ones it has.
+	Labels []uuid.UUID `json:"labels"`
+	// StartDate and DueDate are optional and fall within the dates of the
+	// task's project.
+	StartDate time.Time `json:"start_date"`
+	DueDate   time.Time `json:"due_date"`
+	// OriginalEstimate and RemainingEstimate are in seconds, 0 when the
+	// task has not been estimated.
+	OriginalEstimate  int64     `json:"original_estimate"`
+	RemainingEstimate int64     `json:"remaining_estimate"`
+	CreatedAt         time.Time `json:"created_at"`
+	CompletedAt       time.Time `json:"completed_at"`
+	// OrganizationID is that of the task's project.
+	OrganizationID uuid.UUID `json:"organization_id"`
+}
+
+// Overdue tells whether the task is still open past its due date.
+func (t *Task) Overdue(now time.Time) bool {
+	return !t.DueDate.IsZero() && t.CompletedAt.IsZero() && t.DueDate.Before(now)
+}
+
+// TaskTree is a task with its subtasks, at any depth.
+type TaskTree struct {
+	Task
+	// Progress counts the subtasks below the task, at any depth.
+	Progress Progress   `json:"progress"`
+	Subtasks []TaskTree `json:"subtasks"`
+}
+
+// Progress tells how many of a set of tasks are complete. Percent is
+// rounded down, and 0 when there are no tasks.
+type Progress struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	Percent   int `json:"percent"`
 }
@@ -1,15 +1,58 @@
 package domain
 
 import (
+	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type User struct {
-	ID           uuid.UUID `json:"id"`
-	FullName     string    `json:"full_name"`
-	Email        string    `json:"email"`
+	ID       uuid.UUID `json:"id"`
+	FullName string    `json:"full_name"`
+	Email    string    `json:"email"`
+	// Handle names the user in mentions, as "@ada". It is unique and
+	// lowercase, and empty for users who have none.
+	Handle       string    `json:"handle"`
 	Registration time.Time `json:"registration"`
-	Role         string    `json:"role"`
+	// Role is the user's role in the organization it was loaded for, and
+	// empty when it was loaded without one.
+	Role Role `json:"role"`
+	// PasswordHash is empty for users who cannot log in.
+	PasswordHash string `json:"-"`
+}
+
+// MaxHandleLength is the longest handle accepted, in characters.
+const MaxHandleLength = 39
+
+// handlePattern allows letters, digits, ".", "_" and "-" in a handle, which
+// starts and ends with a letter or digit.
+var handlePattern = regexp.MustCompile(`^[a-z0-9](?:[a-z0-9._-]*[a-z0-9])?$`)
+
+// NormalizeHandle lowercases the user's handle and checks it.
+func (u *User) NormalizeHandle() error {
+	u.Handle = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(u.Handle), "@"))
+	switch {
+	case u.Handle == "":
+		return nil
+	case len(u.Handle) > MaxHandleLength:
+		return fmt.Errorf("handle must be at most %d characters", MaxHandleLength)
+	case !handlePattern.MatchString(u.Handle):
+		return fmt.Errorf("handle %q may only hold letters, digits, \".\", \"_\" and \"-\", and must start and end with a letter or digit", u.Handle)
+	}
+	return nil
+}
+
+// DefaultHandle returns the handle a user with email gets unless it picks
+// one: the part of the email before the "@", when that makes a valid
+// handle, or else "".
+func DefaultHandle(email string) string {
+	local, _, ok := strings.Cut(email, "@")
+	user := User{Handle: local}
+	if !ok || user.NormalizeHandle() != nil {
+		return ""
+	}
+	return user.Handle
 }
@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+type WorkflowState struct {
+	Name     string `json:"name"`
+	Terminal bool   `json:"terminal"`
+}
+
+type Transition struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Workflow lists the states a project's tasks can be in and which moves
+// between them are allowed. Tasks entering a terminal state are completed.
+type Workflow struct {
+	ProjectID    uuid.UUID       `json:"project_id"`
+	InitialState string          `json:"initial_state"`
+	States       []WorkflowState `json:"states"`
+	Transitions  []Transition    `json:"transitions"`
+}
+
+// DefaultWorkflow is used by projects that have not defined their own:
+// todo → in_progress → review → done, with the ability to step back.
+func DefaultWorkflow(projectID uuid.UUID) *Workflow {
+	return &Workflow{
+		ProjectID:    projectID,
+		InitialState: "todo",
+		States: []WorkflowState{
+			{Name: "todo"},
+			{Name: "in_progress"},
+			{Name: "review"},
+			{Name: "done", Terminal: true},
+		},
+		Transitions: []Transition{
+			{From: "todo", To: "in_progress"},
+			{From: "in_progress", To: "todo"},
+			{From: "in_progress", To: "review"},
+			{From: "review", To: "in_progress"},
+			{From: "review", To: "done"},
+			{From: "done", To: "in_progress"},
+		},
+	}
+}
+
+func (w *Workflow) Validate() error {
+	if len(w.States) == 0 {
+		return errors.New("workflow must define at least one state")
+	}
+
+	seen := make(map[string]bool, len(w.States))
+	for _, state := range w.States {
+		if state.Name == "" {
+			return errors.New("workflow state names must not be empty")
+		}
+		if seen[state.Name] {
+			return fmt.Errorf("workflow state %q is defined twice", state.Name)
+		}
+		seen[state.Name] = true
+	}
+
+	if !seen[w.InitialState] {
+		return fmt.Errorf("initial state %q is not a workflow state", w.InitialState)
+	}
+	for _, t := range w.Transitions {
+		if !seen[t.From] || !seen[t.To] {
+			return fmt.Errorf("transition %q → %q refers to an unknown state", t.From, t.To)
+		}
+		if t.From == t.To {
+			return fmt.Errorf("transition %q → %q does not change state", t.From, t.To)
+		}
+	}
+	return nil
+}
+
+func (w *Workflow) HasState(name string) bool {
+	for _, state := range w.States {
+		if state.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Workflow) IsTerminal(name string) bool {
+	for _, state := range w.States {
+		if state.Name == name {
+			return state.Terminal
+		}
+	}
+	return false
+}
+
+func (w *Workflow) CanTransition(from, to string) bool {
+	for _, t := range w.Transitions {
+		if t.From == from && t.To == to {
+			return true
+		}
+	}
+	return false
+}
+
+// NextStates lists the states a task in state from may move to.
+func (w *Workflow) NextStates(from string) []string {
+	var next []string
+	for _, t := range w.Transitions {
+		if t.From == from {
+			next = append(next, t.To)
+		}
+	}
+	return next
+}
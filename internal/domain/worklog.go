@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Worklog records time a user spent on a task. A worklog that has not
+// ended is a running timer; each user runs at most one.
+type Worklog struct {
+	ID     uuid.UUID `json:"id"`
+	TaskID uuid.UUID `json:"task_id"`
+	// UserID is uuid.Nil once the user's account has been deleted.
+	UserID    uuid.UUID `json:"user_id"`
+	StartedAt time.Time `json:"started_at"`
+	// EndedAt is zero while the timer is running.
+	EndedAt time.Time `json:"ended_at"`
+	// Seconds is the time logged, 0 while the timer is running.
+	Seconds   int64     `json:"seconds"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MaxWorklogNoteLength is the longest worklog note accepted, in characters.
+const MaxWorklogNoteLength = 2000
+
+// Running tells whether the worklog is a timer that has not been stopped.
+func (w *Worklog) Running() bool {
+	return w.EndedAt.IsZero()
+}
+
+// Normalize completes a worklog logged by hand and checks it. The work is
+// given by its start and end, by its start and duration in Seconds, or by
+// its duration alone, in which case it is taken to have ended at now.
+// Times are kept in UTC, to the second.
+func (w *Worklog) Normalize(now time.Time) error {
+	now = now.UTC().Truncate(time.Second)
+	w.Note = strings.TrimSpace(w.Note)
+	w.StartedAt = w.StartedAt.UTC().Truncate(time.Second)
+	w.EndedAt = w.EndedAt.UTC().Truncate(time.Second)
+	switch {
+	case utf8.RuneCountInString(w.Note) > MaxWorklogNoteLength:
+		return fmt.Errorf("note must be at most %d characters", MaxWorklogNoteLength)
+	case w.Seconds < 0:
+		return errors.New("seconds must not be negative")
+	case w.EndedAt.IsZero() && w.Seconds == 0:
+		return errors.New("either ended_at or seconds is required")
+	case w.StartedAt.IsZero() && !w.EndedAt.IsZero():
+		return errors.New("started_at is required with ended_at")
+	}
+
+	switch {
+	case w.StartedAt.IsZero():
+		w.EndedAt = now
+		w.StartedAt = now.Add(-time.Duration(w.Seconds) * time.Second)
+	case w.EndedAt.IsZero():
+		w.EndedAt = w.StartedAt.Add(time.Duration(w.Seconds) * time.Second)
+	default:
+		seconds := int64(w.EndedAt.Sub(w.StartedAt) / time.Second)
+		if w.Seconds != 0 && w.Seconds != seconds {
+			return fmt.Errorf("seconds is %d, but started_at and ended_at are %d seconds apart", w.Seconds, seconds)
+		}
+		w.Seconds = seconds
+	}
+	switch {
+	case !w.EndedAt.After(w.StartedAt):
+		return errors.New("ended_at must be after started_at")
+	case w.EndedAt.After(now):
+		return errors.New("work cannot be logged ahead of time")
+	}
+	return nil
+}
+
+// Timesheet sums up the time logged from From until To, by the day the
+// work started on in UTC. Running timers are left out.
+type Timesheet struct {
+	From         time.Time `json:"from"`
+	To           time.Time `json:"to"`
+	TotalSeconds int64     `json:"total_seconds"`
+	// Users, Projects and Tasks total the seconds by user, project and
+	// task ID.
+	Users    map[uuid.UUID]int64 `json:"users"`
+	Projects map[uuid.UUID]int64 `json:"projects"`
+	Tasks    map[uuid.UUID]int64 `json:"tasks"`
+	// Rows are ordered by date, user and task.
+	Rows []TimesheetRow `json:"rows"`
+}
+
+// TimesheetRow is the time a user logged on a task in a day.
+type TimesheetRow struct {
+	// Date is formatted as YYYY-MM-DD.
+	Date      string    `json:"date"`
+	UserID    uuid.UUID `json:"user_id"`
+	ProjectID uuid.UUID `json:"project_id"`
+	TaskID    uuid.UUID `json:"task_id"`
+	Seconds   int64     `json:"seconds"`
+}
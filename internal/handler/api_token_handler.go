@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	_ "github.com/yelnar0112/project-management/docs"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/service"
+)
+
+type APITokenHandler struct {
+	service *service.APITokenService
+}
+
+func NewAPITokenHandler(service *service.APITokenService) *APITokenHandler {
+	return &APITokenHandler{service: service}
+}
+
+type createAPITokenRequest struct {
+	Name   string   `json:"name" binding:"required"`
+	Scopes []string `json:"scopes" binding:"required"`
+	// ExpiresAt may be left out for a token that does not expire.
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// createAPITokenResponse is the only place the token itself is ever shown.
+type createAPITokenResponse struct {
+	domain.APIToken
+	Token string `json:"token"`
+}
+
+// GetAPITokens godoc
+// @Summary Get my API tokens
+// @Description List the caller's personal access tokens, newest first. The tokens themselves are never shown again after creation.
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {array} domain.APIToken
+// @Failure 401 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /me/tokens [get]
+func (h *APITokenHandler) GetAPITokens(c *gin.Context) {
+	tokens, err := h.service.List(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+// CreateAPIToken godoc
+// @Summary Create an API token
+// @Description Create a personal access token for scripts, sent as "Authorization: Bearer <token>". The token is limited to the given scopes, which are permissions such as "tasks:read" that the caller's role holds. The token is returned once, in this response. Requires a password session.
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param token body createAPITokenRequest true "Token"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 201 {object} createAPITokenResponse
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /me/tokens [post]
+func (h *APITokenHandler) CreateAPIToken(c *gin.Context) {
+	var req createAPITokenRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	token := domain.APIToken{ID: uuid.New(), Name: req.Name, Scopes: req.Scopes, ExpiresAt: req.ExpiresAt}
+	secret, err := h.service.Create(c.Request.Context(), &token)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, createAPITokenResponse{APIToken: token, Token: secret})
+}
+
+// RevokeAPIToken godoc
+// @Summary Revoke an API token
+// @Description Revoke one of the caller's personal access tokens. Requests made with it are rejected from then on. Tokens can only be revoked after logging in with a password.
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Token ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.APIToken
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /me/tokens/{id} [delete]
+func (h *APITokenHandler) RevokeAPIToken(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	token, err := h.service.Revoke(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, token)
+}
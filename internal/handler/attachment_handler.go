@@ -0,0 +1,378 @@
+package handler
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	_ "github.com/yelnar0112/project-management/docs"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/service"
+)
+
+// multipartOverhead is allowed on top of the largest file for the rest of an
+// upload request: boundaries, part headers and the filename.
+const multipartOverhead = 64 << 10
+
+type AttachmentHandler struct {
+	service *service.AttachmentService
+}
+
+func NewAttachmentHandler(service *service.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{service: service}
+}
+
+func taskOwner(c *gin.Context) (domain.AttachmentOwner, error) {
+	id, err := pathID(c)
+	return domain.AttachmentOwner{TaskID: id}, err
+}
+
+func projectOwner(c *gin.Context) (domain.AttachmentOwner, error) {
+	id, err := pathID(c)
+	return domain.AttachmentOwner{ProjectID: id}, err
+}
+
+// attachmentPath parses the :id and :attachment_id path parameters.
+func attachmentPath(c *gin.Context, owner func(*gin.Context) (domain.AttachmentOwner, error)) (domain.AttachmentOwner, uuid.UUID, error) {
+	o, err := owner(c)
+	if err != nil {
+		return o, uuid.Nil, err
+	}
+	id, err := pathUUID(c, "attachment_id")
+	return o, id, err
+}
+
+// GetTaskAttachments godoc
+// @Summary Get a task's attachments
+// @Description List the files attached to a task, oldest first
+// @Tags tasks
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {array} domain.Attachment
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /tasks/{id}/attachments [get]
+func (h *AttachmentHandler) GetTaskAttachments(c *gin.Context) {
+	owner, err := taskOwner(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	h.list(c, owner)
+}
+
+// UploadTaskAttachment godoc
+// @Summary Attach a file to a task
+// @Description Upload a file as the "file" field of a multipart form. Its size and content type must be within the configured limits.
+// @Tags tasks
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param file formData file true "File"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 201 {object} domain.Attachment
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 413 {object} Problem
+// @Failure 415 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /tasks/{id}/attachments [post]
+func (h *AttachmentHandler) UploadTaskAttachment(c *gin.Context) {
+	owner, err := taskOwner(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	h.upload(c, owner)
+}
+
+// GetTaskAttachment godoc
+// @Summary Get an attachment of a task
+// @Description Get what describes a file attached to a task
+// @Tags tasks
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param attachment_id path string true "Attachment ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.Attachment
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /tasks/{id}/attachments/{attachment_id} [get]
+func (h *AttachmentHandler) GetTaskAttachment(c *gin.Context) {
+	owner, id, err := attachmentPath(c, taskOwner)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	h.get(c, owner, id)
+}
+
+// DownloadTaskAttachment godoc
+// @Summary Download an attachment of a task
+// @Description Download the content of a file attached to a task
+// @Tags tasks
+// @Security BearerAuth
+// @Produce octet-stream
+// @Param id path string true "Task ID"
+// @Param attachment_id path string true "Attachment ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {file} file
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /tasks/{id}/attachments/{attachment_id}/download [get]
+func (h *AttachmentHandler) DownloadTaskAttachment(c *gin.Context) {
+	owner, id, err := attachmentPath(c, taskOwner)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	h.download(c, owner, id)
+}
+
+// DeleteTaskAttachment godoc
+// @Summary Delete an attachment of a task
+// @Description Delete a file attached to a task
+// @Tags tasks
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param attachment_id path string true "Attachment ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /tasks/{id}/attachments/{attachment_id} [delete]
+func (h *AttachmentHandler) DeleteTaskAttachment(c *gin.Context) {
+	owner, id, err := attachmentPath(c, taskOwner)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	h.delete(c, owner, id)
+}
+
+// GetProjectAttachments godoc
+// @Summary Get a project's attachments
+// @Description List the files attached to a project, oldest first
+// @Tags projects
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {array} domain.Attachment
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/attachments [get]
+func (h *AttachmentHandler) GetProjectAttachments(c *gin.Context) {
+	owner, err := projectOwner(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	h.list(c, owner)
+}
+
+// UploadProjectAttachment godoc
+// @Summary Attach a file to a project
+// @Description Upload a file as the "file" field of a multipart form. Its size and content type must be within the configured limits.
+// @Tags projects
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param file formData file true "File"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 201 {object} domain.Attachment
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 413 {object} Problem
+// @Failure 415 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/attachments [post]
+func (h *AttachmentHandler) UploadProjectAttachment(c *gin.Context) {
+	owner, err := projectOwner(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	h.upload(c, owner)
+}
+
+// GetProjectAttachment godoc
+// @Summary Get an attachment of a project
+// @Description Get what describes a file attached to a project
+// @Tags projects
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param attachment_id path string true "Attachment ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.Attachment
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/attachments/{attachment_id} [get]
+func (h *AttachmentHandler) GetProjectAttachment(c *gin.Context) {
+	owner, id, err := attachmentPath(c, projectOwner)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	h.get(c, owner, id)
+}
+
+// DownloadProjectAttachment godoc
+// @Summary Download an attachment of a project
+// @Description Download the content of a file attached to a project
+// @Tags projects
+// @Security BearerAuth
+// @Produce octet-stream
+// @Param id path string true "Project ID"
+// @Param attachment_id path string true "Attachment ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {file} file
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/attachments/{attachment_id}/download [get]
+func (h *AttachmentHandler) DownloadProjectAttachment(c *gin.Context) {
+	owner, id, err := attachmentPath(c, projectOwner)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	h.download(c, owner, id)
+}
+
+// DeleteProjectAttachment godoc
+// @Summary Delete an attachment of a project
+// @Description Delete a file attached to a project
+// @Tags projects
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Param attachment_id path string true "Attachment ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/attachments/{attachment_id} [delete]
+func (h *AttachmentHandler) DeleteProjectAttachment(c *gin.Context) {
+	owner, id, err := attachmentPath(c, projectOwner)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	h.delete(c, owner, id)
+}
+
+func (h *AttachmentHandler) list(c *gin.Context, owner domain.AttachmentOwner) {
+	attachments, err := h.service.List(c.Request.Context(), owner)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, attachments)
+}
+
+func (h *AttachmentHandler) upload(c *gin.Context, owner domain.AttachmentOwner) {
+	maxSize := h.service.Limits().MaxSize
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+multipartOverhead)
+	header, err := c.FormFile("file")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		c.Error(domain.TooLarge("attachment_too_large", "file is larger than %d bytes", maxSize).
+			With("max_size", maxSize))
+		return
+	}
+	if err != nil {
+		c.Error(domain.Validation("invalid_body", "expected a multipart form with a file field: %v", err))
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer file.Close()
+
+	attachment := domain.Attachment{
+		ID:          uuid.New(),
+		ProjectID:   owner.ProjectID,
+		TaskID:      owner.TaskID,
+		Filename:    header.Filename,
+		ContentType: header.Header.Get("Content-Type"),
+		Size:        header.Size,
+	}
+	if err := h.service.Create(c.Request.Context(), &attachment, file); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, attachment)
+}
+
+func (h *AttachmentHandler) get(c *gin.Context, owner domain.AttachmentOwner, id uuid.UUID) {
+	attachment, err := h.service.Get(c.Request.Context(), owner, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, attachment)
+}
+
+// download always offers the file for saving rather than display, and stops
+// browsers from second-guessing its type, so that uploads cannot run as
+// pages of this site.
+func (h *AttachmentHandler) download(c *gin.Context, owner domain.AttachmentOwner, id uuid.UUID) {
+	attachment, content, err := h.service.Open(c.Request.Context(), owner, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}),
+		"X-Content-Type-Options": "nosniff",
+		"ETag":                   strconv.Quote(attachment.Checksum),
+	})
+}
+
+func (h *AttachmentHandler) delete(c *gin.Context, owner domain.AttachmentOwner, id uuid.UUID) {
+	if err := h.service.Delete(c.Request.Context(), owner, id); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Attachment deleted successfully"})
+}
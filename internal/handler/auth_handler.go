@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	_ "github.com/yelnar0112/project-management/docs"
+	"github.com/yelnar0112/project-management/internal/auth"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/service"
+)
+
+type AuthHandler struct {
+	service *service.AuthService
+}
+
+func NewAuthHandler(service *service.AuthService) *AuthHandler {
+	return &AuthHandler{service: service}
+}
+
+type loginRequest struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// permissionsResponse maps each permission the caller holds in the
+// organization it acts in to the scopes it holds it in. For API tokens only
+// the token's scopes are listed.
+type permissionsResponse struct {
+	UserID         uuid.UUID                        `json:"user_id"`
+	OrganizationID uuid.UUID                        `json:"organization_id"`
+	Role           domain.Role                      `json:"role"`
+	Permissions    map[auth.Permission][]auth.Scope `json:"permissions"`
+}
+
+// Login godoc
+// @Summary Log in
+// @Description Exchange an email and password for an access token and a refresh token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param credentials body loginRequest true "Credentials"
+// @Success 200 {object} service.TokenPair
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /auth/login [post]
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req loginRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	tokens, err := h.service.Login(c.Request.Context(), req.Email, req.Password)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Refresh godoc
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for a new access token and refresh token. Each refresh token can be used once; reusing one revokes its session.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param token body refreshRequest true "Refresh token"
+// @Success 200 {object} service.TokenPair
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req refreshRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	tokens, err := h.service.Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Logout godoc
+// @Summary Log out
+// @Description Revoke the session of the access token, together with its refresh token. API tokens are revoked through DELETE /me/tokens/{id} instead.
+// @Tags auth
+// @Security BearerAuth
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /auth/logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	principal := auth.PrincipalFrom(c.Request.Context())
+	if principal.SessionID == uuid.Nil {
+		c.Error(domain.Validation("not_a_session", "API tokens have no session to log out of, revoke the token instead"))
+		return
+	}
+	if err := h.service.Logout(c.Request.Context(), principal.SessionID); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// Permissions godoc
+// @Summary Get my permissions
+// @Description List what the caller may do, so that clients can hide what is not allowed. A scope other than "any" limits a permission to some resources: "self" to the caller's own user, "managed" to projects whose manager_id is the caller and their tasks, "assigned" to tasks assigned to the caller, "contributed" to projects the caller owns or contributes to and their tasks. With an API token, only the permissions in the token's scopes are listed.
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} permissionsResponse
+// @Failure 401 {object} Problem
+// @Router /me/permissions [get]
+func (h *AuthHandler) Permissions(c *gin.Context) {
+	principal := auth.PrincipalFrom(c.Request.Context())
+	c.JSON(http.StatusOK, permissionsResponse{
+		UserID:         principal.User.ID,
+		OrganizationID: principal.OrganizationID,
+		Role:           principal.User.Role,
+		Permissions:    principal.Grants(),
+	})
+}
+
+// OrganizationHeader selects which of the caller's organizations a request
+// acts in.
+const OrganizationHeader = "X-Organization-ID"
+
+// RequireAuth rejects requests without a valid bearer access token or API
+// token and attaches the caller to the request context of the others.
+func RequireAuth(service *service.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scheme, token, ok := strings.Cut(c.GetHeader("Authorization"), " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+			c.Error(domain.Unauthorized("missing_token", "an Authorization: Bearer access token or API token is required"))
+			c.Abort()
+			return
+		}
+		var organizationID uuid.UUID
+		if header := c.GetHeader(OrganizationHeader); header != "" {
+			var err error
+			if organizationID, err = uuid.Parse(header); err != nil {
+				c.Error(domain.Validation("invalid_id", "invalid %s header %q: %v", OrganizationHeader, header, err))
+				c.Abort()
+				return
+			}
+		}
+
+		principal, err := service.Authenticate(c.Request.Context(), token, organizationID)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+		c.Next()
+	}
+}
+
+// RequirePermission rejects callers that do not hold permission in any
+// scope. Services check whether a held permission covers the resource at
+// hand.
+func RequirePermission(permission auth.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := auth.AuthorizeAny(c.Request.Context(), permission); err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	_ "github.com/yelnar0112/project-management/docs"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/service"
+)
+
+type BoardHandler struct {
+	service *service.BoardService
+}
+
+func NewBoardHandler(service *service.BoardService) *BoardHandler {
+	return &BoardHandler{service: service}
+}
+
+// GetBoard godoc
+// @Summary Get a project's board
+// @Description Retrieve the columns of a project's board with the tasks in each, in board order. Projects without their own board have one column per workflow state.
+// @Tags projects
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.BoardView
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/board [get]
+func (h *BoardHandler) GetBoard(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	board, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, board)
+}
+
+// UpdateBoard godoc
+// @Summary Update a project's board
+// @Description Replace the columns of a project's board, each mapping one or more workflow states, with optional WIP limits
+// @Tags projects
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param board body domain.Board true "Board"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.Board
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/board [put]
+func (h *BoardHandler) UpdateBoard(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var board domain.Board
+	if err := bindJSON(c, &board); err != nil {
+		c.Error(err)
+		return
+	}
+
+	board.ProjectID = id
+	if err := h.service.Update(c.Request.Context(), &board); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, board)
+}
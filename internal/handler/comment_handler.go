@@ -0,0 +1,238 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	_ "github.com/yelnar0112/project-management/docs"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/service"
+)
+
+type CommentHandler struct {
+	service *service.CommentService
+}
+
+func NewCommentHandler(service *service.CommentService) *CommentHandler {
+	return &CommentHandler{service: service}
+}
+
+type createCommentRequest struct {
+	Body     string    `json:"body" binding:"required"`
+	ParentID uuid.UUID `json:"parent_id"`
+}
+
+type updateCommentRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// commentPath parses the :id and :comment_id path parameters.
+func commentPath(c *gin.Context) (taskID, commentID uuid.UUID, err error) {
+	if taskID, err = pathID(c); err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	if commentID, err = pathUUID(c, "comment_id"); err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	return taskID, commentID, nil
+}
+
+// GetComments godoc
+// @Summary Get a task's comments
+// @Description List the comments on a task, oldest first. Replies name the comment that started their thread in parent_id; deleted comments stay in place with an empty body.
+// @Tags tasks
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {array} domain.Comment
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /tasks/{id}/comments [get]
+func (h *CommentHandler) GetComments(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	comments, err := h.service.List(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, comments)
+}
+
+// CreateComment godoc
+// @Summary Comment on a task
+// @Description Post a Markdown comment on a task, or a reply when parent_id names another comment on it. Mentions written as @handle or @email of users in the organization are recorded.
+// @Tags tasks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param comment body createCommentRequest true "Comment"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 201 {object} domain.Comment
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /tasks/{id}/comments [post]
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req createCommentRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	comment := domain.Comment{ID: uuid.New(), TaskID: id, ParentID: req.ParentID, Body: req.Body}
+	if err := h.service.Create(c.Request.Context(), &comment); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, comment)
+}
+
+// GetComment godoc
+// @Summary Get a comment
+// @Description Get a comment on a task
+// @Tags tasks
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param comment_id path string true "Comment ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.Comment
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /tasks/{id}/comments/{comment_id} [get]
+func (h *CommentHandler) GetComment(c *gin.Context) {
+	taskID, id, err := commentPath(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	comment, err := h.service.Get(c.Request.Context(), taskID, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, comment)
+}
+
+// UpdateComment godoc
+// @Summary Edit a comment
+// @Description Replace the body of a comment. The previous body is kept in the comment's revisions.
+// @Tags tasks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param comment_id path string true "Comment ID"
+// @Param comment body updateCommentRequest true "Comment"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.Comment
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /tasks/{id}/comments/{comment_id} [put]
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	taskID, id, err := commentPath(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req updateCommentRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	comment, err := h.service.Update(c.Request.Context(), taskID, id, req.Body)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, comment)
+}
+
+// DeleteComment godoc
+// @Summary Delete a comment
+// @Description Empty a comment and drop its revisions. It stays in its thread, marked as deleted.
+// @Tags tasks
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param comment_id path string true "Comment ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /tasks/{id}/comments/{comment_id} [delete]
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	taskID, id, err := commentPath(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), taskID, id); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
+}
+
+// GetCommentRevisions godoc
+// @Summary Get a comment's edit history
+// @Description List the earlier versions of a comment, oldest first. Each holds the body an edit replaced.
+// @Tags tasks
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param comment_id path string true "Comment ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {array} domain.CommentRevision
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /tasks/{id}/comments/{comment_id}/revisions [get]
+func (h *CommentHandler) GetCommentRevisions(c *gin.Context) {
+	taskID, id, err := commentPath(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	revisions, err := h.service.ListRevisions(c.Request.Context(), taskID, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, revisions)
+}
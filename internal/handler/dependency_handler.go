@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	_ "github.com/yelnar0112/project-management/docs"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/service"
+)
+
+type DependencyHandler struct {
+	service *service.DependencyService
+}
+
+func NewDependencyHandler(service *service.DependencyService) *DependencyHandler {
+	return &DependencyHandler{service: service}
+}
+
+// addDependencyRequest names exactly one other task, which either blocks
+// the task or is blocked by it.
+type addDependencyRequest struct {
+	BlockedBy uuid.UUID `json:"blocked_by"`
+	Blocks    uuid.UUID `json:"blocks"`
+}
+
+// GetDependencies godoc
+// @Summary Get a task's dependencies
+// @Description Get every task the task waits on and every task waiting on it, directly or through other tasks, with the links between them. open_blockers lists the direct blockers that keep the task from being completed.
+// @Tags tasks
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.DependencyGraph
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /tasks/{id}/dependencies [get]
+func (h *DependencyHandler) GetDependencies(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	graph, err := h.service.Graph(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, graph)
+}
+
+// AddDependency godoc
+// @Summary Link a task to a blocker
+// @Description Record that the task is blocked by the task in blocked_by, or blocks the task in blocks. Tasks may be in different projects. Links that would make a task wait on itself are refused with the cycle they would close.
+// @Tags tasks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param dependency body addDependencyRequest true "Other task"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 201 {object} domain.Dependency
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /tasks/{id}/dependencies [post]
+func (h *DependencyHandler) AddDependency(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req addDependencyRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+	if req.BlockedBy != uuid.Nil && req.Blocks != uuid.Nil {
+		c.Error(domain.Validation("invalid_dependency", "set either blocked_by or blocks, not both"))
+		return
+	}
+
+	other, blocks := req.BlockedBy, false
+	if req.Blocks != uuid.Nil {
+		other, blocks = req.Blocks, true
+	}
+	dependency, err := h.service.Add(c.Request.Context(), id, other, blocks)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, dependency)
+}
+
+// RemoveDependency godoc
+// @Summary Unlink two tasks
+// @Description Remove the link between the task and another one, whichever of them blocks the other.
+// @Tags tasks
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param task_id path string true "Other task ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /tasks/{id}/dependencies/{task_id} [delete]
+func (h *DependencyHandler) RemoveDependency(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	other, err := pathUUID(c, "task_id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.service.Remove(c.Request.Context(), id, other); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Dependency removed successfully"})
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+)
+
+const problemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details body. Code is stable and meant for
+// clients to branch on; Detail is for people. The fields of a domain.Error
+// are added as extension members next to these.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+}
+
+// Errors renders the last error attached to the request with c.Error as
+// problem+json, unless the handler has already written a response.
+func Errors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		writeProblem(c, c.Errors.Last().Err)
+	}
+}
+
+// NoRoute answers requests that match no route.
+func NoRoute(c *gin.Context) {
+	c.Error(domain.NotFound("route_not_found", "no route for %s %s", c.Request.Method, c.Request.URL.Path))
+}
+
+func writeProblem(c *gin.Context, err error) {
+	problem := Problem{Type: "about:blank", Instance: c.Request.URL.Path}
+	var fields map[string]any
+
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		problem.Status = errorStatus(domainErr)
+		problem.Code = domainErr.Code
+		problem.Detail = domainErr.Message
+		fields = domainErr.Fields
+	} else {
+		log.Printf("%s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		problem.Status = http.StatusInternalServerError
+		problem.Code = "internal_error"
+		problem.Detail = "The server could not complete the request."
+	}
+	problem.Title = http.StatusText(problem.Status)
+
+	body := make(map[string]any, len(fields)+6)
+	for key, value := range fields {
+		body[key] = value
+	}
+	body["type"] = problem.Type
+	body["title"] = problem.Title
+	body["status"] = problem.Status
+	body["detail"] = problem.Detail
+	body["instance"] = problem.Instance
+	body["code"] = problem.Code
+
+	if problem.Status == http.StatusUnauthorized {
+		c.Header("WWW-Authenticate", `Bearer realm="project-management"`)
+	}
+	c.Header("Content-Type", problemContentType)
+	c.JSON(problem.Status, body)
+}
+
+func errorStatus(err *domain.Error) int {
+	switch {
+	case errors.Is(err.Kind, domain.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err.Kind, domain.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err.Kind, domain.ErrValidation):
+		return http.StatusBadRequest
+	case errors.Is(err.Kind, domain.ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err.Kind, domain.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err.Kind, domain.ErrTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err.Kind, domain.ErrUnsupported):
+		return http.StatusUnsupportedMediaType
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// pathID parses the :id path parameter.
+func pathID(c *gin.Context) (uuid.UUID, error) {
+	return pathUUID(c, "id")
+}
+
+// pathUUID parses a path parameter holding an ID.
+func pathUUID(c *gin.Context, name string) (uuid.UUID, error) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		return uuid.Nil, domain.Validation("invalid_id", "invalid ID %q: %v", c.Param(name), err)
+	}
+	return id, nil
+}
+
+// bindJSON decodes the request body into v.
+func bindJSON(c *gin.Context, v any) error {
+	if err := c.ShouldBindJSON(v); err != nil {
+		return domain.Validation("invalid_body", "invalid request body: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,260 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	_ "github.com/yelnar0112/project-management/docs"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/service"
+)
+
+type LabelHandler struct {
+	service *service.LabelService
+}
+
+func NewLabelHandler(service *service.LabelService) *LabelHandler {
+	return &LabelHandler{service: service}
+}
+
+type labelRequest struct {
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	Description string `json:"description"`
+}
+
+type mergeLabelRequest struct {
+	Into uuid.UUID `json:"into"`
+}
+
+// labelPath parses the :id and :label_id path parameters.
+func labelPath(c *gin.Context) (projectID, labelID uuid.UUID, err error) {
+	if projectID, err = pathID(c); err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	if labelID, err = pathUUID(c, "label_id"); err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	return projectID, labelID, nil
+}
+
+// GetLabels godoc
+// @Summary Get a project's labels
+// @Description List the label catalog of a project, ordered by name
+// @Tags projects
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {array} domain.Label
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/labels [get]
+func (h *LabelHandler) GetLabels(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	labels, err := h.service.List(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, labels)
+}
+
+// CreateLabel godoc
+// @Summary Create a label
+// @Description Add a label to a project's catalog. Names are unique in the project regardless of letter case and cannot contain commas; colors are hex colors such as #d73a4a.
+// @Tags projects
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param label body labelRequest true "Label"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 201 {object} domain.Label
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/labels [post]
+func (h *LabelHandler) CreateLabel(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req labelRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	label := domain.Label{
+		ID:          uuid.New(),
+		ProjectID:   id,
+		Name:        req.Name,
+		Color:       req.Color,
+		Description: req.Description,
+	}
+	if err := h.service.Create(c.Request.Context(), &label); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, label)
+}
+
+// GetLabel godoc
+// @Summary Get a label
+// @Description Retrieve a label of a project's catalog
+// @Tags projects
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param label_id path string true "Label ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.Label
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/labels/{label_id} [get]
+func (h *LabelHandler) GetLabel(c *gin.Context) {
+	projectID, labelID, err := labelPath(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	label, err := h.service.Get(c.Request.Context(), projectID, labelID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, label)
+}
+
+// UpdateLabel godoc
+// @Summary Update a label
+// @Description Rename, recolor or describe a label. An empty name or color keeps the current one. Tasks refer to labels by ID, so they all show the change at once.
+// @Tags projects
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param label_id path string true "Label ID"
+// @Param label body labelRequest true "Label"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.Label
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/labels/{label_id} [put]
+func (h *LabelHandler) UpdateLabel(c *gin.Context) {
+	projectID, labelID, err := labelPath(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req labelRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	label := domain.Label{
+		ID:          labelID,
+		ProjectID:   projectID,
+		Name:        req.Name,
+		Color:       req.Color,
+		Description: req.Description,
+	}
+	if err := h.service.Update(c.Request.Context(), &label); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, label)
+}
+
+// DeleteLabel godoc
+// @Summary Delete a label
+// @Description Remove a label from a project's catalog and from every task that has it
+// @Tags projects
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Param label_id path string true "Label ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/labels/{label_id} [delete]
+func (h *LabelHandler) DeleteLabel(c *gin.Context) {
+	projectID, labelID, err := labelPath(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), projectID, labelID); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Label deleted successfully"})
+}
+
+// MergeLabel godoc
+// @Summary Merge a label into another
+// @Description Give every task with the label the label in into instead, and delete the label, all at once. Both labels must be in the project.
+// @Tags projects
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param label_id path string true "Label ID"
+// @Param merge body mergeLabelRequest true "Target label"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.Label
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/labels/{label_id}/merge [post]
+func (h *LabelHandler) MergeLabel(c *gin.Context) {
+	projectID, labelID, err := labelPath(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req mergeLabelRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	target, err := h.service.Merge(c.Request.Context(), projectID, labelID, req.Into)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, target)
+}
@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	_ "github.com/yelnar0112/project-management/docs"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/service"
+)
+
+type MemberHandler struct {
+	service *service.MemberService
+}
+
+func NewMemberHandler(service *service.MemberService) *MemberHandler {
+	return &MemberHandler{service: service}
+}
+
+type addMemberRequest struct {
+	UserID uuid.UUID          `json:"user_id" binding:"required"`
+	Role   domain.ProjectRole `json:"role"`
+}
+
+type updateMemberRequest struct {
+	Role domain.ProjectRole `json:"role" binding:"required"`
+}
+
+// memberPath parses the :id and :user_id path parameters.
+func memberPath(c *gin.Context) (projectID, userID uuid.UUID, err error) {
+	if projectID, err = pathID(c); err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	if userID, err = pathUUID(c, "user_id"); err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	return projectID, userID, nil
+}
+
+// GetMembers godoc
+// @Summary Get a project's members
+// @Description List the members of a project in the order they joined
+// @Tags projects
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {array} domain.Member
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/members [get]
+func (h *MemberHandler) GetMembers(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	members, err := h.service.List(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, members)
+}
+
+// AddMember godoc
+// @Summary Add a project member
+// @Description Add a user to a project as an owner, contributor (the default) or observer
+// @Tags projects
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param member body addMemberRequest true "Member"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 201 {object} domain.Member
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/members [post]
+func (h *MemberHandler) AddMember(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req addMemberRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	member := domain.Member{ProjectID: id, UserID: req.UserID, Role: req.Role}
+	if err := h.service.Add(c.Request.Context(), &member); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, member)
+}
+
+// GetMember godoc
+// @Summary Get a project member
+// @Description Get the membership of a user in a project
+// @Tags projects
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param user_id path string true "User ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.Member
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/members/{user_id} [get]
+func (h *MemberHandler) GetMember(c *gin.Context) {
+	projectID, userID, err := memberPath(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	member, err := h.service.Get(c.Request.Context(), projectID, userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, member)
+}
+
+// UpdateMember godoc
+// @Summary Change a member's role
+// @Description Change the role of a project member. Members with open tasks in the project cannot become observers, and the manager stays an owner.
+// @Tags projects
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param user_id path string true "User ID"
+// @Param member body updateMemberRequest true "Role"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.Member
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/members/{user_id} [put]
+func (h *MemberHandler) UpdateMember(c *gin.Context) {
+	projectID, userID, err := memberPath(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req updateMemberRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	member := domain.Member{ProjectID: projectID, UserID: userID, Role: req.Role}
+	if err := h.service.Update(c.Request.Context(), &member); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, member)
+}
+
+// RemoveMember godoc
+// @Summary Remove a project member
+// @Description Take a user off a project. Members with open tasks in the project and the manager cannot be removed.
+// @Tags projects
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Param user_id path string true "User ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/members/{user_id} [delete]
+func (h *MemberHandler) RemoveMember(c *gin.Context) {
+	projectID, userID, err := memberPath(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.service.Remove(c.Request.Context(), projectID, userID); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
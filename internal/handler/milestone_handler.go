@@ -0,0 +1,246 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	_ "github.com/yelnar0112/project-management/docs"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/service"
+)
+
+type MilestoneHandler struct {
+	service *service.MilestoneService
+}
+
+func NewMilestoneHandler(service *service.MilestoneService) *MilestoneHandler {
+	return &MilestoneHandler{service: service}
+}
+
+type milestoneRequest struct {
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	TargetDate  time.Time `json:"target_date"`
+}
+
+// milestonePath parses the :id and :milestone_id path parameters.
+func milestonePath(c *gin.Context) (projectID, milestoneID uuid.UUID, err error) {
+	if projectID, err = pathID(c); err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	if milestoneID, err = pathUUID(c, "milestone_id"); err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	return projectID, milestoneID, nil
+}
+
+// GetMilestones godoc
+// @Summary Get a project's milestones
+// @Description List the milestones of a project with the progress of their tasks, ordered by target date
+// @Tags projects
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {array} domain.MilestoneProgress
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/milestones [get]
+func (h *MilestoneHandler) GetMilestones(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	milestones, err := h.service.List(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, milestones)
+}
+
+// CreateMilestone godoc
+// @Summary Add a milestone
+// @Description Add a milestone to a project. Its target date is required and must fall within the dates of the project.
+// @Tags projects
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param milestone body milestoneRequest true "Milestone"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 201 {object} domain.Milestone
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/milestones [post]
+func (h *MilestoneHandler) CreateMilestone(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req milestoneRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	milestone := domain.Milestone{
+		ID:          uuid.New(),
+		ProjectID:   id,
+		Title:       req.Title,
+		Description: req.Description,
+		TargetDate:  req.TargetDate,
+	}
+	if err := h.service.Create(c.Request.Context(), &milestone); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, milestone)
+}
+
+// GetMilestone godoc
+// @Summary Get a milestone
+// @Description Retrieve a milestone of a project with the progress of its tasks. A milestone is completed once all of its tasks are, overdue when its target date passes first, and at risk while the percentage of its tasks completed is below that of its time elapsed.
+// @Tags projects
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param milestone_id path string true "Milestone ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.MilestoneProgress
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/milestones/{milestone_id} [get]
+func (h *MilestoneHandler) GetMilestone(c *gin.Context) {
+	projectID, milestoneID, err := milestonePath(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	milestone, err := h.service.Get(c.Request.Context(), projectID, milestoneID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, milestone)
+}
+
+// UpdateMilestone godoc
+// @Summary Update a milestone
+// @Description Change the title, description or target date of a milestone
+// @Tags projects
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param milestone_id path string true "Milestone ID"
+// @Param milestone body milestoneRequest true "Milestone"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.Milestone
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/milestones/{milestone_id} [put]
+func (h *MilestoneHandler) UpdateMilestone(c *gin.Context) {
+	projectID, milestoneID, err := milestonePath(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req milestoneRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	milestone := domain.Milestone{
+		ID:          milestoneID,
+		ProjectID:   projectID,
+		Title:       req.Title,
+		Description: req.Description,
+		TargetDate:  req.TargetDate,
+	}
+	if err := h.service.Update(c.Request.Context(), &milestone); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, milestone)
+}
+
+// DeleteMilestone godoc
+// @Summary Delete a milestone
+// @Description Delete a milestone of a project. Its tasks are kept, linked to no milestone.
+// @Tags projects
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Param milestone_id path string true "Milestone ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/milestones/{milestone_id} [delete]
+func (h *MilestoneHandler) DeleteMilestone(c *gin.Context) {
+	projectID, milestoneID, err := milestonePath(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), projectID, milestoneID); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Milestone deleted successfully"})
+}
+
+// GetUpcomingMilestones godoc
+// @Summary Get my upcoming milestones
+// @Description List the milestones not due yet of the projects the caller is a member of, soonest first, with the progress of their tasks
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Param before query string false "Only milestones due before"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {array} domain.MilestoneProgress
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /me/milestones [get]
+func (h *MilestoneHandler) GetUpcomingMilestones(c *gin.Context) {
+	p := queryParser{c: c}
+	before := p.time("before")
+	if p.err != nil {
+		c.Error(p.err)
+		return
+	}
+
+	milestones, err := h.service.Upcoming(c.Request.Context(), before)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, milestones)
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	_ "github.com/yelnar0112/project-management/docs"
+	"github.com/yelnar0112/project-management/internal/service"
+)
+
+type NotificationHandler struct {
+	service *service.NotificationService
+}
+
+func NewNotificationHandler(service *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{service: service}
+}
+
+// GetNotifications godoc
+// @Summary Get my notifications
+// @Description List the caller's notifications about the tasks of the organization it acts in, newest first, such as reminders of tasks that are due soon or overdue
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Param unread query bool false "Only notifications that were not read yet"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {array} domain.Notification
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /me/notifications [get]
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	p := queryParser{c: c}
+	unread := p.bool("unread")
+	if p.err != nil {
+		c.Error(p.err)
+		return
+	}
+
+	notifications, err := h.service.List(c.Request.Context(), unread)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, notifications)
+}
+
+// ReadNotification godoc
+// @Summary Mark a notification as read
+// @Description Mark one of the caller's notifications as read. A notification that was read already keeps the time it was first read. API tokens cannot mark notifications read.
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Notification ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.Notification
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /me/notifications/{id}/read [post]
+func (h *NotificationHandler) ReadNotification(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	notification, err := h.service.MarkRead(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, notification)
+}
+
+// ReadAllNotifications godoc
+// @Summary Mark all notifications as read
+// @Description Mark every unread notification of the caller in the organization it acts in as read. API tokens cannot mark notifications read.
+// @Tags auth
+// @Security BearerAuth
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /me/notifications/read [post]
+func (h *NotificationHandler) ReadAllNotifications(c *gin.Context) {
+	if err := h.service.MarkAllRead(c.Request.Context()); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Notifications marked as read"})
+}
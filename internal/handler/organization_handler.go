@@ -0,0 +1,288 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	_ "github.com/yelnar0112/project-management/docs"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/service"
+)
+
+type OrganizationHandler struct {
+	service *service.OrganizationService
+}
+
+func NewOrganizationHandler(service *service.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{service: service}
+}
+
+type organizationRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type addOrganizationMemberRequest struct {
+	Email string      `json:"email" binding:"required"`
+	Role  domain.Role `json:"role"`
+}
+
+type updateOrganizationMemberRequest struct {
+	Role domain.Role `json:"role" binding:"required"`
+}
+
+// GetOrganizations godoc
+// @Summary Get the caller's organizations
+// @Description List the organizations the caller belongs to, in the order it joined them. Requests act in the first one unless the X-Organization-ID header names another.
+// @Tags organizations
+// @Security BearerAuth
+// @Produce json
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {array} domain.Organization
+// @Failure 401 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /organizations/ [get]
+func (h *OrganizationHandler) GetOrganizations(c *gin.Context) {
+	organizations, err := h.service.List(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, organizations)
+}
+
+// CreateOrganization godoc
+// @Summary Create an organization
+// @Description Create an organization with the caller as its admin. Requires a password session, not an API token.
+// @Tags organizations
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param organization body organizationRequest true "Organization"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 201 {object} domain.Organization
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /organizations/ [post]
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	var req organizationRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	organization := domain.Organization{ID: uuid.New(), Name: req.Name}
+	if err := h.service.Create(c.Request.Context(), &organization); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, organization)
+}
+
+// GetOrganization godoc
+// @Summary Get an organization
+// @Description Get one of the caller's organizations
+// @Tags organizations
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.Organization
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /organizations/{id} [get]
+func (h *OrganizationHandler) GetOrganization(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	organization, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, organization)
+}
+
+// UpdateOrganization godoc
+// @Summary Rename an organization
+// @Description Rename one of the caller's organizations. Requires organizations:update there.
+// @Tags organizations
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param organization body organizationRequest true "Organization"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.Organization
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /organizations/{id} [put]
+func (h *OrganizationHandler) UpdateOrganization(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req organizationRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	organization := domain.Organization{ID: id, Name: req.Name}
+	if err := h.service.Update(c.Request.Context(), &organization); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, organization)
+}
+
+// GetOrganizationMembers godoc
+// @Summary Get an organization's members
+// @Description List the members of one of the caller's organizations in the order they joined
+// @Tags organizations
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {array} domain.OrganizationMember
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /organizations/{id}/members [get]
+func (h *OrganizationHandler) GetOrganizationMembers(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	members, err := h.service.ListMembers(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, members)
+}
+
+// AddOrganizationMember godoc
+// @Summary Add an organization member
+// @Description Add an existing user, found by email, to an organization as an admin, manager, member (the default) or viewer. Requires users:create there.
+// @Tags organizations
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param member body addOrganizationMemberRequest true "Member"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 201 {object} domain.OrganizationMember
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /organizations/{id}/members [post]
+func (h *OrganizationHandler) AddOrganizationMember(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req addOrganizationMemberRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	member, err := h.service.AddMember(c.Request.Context(), id, req.Email, req.Role)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, member)
+}
+
+// UpdateOrganizationMember godoc
+// @Summary Change an organization member's role
+// @Description Change the role of a member of an organization. Requires users:update on every user there. The last admin keeps the role.
+// @Tags organizations
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param user_id path string true "User ID"
+// @Param member body updateOrganizationMemberRequest true "Role"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.OrganizationMember
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /organizations/{id}/members/{user_id} [put]
+func (h *OrganizationHandler) UpdateOrganizationMember(c *gin.Context) {
+	organizationID, userID, err := memberPath(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req updateOrganizationMemberRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	member := domain.OrganizationMember{OrganizationID: organizationID, UserID: userID, Role: req.Role}
+	if err := h.service.UpdateMember(c.Request.Context(), &member); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, member)
+}
+
+// RemoveOrganizationMember godoc
+// @Summary Remove an organization member
+// @Description Take a user out of an organization and off its projects. Anyone may leave after logging in with a password, but not with an API token; removing others requires users:delete there. The last admin, members with open tasks and project managers cannot be removed.
+// @Tags organizations
+// @Security BearerAuth
+// @Param id path string true "Organization ID"
+// @Param user_id path string true "User ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /organizations/{id}/members/{user_id} [delete]
+func (h *OrganizationHandler) RemoveOrganizationMember(c *gin.Context) {
+	organizationID, userID, err := memberPath(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.service.RemoveMember(c.Request.Context(), organizationID, userID); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
@@ -1,54 +1,145 @@
 package handler
 
 import (
-	"database/sql"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	_ "github.com/yelnar0112/project-management/docs"
 	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
 	"github.com/yelnar0112/project-management/internal/service"
 )
 
+type ProjectHandler struct {
+	service *service.ProjectService
+}
+
+func NewProjectHandler(service *service.ProjectService) *ProjectHandler {
+	return &ProjectHandler{service: service}
+}
+
 // GetProjects godoc
 // @Summary Get all projects
-// @Description Retrieve a list of all projects
+// @Description Retrieve a page of projects, optionally filtered and sorted. Dates are RFC 3339 timestamps or YYYY-MM-DD, with *_after inclusive and *_before exclusive.
 // @Tags projects
+// @Security BearerAuth
 // @Produce json
-// @Success 200 {array} domain.Entity
-// @Failure 500 {object} gin.H{"error": string, "details": string}
+// @Param manager_id query string false "Manager ID"
+// @Param member_id query string false "ID of a member"
+// @Param starts_after query string false "Starting at or after"
+// @Param starts_before query string false "Starting before"
+// @Param ends_after query string false "Ending at or after"
+// @Param ends_before query string false "Ending before"
+// @Param sort query string false "Sort fields: title, start_date, end_date; prefix with - to reverse"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} repository.Page[domain.Entity]
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /projects/ [get]
-func GetProjects(c *gin.Context) {
-	projects, err := service.GetAllProjects()
+func (h *ProjectHandler) GetProjects(c *gin.Context) {
+	query, err := parseProjectQuery(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	projects, err := h.service.GetAll(c.Request.Context(), query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, projects)
+}
+
+// GetUserProjects godoc
+// @Summary Get a user's projects
+// @Description Retrieve a page of the projects a user is a member of. Accepts the same filters, sorting and pagination as GET /projects, except member_id.
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Param manager_id query string false "Manager ID"
+// @Param starts_after query string false "Starting at or after"
+// @Param starts_before query string false "Starting before"
+// @Param ends_after query string false "Ending at or after"
+// @Param ends_before query string false "Ending before"
+// @Param sort query string false "Sort fields: title, start_date, end_date; prefix with - to reverse"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} repository.Page[domain.Entity]
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /user/{id}/projects [get]
+func (h *ProjectHandler) GetUserProjects(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	query, err := parseProjectQuery(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	projects, err := h.service.GetForUser(c.Request.Context(), id, query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve projects", "details": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, projects)
 }
 
+func parseProjectQuery(c *gin.Context) (repository.ProjectQuery, error) {
+	p := queryParser{c: c}
+	query := repository.ProjectQuery{
+		ManagerID:    p.uuid("manager_id"),
+		MemberID:     p.uuid("member_id"),
+		StartsAfter:  p.time("starts_after"),
+		StartsBefore: p.time("starts_before"),
+		EndsAfter:    p.time("ends_after"),
+		EndsBefore:   p.time("ends_before"),
+		Sort:         parseSort(&p, repository.ProjectSorting),
+		Limit:        p.int("limit"),
+		Cursor:       c.Query("cursor"),
+	}
+	return query, p.err
+}
+
 // CreateProject godoc
 // @Summary Create a new project
 // @Description Create a new project in the system
 // @Tags projects
+// @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param project body domain.Entity true "Project"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
 // @Success 201 {object} domain.Entity
-// @Failure 400 {object} gin.H{"error": string, "details": string}
-// @Failure 500 {object} gin.H{"error": string, "details": string}
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /projects/ [post]
-func CreateProject(c *gin.Context) {
+func (h *ProjectHandler) CreateProject(c *gin.Context) {
 	var project domain.Entity
-	if err := c.ShouldBindJSON(&project); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project data", "details": err.Error()})
+	if err := bindJSON(c, &project); err != nil {
+		c.Error(err)
 		return
 	}
 
 	project.ID = uuid.New()
-	if err := service.CreateProject(&project); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create project", "details": err.Error()})
+	if err := h.service.Create(c.Request.Context(), &project); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -59,27 +150,27 @@ func CreateProject(c *gin.Context) {
 // @Summary Get a project by ID
 // @Description Retrieve a project by its ID
 // @Tags projects
+// @Security BearerAuth
 // @Produce json
 // @Param id path string true "Project ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
 // @Success 200 {object} domain.Entity
-// @Failure 400 {object} gin.H{"error": string, "details": string}
-// @Failure 404 {object} gin.H{"error": string}
-// @Failure 500 {object} gin.H{"error": string, "details": string}
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /projects/{id} [get]
-func GetProject(c *gin.Context) {
-	id, err := uuid.Parse(c.Param("id"))
+func (h *ProjectHandler) GetProject(c *gin.Context) {
+	id, err := pathID(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID", "details": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	project, err := service.GetProject(id)
+	project, err := h.service.Get(c.Request.Context(), id)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve project", "details": err.Error()})
-		}
+		c.Error(err)
 		return
 	}
 
@@ -90,30 +181,35 @@ func GetProject(c *gin.Context) {
 // @Summary Update a project
 // @Description Update a project by its ID
 // @Tags projects
+// @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "Project ID"
 // @Param project body domain.Entity true "Project"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
 // @Success 200 {object} domain.Entity
-// @Failure 400 {object} gin.H{"error": string, "details": string}
-// @Failure 500 {object} gin.H{"error": string, "details": string}
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /projects/{id} [put]
-func UpdateProject(c *gin.Context) {
-	id, err := uuid.Parse(c.Param("id"))
+func (h *ProjectHandler) UpdateProject(c *gin.Context) {
+	id, err := pathID(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID", "details": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	var project domain.Entity
-	if err := c.ShouldBindJSON(&project); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project data", "details": err.Error()})
+	if err := bindJSON(c, &project); err != nil {
+		c.Error(err)
 		return
 	}
 
 	project.ID = id
-	if err := service.UpdateProject(&project); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update project", "details": err.Error()})
+	if err := h.service.Update(c.Request.Context(), &project); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -122,22 +218,35 @@ func UpdateProject(c *gin.Context) {
 
 // DeleteProject godoc
 // @Summary Delete a project
-// @Description Delete a project by its ID
+// @Description Delete a project by its ID. A project that still has tasks is only deleted with cascade=tasks, which deletes its tasks too.
 // @Tags projects
+// @Security BearerAuth
 // @Param id path string true "Project ID"
-// @Success 200 {object} gin.H{"message": string}
-// @Failure 400 {object} gin.H{"error": string, "details": string}
-// @Failure 500 {object} gin.H{"error": string, "details": string}
+// @Param cascade query string false "Delete the project's tasks too" Enums(tasks)
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /projects/{id} [delete]
-func DeleteProject(c *gin.Context) {
-	id, err := uuid.Parse(c.Param("id"))
+func (h *ProjectHandler) DeleteProject(c *gin.Context) {
+	id, err := pathID(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID", "details": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	cascade := c.Query("cascade")
+	if cascade != "" && cascade != "tasks" {
+		c.Error(domain.Validation("invalid_query", "invalid cascade parameter: expected \"tasks\", got %q", cascade))
 		return
 	}
 
-	if err := service.DeleteProject(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete project", "details": err.Error()})
+	if err := h.service.Delete(c.Request.Context(), id, cascade == "tasks"); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+// queryParser reads typed query parameters, remembering the first invalid
+// one so that a handler can check for errors once. Its error is an
+// "invalid_query" validation error.
+type queryParser struct {
+	c   *gin.Context
+	err error
+}
+
+func (p *queryParser) fail(name string, err error) {
+	if p.err == nil {
+		p.err = domain.Validation("invalid_query", "invalid %s parameter: %v", name, err)
+	}
+}
+
+func (p *queryParser) uuid(name string) uuid.UUID {
+	value := p.c.Query(name)
+	if value == "" {
+		return uuid.Nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		p.fail(name, err)
+	}
+	return id
+}
+
+// time accepts either an RFC 3339 timestamp or a plain date.
+func (p *queryParser) time(name string) time.Time {
+	value := p.c.Query(name)
+	if value == "" {
+		return time.Time{}
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		p.fail(name, fmt.Errorf("expected a date or RFC 3339 timestamp, got %q", value))
+	}
+	return t
+}
+
+func (p *queryParser) int(name string) int {
+	value := p.c.Query(name)
+	if value == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		p.fail(name, fmt.Errorf("expected a non-negative number, got %q", value))
+	}
+	return n
+}
+
+func (p *queryParser) bool(name string) bool {
+	value := p.c.Query(name)
+	if value == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		p.fail(name, fmt.Errorf("expected true or false, got %q", value))
+	}
+	return b
+}
+
+// list accepts comma-separated values and repeated parameters alike.
+func (p *queryParser) list(name string) []string {
+	var values []string
+	for _, value := range p.c.QueryArray(name) {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
+
+func parseSort[T any](p *queryParser, sorting repository.Sorting[T]) []repository.SortField {
+	sort, err := sorting.Parse(p.c.Query("sort"))
+	if err != nil {
+		p.fail("sort", err)
+	}
+	return sort
+}
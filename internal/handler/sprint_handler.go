@@ -0,0 +1,331 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	_ "github.com/yelnar0112/project-management/docs"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/service"
+)
+
+type SprintHandler struct {
+	service *service.SprintService
+}
+
+func NewSprintHandler(service *service.SprintService) *SprintHandler {
+	return &SprintHandler{service: service}
+}
+
+type sprintRequest struct {
+	Name      string    `json:"name"`
+	Goal      string    `json:"goal"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+}
+
+type closeSprintRequest struct {
+	RolloverTo uuid.UUID `json:"rollover_to"`
+	Backlog    bool      `json:"backlog"`
+}
+
+// sprintPath parses the :id and :sprint_id path parameters.
+func sprintPath(c *gin.Context) (projectID, sprintID uuid.UUID, err error) {
+	if projectID, err = pathID(c); err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	if sprintID, err = pathUUID(c, "sprint_id"); err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	return projectID, sprintID, nil
+}
+
+// GetSprints godoc
+// @Summary Get a project's sprints
+// @Description List the sprints of a project, ordered by start date
+// @Tags projects
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {array} domain.Sprint
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/sprints [get]
+func (h *SprintHandler) GetSprints(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	sprints, err := h.service.List(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, sprints)
+}
+
+// CreateSprint godoc
+// @Summary Plan a sprint
+// @Description Plan a sprint in a project. Its dates are required and must fall within those of the project.
+// @Tags projects
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param sprint body sprintRequest true "Sprint"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 201 {object} domain.Sprint
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/sprints [post]
+func (h *SprintHandler) CreateSprint(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req sprintRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	sprint := domain.Sprint{
+		ID:        uuid.New(),
+		ProjectID: id,
+		Name:      req.Name,
+		Goal:      req.Goal,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+	}
+	if err := h.service.Create(c.Request.Context(), &sprint); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, sprint)
+}
+
+// GetSprint godoc
+// @Summary Get a sprint
+// @Description Retrieve a sprint of a project
+// @Tags projects
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param sprint_id path string true "Sprint ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.Sprint
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/sprints/{sprint_id} [get]
+func (h *SprintHandler) GetSprint(c *gin.Context) {
+	projectID, sprintID, err := sprintPath(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	sprint, err := h.service.Get(c.Request.Context(), projectID, sprintID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, sprint)
+}
+
+// UpdateSprint godoc
+// @Summary Update a sprint
+// @Description Rename a sprint, or change its goal or dates. Closed sprints cannot be changed.
+// @Tags projects
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param sprint_id path string true "Sprint ID"
+// @Param sprint body sprintRequest true "Sprint"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.Sprint
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/sprints/{sprint_id} [put]
+func (h *SprintHandler) UpdateSprint(c *gin.Context) {
+	projectID, sprintID, err := sprintPath(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req sprintRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	sprint := domain.Sprint{
+		ID:        sprintID,
+		ProjectID: projectID,
+		Name:      req.Name,
+		Goal:      req.Goal,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+	}
+	if err := h.service.Update(c.Request.Context(), &sprint); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, sprint)
+}
+
+// DeleteSprint godoc
+// @Summary Delete a sprint
+// @Description Delete a sprint of a project. Its tasks go back to the backlog.
+// @Tags projects
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Param sprint_id path string true "Sprint ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/sprints/{sprint_id} [delete]
+func (h *SprintHandler) DeleteSprint(c *gin.Context) {
+	projectID, sprintID, err := sprintPath(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), projectID, sprintID); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Sprint deleted successfully"})
+}
+
+// StartSprint godoc
+// @Summary Start a sprint
+// @Description Start a planned sprint, committing to the tasks in it. A project runs one sprint at a time; starting another is refused with the active one in sprint.
+// @Tags projects
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param sprint_id path string true "Sprint ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.Sprint
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/sprints/{sprint_id}/start [post]
+func (h *SprintHandler) StartSprint(c *gin.Context) {
+	projectID, sprintID, err := sprintPath(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	sprint, err := h.service.Start(c.Request.Context(), projectID, sprintID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, sprint)
+}
+
+// CloseSprint godoc
+// @Summary Close a sprint
+// @Description Close the active sprint. Completed tasks stay in it; the others roll over to the planned sprint rollover_to, by default the next planned one, or to the backlog with backlog or when no sprint is planned.
+// @Tags projects
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param sprint_id path string true "Sprint ID"
+// @Param rollover body closeSprintRequest false "Where unfinished tasks go"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.Sprint
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/sprints/{sprint_id}/close [post]
+func (h *SprintHandler) CloseSprint(c *gin.Context) {
+	projectID, sprintID, err := sprintPath(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// The body is optional, as tasks roll over to the next sprint by default.
+	var req closeSprintRequest
+	if c.Request.ContentLength != 0 {
+		if err := bindJSON(c, &req); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+
+	sprint, err := h.service.Close(c.Request.Context(), projectID, sprintID, req.RolloverTo, req.Backlog)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, sprint)
+}
+
+// GetSprintReport godoc
+// @Summary Get a sprint report
+// @Description Compare the work committed to a sprint when it started with the work completed in it, in tasks and original estimates. Until the sprint closes, completed and unfinished are of the tasks in it now.
+// @Tags projects
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param sprint_id path string true "Sprint ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.SprintReport
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/sprints/{sprint_id}/report [get]
+func (h *SprintHandler) GetSprintReport(c *gin.Context) {
+	projectID, sprintID, err := sprintPath(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	report, err := h.service.Report(c.Request.Context(), projectID, sprintID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
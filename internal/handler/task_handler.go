@@ -1,27 +1,69 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	_ "github.com/yelnar0112/project-management/docs"
 	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
 	"github.com/yelnar0112/project-management/internal/service"
 )
 
+type TaskHandler struct {
+	service *service.TaskService
+}
+
+func NewTaskHandler(service *service.TaskService) *TaskHandler {
+	return &TaskHandler{service: service}
+}
+
 // GetTasks godoc
 // @Summary Get all tasks
-// @Description Retrieve a list of all tasks
+// @Description Retrieve a page of tasks, optionally filtered and sorted. List parameters accept comma-separated values; dates are RFC 3339 timestamps or YYYY-MM-DD, with *_after inclusive and *_before exclusive.
 // @Tags tasks
+// @Security BearerAuth
 // @Produce json
-// @Success 200 {array} domain.Task
-// @Failure 500 {object} gin.H{"error": string}
+// @Param state query string false "Task states"
+// @Param priority query string false "Task priorities"
+// @Param assignee query string false "Assignee ID"
+// @Param project_id query string false "Project ID"
+// @Param parent_id query string false "Parent task ID"
+// @Param sprint_id query string false "Sprint ID"
+// @Param backlog query bool false "Only tasks in no sprint"
+// @Param milestone_id query string false "Milestone ID"
+// @Param created_after query string false "Created at or after"
+// @Param created_before query string false "Created before"
+// @Param completed_after query string false "Completed at or after"
+// @Param completed_before query string false "Completed before"
+// @Param due_after query string false "Due at or after"
+// @Param due_before query string false "Due before"
+// @Param overdue query bool false "Only open tasks past their due date"
+// @Param labels query string false "Label names, in any letter case"
+// @Param labels_match query string false "Whether tasks need any (the default) or all of the labels" Enums(any, all)
+// @Param sort query string false "Sort fields: priority, created_at, completed_at, due_date, title; prefix with - to reverse"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} repository.Page[domain.Task]
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /tasks/ [get]
-func GetTasks(c *gin.Context) {
-	tasks, err := service.GetAllTasks()
+func (h *TaskHandler) GetTasks(c *gin.Context) {
+	query, err := parseTaskQuery(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	tasks, err := h.service.GetAll(c.Request.Context(), query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, tasks)
@@ -31,23 +73,27 @@ func GetTasks(c *gin.Context) {
 // @Summary Create a new task
 // @Description Create a new task in the system
 // @Tags tasks
+// @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param task body domain.Task true "Task"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
 // @Success 201 {object} domain.Task
-// @Failure 400 {object} gin.H{"error": string}
-// @Failure 500 {object} gin.H{"error": string}
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /tasks/ [post]
-func CreateTask(c *gin.Context) {
+func (h *TaskHandler) CreateTask(c *gin.Context) {
 	var task domain.Task
-	if err := c.ShouldBindJSON(&task); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bindJSON(c, &task); err != nil {
+		c.Error(err)
 		return
 	}
 
 	task.ID = uuid.New()
-	if err := service.CreateTask(&task); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.service.Create(c.Request.Context(), &task); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -58,22 +104,27 @@ func CreateTask(c *gin.Context) {
 // @Summary Get a task by ID
 // @Description Retrieve a task by its ID
 // @Tags tasks
+// @Security BearerAuth
 // @Produce json
 // @Param id path string true "Task ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
 // @Success 200 {object} domain.Task
-// @Failure 400 {object} gin.H{"error": string}
-// @Failure 404 {object} gin.H{"error": string}
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /tasks/{id} [get]
-func GetTask(c *gin.Context) {
-	id, err := uuid.Parse(c.Param("id"))
+func (h *TaskHandler) GetTask(c *gin.Context) {
+	id, err := pathID(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	task, err := service.GetTask(id)
+	task, err := h.service.Get(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -82,32 +133,38 @@ func GetTask(c *gin.Context) {
 
 // UpdateTask godoc
 // @Summary Update a task
-// @Description Update a task by its ID
+// @Description Update a task by its ID. A change of state must be allowed by the project's workflow. Moving a task to another project takes its subtasks along.
 // @Tags tasks
+// @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "Task ID"
 // @Param task body domain.Task true "Task"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
 // @Success 200 {object} domain.Task
-// @Failure 400 {object} gin.H{"error": string}
-// @Failure 500 {object} gin.H{"error": string}
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /tasks/{id} [put]
-func UpdateTask(c *gin.Context) {
-	id, err := uuid.Parse(c.Param("id"))
+func (h *TaskHandler) UpdateTask(c *gin.Context) {
+	id, err := pathID(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	var task domain.Task
-	if err := c.ShouldBindJSON(&task); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bindJSON(c, &task); err != nil {
+		c.Error(err)
 		return
 	}
 
 	task.ID = id
-	if err := service.UpdateTask(&task); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.service.Update(c.Request.Context(), &task); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -116,24 +173,477 @@ func UpdateTask(c *gin.Context) {
 
 // DeleteTask godoc
 // @Summary Delete a task
-// @Description Delete a task by its ID
+// @Description Delete a task by its ID. A task that still has subtasks is only deleted with cascade=subtasks, which deletes them too.
 // @Tags tasks
+// @Security BearerAuth
 // @Param id path string true "Task ID"
-// @Success 200 {object} gin.H{"message": string}
-// @Failure 400 {object} gin.H{"error": string}
-// @Failure 500 {object} gin.H{"error": string}
+// @Param cascade query string false "Delete the task's subtasks too" Enums(subtasks)
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /tasks/{id} [delete]
-func DeleteTask(c *gin.Context) {
-	id, err := uuid.Parse(c.Param("id"))
+func (h *TaskHandler) DeleteTask(c *gin.Context) {
+	id, err := pathID(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	cascade := c.Query("cascade")
+	if cascade != "" && cascade != "subtasks" {
+		c.Error(domain.Validation("invalid_query", "invalid cascade parameter: expected \"subtasks\", got %q", cascade))
 		return
 	}
 
-	if err := service.DeleteTask(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.service.Delete(c.Request.Context(), id, cascade == "subtasks"); err != nil {
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Task deleted successfully"})
 }
+
+// GetProjectTasks godoc
+// @Summary Get a project's tasks
+// @Description Retrieve a page of the tasks of a project. Accepts the same filters, sorting and pagination as GET /tasks, except project_id.
+// @Tags projects
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param state query string false "Task states"
+// @Param priority query string false "Task priorities"
+// @Param assignee query string false "Assignee ID"
+// @Param sort query string false "Sort fields: priority, created_at, completed_at, due_date, title; prefix with - to reverse"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} repository.Page[domain.Task]
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/tasks [get]
+func (h *TaskHandler) GetProjectTasks(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	query, err := parseTaskQuery(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	tasks, err := h.service.GetForProject(c.Request.Context(), id, query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, tasks)
+}
+
+// CreateProjectTask godoc
+// @Summary Create a task in a project
+// @Description Create a new task in a project. The project_id of the body is ignored.
+// @Tags projects
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param task body domain.Task true "Task"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 201 {object} domain.Task
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/tasks [post]
+func (h *TaskHandler) CreateProjectTask(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var task domain.Task
+	if err := bindJSON(c, &task); err != nil {
+		c.Error(err)
+		return
+	}
+
+	task.ID = uuid.New()
+	if err := h.service.CreateInProject(c.Request.Context(), id, &task); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, task)
+}
+
+// GetSubtasks godoc
+// @Summary Get a task's subtasks
+// @Description Retrieve a page of the tasks directly below a task. Accepts the same filters, sorting and pagination as GET /tasks, except parent_id.
+// @Tags tasks
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param state query string false "Task states"
+// @Param priority query string false "Task priorities"
+// @Param assignee query string false "Assignee ID"
+// @Param sort query string false "Sort fields: priority, created_at, completed_at, due_date, title; prefix with - to reverse"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} repository.Page[domain.Task]
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /tasks/{id}/subtasks [get]
+func (h *TaskHandler) GetSubtasks(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	query, err := parseTaskQuery(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	tasks, err := h.service.GetSubtasks(c.Request.Context(), id, query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, tasks)
+}
+
+// CreateSubtask godoc
+// @Summary Create a subtask
+// @Description Create a new task below a task, in its project. The parent_id and project_id of the body are ignored.
+// @Tags tasks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Parent task ID"
+// @Param task body domain.Task true "Task"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 201 {object} domain.Task
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /tasks/{id}/subtasks [post]
+func (h *TaskHandler) CreateSubtask(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var task domain.Task
+	if err := bindJSON(c, &task); err != nil {
+		c.Error(err)
+		return
+	}
+
+	task.ID = uuid.New()
+	if err := h.service.CreateSubtask(c.Request.Context(), id, &task); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, task)
+}
+
+// GetTaskTree godoc
+// @Summary Get a task's tree
+// @Description Get a task with all of its subtasks, at any depth, oldest first. Each task reports the progress of the subtasks below it: how many are in a terminal state of the project's workflow.
+// @Tags tasks
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.TaskTree
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /tasks/{id}/tree [get]
+func (h *TaskHandler) GetTaskTree(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	tree, err := h.service.Tree(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, tree)
+}
+
+type addTaskLabelRequest struct {
+	LabelID uuid.UUID `json:"label_id"`
+}
+
+// AddTaskLabel godoc
+// @Summary Label a task
+// @Description Put a label of the task's project on the task. Adding a label the task already has changes nothing.
+// @Tags tasks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param label body addTaskLabelRequest true "Label"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.Task
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /tasks/{id}/labels [post]
+func (h *TaskHandler) AddTaskLabel(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req addTaskLabelRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	task, err := h.service.AddLabel(c.Request.Context(), id, req.LabelID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, task)
+}
+
+// RemoveTaskLabel godoc
+// @Summary Unlabel a task
+// @Description Take a label off the task
+// @Tags tasks
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param label_id path string true "Label ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.Task
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /tasks/{id}/labels/{label_id} [delete]
+func (h *TaskHandler) RemoveTaskLabel(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	labelID, err := pathUUID(c, "label_id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	task, err := h.service.RemoveLabel(c.Request.Context(), id, labelID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, task)
+}
+
+// GetUserTasks godoc
+// @Summary Get a user's tasks
+// @Description Retrieve a page of the tasks assigned to a user. Accepts the same filters, sorting and pagination as GET /tasks, except assignee.
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Param state query string false "Task states"
+// @Param priority query string false "Task priorities"
+// @Param project_id query string false "Project ID"
+// @Param sort query string false "Sort fields: priority, created_at, completed_at, due_date, title; prefix with - to reverse"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} repository.Page[domain.Task]
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /user/{id}/tasks [get]
+func (h *TaskHandler) GetUserTasks(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	query, err := parseTaskQuery(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	tasks, err := h.service.GetForUser(c.Request.Context(), id, query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, tasks)
+}
+
+type transitionRequest struct {
+	To string `json:"to" binding:"required"`
+}
+
+// TransitionTask godoc
+// @Summary Move a task to another state
+// @Description Move a task to another state of its project's workflow. Entering a terminal state sets completed_at, leaving one clears it.
+// @Tags tasks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param transition body transitionRequest true "Target state"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.Task
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /tasks/{id}/transitions [post]
+func (h *TaskHandler) TransitionTask(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req transitionRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	task, err := h.service.Transition(c.Request.Context(), id, req.To)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+type moveRequest struct {
+	Column string    `json:"column" binding:"required"`
+	Before uuid.UUID `json:"before"`
+	After  uuid.UUID `json:"after"`
+}
+
+// MoveTask godoc
+// @Summary Move a task on its project's board
+// @Description Put a task in a board column, right before or after another task in it, or else at its top. Entering another column moves the task to the first of its states that the workflow allows. Columns at their WIP limit refuse the task or let it in with a warning, depending on their policy.
+// @Tags tasks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param move body moveRequest true "Target column and place"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.TaskMove
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /tasks/{id}/move [post]
+func (h *TaskHandler) MoveTask(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req moveRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	move, err := h.service.Move(c.Request.Context(), id, req.Column, req.Before, req.After)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, move)
+}
+
+func parseTaskQuery(c *gin.Context) (repository.TaskQuery, error) {
+	p := queryParser{c: c}
+	query := repository.TaskQuery{
+		States:          p.list("state"),
+		Assignee:        p.uuid("assignee"),
+		ProjectID:       p.uuid("project_id"),
+		ParentID:        p.uuid("parent_id"),
+		SprintID:        p.uuid("sprint_id"),
+		MilestoneID:     p.uuid("milestone_id"),
+		Backlog:         p.bool("backlog"),
+		CreatedAfter:    p.time("created_after"),
+		CreatedBefore:   p.time("created_before"),
+		CompletedAfter:  p.time("completed_after"),
+		CompletedBefore: p.time("completed_before"),
+		DueAfter:        p.time("due_after"),
+		DueBefore:       p.time("due_before"),
+		Sort:            parseSort(&p, repository.TaskSorting),
+		Limit:           p.int("limit"),
+		Cursor:          c.Query("cursor"),
+	}
+	for _, value := range p.list("priority") {
+		priority, err := domain.ParsePriority(value)
+		if err != nil {
+			p.fail("priority", err)
+		}
+		query.Priorities = append(query.Priorities, priority)
+	}
+	if p.bool("overdue") {
+		query.OverdueAt = time.Now().UTC()
+	}
+	query.Labels = p.list("labels")
+	switch match := c.Query("labels_match"); match {
+	case "", "any":
+	case "all":
+		query.AllLabels = true
+	default:
+		p.fail("labels_match", fmt.Errorf("expected any or all, got %q", match))
+	}
+	return query, p.err
+}
@@ -1,28 +1,63 @@
 package handler
 
 import (
-	"database/sql"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	_ "github.com/yelnar0112/project-management/docs"
 	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
 	"github.com/yelnar0112/project-management/internal/service"
 )
 
+type UserHandler struct {
+	service *service.UserService
+}
+
+func NewUserHandler(service *service.UserService) *UserHandler {
+	return &UserHandler{service: service}
+}
+
+// userRequest is a user as sent by clients, who may also set its password.
+type userRequest struct {
+	domain.User
+	Password string `json:"password"`
+}
+
 // GetUsers godoc
 // @Summary Get all users
-// @Description Get all users
+// @Description Get a page of users, optionally filtered by role and sorted
 // @Tags users
+// @Security BearerAuth
 // @Produce json
-// @Success 200 {array} domain.User
-// @Failure 500 {object} gin.H{"error": string}
+// @Param role query string false "Role"
+// @Param sort query string false "Sort fields: full_name, email, registration; prefix with - to reverse"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} repository.Page[domain.User]
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /user/ [get]
-func GetUsers(c *gin.Context) {
-	users, err := service.GetAllUsers()
+func (h *UserHandler) GetUsers(c *gin.Context) {
+	p := queryParser{c: c}
+	query := repository.UserQuery{
+		Role:   domain.Role(c.Query("role")),
+		Sort:   parseSort(&p, repository.UserSorting),
+		Limit:  p.int("limit"),
+		Cursor: c.Query("cursor"),
+	}
+	if p.err != nil {
+		c.Error(p.err)
+		return
+	}
+
+	users, err := h.service.GetAll(c.Request.Context(), query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve users", "details": err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, users)
@@ -30,25 +65,31 @@ func GetUsers(c *gin.Context) {
 
 // CreateUser godoc
 // @Summary Create a user
-// @Description Create a new user
+// @Description Create a new user. Users created without a password cannot log in, and those created without a handle get the part of their email before the @ when it is a free, valid handle.
 // @Tags users
+// @Security BearerAuth
 // @Accept json
 // @Produce json
-// @Param user body domain.User true "User"
+// @Param user body userRequest true "User"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
 // @Success 201 {object} domain.User
-// @Failure 400 {object} gin.H{"error": string}
-// @Failure 500 {object} gin.H{"error": string}
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /user/ [post]
-func CreateUser(c *gin.Context) {
-	var user domain.User
-	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user data", "details": err.Error()})
+func (h *UserHandler) CreateUser(c *gin.Context) {
+	var req userRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
 		return
 	}
 
+	user := req.User
 	user.ID = uuid.New()
-	if err := service.CreateUser(&user); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user", "details": err.Error()})
+	if err := h.service.Create(c.Request.Context(), &user, req.Password); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -59,26 +100,27 @@ func CreateUser(c *gin.Context) {
 // @Summary Get a user
 // @Description Get a user by ID
 // @Tags users
+// @Security BearerAuth
 // @Produce json
 // @Param id path string true "User ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
 // @Success 200 {object} domain.User
-// @Failure 400 {object} gin.H{"error": string}
-// @Failure 404 {object} gin.H{"error": string}
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /user/{id} [get]
-func GetUser(c *gin.Context) {
-	id, err := uuid.Parse(c.Param("id"))
+func (h *UserHandler) GetUser(c *gin.Context) {
+	id, err := pathID(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID", "details": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	user, err := service.GetUser(id)
+	user, err := h.service.Get(c.Request.Context(), id)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user", "details": err.Error()})
-		}
+		c.Error(err)
 		return
 	}
 
@@ -87,32 +129,39 @@ func GetUser(c *gin.Context) {
 
 // UpdateUser godoc
 // @Summary Update a user
-// @Description Update a user by ID
+// @Description Update a user by ID. The handle is kept unless a new one is given. The password is kept unless a new one is given, which signs the user out of every session.
 // @Tags users
+// @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param id path string true "User ID"
-// @Param user body domain.User true "User"
+// @Param user body userRequest true "User"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
 // @Success 200 {object} domain.User
-// @Failure 400 {object} gin.H{"error": string}
-// @Failure 500 {object} gin.H{"error": string}
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /user/{id} [put]
-func UpdateUser(c *gin.Context) {
-	id, err := uuid.Parse(c.Param("id"))
+func (h *UserHandler) UpdateUser(c *gin.Context) {
+	id, err := pathID(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID", "details": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	var user domain.User
-	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user data", "details": err.Error()})
+	var req userRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
 		return
 	}
 
+	user := req.User
 	user.ID = id
-	if err := service.UpdateUser(&user); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user", "details": err.Error()})
+	if err := h.service.Update(c.Request.Context(), &user, req.Password); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -121,22 +170,40 @@ func UpdateUser(c *gin.Context) {
 
 // DeleteUser godoc
 // @Summary Delete a user
-// @Description Delete a user by ID
+// @Description Delete a user by ID. A user with open tasks can only be deleted by reassigning them to another user or unassigning them; both happen in the same transaction as the delete.
 // @Tags users
+// @Security BearerAuth
 // @Param id path string true "User ID"
-// @Success 200 {object} gin.H{"message": string}
-// @Failure 400 {object} gin.H{"error": string}
-// @Failure 500 {object} gin.H{"error": string}
+// @Param reassign_to query string false "User to hand the open tasks to"
+// @Param unassign query bool false "Leave the open tasks unassigned"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /user/{id} [delete]
-func DeleteUser(c *gin.Context) {
-	id, err := uuid.Parse(c.Param("id"))
+func (h *UserHandler) DeleteUser(c *gin.Context) {
+	id, err := pathID(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID", "details": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	p := queryParser{c: c}
+	deletion := service.UserDeletion{
+		ReassignTo: p.uuid("reassign_to"),
+		Unassign:   p.bool("unassign"),
+	}
+	if p.err != nil {
+		c.Error(p.err)
 		return
 	}
 
-	if err := service.DeleteUser(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user", "details": err.Error()})
+	if err := h.service.Delete(c.Request.Context(), id, deletion); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	_ "github.com/yelnar0112/project-management/docs"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/service"
+)
+
+type WorkflowHandler struct {
+	service *service.WorkflowService
+}
+
+func NewWorkflowHandler(service *service.WorkflowService) *WorkflowHandler {
+	return &WorkflowHandler{service: service}
+}
+
+// GetWorkflow godoc
+// @Summary Get a project's workflow
+// @Description Retrieve the task states and transitions of a project. Projects without their own workflow use the default one.
+// @Tags projects
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.Workflow
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/workflow [get]
+func (h *WorkflowHandler) GetWorkflow(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	workflow, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, workflow)
+}
+
+// UpdateWorkflow godoc
+// @Summary Update a project's workflow
+// @Description Replace the task states and allowed transitions of a project. States that tasks are still in, or that columns of the project's board still map, cannot be removed, and states that tasks are still in cannot be made terminal or not.
+// @Tags projects
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param workflow body domain.Workflow true "Workflow"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.Workflow
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/workflow [put]
+func (h *WorkflowHandler) UpdateWorkflow(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var workflow domain.Workflow
+	if err := bindJSON(c, &workflow); err != nil {
+		c.Error(err)
+		return
+	}
+
+	workflow.ProjectID = id
+	if err := h.service.Update(c.Request.Context(), &workflow); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, workflow)
+}
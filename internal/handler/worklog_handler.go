@@ -0,0 +1,400 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	_ "github.com/yelnar0112/project-management/docs"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/service"
+)
+
+type WorklogHandler struct {
+	service *service.WorklogService
+}
+
+func NewWorklogHandler(service *service.WorklogService) *WorklogHandler {
+	return &WorklogHandler{service: service}
+}
+
+// worklogRequest gives the work by started_at and ended_at, by started_at
+// and seconds, or by seconds alone, in which case it ended now.
+type worklogRequest struct {
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at"`
+	Seconds   int64     `json:"seconds"`
+	Note      string    `json:"note"`
+}
+
+type startTimerRequest struct {
+	Note string `json:"note"`
+}
+
+// worklogPath parses the :id and :worklog_id path parameters.
+func worklogPath(c *gin.Context) (taskID, worklogID uuid.UUID, err error) {
+	if taskID, err = pathID(c); err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	if worklogID, err = pathUUID(c, "worklog_id"); err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	return taskID, worklogID, nil
+}
+
+// timesheetRange parses the from and to query parameters.
+func timesheetRange(c *gin.Context) (from, to time.Time, err error) {
+	p := queryParser{c: c}
+	from, to = p.time("from"), p.time("to")
+	return from, to, p.err
+}
+
+// GetWorklogs godoc
+// @Summary Get a task's worklogs
+// @Description List the time logged on a task, ordered by when the work started. Running timers have a zero ended_at.
+// @Tags tasks
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {array} domain.Worklog
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /tasks/{id}/worklogs [get]
+func (h *WorklogHandler) GetWorklogs(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	worklogs, err := h.service.List(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, worklogs)
+}
+
+// CreateWorklog godoc
+// @Summary Log time on a task
+// @Description Log time the caller spent on a task, given by started_at and ended_at, by started_at and seconds, or by seconds alone, taken to have ended now. The time comes off the task's remaining estimate, down to 0.
+// @Tags tasks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param worklog body worklogRequest true "Worklog"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 201 {object} domain.Worklog
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /tasks/{id}/worklogs [post]
+func (h *WorklogHandler) CreateWorklog(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req worklogRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	worklog := domain.Worklog{
+		ID:        uuid.New(),
+		TaskID:    id,
+		StartedAt: req.StartedAt,
+		EndedAt:   req.EndedAt,
+		Seconds:   req.Seconds,
+		Note:      req.Note,
+	}
+	if err := h.service.Create(c.Request.Context(), &worklog); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, worklog)
+}
+
+// GetWorklog godoc
+// @Summary Get a worklog
+// @Description Retrieve a worklog of a task
+// @Tags tasks
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param worklog_id path string true "Worklog ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.Worklog
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /tasks/{id}/worklogs/{worklog_id} [get]
+func (h *WorklogHandler) GetWorklog(c *gin.Context) {
+	taskID, worklogID, err := worklogPath(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	worklog, err := h.service.Get(c.Request.Context(), taskID, worklogID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, worklog)
+}
+
+// UpdateWorklog godoc
+// @Summary Update a worklog
+// @Description Replace the times and note of a worklog, given like when logging time. Running timers must be stopped first. The task's remaining estimate follows the change in the time logged.
+// @Tags tasks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param worklog_id path string true "Worklog ID"
+// @Param worklog body worklogRequest true "Worklog"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.Worklog
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /tasks/{id}/worklogs/{worklog_id} [put]
+func (h *WorklogHandler) UpdateWorklog(c *gin.Context) {
+	taskID, worklogID, err := worklogPath(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var req worklogRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	worklog := domain.Worklog{
+		ID:        worklogID,
+		TaskID:    taskID,
+		StartedAt: req.StartedAt,
+		EndedAt:   req.EndedAt,
+		Seconds:   req.Seconds,
+		Note:      req.Note,
+	}
+	if err := h.service.Update(c.Request.Context(), &worklog); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, worklog)
+}
+
+// DeleteWorklog godoc
+// @Summary Delete a worklog
+// @Description Remove a worklog from a task. Deleting a running timer discards it. The time logged goes back on the task's remaining estimate, up to its original estimate.
+// @Tags tasks
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param worklog_id path string true "Worklog ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /tasks/{id}/worklogs/{worklog_id} [delete]
+func (h *WorklogHandler) DeleteWorklog(c *gin.Context) {
+	taskID, worklogID, err := worklogPath(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), taskID, worklogID); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Worklog deleted successfully"})
+}
+
+// StartTimer godoc
+// @Summary Start a timer on a task
+// @Description Start logging the caller's time on a task. Users run at most one timer at a time; starting another is refused with the running one in worklog.
+// @Tags tasks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param timer body startTimerRequest false "Note"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 201 {object} domain.Worklog
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /tasks/{id}/timer/start [post]
+func (h *WorklogHandler) StartTimer(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// The body is optional, as a timer needs no note.
+	var req startTimerRequest
+	if c.Request.ContentLength != 0 {
+		if err := bindJSON(c, &req); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+
+	worklog, err := h.service.StartTimer(c.Request.Context(), id, req.Note)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, worklog)
+}
+
+// StopTimer godoc
+// @Summary Stop the timer on a task
+// @Description Stop the timer the caller is running on a task, logging the time it ran and taking it off the task's remaining estimate
+// @Tags tasks
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.Worklog
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /tasks/{id}/timer/stop [post]
+func (h *WorklogHandler) StopTimer(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	worklog, err := h.service.StopTimer(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, worklog)
+}
+
+// GetTimer godoc
+// @Summary Get my running timer
+// @Description Retrieve the timer the caller is running, if any
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.Worklog
+// @Failure 401 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /me/timer [get]
+func (h *WorklogHandler) GetTimer(c *gin.Context) {
+	worklog, err := h.service.Timer(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, worklog)
+}
+
+// GetProjectTimesheet godoc
+// @Summary Get a project's timesheet
+// @Description Sum up the time logged on a project's tasks by day, user and task, and in total by user, project and task. Work counts on the day it started, in UTC; running timers are left out. Without a range, the last 7 days are covered.
+// @Tags projects
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param from query string false "Work started at or after"
+// @Param to query string false "Work started before"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.Timesheet
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /projects/{id}/timesheet [get]
+func (h *WorklogHandler) GetProjectTimesheet(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	from, to, err := timesheetRange(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	timesheet, err := h.service.ProjectTimesheet(c.Request.Context(), id, from, to)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, timesheet)
+}
+
+// GetUserTimesheet godoc
+// @Summary Get a user's timesheet
+// @Description Sum up the time a user logged on the organization's tasks, like a project's timesheet
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Param from query string false "Work started at or after"
+// @Param to query string false "Work started before"
+// @Param X-Organization-ID header string false "Organization to act in, by default the first one the caller joined"
+// @Success 200 {object} domain.Timesheet
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /user/{id}/timesheet [get]
+func (h *WorklogHandler) GetUserTimesheet(c *gin.Context) {
+	id, err := pathID(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	from, to, err := timesheetRange(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	timesheet, err := h.service.UserTimesheet(c.Request.Context(), id, from, to)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, timesheet)
+}
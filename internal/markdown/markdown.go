@@ -0,0 +1,102 @@
+// Package markdown turns the Markdown that users write into HTML that is safe
+// to put on a page, and finds the people it mentions.
+package markdown
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+// GitHub flavoured Markdown without linkify, which would turn the email in
+// every mention into a mailto link.
+var parser = goldmark.New(goldmark.WithExtensions(extension.Table, extension.Strikethrough, extension.TaskList))
+
+// policy strips whatever could run script or change the page around the
+// content, even though goldmark already leaves raw HTML out.
+var policy = bluemonday.UGCPolicy()
+
+// mention matches "@" followed by an email address or else by a handle,
+// when the "@" does not itself continue a word or an address. A handle
+// starts and ends with a letter or digit, so that punctuation after it is
+// left out.
+var mention = regexp.MustCompile(`(?:^|[^\w.@+-])@(?:([\w.%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)+)|([A-Za-z0-9](?:[A-Za-z0-9._-]*[A-Za-z0-9])?))`)
+
+// maxHandleLength matches domain.MaxHandleLength.
+const maxHandleLength = 39
+
+// Render converts source to sanitized HTML.
+func Render(source string) string {
+	var buf bytes.Buffer
+	if err := parser.Convert([]byte(source), &buf); err != nil {
+		// Rendering into a buffer does not fail; fall back to plain text
+		// anyway rather than lose the content.
+		return "<p>" + html.EscapeString(source) + "</p>"
+	}
+	return policy.Sanitize(buf.String())
+}
+
+// Mentions returns the lowercased email addresses and handles mentioned as
+// "@ada@example.com" or "@ada" in source, each once, in the order they
+// first appear. Mentions inside code and raw HTML do not count.
+func Mentions(source string) []string {
+	src := []byte(source)
+	doc := parser.Parser().Parse(text.NewReader(src))
+
+	var plain strings.Builder
+	ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		switch n := node.(type) {
+		case *ast.CodeSpan, *ast.CodeBlock, *ast.FencedCodeBlock, *ast.HTMLBlock, *ast.RawHTML:
+			if entering {
+				plain.WriteByte(' ')
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.Text:
+			if entering {
+				plain.Write(n.Segment.Value(src))
+				if n.SoftLineBreak() || n.HardLineBreak() {
+					plain.WriteByte('\n')
+				}
+			}
+		case *ast.String:
+			if entering {
+				plain.Write(n.Value)
+			}
+		default:
+			if node.Type() == ast.TypeBlock && !entering {
+				plain.WriteByte('\n')
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+
+	var mentions []string
+	seen := make(map[string]bool)
+	content := plain.String()
+	for _, match := range mention.FindAllStringSubmatchIndex(content, -1) {
+		var name string
+		if match[2] >= 0 {
+			name = content[match[2]:match[3]]
+		} else {
+			// A handle running into an "@" or into characters it cannot end
+			// with is not one.
+			name = content[match[4]:match[5]]
+			if len(name) > maxHandleLength || match[5] < len(content) && strings.ContainsAny(content[match[5]:match[5]+1], "@_") {
+				continue
+			}
+		}
+		name = strings.ToLower(name)
+		if !seen[name] {
+			seen[name] = true
+			mentions = append(mentions, name)
+		}
+	}
+	return mentions
+}
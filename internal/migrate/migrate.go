@@ -0,0 +1,384 @@
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed migrations
+var migrationsFS embed.FS
+
+// lockKey identifies the advisory lock held while migrations run, so that
+// several replicas starting at once apply the schema only once.
+const lockKey int64 = 7_245_031_118
+
+var fileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is a single versioned schema change.
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// Status describes whether a migration has been applied to the database.
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+	Modified  bool
+}
+
+// Migrator applies and reverts the embedded migrations.
+type Migrator struct {
+	db         *sql.DB
+	driver     string
+	migrations []Migration
+}
+
+// New loads the embedded migrations for driver, either "postgres" or
+// "sqlite". Files are named NNNN_name.up.sql and NNNN_name.down.sql.
+func New(db *sql.DB, driver string) (*Migrator, error) {
+	switch driver {
+	case "postgres", "sqlite":
+	default:
+		return nil, fmt.Errorf("no migrations for driver %q", driver)
+	}
+
+	source, err := fs.Sub(migrationsFS, path.Join("migrations", driver))
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := load(source)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, driver: driver, migrations: migrations}, nil
+}
+
+// Up applies every pending migration in version order.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.verify(applied); err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			if err := m.checkEmpty(ctx, conn); err != nil {
+				return err
+			}
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			if err := m.apply(ctx, conn, migration); err != nil {
+				return err
+			}
+			log.Printf("Applied migration %04d_%s", migration.Version, migration.Name)
+		}
+		return nil
+	})
+}
+
+// Down reverts the most recently applied migrations, up to steps of them.
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.verify(applied); err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && steps > 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if err := m.revert(ctx, conn, migration); err != nil {
+				return err
+			}
+			log.Printf("Reverted migration %04d_%s", migration.Version, migration.Name)
+			steps--
+		}
+		return nil
+	})
+}
+
+// Baseline records the migrations up to version as applied without running
+// them, adopting a database whose schema was created before migrations were
+// tracked. It refuses to touch a database that already has migrations
+// recorded.
+func (m *Migrator) Baseline(ctx context.Context, version int64) error {
+	at := sort.Search(len(m.migrations), func(i int) bool { return m.migrations[i].Version >= version })
+	if at == len(m.migrations) || m.migrations[at].Version != version {
+		return fmt.Errorf("no migration %04d to baseline at", version)
+	}
+
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if len(applied) > 0 {
+			return errors.New("the database already has migrations recorded")
+		}
+
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		now := time.Now().UTC()
+		for _, migration := range m.migrations[:at+1] {
+			if _, err := tx.ExecContext(ctx,
+				"INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES ($1, $2, $3, $4)",
+				migration.Version, migration.Name, migration.Checksum, now,
+			); err != nil {
+				return err
+			}
+			log.Printf("Recorded migration %04d_%s as applied", migration.Version, migration.Name)
+		}
+		return tx.Commit()
+	})
+}
+
+// Status reports every known migration and whether it has been applied.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			status := Status{Version: migration.Version, Name: migration.Name}
+			if record, ok := applied[migration.Version]; ok {
+				status.Applied = true
+				status.AppliedAt = record.appliedAt
+				status.Modified = record.checksum != migration.Checksum
+			}
+			statuses = append(statuses, status)
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+type record struct {
+	checksum  string
+	appliedAt time.Time
+}
+
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("error acquiring connection: %w", err)
+	}
+	defer conn.Close()
+
+	// SQLite has no advisory locks, but it is only ever used by a single
+	// process, so there is nobody to race with.
+	timestampType := "TIMESTAMP"
+	if m.driver == "postgres" {
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockKey); err != nil {
+			return fmt.Errorf("error acquiring migration lock: %w", err)
+		}
+		defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockKey)
+		timestampType = "TIMESTAMPTZ"
+	}
+
+	_, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    BIGINT PRIMARY KEY,
+		name       TEXT NOT NULL,
+		checksum   TEXT NOT NULL,
+		applied_at `+timestampType+` NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("error creating schema_migrations: %w", err)
+	}
+
+	return fn(conn)
+}
+
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[int64]record, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, checksum, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]record)
+	for rows.Next() {
+		var version int64
+		var r record
+		if err := rows.Scan(&version, &r.checksum, &r.appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = r
+	}
+
+	return applied, rows.Err()
+}
+
+// verify makes sure the database has not diverged from the embedded
+// migrations: every applied version must still exist unchanged.
+func (m *Migrator) verify(applied map[int64]record) error {
+	known := make(map[int64]Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = migration
+	}
+
+	for version, r := range applied {
+		migration, ok := known[version]
+		if !ok {
+			return fmt.Errorf("migration %04d is applied but missing from the source", version)
+		}
+		if r.checksum != migration.Checksum {
+			return fmt.Errorf("migration %04d_%s was modified after being applied", version, migration.Name)
+		}
+	}
+	return nil
+}
+
+// Databases set up before migrations were tracked have the users, projects
+// and tasks tables of the first migrations, up to legacyVersion. Finding
+// baselineTable in a database without recorded migrations means it is one.
+const (
+	legacyVersion int64 = 3
+	baselineTable       = "users"
+)
+
+// checkEmpty refuses to migrate a database that has tables from before
+// migrations were tracked, which the first migrations would fail to create.
+func (m *Migrator) checkEmpty(ctx context.Context, conn *sql.Conn) error {
+	query := "SELECT count(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = $1"
+	if m.driver == "sqlite" {
+		query = "SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = $1"
+	}
+	var n int
+	if err := conn.QueryRowContext(ctx, query, baselineTable).Scan(&n); err != nil {
+		return err
+	}
+	if n > 0 {
+		return fmt.Errorf("table %s exists but no migrations are recorded; adopt the existing schema with "+
+			"`migrate baseline %d` first", baselineTable, legacyVersion)
+	}
+	return nil
+}
+
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+		return fmt.Errorf("error applying migration %04d_%s: %w", migration.Version, migration.Name, err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES ($1, $2, $3, $4)",
+		migration.Version, migration.Name, migration.Checksum, time.Now().UTC(),
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (m *Migrator) revert(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	if migration.Down == "" {
+		return fmt.Errorf("migration %04d_%s cannot be reverted", migration.Version, migration.Name)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+		return fmt.Errorf("error reverting migration %04d_%s: %w", migration.Version, migration.Name, err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = $1", migration.Version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func load(source fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(source, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version %q: %w", entry.Name(), err)
+		}
+		body, err := fs.ReadFile(source, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %04d has conflicting names %q and %q", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			sum := sha256.Sum256(body)
+			migration.Up = string(body)
+			migration.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			migration.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %04d_%s has no up script", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	if len(migrations) == 0 {
+		return nil, errors.New("no migrations found")
+	}
+	return migrations, nil
+}
@@ -0,0 +1,9 @@
+CREATE TABLE users (
+    id           UUID PRIMARY KEY,
+    full_name    TEXT        NOT NULL,
+    email        TEXT        NOT NULL,
+    registration TIMESTAMPTZ NOT NULL DEFAULT now(),
+    role         TEXT        NOT NULL DEFAULT ''
+);
+
+CREATE UNIQUE INDEX users_email_key ON users (lower(email));
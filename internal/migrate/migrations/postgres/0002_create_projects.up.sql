@@ -0,0 +1,10 @@
+CREATE TABLE projects (
+    id          UUID PRIMARY KEY,
+    title       TEXT        NOT NULL,
+    description TEXT        NOT NULL DEFAULT '',
+    start_date  TIMESTAMPTZ NOT NULL,
+    end_date    TIMESTAMPTZ NOT NULL,
+    manager_id  UUID REFERENCES users (id)
+);
+
+CREATE INDEX projects_manager_id_idx ON projects (manager_id);
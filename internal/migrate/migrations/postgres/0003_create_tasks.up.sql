@@ -0,0 +1,14 @@
+CREATE TABLE tasks (
+    id           UUID PRIMARY KEY,
+    title        TEXT        NOT NULL,
+    description  TEXT        NOT NULL DEFAULT '',
+    priority     TEXT        NOT NULL DEFAULT '',
+    state        TEXT        NOT NULL DEFAULT '',
+    assignee     UUID REFERENCES users (id),
+    project_id   UUID        NOT NULL REFERENCES projects (id),
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX tasks_assignee_idx ON tasks (assignee);
+CREATE INDEX tasks_project_id_idx ON tasks (project_id);
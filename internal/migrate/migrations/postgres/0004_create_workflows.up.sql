@@ -0,0 +1,6 @@
+CREATE TABLE workflows (
+    project_id    UUID  PRIMARY KEY REFERENCES projects (id) ON DELETE CASCADE,
+    initial_state TEXT  NOT NULL,
+    states        JSONB NOT NULL,
+    transitions   JSONB NOT NULL
+);
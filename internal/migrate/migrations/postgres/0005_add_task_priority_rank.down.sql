@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS tasks_priority_rank_idx;
+ALTER TABLE tasks DROP COLUMN priority_rank;
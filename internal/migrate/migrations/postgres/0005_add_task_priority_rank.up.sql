@@ -0,0 +1,14 @@
+UPDATE tasks SET priority = lower(trim(priority));
+UPDATE tasks SET priority = 'medium'
+WHERE priority NOT IN ('lowest', 'low', 'medium', 'high', 'critical');
+
+ALTER TABLE tasks ADD COLUMN priority_rank SMALLINT NOT NULL DEFAULT 0;
+UPDATE tasks SET priority_rank = CASE priority
+    WHEN 'lowest' THEN 1
+    WHEN 'low' THEN 2
+    WHEN 'medium' THEN 3
+    WHEN 'high' THEN 4
+    WHEN 'critical' THEN 5
+END;
+
+CREATE INDEX tasks_priority_rank_idx ON tasks (priority_rank DESC, created_at);
@@ -0,0 +1,19 @@
+CREATE TABLE project_members (
+    project_id UUID        NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    user_id    UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role       TEXT        NOT NULL,
+    joined_at  TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (project_id, user_id)
+);
+
+CREATE INDEX project_members_user_id_idx ON project_members (user_id);
+
+-- Managers own their projects, and whoever is already assigned a task works
+-- on its project.
+INSERT INTO project_members (project_id, user_id, role, joined_at)
+SELECT id, manager_id, 'owner', now() FROM projects WHERE manager_id IS NOT NULL;
+
+INSERT INTO project_members (project_id, user_id, role, joined_at)
+SELECT DISTINCT project_id, assignee, 'contributor', now() FROM tasks
+WHERE assignee IS NOT NULL
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,16 @@
+CREATE TABLE attachments (
+    id           UUID PRIMARY KEY,
+    project_id   UUID REFERENCES projects (id) ON DELETE CASCADE,
+    task_id      UUID REFERENCES tasks (id) ON DELETE CASCADE,
+    filename     TEXT        NOT NULL,
+    content_type TEXT        NOT NULL,
+    size         BIGINT      NOT NULL,
+    checksum     TEXT        NOT NULL,
+    storage_key  TEXT        NOT NULL,
+    uploader_id  UUID REFERENCES users (id) ON DELETE SET NULL,
+    created_at   TIMESTAMPTZ NOT NULL,
+    CHECK ((project_id IS NULL) <> (task_id IS NULL))
+);
+
+CREATE INDEX attachments_project_id_idx ON attachments (project_id);
+CREATE INDEX attachments_task_id_idx ON attachments (task_id);
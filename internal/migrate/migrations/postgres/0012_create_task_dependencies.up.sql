@@ -0,0 +1,9 @@
+CREATE TABLE task_dependencies (
+    task_id    UUID        NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    blocker_id UUID        NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (task_id, blocker_id),
+    CHECK (task_id <> blocker_id)
+);
+
+CREATE INDEX task_dependencies_blocker_id_idx ON task_dependencies (blocker_id);
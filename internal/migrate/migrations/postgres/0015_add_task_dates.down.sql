@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS tasks_due_date_idx;
+ALTER TABLE tasks DROP COLUMN due_date;
+ALTER TABLE tasks DROP COLUMN start_date;
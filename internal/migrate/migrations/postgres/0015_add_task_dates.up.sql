@@ -0,0 +1,5 @@
+-- Tasks without dates keep the zero time, like open tasks do in completed_at.
+ALTER TABLE tasks ADD COLUMN start_date TIMESTAMPTZ NOT NULL DEFAULT '0001-01-01 00:00:00+00';
+ALTER TABLE tasks ADD COLUMN due_date TIMESTAMPTZ NOT NULL DEFAULT '0001-01-01 00:00:00+00';
+
+CREATE INDEX tasks_due_date_idx ON tasks (due_date);
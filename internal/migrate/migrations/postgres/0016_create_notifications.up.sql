@@ -0,0 +1,15 @@
+CREATE TABLE notifications (
+    id         UUID PRIMARY KEY,
+    user_id    UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    task_id    UUID        NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    kind       TEXT        NOT NULL,
+    due_date   TIMESTAMPTZ NOT NULL,
+    message    TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    read_at    TIMESTAMPTZ,
+    -- A reminder is sent once for each due date a task is given.
+    UNIQUE (user_id, task_id, kind, due_date)
+);
+
+CREATE INDEX notifications_user_id_idx ON notifications (user_id, created_at);
+CREATE INDEX notifications_task_id_idx ON notifications (task_id);
@@ -0,0 +1,2 @@
+ALTER TABLE tasks DROP COLUMN remaining_estimate;
+ALTER TABLE tasks DROP COLUMN original_estimate;
@@ -0,0 +1,3 @@
+-- Estimates are in seconds; 0 means the task has not been estimated.
+ALTER TABLE tasks ADD COLUMN original_estimate BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN remaining_estimate BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,17 @@
+CREATE TABLE worklogs (
+    id         UUID PRIMARY KEY,
+    task_id    UUID        NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    -- Time logged by deleted users is kept for the records.
+    user_id    UUID REFERENCES users (id) ON DELETE SET NULL,
+    started_at TIMESTAMPTZ NOT NULL,
+    -- Running timers end at the zero time.
+    ended_at   TIMESTAMPTZ NOT NULL,
+    seconds    BIGINT      NOT NULL,
+    note       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX worklogs_task_id_idx ON worklogs (task_id, started_at);
+CREATE INDEX worklogs_user_id_idx ON worklogs (user_id, started_at);
+-- Each user runs at most one timer.
+CREATE UNIQUE INDEX worklogs_running_idx ON worklogs (user_id) WHERE ended_at = '0001-01-01 00:00:00+00';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS tasks_sprint_id_idx;
+ALTER TABLE tasks DROP COLUMN sprint_id;
+DROP TABLE IF EXISTS sprints;
@@ -0,0 +1,2 @@
+ALTER TABLE tasks DROP COLUMN board_rank;
+DROP TABLE IF EXISTS boards;
@@ -0,0 +1,7 @@
+CREATE TABLE boards (
+    project_id UUID  PRIMARY KEY REFERENCES projects (id) ON DELETE CASCADE,
+    columns    JSONB NOT NULL
+);
+
+-- Orders tasks within their board column; tasks never moved there have ''.
+ALTER TABLE tasks ADD COLUMN board_rank TEXT NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+-- The legacy task states mapped by the up migration are not restored.
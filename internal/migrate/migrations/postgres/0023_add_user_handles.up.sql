@@ -0,0 +1,15 @@
+ALTER TABLE users ADD COLUMN handle TEXT NOT NULL DEFAULT '';
+
+-- Existing users get the part of their email before the "@" as their handle
+-- when it makes a valid one that no other user's email would give too.
+UPDATE users SET handle = lower(split_part(email, '@', 1))
+WHERE position('@' in email) > 1
+  AND length(split_part(email, '@', 1)) <= 39
+  AND lower(split_part(email, '@', 1)) ~ '^[a-z0-9]([a-z0-9._-]*[a-z0-9])?$'
+  AND NOT EXISTS (
+      SELECT 1 FROM users other
+      WHERE other.id <> users.id
+        AND lower(split_part(other.email, '@', 1)) = lower(split_part(users.email, '@', 1))
+  );
+
+CREATE UNIQUE INDEX users_handle_key ON users (handle) WHERE handle <> '';
@@ -0,0 +1,2 @@
+ALTER TABLE tasks ALTER COLUMN completed_at DROP NOT NULL;
+ALTER TABLE tasks ALTER COLUMN completed_at DROP DEFAULT;
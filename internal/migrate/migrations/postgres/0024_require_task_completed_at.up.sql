@@ -0,0 +1,6 @@
+-- Open tasks store the zero time in completed_at, but the column was created
+-- nullable and databases adopted through "migrate baseline" may hold NULL
+-- there.
+UPDATE tasks SET completed_at = '0001-01-01 00:00:00+00' WHERE completed_at IS NULL;
+ALTER TABLE tasks ALTER COLUMN completed_at SET DEFAULT '0001-01-01 00:00:00+00';
+ALTER TABLE tasks ALTER COLUMN completed_at SET NOT NULL;
@@ -0,0 +1 @@
+-- The completion times set by the up migration are not cleared.
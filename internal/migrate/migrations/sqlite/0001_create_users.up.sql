@@ -0,0 +1,9 @@
+CREATE TABLE users (
+    id           TEXT PRIMARY KEY,
+    full_name    TEXT      NOT NULL,
+    email        TEXT      NOT NULL,
+    registration TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    role         TEXT      NOT NULL DEFAULT ''
+);
+
+CREATE UNIQUE INDEX users_email_key ON users (lower(email));
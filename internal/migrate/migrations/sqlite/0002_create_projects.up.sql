@@ -0,0 +1,10 @@
+CREATE TABLE projects (
+    id          TEXT PRIMARY KEY,
+    title       TEXT      NOT NULL,
+    description TEXT      NOT NULL DEFAULT '',
+    start_date  TIMESTAMP NOT NULL,
+    end_date    TIMESTAMP NOT NULL,
+    manager_id  TEXT REFERENCES users (id)
+);
+
+CREATE INDEX projects_manager_id_idx ON projects (manager_id);
@@ -0,0 +1,14 @@
+CREATE TABLE tasks (
+    id           TEXT PRIMARY KEY,
+    title        TEXT      NOT NULL,
+    description  TEXT      NOT NULL DEFAULT '',
+    priority     TEXT      NOT NULL DEFAULT '',
+    state        TEXT      NOT NULL DEFAULT '',
+    assignee     TEXT REFERENCES users (id),
+    project_id   TEXT      NOT NULL REFERENCES projects (id),
+    created_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP
+);
+
+CREATE INDEX tasks_assignee_idx ON tasks (assignee);
+CREATE INDEX tasks_project_id_idx ON tasks (project_id);
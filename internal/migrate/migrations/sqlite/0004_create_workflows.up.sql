@@ -0,0 +1,6 @@
+CREATE TABLE workflows (
+    project_id    TEXT  PRIMARY KEY REFERENCES projects (id) ON DELETE CASCADE,
+    initial_state TEXT  NOT NULL,
+    states        TEXT  NOT NULL,
+    transitions   TEXT  NOT NULL
+);
@@ -0,0 +1,21 @@
+CREATE TABLE project_members (
+    project_id TEXT      NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    user_id    TEXT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role       TEXT      NOT NULL,
+    joined_at  TIMESTAMP NOT NULL,
+    PRIMARY KEY (project_id, user_id)
+);
+
+CREATE INDEX project_members_user_id_idx ON project_members (user_id);
+
+-- Managers own their projects, and whoever is already assigned a task works
+-- on its project. Timestamps use the store's fixed-width text layout.
+INSERT INTO project_members (project_id, user_id, role, joined_at)
+SELECT id, manager_id, 'owner', strftime('%Y-%m-%d %H:%M:%S.000000000+00:00', 'now')
+FROM projects WHERE manager_id IS NOT NULL;
+
+INSERT INTO project_members (project_id, user_id, role, joined_at)
+SELECT DISTINCT project_id, assignee, 'contributor', strftime('%Y-%m-%d %H:%M:%S.000000000+00:00', 'now')
+FROM tasks
+WHERE assignee IS NOT NULL
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,15 @@
+-- Users get back the role they had in the first organization they joined.
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT '';
+UPDATE users SET role = COALESCE((
+    SELECT role FROM organization_members
+    WHERE organization_members.user_id = users.id
+    ORDER BY joined_at LIMIT 1
+), '');
+
+DROP INDEX IF EXISTS tasks_organization_id_idx;
+DROP INDEX IF EXISTS projects_organization_id_idx;
+ALTER TABLE tasks DROP COLUMN organization_id;
+ALTER TABLE projects DROP COLUMN organization_id;
+
+DROP TABLE IF EXISTS organization_members;
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,37 @@
+CREATE TABLE organizations (
+    id         TEXT PRIMARY KEY,
+    name       TEXT      NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE organization_members (
+    organization_id TEXT      NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    user_id         TEXT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role            TEXT      NOT NULL,
+    joined_at       TIMESTAMP NOT NULL,
+    PRIMARY KEY (organization_id, user_id)
+);
+
+CREATE INDEX organization_members_user_id_idx ON organization_members (user_id);
+
+-- SQLite cannot add a NOT NULL reference to an existing table. The columns
+-- stay nullable, but the application always sets them.
+ALTER TABLE projects ADD COLUMN organization_id TEXT REFERENCES organizations (id);
+ALTER TABLE tasks ADD COLUMN organization_id TEXT REFERENCES organizations (id);
+
+-- Existing data moves into one organization, in which every user keeps its
+-- role. Timestamps use the store's fixed-width text layout.
+INSERT INTO organizations (id, name, created_at)
+SELECT '00000000-0000-0000-0000-000000000001', 'Default', strftime('%Y-%m-%d %H:%M:%S.000000000+00:00', 'now')
+WHERE EXISTS (SELECT 1 FROM users) OR EXISTS (SELECT 1 FROM projects);
+
+INSERT INTO organization_members (organization_id, user_id, role, joined_at)
+SELECT '00000000-0000-0000-0000-000000000001', id, role, registration FROM users;
+
+UPDATE projects SET organization_id = '00000000-0000-0000-0000-000000000001';
+UPDATE tasks SET organization_id = '00000000-0000-0000-0000-000000000001';
+
+CREATE INDEX projects_organization_id_idx ON projects (organization_id);
+CREATE INDEX tasks_organization_id_idx ON tasks (organization_id);
+
+ALTER TABLE users DROP COLUMN role;
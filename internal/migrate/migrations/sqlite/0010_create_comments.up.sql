@@ -0,0 +1,30 @@
+CREATE TABLE comments (
+    id         TEXT PRIMARY KEY,
+    task_id    TEXT      NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    parent_id  TEXT REFERENCES comments (id) ON DELETE CASCADE,
+    author_id  TEXT REFERENCES users (id) ON DELETE SET NULL,
+    body       TEXT      NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    edited_at  TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX comments_task_id_idx ON comments (task_id, created_at);
+
+CREATE TABLE comment_mentions (
+    comment_id TEXT NOT NULL REFERENCES comments (id) ON DELETE CASCADE,
+    user_id    TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    PRIMARY KEY (comment_id, user_id)
+);
+
+CREATE INDEX comment_mentions_user_id_idx ON comment_mentions (user_id);
+
+CREATE TABLE comment_revisions (
+    id         TEXT PRIMARY KEY,
+    comment_id TEXT      NOT NULL REFERENCES comments (id) ON DELETE CASCADE,
+    body       TEXT      NOT NULL,
+    editor_id  TEXT REFERENCES users (id) ON DELETE SET NULL,
+    edited_at  TIMESTAMP NOT NULL
+);
+
+CREATE INDEX comment_revisions_comment_id_idx ON comment_revisions (comment_id, edited_at);
@@ -0,0 +1,16 @@
+CREATE TABLE attachments (
+    id           TEXT PRIMARY KEY,
+    project_id   TEXT REFERENCES projects (id) ON DELETE CASCADE,
+    task_id      TEXT REFERENCES tasks (id) ON DELETE CASCADE,
+    filename     TEXT      NOT NULL,
+    content_type TEXT      NOT NULL,
+    size         INTEGER   NOT NULL,
+    checksum     TEXT      NOT NULL,
+    storage_key  TEXT      NOT NULL,
+    uploader_id  TEXT REFERENCES users (id) ON DELETE SET NULL,
+    created_at   TIMESTAMP NOT NULL,
+    CHECK ((project_id IS NULL) <> (task_id IS NULL))
+);
+
+CREATE INDEX attachments_project_id_idx ON attachments (project_id);
+CREATE INDEX attachments_task_id_idx ON attachments (task_id);
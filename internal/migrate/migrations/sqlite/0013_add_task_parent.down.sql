@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS tasks_parent_id_idx;
+ALTER TABLE tasks DROP COLUMN parent_id;
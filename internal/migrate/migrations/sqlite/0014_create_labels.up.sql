@@ -0,0 +1,18 @@
+CREATE TABLE labels (
+    id          TEXT PRIMARY KEY,
+    project_id  TEXT      NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    name        TEXT      NOT NULL,
+    color       TEXT      NOT NULL,
+    description TEXT      NOT NULL DEFAULT '',
+    created_at  TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX labels_project_id_name_key ON labels (project_id, lower(name));
+
+CREATE TABLE task_labels (
+    task_id  TEXT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    label_id TEXT NOT NULL REFERENCES labels (id) ON DELETE CASCADE,
+    PRIMARY KEY (task_id, label_id)
+);
+
+CREATE INDEX task_labels_label_id_idx ON task_labels (label_id);
@@ -0,0 +1,15 @@
+CREATE TABLE notifications (
+    id         TEXT PRIMARY KEY,
+    user_id    TEXT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    task_id    TEXT      NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    kind       TEXT      NOT NULL,
+    due_date   TIMESTAMP NOT NULL,
+    message    TEXT      NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    read_at    TIMESTAMP,
+    -- A reminder is sent once for each due date a task is given.
+    UNIQUE (user_id, task_id, kind, due_date)
+);
+
+CREATE INDEX notifications_user_id_idx ON notifications (user_id, created_at);
+CREATE INDEX notifications_task_id_idx ON notifications (task_id);
@@ -0,0 +1,32 @@
+CREATE TABLE sprints (
+    id                  TEXT PRIMARY KEY,
+    project_id          TEXT      NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    name                TEXT      NOT NULL,
+    goal                TEXT      NOT NULL DEFAULT '',
+    start_date          TIMESTAMP NOT NULL,
+    end_date            TIMESTAMP NOT NULL,
+    state               TEXT      NOT NULL,
+    -- Work in the sprint when it started, and completed or rolled over when
+    -- it closed; estimates are in seconds.
+    committed_tasks     INTEGER   NOT NULL DEFAULT 0,
+    committed_estimate  BIGINT    NOT NULL DEFAULT 0,
+    completed_tasks     INTEGER   NOT NULL DEFAULT 0,
+    completed_estimate  BIGINT    NOT NULL DEFAULT 0,
+    unfinished_tasks    INTEGER   NOT NULL DEFAULT 0,
+    unfinished_estimate BIGINT    NOT NULL DEFAULT 0,
+    -- The sprint that unfinished tasks were moved to, NULL for the backlog.
+    rolled_over_to      TEXT REFERENCES sprints (id) ON DELETE SET NULL,
+    -- Sprints not started or closed yet store the zero time.
+    started_at          TIMESTAMP NOT NULL,
+    closed_at           TIMESTAMP NOT NULL,
+    created_at          TIMESTAMP NOT NULL
+);
+
+CREATE INDEX sprints_project_id_idx ON sprints (project_id, start_date);
+-- Each project runs at most one sprint at a time.
+CREATE UNIQUE INDEX sprints_active_idx ON sprints (project_id) WHERE state = 'active';
+
+-- Tasks in no sprint are in their project's backlog.
+ALTER TABLE tasks ADD COLUMN sprint_id TEXT REFERENCES sprints (id) ON DELETE SET NULL;
+
+CREATE INDEX tasks_sprint_id_idx ON tasks (sprint_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS tasks_milestone_id_idx;
+ALTER TABLE tasks DROP COLUMN milestone_id;
+DROP TABLE IF EXISTS milestones;
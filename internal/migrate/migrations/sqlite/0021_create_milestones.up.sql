@@ -0,0 +1,14 @@
+CREATE TABLE milestones (
+    id          TEXT PRIMARY KEY,
+    project_id  TEXT NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    title       TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    target_date TIMESTAMP NOT NULL,
+    created_at  TIMESTAMP NOT NULL
+);
+
+CREATE INDEX milestones_project_id_idx ON milestones (project_id, target_date);
+
+ALTER TABLE tasks ADD COLUMN milestone_id TEXT REFERENCES milestones (id) ON DELETE SET NULL;
+
+CREATE INDEX tasks_milestone_id_idx ON tasks (milestone_id);
@@ -0,0 +1,26 @@
+-- Tasks created before workflows existed may be in states that their
+-- project's workflow does not define, written in any letter case. On the
+-- default workflow, the legacy names map onto its states and anything else
+-- goes back to the initial state.
+UPDATE tasks SET state = CASE
+    WHEN lower(trim(state)) IN ('in_progress', 'in progress', 'in-progress', 'inprogress', 'doing', 'started')
+        THEN 'in_progress'
+    WHEN lower(trim(state)) IN ('review', 'in_review', 'in review', 'in-review')
+        THEN 'review'
+    WHEN lower(trim(state)) IN ('done', 'finished', 'completed', 'complete', 'closed', 'resolved')
+        THEN 'done'
+    ELSE 'todo'
+END
+WHERE state NOT IN ('todo', 'in_progress', 'review', 'done')
+    AND project_id NOT IN (SELECT project_id FROM workflows);
+
+-- Projects with a workflow of their own get the state of it that matches in
+-- letter case, or else its initial state.
+UPDATE tasks SET state = coalesce(
+    (SELECT json_extract(s.value, '$.name') FROM workflows w, json_each(w.states) s
+        WHERE w.project_id = tasks.project_id AND lower(json_extract(s.value, '$.name')) = lower(trim(tasks.state))
+        LIMIT 1),
+    (SELECT w.initial_state FROM workflows w WHERE w.project_id = tasks.project_id))
+WHERE EXISTS (SELECT 1 FROM workflows w
+    WHERE w.project_id = tasks.project_id
+        AND NOT EXISTS (SELECT 1 FROM json_each(w.states) s WHERE json_extract(s.value, '$.name') = tasks.state));
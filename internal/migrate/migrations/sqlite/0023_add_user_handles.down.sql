@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS users_handle_key;
+ALTER TABLE users DROP COLUMN handle;
@@ -0,0 +1,18 @@
+ALTER TABLE users ADD COLUMN handle TEXT NOT NULL DEFAULT '';
+
+-- Existing users get the part of their email before the "@" as their handle
+-- when it makes a valid one that no other user's email would give too.
+WITH candidates (id, handle) AS (
+    SELECT id, lower(substr(email, 1, instr(email, '@') - 1)) FROM users WHERE instr(email, '@') > 1
+)
+UPDATE users SET handle = (SELECT handle FROM candidates WHERE candidates.id = users.id)
+WHERE id IN (
+    SELECT id FROM candidates c
+    WHERE length(c.handle) <= 39
+      AND c.handle GLOB '[a-z0-9]*'
+      AND c.handle GLOB '*[a-z0-9]'
+      AND NOT c.handle GLOB '*[^a-z0-9._-]*'
+      AND NOT EXISTS (SELECT 1 FROM candidates other WHERE other.id <> c.id AND other.handle = c.handle)
+);
+
+CREATE UNIQUE INDEX users_handle_key ON users (handle) WHERE handle <> '';
@@ -0,0 +1 @@
+-- The NULLs replaced by the up migration are not restored.
@@ -0,0 +1,4 @@
+-- Open tasks store the zero time in completed_at, but the column was created
+-- nullable. SQLite cannot add the constraint to an existing column, so NULLs
+-- are only replaced.
+UPDATE tasks SET completed_at = '0001-01-01 00:00:00.000000000+00:00' WHERE completed_at IS NULL;
@@ -0,0 +1,9 @@
+-- Tasks that migration 0022 moved into a terminal state were left without a
+-- completed_at. When they were completed is not known, so they take the time
+-- they were created at.
+UPDATE tasks SET completed_at = created_at
+WHERE completed_at = '0001-01-01 00:00:00.000000000+00:00' AND (
+    (state = 'done' AND project_id NOT IN (SELECT project_id FROM workflows))
+    OR EXISTS (SELECT 1 FROM workflows w, json_each(w.states) s
+        WHERE w.project_id = tasks.project_id AND json_extract(s.value, '$.name') = tasks.state
+            AND json_extract(s.value, '$.terminal') = 1));
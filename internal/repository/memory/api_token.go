@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+type APITokenRepository struct {
+	store *Store
+}
+
+func NewAPITokenRepository(store *Store) *APITokenRepository {
+	return &APITokenRepository{store: store}
+}
+
+// cloneAPIToken keeps callers from sharing the stored scopes.
+func cloneAPIToken(token domain.APIToken) domain.APIToken {
+	token.Scopes = slices.Clone(token.Scopes)
+	return token
+}
+
+func (r *APITokenRepository) List(ctx context.Context, userID uuid.UUID) ([]domain.APIToken, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tokens := []domain.APIToken{}
+	for _, token := range r.store.apiTokens {
+		if token.UserID == userID {
+			tokens = append(tokens, cloneAPIToken(token))
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		if !tokens[i].CreatedAt.Equal(tokens[j].CreatedAt) {
+			return tokens[i].CreatedAt.After(tokens[j].CreatedAt)
+		}
+		return tokens[i].ID.String() < tokens[j].ID.String()
+	})
+	return tokens, nil
+}
+
+func (r *APITokenRepository) Get(ctx context.Context, id uuid.UUID) (*domain.APIToken, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	token, ok := r.store.apiTokens[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	token = cloneAPIToken(token)
+	return &token, nil
+}
+
+func (r *APITokenRepository) GetByHash(ctx context.Context, hash string) (*domain.APIToken, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, token := range r.store.apiTokens {
+		if token.Hash == hash {
+			token = cloneAPIToken(token)
+			return &token, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *APITokenRepository) Create(ctx context.Context, token *domain.APIToken) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[token.UserID]; !ok {
+		return repository.ErrReferenced
+	}
+	if _, ok := r.store.apiTokens[token.ID]; ok {
+		return repository.ErrDuplicate
+	}
+	for _, existing := range r.store.apiTokens {
+		if existing.Hash == token.Hash {
+			return repository.ErrDuplicate
+		}
+	}
+
+	r.store.apiTokens[token.ID] = cloneAPIToken(*token)
+	return nil
+}
+
+func (r *APITokenRepository) Touch(ctx context.Context, id uuid.UUID, at time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	token, ok := r.store.apiTokens[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	token.LastUsedAt = at
+	r.store.apiTokens[id] = token
+	return nil
+}
+
+func (r *APITokenRepository) Revoke(ctx context.Context, id uuid.UUID, at time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	token, ok := r.store.apiTokens[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if token.RevokedAt.IsZero() {
+		token.RevokedAt = at
+		r.store.apiTokens[id] = token
+	}
+	return nil
+}
+
+// deleteAPITokens mirrors ON DELETE CASCADE from users to API tokens. The
+// caller holds the write lock.
+func (s *Store) deleteAPITokens(userID uuid.UUID) {
+	for id, token := range s.apiTokens {
+		if token.UserID == userID {
+			delete(s.apiTokens, id)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+type AttachmentRepository struct {
+	store *Store
+}
+
+func NewAttachmentRepository(store *Store) *AttachmentRepository {
+	return &AttachmentRepository{store: store}
+}
+
+func (r *AttachmentRepository) List(ctx context.Context, owner domain.AttachmentOwner) ([]domain.Attachment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.collect(func(a *domain.Attachment) bool { return a.Owner() == owner }), nil
+}
+
+func (r *AttachmentRepository) ListInProject(ctx context.Context, projectID uuid.UUID) ([]domain.Attachment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.collect(func(a *domain.Attachment) bool {
+		if a.TaskID != uuid.Nil {
+			task, ok := r.store.tasks[a.TaskID]
+			return ok && task.ProjectID == projectID
+		}
+		return a.ProjectID == projectID
+	}), nil
+}
+
+// collect returns the attachments that match, oldest first. The caller holds
+// a lock.
+func (r *AttachmentRepository) collect(match func(*domain.Attachment) bool) []domain.Attachment {
+	attachments := []domain.Attachment{}
+	for _, attachment := range r.store.attachments {
+		if match(&attachment) {
+			attachments = append(attachments, attachment)
+		}
+	}
+	sort.Slice(attachments, func(i, j int) bool {
+		if !attachments[i].CreatedAt.Equal(attachments[j].CreatedAt) {
+			return attachments[i].CreatedAt.Before(attachments[j].CreatedAt)
+		}
+		return attachments[i].ID.String() < attachments[j].ID.String()
+	})
+	return attachments
+}
+
+func (r *AttachmentRepository) Get(ctx context.Context, id uuid.UUID) (*domain.Attachment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	attachment, ok := r.store.attachments[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &attachment, nil
+}
+
+func (r *AttachmentRepository) Create(ctx context.Context, attachment *domain.Attachment) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.attachments[attachment.ID]; ok {
+		return repository.ErrDuplicate
+	}
+	if _, ok := r.store.tasks[attachment.TaskID]; attachment.TaskID != uuid.Nil && !ok {
+		return repository.ErrReferenced
+	}
+	if _, ok := r.store.projects[attachment.ProjectID]; attachment.ProjectID != uuid.Nil && !ok {
+		return repository.ErrReferenced
+	}
+	if _, ok := r.store.users[attachment.UploaderID]; attachment.UploaderID != uuid.Nil && !ok {
+		return repository.ErrReferenced
+	}
+
+	r.store.attachments[attachment.ID] = *attachment
+	return nil
+}
+
+func (r *AttachmentRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.attachments[id]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.store.attachments, id)
+	return nil
+}
+
+// deleteAttachments mirrors ON DELETE CASCADE from tasks and projects to
+// attachments. The caller holds the write lock.
+func (s *Store) deleteAttachments(owner domain.AttachmentOwner) {
+	for id, attachment := range s.attachments {
+		if attachment.Owner() == owner {
+			delete(s.attachments, id)
+		}
+	}
+}
+
+// detachUploader mirrors ON DELETE SET NULL from users to attachments. The
+// caller holds the write lock.
+func (s *Store) detachUploader(userID uuid.UUID) {
+	for id, attachment := range s.attachments {
+		if attachment.UploaderID == userID {
+			attachment.UploaderID = uuid.Nil
+			s.attachments[id] = attachment
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+type BoardRepository struct {
+	store *Store
+}
+
+func NewBoardRepository(store *Store) *BoardRepository {
+	return &BoardRepository{store: store}
+}
+
+func (r *BoardRepository) Get(ctx context.Context, projectID uuid.UUID) (*domain.Board, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	board, ok := r.store.boards[projectID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return cloneBoard(board), nil
+}
+
+func (r *BoardRepository) Save(ctx context.Context, board *domain.Board) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.projects[board.ProjectID]; !ok {
+		return repository.ErrReferenced
+	}
+	r.store.boards[board.ProjectID] = *cloneBoard(*board)
+	return nil
+}
+
+// cloneBoard copies the columns and their states so callers cannot mutate
+// stored state.
+func cloneBoard(board domain.Board) *domain.Board {
+	columns := make([]domain.BoardColumn, len(board.Columns))
+	for i, column := range board.Columns {
+		column.States = append([]string(nil), column.States...)
+		columns[i] = column
+	}
+	board.Columns = columns
+	return &board
+}
@@ -0,0 +1,198 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+type CommentRepository struct {
+	store *Store
+}
+
+func NewCommentRepository(store *Store) *CommentRepository {
+	return &CommentRepository{store: store}
+}
+
+func (r *CommentRepository) List(ctx context.Context, taskID uuid.UUID) ([]domain.Comment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	comments := []domain.Comment{}
+	for _, comment := range r.store.comments {
+		if comment.TaskID == taskID {
+			comments = append(comments, cloneComment(comment))
+		}
+	}
+	sort.Slice(comments, func(i, j int) bool {
+		if !comments[i].CreatedAt.Equal(comments[j].CreatedAt) {
+			return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+		}
+		return comments[i].ID.String() < comments[j].ID.String()
+	})
+	return comments, nil
+}
+
+func (r *CommentRepository) Get(ctx context.Context, id uuid.UUID) (*domain.Comment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	comment, ok := r.store.comments[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	comment = cloneComment(comment)
+	return &comment, nil
+}
+
+func (r *CommentRepository) Create(ctx context.Context, comment *domain.Comment) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.comments[comment.ID]; ok {
+		return repository.ErrDuplicate
+	}
+	if _, ok := r.store.tasks[comment.TaskID]; !ok {
+		return repository.ErrReferenced
+	}
+	if _, ok := r.store.comments[comment.ParentID]; comment.ParentID != uuid.Nil && !ok {
+		return repository.ErrReferenced
+	}
+	if _, ok := r.store.users[comment.AuthorID]; comment.AuthorID != uuid.Nil && !ok {
+		return repository.ErrReferenced
+	}
+	if !r.store.usersExist(comment.Mentions) {
+		return repository.ErrReferenced
+	}
+
+	r.store.comments[comment.ID] = cloneComment(*comment)
+	return nil
+}
+
+func (r *CommentRepository) Update(ctx context.Context, comment *domain.Comment, revision *domain.CommentRevision) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	current, ok := r.store.comments[comment.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if !r.store.usersExist(comment.Mentions) {
+		return repository.ErrReferenced
+	}
+	if _, ok := r.store.users[revision.EditorID]; revision.EditorID != uuid.Nil && !ok {
+		return repository.ErrReferenced
+	}
+	for _, other := range r.store.commentRevisions[comment.ID] {
+		if other.ID == revision.ID {
+			return repository.ErrDuplicate
+		}
+	}
+
+	current.Body = comment.Body
+	current.Mentions = comment.Mentions
+	current.EditedAt = comment.EditedAt
+	r.store.comments[comment.ID] = cloneComment(current)
+	r.store.commentRevisions[comment.ID] = append(r.store.commentRevisions[comment.ID], *revision)
+	return nil
+}
+
+func (r *CommentRepository) Delete(ctx context.Context, id uuid.UUID, at time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	comment, ok := r.store.comments[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	comment.Body = ""
+	comment.Mentions = nil
+	comment.DeletedAt = at
+	r.store.comments[id] = comment
+	delete(r.store.commentRevisions, id)
+	return nil
+}
+
+func (r *CommentRepository) ListRevisions(ctx context.Context, commentID uuid.UUID) ([]domain.CommentRevision, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	revisions := slices.Clone(r.store.commentRevisions[commentID])
+	if revisions == nil {
+		revisions = []domain.CommentRevision{}
+	}
+	sort.SliceStable(revisions, func(i, j int) bool {
+		return revisions[i].EditedAt.Before(revisions[j].EditedAt)
+	})
+	return revisions, nil
+}
+
+// cloneComment copies a comment so that callers never share its mentions
+// with the store. Mentions come out sorted and never nil, like from the
+// database.
+func cloneComment(comment domain.Comment) domain.Comment {
+	mentions := make([]uuid.UUID, len(comment.Mentions))
+	copy(mentions, comment.Mentions)
+	sort.Slice(mentions, func(i, j int) bool { return mentions[i].String() < mentions[j].String() })
+	comment.Mentions = slices.Compact(mentions)
+	return comment
+}
+
+// usersExist mirrors the foreign key from comment mentions to users. The
+// caller holds a lock.
+func (s *Store) usersExist(ids []uuid.UUID) bool {
+	for _, id := range ids {
+		if _, ok := s.users[id]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// deleteTask removes a task and, like ON DELETE CASCADE, its subtasks,
+// comments, attachments, dependencies, labels, notifications and worklogs.
+// The caller holds the write lock.
+func (s *Store) deleteTask(id uuid.UUID) {
+	for subtaskID, subtask := range s.tasks {
+		if subtask.ParentID == id {
+			s.deleteTask(subtaskID)
+		}
+	}
+	s.deleteAttachments(domain.AttachmentOwner{TaskID: id})
+	s.deleteDependencies(id)
+	delete(s.taskLabels, id)
+	s.deleteNotifications(id)
+	s.deleteWorklogs(id)
+	for commentID, comment := range s.comments {
+		if comment.TaskID == id {
+			delete(s.comments, commentID)
+			delete(s.commentRevisions, commentID)
+		}
+	}
+	delete(s.tasks, id)
+}
+
+// detachCommentUser mirrors the foreign keys from comments to users:
+// authors and editors are set to NULL, mentions are deleted. The caller
+// holds the write lock.
+func (s *Store) detachCommentUser(userID uuid.UUID) {
+	for id, comment := range s.comments {
+		if comment.AuthorID == userID {
+			comment.AuthorID = uuid.Nil
+		}
+		comment.Mentions = slices.DeleteFunc(comment.Mentions, func(m uuid.UUID) bool { return m == userID })
+		s.comments[id] = comment
+	}
+	for _, revisions := range s.commentRevisions {
+		for i := range revisions {
+			if revisions[i].EditorID == userID {
+				revisions[i].EditorID = uuid.Nil
+			}
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+type DependencyRepository struct {
+	store *Store
+}
+
+func NewDependencyRepository(store *Store) *DependencyRepository {
+	return &DependencyRepository{store: store}
+}
+
+func (r *DependencyRepository) Blockers(ctx context.Context, taskID uuid.UUID) ([]domain.Dependency, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.walk(taskID, func(d *domain.Dependency) (from, to uuid.UUID) { return d.TaskID, d.BlockerID }), nil
+}
+
+func (r *DependencyRepository) Dependents(ctx context.Context, taskID uuid.UUID) ([]domain.Dependency, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.walk(taskID, func(d *domain.Dependency) (from, to uuid.UUID) { return d.BlockerID, d.TaskID }), nil
+}
+
+// walk returns the links reachable from taskID when each link leads from
+// one of its ends to the other, oldest first. The caller holds a lock.
+func (r *DependencyRepository) walk(taskID uuid.UUID, ends func(*domain.Dependency) (from, to uuid.UUID)) []domain.Dependency {
+	next := make(map[uuid.UUID][]domain.Dependency)
+	for _, blockers := range r.store.dependencies {
+		for _, dependency := range blockers {
+			from, _ := ends(&dependency)
+			next[from] = append(next[from], dependency)
+		}
+	}
+
+	dependencies := []domain.Dependency{}
+	visited := map[uuid.UUID]bool{taskID: true}
+	queue := []uuid.UUID{taskID}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, dependency := range next[current] {
+			dependencies = append(dependencies, dependency)
+			if _, to := ends(&dependency); !visited[to] {
+				visited[to] = true
+				queue = append(queue, to)
+			}
+		}
+	}
+
+	sort.Slice(dependencies, func(i, j int) bool {
+		a, b := dependencies[i], dependencies[j]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		if a.TaskID != b.TaskID {
+			return a.TaskID.String() < b.TaskID.String()
+		}
+		return a.BlockerID.String() < b.BlockerID.String()
+	})
+	return dependencies
+}
+
+func (r *DependencyRepository) Add(ctx context.Context, dependency *domain.Dependency,
+	check func(blockers []domain.Dependency) error) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.tasks[dependency.TaskID]; !ok {
+		return repository.ErrReferenced
+	}
+	if _, ok := r.store.tasks[dependency.BlockerID]; !ok {
+		return repository.ErrReferenced
+	}
+	if _, ok := r.store.dependencies[dependency.TaskID][dependency.BlockerID]; ok {
+		return repository.ErrDuplicate
+	}
+	if err := check(r.walk(dependency.BlockerID, func(d *domain.Dependency) (from, to uuid.UUID) {
+		return d.TaskID, d.BlockerID
+	})); err != nil {
+		return err
+	}
+
+	if r.store.dependencies[dependency.TaskID] == nil {
+		r.store.dependencies[dependency.TaskID] = make(map[uuid.UUID]domain.Dependency)
+	}
+	r.store.dependencies[dependency.TaskID][dependency.BlockerID] = *dependency
+	return nil
+}
+
+func (r *DependencyRepository) Remove(ctx context.Context, taskID, blockerID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.dependencies[taskID][blockerID]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.store.dependencies[taskID], blockerID)
+	return nil
+}
+
+// deleteDependencies mirrors ON DELETE CASCADE from tasks to the links on
+// either side of them. The caller holds the write lock.
+func (s *Store) deleteDependencies(taskID uuid.UUID) {
+	delete(s.dependencies, taskID)
+	for _, blockers := range s.dependencies {
+		delete(blockers, taskID)
+	}
+}
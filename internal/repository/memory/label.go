@@ -0,0 +1,206 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+type LabelRepository struct {
+	store *Store
+}
+
+func NewLabelRepository(store *Store) *LabelRepository {
+	return &LabelRepository{store: store}
+}
+
+func (r *LabelRepository) List(ctx context.Context, projectID uuid.UUID) ([]domain.Label, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	labels := []domain.Label{}
+	for _, label := range r.store.labels {
+		if label.ProjectID == projectID {
+			labels = append(labels, label)
+		}
+	}
+	sortLabels(labels)
+	return labels, nil
+}
+
+func sortLabels(labels []domain.Label) {
+	sort.Slice(labels, func(i, j int) bool {
+		if labels[i].Name != labels[j].Name {
+			return labels[i].Name < labels[j].Name
+		}
+		return labels[i].ID.String() < labels[j].ID.String()
+	})
+}
+
+func (r *LabelRepository) Get(ctx context.Context, id uuid.UUID) (*domain.Label, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	label, ok := r.store.labels[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &label, nil
+}
+
+func (r *LabelRepository) Create(ctx context.Context, label *domain.Label) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.labels[label.ID]; ok {
+		return repository.ErrDuplicate
+	}
+	if _, ok := r.store.projects[label.ProjectID]; !ok {
+		return repository.ErrReferenced
+	}
+	if r.store.labelNameTaken(label) {
+		return repository.ErrDuplicate
+	}
+
+	r.store.labels[label.ID] = *label
+	return nil
+}
+
+func (r *LabelRepository) Update(ctx context.Context, label *domain.Label) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	current, ok := r.store.labels[label.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	updated := *label
+	updated.ProjectID = current.ProjectID
+	if r.store.labelNameTaken(&updated) {
+		return repository.ErrDuplicate
+	}
+
+	r.store.labels[label.ID] = updated
+	return nil
+}
+
+// labelNameTaken mirrors the unique index on the lowercased names of a
+// project's labels. The caller holds a lock.
+func (s *Store) labelNameTaken(label *domain.Label) bool {
+	for _, other := range s.labels {
+		if other.ID != label.ID && other.ProjectID == label.ProjectID &&
+			strings.ToLower(other.Name) == strings.ToLower(label.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *LabelRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.labels[id]; !ok {
+		return repository.ErrNotFound
+	}
+	r.store.deleteLabel(id)
+	return nil
+}
+
+func (r *LabelRepository) Merge(ctx context.Context, sourceID, targetID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.labels[sourceID]; !ok {
+		return repository.ErrNotFound
+	}
+	if _, ok := r.store.labels[targetID]; !ok {
+		return repository.ErrNotFound
+	}
+
+	for _, labels := range r.store.taskLabels {
+		if labels[sourceID] {
+			labels[targetID] = true
+		}
+	}
+	r.store.deleteLabel(sourceID)
+	return nil
+}
+
+// deleteLabel removes a label and, like ON DELETE CASCADE, takes it off its
+// tasks. The caller holds the write lock.
+func (s *Store) deleteLabel(id uuid.UUID) {
+	for _, labels := range s.taskLabels {
+		delete(labels, id)
+	}
+	delete(s.labels, id)
+}
+
+// deleteLabels mirrors ON DELETE CASCADE from projects to their labels. The
+// caller holds the write lock.
+func (s *Store) deleteLabels(projectID uuid.UUID) {
+	for id, label := range s.labels {
+		if label.ProjectID == projectID {
+			s.deleteLabel(id)
+		}
+	}
+}
+
+// labelsExist tells whether every label in ids is stored. The caller holds
+// a lock.
+func (s *Store) labelsExist(ids []uuid.UUID) bool {
+	for _, id := range ids {
+		if _, ok := s.labels[id]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// setTaskLabels replaces the labels of a task. The caller holds the write
+// lock.
+func (s *Store) setTaskLabels(taskID uuid.UUID, ids []uuid.UUID) {
+	labels := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		labels[id] = true
+	}
+	s.taskLabels[taskID] = labels
+}
+
+// withLabels returns task with the IDs of its labels, ordered by name. The
+// caller holds a lock.
+func (s *Store) withLabels(task domain.Task) domain.Task {
+	labels := make([]domain.Label, 0, len(s.taskLabels[task.ID]))
+	for id := range s.taskLabels[task.ID] {
+		labels = append(labels, s.labels[id])
+	}
+	sortLabels(labels)
+
+	task.Labels = make([]uuid.UUID, len(labels))
+	for i, label := range labels {
+		task.Labels[i] = label.ID
+	}
+	return task
+}
+
+// matchLabels applies the label filter of query to a task. The caller holds
+// a lock.
+func (s *Store) matchLabels(taskID uuid.UUID, query *repository.TaskQuery) bool {
+	if len(query.Labels) == 0 {
+		return true
+	}
+	names := make(map[string]bool)
+	for id := range s.taskLabels[taskID] {
+		names[strings.ToLower(s.labels[id].Name)] = true
+	}
+	has := func(name string) bool { return names[strings.ToLower(name)] }
+	if query.AllLabels {
+		return !slices.ContainsFunc(query.Labels, func(name string) bool { return !has(name) })
+	}
+	return slices.ContainsFunc(query.Labels, has)
+}
@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"slices"
+	"time"
+
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+// paginate sorts the already filtered items and cuts out the page following
+// cursor, mirroring the keyset pagination of the SQL repositories.
+func paginate[T any](items []T, sorting repository.Sorting[T], sort []repository.SortField, limit int, cursor string) (*repository.Page[T], error) {
+	sort = sorting.OrDefault(sort)
+	limit = repository.Limit(limit)
+
+	slices.SortFunc(items, func(a, b T) int { return sorting.Compare(&a, &b, sort) })
+
+	if cursor != "" {
+		key, err := sorting.DecodeCursor(cursor, sort)
+		if err != nil {
+			return nil, err
+		}
+		start := len(items)
+		for i := range items {
+			if sorting.CompareKey(&items[i], sort, key) > 0 {
+				start = i
+				break
+			}
+		}
+		items = items[start:]
+	}
+
+	page := &repository.Page[T]{Items: []T{}}
+	if len(items) > limit {
+		items = items[:limit]
+		page.NextCursor = sorting.Cursor(&items[limit-1], sort)
+	}
+	page.Items = append(page.Items, items...)
+	return page, nil
+}
+
+// inRange reports whether t falls inside [after, before), ignoring zero
+// bounds.
+func inRange(t, after, before time.Time) bool {
+	if !after.IsZero() && t.Before(after) {
+		return false
+	}
+	if !before.IsZero() && !t.Before(before) {
+		return false
+	}
+	return true
+}
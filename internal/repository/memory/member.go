@@ -0,0 +1,118 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+type MemberRepository struct {
+	store *Store
+}
+
+func NewMemberRepository(store *Store) *MemberRepository {
+	return &MemberRepository{store: store}
+}
+
+func (r *MemberRepository) List(ctx context.Context, projectID uuid.UUID) ([]domain.Member, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	members := []domain.Member{}
+	for _, member := range r.store.members[projectID] {
+		members = append(members, member)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if !members[i].JoinedAt.Equal(members[j].JoinedAt) {
+			return members[i].JoinedAt.Before(members[j].JoinedAt)
+		}
+		return members[i].UserID.String() < members[j].UserID.String()
+	})
+	return members, nil
+}
+
+func (r *MemberRepository) Get(ctx context.Context, projectID, userID uuid.UUID) (*domain.Member, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	member, ok := r.store.members[projectID][userID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &member, nil
+}
+
+func (r *MemberRepository) Add(ctx context.Context, member *domain.Member) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.projects[member.ProjectID]; !ok {
+		return repository.ErrReferenced
+	}
+	if _, ok := r.store.users[member.UserID]; !ok {
+		return repository.ErrReferenced
+	}
+	if _, ok := r.store.members[member.ProjectID][member.UserID]; ok {
+		return repository.ErrDuplicate
+	}
+
+	r.store.setMember(*member)
+	return nil
+}
+
+func (r *MemberRepository) Update(ctx context.Context, member *domain.Member) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	current, ok := r.store.members[member.ProjectID][member.UserID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	current.Role = member.Role
+	r.store.setMember(current)
+	return nil
+}
+
+func (r *MemberRepository) Remove(ctx context.Context, projectID, userID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.members[projectID][userID]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.store.members[projectID], userID)
+	return nil
+}
+
+func (s *Store) setMember(member domain.Member) {
+	if s.members[member.ProjectID] == nil {
+		s.members[member.ProjectID] = make(map[uuid.UUID]domain.Member)
+	}
+	s.members[member.ProjectID][member.UserID] = member
+}
+
+// joinProject makes the user a contributor of the project, promoting an
+// observer. The caller holds the write lock.
+func (s *Store) joinProject(projectID, userID uuid.UUID) {
+	member, ok := s.members[projectID][userID]
+	switch {
+	case !ok:
+		member = domain.Member{ProjectID: projectID, UserID: userID, JoinedAt: time.Now().UTC()}
+	case member.Role != domain.ProjectObserver:
+		return
+	}
+	member.Role = domain.ProjectContributor
+	s.setMember(member)
+}
+
+// deleteMemberships mirrors ON DELETE CASCADE from users to project
+// members. The caller holds the write lock.
+func (s *Store) deleteMemberships(userID uuid.UUID) {
+	for _, members := range s.members {
+		delete(members, userID)
+	}
+}
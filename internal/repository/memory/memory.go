@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+)
+
+// Store holds every record in process memory. The repositories built on top
+// of it share a single lock, so they can be used from concurrent requests.
+type Store struct {
+	mu       sync.RWMutex
+	users    map[uuid.UUID]domain.User
+	tasks    map[uuid.UUID]domain.Task
+	projects map[uuid.UUID]domain.Entity
+	// workflows and boards are keyed by project ID.
+	workflows map[uuid.UUID]domain.Workflow
+	boards    map[uuid.UUID]domain.Board
+	sessions  map[uuid.UUID]domain.Session
+	// refreshTokens are keyed by hash.
+	refreshTokens map[string]domain.RefreshToken
+	apiTokens     map[uuid.UUID]domain.APIToken
+	// members are keyed by project ID, then user ID.
+	members       map[uuid.UUID]map[uuid.UUID]domain.Member
+	organizations map[uuid.UUID]domain.Organization
+	// orgMembers are keyed by organization ID, then user ID.
+	orgMembers map[uuid.UUID]map[uuid.UUID]domain.OrganizationMember
+	comments   map[uuid.UUID]domain.Comment
+	// commentRevisions are keyed by comment ID, oldest first.
+	commentRevisions map[uuid.UUID][]domain.CommentRevision
+	attachments      map[uuid.UUID]domain.Attachment
+	// dependencies are keyed by task ID, then blocker ID.
+	dependencies map[uuid.UUID]map[uuid.UUID]domain.Dependency
+	labels       map[uuid.UUID]domain.Label
+	// taskLabels are keyed by task ID, then label ID.
+	taskLabels    map[uuid.UUID]map[uuid.UUID]bool
+	notifications map[uuid.UUID]domain.Notification
+	worklogs      map[uuid.UUID]domain.Worklog
+	sprints       map[uuid.UUID]domain.Sprint
+	milestones    map[uuid.UUID]domain.Milestone
+}
+
+func NewStore() *Store {
+	return &Store{
+		users:     make(map[uuid.UUID]domain.User),
+		tasks:     make(map[uuid.UUID]domain.Task),
+		projects:  make(map[uuid.UUID]domain.Entity),
+		workflows: make(map[uuid.UUID]domain.Workflow),
+		boards:    make(map[uuid.UUID]domain.Board),
+		sessions:  make(map[uuid.UUID]domain.Session),
+
+		refreshTokens: make(map[string]domain.RefreshToken),
+		apiTokens:     make(map[uuid.UUID]domain.APIToken),
+		members:       make(map[uuid.UUID]map[uuid.UUID]domain.Member),
+		organizations: make(map[uuid.UUID]domain.Organization),
+		orgMembers:    make(map[uuid.UUID]map[uuid.UUID]domain.OrganizationMember),
+
+		comments:         make(map[uuid.UUID]domain.Comment),
+		commentRevisions: make(map[uuid.UUID][]domain.CommentRevision),
+		attachments:      make(map[uuid.UUID]domain.Attachment),
+		dependencies:     make(map[uuid.UUID]map[uuid.UUID]domain.Dependency),
+		labels:           make(map[uuid.UUID]domain.Label),
+		taskLabels:       make(map[uuid.UUID]map[uuid.UUID]bool),
+		notifications:    make(map[uuid.UUID]domain.Notification),
+		worklogs:         make(map[uuid.UUID]domain.Worklog),
+		sprints:          make(map[uuid.UUID]domain.Sprint),
+		milestones:       make(map[uuid.UUID]domain.Milestone),
+	}
+}
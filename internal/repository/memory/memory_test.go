@@ -0,0 +1,33 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/yelnar0112/project-management/internal/repository/memory"
+	"github.com/yelnar0112/project-management/internal/repository/repotest"
+)
+
+func TestRepositories(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		store := memory.NewStore()
+		return repotest.Repositories{
+			Users:         memory.NewUserRepository(store),
+			Tasks:         memory.NewTaskRepository(store),
+			Projects:      memory.NewProjectRepository(store),
+			Workflows:     memory.NewWorkflowRepository(store),
+			Boards:        memory.NewBoardRepository(store),
+			Sessions:      memory.NewSessionRepository(store),
+			Members:       memory.NewMemberRepository(store),
+			APITokens:     memory.NewAPITokenRepository(store),
+			Organizations: memory.NewOrganizationRepository(store),
+			Comments:      memory.NewCommentRepository(store),
+			Attachments:   memory.NewAttachmentRepository(store),
+			Dependencies:  memory.NewDependencyRepository(store),
+			Labels:        memory.NewLabelRepository(store),
+			Notifications: memory.NewNotificationRepository(store),
+			Worklogs:      memory.NewWorklogRepository(store),
+			Sprints:       memory.NewSprintRepository(store),
+			Milestones:    memory.NewMilestoneRepository(store),
+		}
+	})
+}
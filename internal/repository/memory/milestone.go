@@ -0,0 +1,164 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+type MilestoneRepository struct {
+	store *Store
+}
+
+func NewMilestoneRepository(store *Store) *MilestoneRepository {
+	return &MilestoneRepository{store: store}
+}
+
+func (r *MilestoneRepository) List(ctx context.Context, projectID uuid.UUID) ([]domain.Milestone, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	milestones := []domain.Milestone{}
+	for _, milestone := range r.store.milestones {
+		if milestone.ProjectID == projectID {
+			milestones = append(milestones, milestone)
+		}
+	}
+	sortMilestones(milestones)
+	return milestones, nil
+}
+
+func (r *MilestoneRepository) Upcoming(ctx context.Context, organizationID, userID uuid.UUID, from, before time.Time) ([]domain.Milestone, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	milestones := []domain.Milestone{}
+	for _, milestone := range r.store.milestones {
+		if r.store.projects[milestone.ProjectID].OrganizationID != organizationID {
+			continue
+		}
+		if _, ok := r.store.members[milestone.ProjectID][userID]; !ok {
+			continue
+		}
+		if !inRange(milestone.TargetDate, from, before) {
+			continue
+		}
+		milestones = append(milestones, milestone)
+	}
+	sortMilestones(milestones)
+	return milestones, nil
+}
+
+func sortMilestones(milestones []domain.Milestone) {
+	sort.Slice(milestones, func(i, j int) bool {
+		if !milestones[i].TargetDate.Equal(milestones[j].TargetDate) {
+			return milestones[i].TargetDate.Before(milestones[j].TargetDate)
+		}
+		return milestones[i].ID.String() < milestones[j].ID.String()
+	})
+}
+
+func (r *MilestoneRepository) Get(ctx context.Context, id uuid.UUID) (*domain.Milestone, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	milestone, ok := r.store.milestones[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &milestone, nil
+}
+
+func (r *MilestoneRepository) Create(ctx context.Context, milestone *domain.Milestone) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.milestones[milestone.ID]; ok {
+		return repository.ErrDuplicate
+	}
+	if _, ok := r.store.projects[milestone.ProjectID]; !ok {
+		return repository.ErrReferenced
+	}
+
+	r.store.milestones[milestone.ID] = *milestone
+	return nil
+}
+
+func (r *MilestoneRepository) Update(ctx context.Context, milestone *domain.Milestone) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	current, ok := r.store.milestones[milestone.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	current.Title = milestone.Title
+	current.Description = milestone.Description
+	current.TargetDate = milestone.TargetDate
+	r.store.milestones[milestone.ID] = current
+	return nil
+}
+
+func (r *MilestoneRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.milestones[id]; !ok {
+		return repository.ErrNotFound
+	}
+	r.store.deleteMilestone(id)
+	return nil
+}
+
+func (r *MilestoneRepository) CountTasks(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]repository.TaskCount, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	counts := make(map[uuid.UUID]repository.TaskCount)
+	for _, id := range ids {
+		counts[id] = repository.TaskCount{}
+	}
+	for _, task := range r.store.tasks {
+		count, ok := counts[task.MilestoneID]
+		if !ok {
+			continue
+		}
+		count.Total++
+		if task.CompletedAt.IsZero() {
+			count.Open++
+		}
+		counts[task.MilestoneID] = count
+	}
+	for id, count := range counts {
+		if count.Total == 0 {
+			delete(counts, id)
+		}
+	}
+	return counts, nil
+}
+
+// deleteMilestone removes a milestone and, like ON DELETE SET NULL, unlinks
+// its tasks. The caller holds the write lock.
+func (s *Store) deleteMilestone(id uuid.UUID) {
+	for taskID, task := range s.tasks {
+		if task.MilestoneID == id {
+			task.MilestoneID = uuid.Nil
+			s.tasks[taskID] = task
+		}
+	}
+	delete(s.milestones, id)
+}
+
+// deleteMilestones mirrors ON DELETE CASCADE from projects to their
+// milestones. The caller holds the write lock.
+func (s *Store) deleteMilestones(projectID uuid.UUID) {
+	for id, milestone := range s.milestones {
+		if milestone.ProjectID == projectID {
+			s.deleteMilestone(id)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+type NotificationRepository struct {
+	store *Store
+}
+
+func NewNotificationRepository(store *Store) *NotificationRepository {
+	return &NotificationRepository{store: store}
+}
+
+func (r *NotificationRepository) List(ctx context.Context, organizationID, userID uuid.UUID,
+	unread bool) ([]domain.Notification, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	notifications := []domain.Notification{}
+	for _, notification := range r.store.notifications {
+		if notification.UserID == userID && (!unread || notification.ReadAt.IsZero()) &&
+			r.store.notifiedIn(&notification, organizationID) {
+			notifications = append(notifications, notification)
+		}
+	}
+	sort.Slice(notifications, func(i, j int) bool {
+		if !notifications[i].CreatedAt.Equal(notifications[j].CreatedAt) {
+			return notifications[i].CreatedAt.After(notifications[j].CreatedAt)
+		}
+		return notifications[i].ID.String() < notifications[j].ID.String()
+	})
+	return notifications, nil
+}
+
+func (r *NotificationRepository) Get(ctx context.Context, id uuid.UUID) (*domain.Notification, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	notification, ok := r.store.notifications[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &notification, nil
+}
+
+func (r *NotificationRepository) Create(ctx context.Context, notification *domain.Notification) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[notification.UserID]; !ok {
+		return repository.ErrReferenced
+	}
+	if _, ok := r.store.tasks[notification.TaskID]; !ok {
+		return repository.ErrReferenced
+	}
+	if _, ok := r.store.notifications[notification.ID]; ok {
+		return repository.ErrDuplicate
+	}
+	for _, existing := range r.store.notifications {
+		if existing.UserID == notification.UserID && existing.TaskID == notification.TaskID &&
+			existing.Kind == notification.Kind && existing.DueDate.Equal(notification.DueDate) {
+			return repository.ErrDuplicate
+		}
+	}
+
+	r.store.notifications[notification.ID] = *notification
+	return nil
+}
+
+func (r *NotificationRepository) MarkRead(ctx context.Context, id uuid.UUID, at time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	notification, ok := r.store.notifications[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if notification.ReadAt.IsZero() {
+		notification.ReadAt = at
+		r.store.notifications[id] = notification
+	}
+	return nil
+}
+
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, organizationID, userID uuid.UUID,
+	at time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, notification := range r.store.notifications {
+		if notification.UserID == userID && notification.ReadAt.IsZero() &&
+			r.store.notifiedIn(&notification, organizationID) {
+			notification.ReadAt = at
+			r.store.notifications[id] = notification
+		}
+	}
+	return nil
+}
+
+// notifiedIn reports whether a notification is about a task of
+// organizationID, or any task when it is uuid.Nil. The caller holds a lock.
+func (s *Store) notifiedIn(notification *domain.Notification, organizationID uuid.UUID) bool {
+	return organizationID == uuid.Nil || s.tasks[notification.TaskID].OrganizationID == organizationID
+}
+
+// reminded reports whether a task has a notification of kind about its
+// current due date. The caller holds a lock.
+func (s *Store) reminded(task *domain.Task, kind domain.NotificationKind) bool {
+	for _, notification := range s.notifications {
+		if notification.TaskID == task.ID && notification.Kind == kind && notification.DueDate.Equal(task.DueDate) {
+			return true
+		}
+	}
+	return false
+}
+
+// deleteNotifications mirrors ON DELETE CASCADE from users and tasks to
+// notifications: it removes those whose user or task is id. The caller
+// holds the write lock.
+func (s *Store) deleteNotifications(id uuid.UUID) {
+	for notificationID, notification := range s.notifications {
+		if notification.UserID == id || notification.TaskID == id {
+			delete(s.notifications, notificationID)
+		}
+	}
+}
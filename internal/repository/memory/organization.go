@@ -0,0 +1,172 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+type OrganizationRepository struct {
+	store *Store
+}
+
+func NewOrganizationRepository(store *Store) *OrganizationRepository {
+	return &OrganizationRepository{store: store}
+}
+
+func (r *OrganizationRepository) ListForUser(ctx context.Context, userID uuid.UUID) ([]domain.Organization, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var memberships []domain.OrganizationMember
+	for _, members := range r.store.orgMembers {
+		if member, ok := members[userID]; ok {
+			memberships = append(memberships, member)
+		}
+	}
+	sort.Slice(memberships, func(i, j int) bool {
+		if !memberships[i].JoinedAt.Equal(memberships[j].JoinedAt) {
+			return memberships[i].JoinedAt.Before(memberships[j].JoinedAt)
+		}
+		return memberships[i].OrganizationID.String() < memberships[j].OrganizationID.String()
+	})
+
+	organizations := make([]domain.Organization, 0, len(memberships))
+	for _, member := range memberships {
+		organizations = append(organizations, r.store.organizations[member.OrganizationID])
+	}
+	return organizations, nil
+}
+
+func (r *OrganizationRepository) Get(ctx context.Context, id uuid.UUID) (*domain.Organization, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	organization, ok := r.store.organizations[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &organization, nil
+}
+
+func (r *OrganizationRepository) Create(ctx context.Context, organization *domain.Organization, member *domain.OrganizationMember) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.organizations[organization.ID]; ok {
+		return repository.ErrDuplicate
+	}
+	if _, ok := r.store.users[member.UserID]; !ok || member.OrganizationID != organization.ID {
+		return repository.ErrReferenced
+	}
+
+	r.store.organizations[organization.ID] = *organization
+	r.store.setOrganizationMember(*member)
+	return nil
+}
+
+func (r *OrganizationRepository) Update(ctx context.Context, organization *domain.Organization) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	current, ok := r.store.organizations[organization.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	current.Name = organization.Name
+	r.store.organizations[organization.ID] = current
+	return nil
+}
+
+func (r *OrganizationRepository) ListMembers(ctx context.Context, organizationID uuid.UUID) ([]domain.OrganizationMember, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	members := []domain.OrganizationMember{}
+	for _, member := range r.store.orgMembers[organizationID] {
+		members = append(members, member)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if !members[i].JoinedAt.Equal(members[j].JoinedAt) {
+			return members[i].JoinedAt.Before(members[j].JoinedAt)
+		}
+		return members[i].UserID.String() < members[j].UserID.String()
+	})
+	return members, nil
+}
+
+func (r *OrganizationRepository) GetMember(ctx context.Context, organizationID, userID uuid.UUID) (*domain.OrganizationMember, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	member, ok := r.store.orgMembers[organizationID][userID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &member, nil
+}
+
+func (r *OrganizationRepository) AddMember(ctx context.Context, member *domain.OrganizationMember) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.organizations[member.OrganizationID]; !ok {
+		return repository.ErrReferenced
+	}
+	if _, ok := r.store.users[member.UserID]; !ok {
+		return repository.ErrReferenced
+	}
+	if _, ok := r.store.orgMembers[member.OrganizationID][member.UserID]; ok {
+		return repository.ErrDuplicate
+	}
+
+	r.store.setOrganizationMember(*member)
+	return nil
+}
+
+func (r *OrganizationRepository) UpdateMember(ctx context.Context, member *domain.OrganizationMember) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	current, ok := r.store.orgMembers[member.OrganizationID][member.UserID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	current.Role = member.Role
+	r.store.setOrganizationMember(current)
+	return nil
+}
+
+func (r *OrganizationRepository) RemoveMember(ctx context.Context, organizationID, userID uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.orgMembers[organizationID][userID]; !ok {
+		return repository.ErrNotFound
+	}
+	for projectID, project := range r.store.projects {
+		if project.OrganizationID == organizationID {
+			delete(r.store.members[projectID], userID)
+		}
+	}
+	delete(r.store.orgMembers[organizationID], userID)
+	return nil
+}
+
+func (s *Store) setOrganizationMember(member domain.OrganizationMember) {
+	if s.orgMembers[member.OrganizationID] == nil {
+		s.orgMembers[member.OrganizationID] = make(map[uuid.UUID]domain.OrganizationMember)
+	}
+	s.orgMembers[member.OrganizationID][member.UserID] = member
+}
+
+// deleteOrganizationMemberships mirrors ON DELETE CASCADE from users to
+// organization members. The caller holds the write lock.
+func (s *Store) deleteOrganizationMemberships(userID uuid.UUID) {
+	for _, members := range s.orgMembers {
+		delete(members, userID)
+	}
+}
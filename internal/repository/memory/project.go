@@ -0,0 +1,129 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+type ProjectRepository struct {
+	store *Store
+}
+
+func NewProjectRepository(store *Store) *ProjectRepository {
+	return &ProjectRepository{store: store}
+}
+
+func (r *ProjectRepository) GetAll(ctx context.Context, query repository.ProjectQuery) (*repository.Page[domain.Entity], error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var projects []domain.Entity
+	for _, project := range r.store.projects {
+		if query.OrganizationID != uuid.Nil && project.OrganizationID != query.OrganizationID {
+			continue
+		}
+		if query.ManagerID != uuid.Nil && project.ManagerID != query.ManagerID {
+			continue
+		}
+		if _, ok := r.store.members[project.ID][query.MemberID]; query.MemberID != uuid.Nil && !ok {
+			continue
+		}
+		if !inRange(project.StartDate, query.StartsAfter, query.StartsBefore) ||
+			!inRange(project.EndDate, query.EndsAfter, query.EndsBefore) {
+			continue
+		}
+		projects = append(projects, project)
+	}
+
+	return paginate(projects, repository.ProjectSorting, query.Sort, query.Limit, query.Cursor)
+}
+
+func (r *ProjectRepository) Create(ctx context.Context, project *domain.Entity) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.projects[project.ID]; ok {
+		return repository.ErrDuplicate
+	}
+	if _, ok := r.store.organizations[project.OrganizationID]; !ok {
+		return repository.ErrReferenced
+	}
+
+	r.store.projects[project.ID] = *project
+	return nil
+}
+
+func (r *ProjectRepository) Get(ctx context.Context, id uuid.UUID) (*domain.Entity, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	project, ok := r.store.projects[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &project, nil
+}
+
+func (r *ProjectRepository) Update(ctx context.Context, project *domain.Entity) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	current, ok := r.store.projects[project.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+
+	updated := *project
+	updated.OrganizationID = current.OrganizationID
+	r.store.projects[project.ID] = updated
+	return nil
+}
+
+func (r *ProjectRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.projects[id]; !ok {
+		return repository.ErrNotFound
+	}
+	for _, task := range r.store.tasks {
+		if task.ProjectID == id {
+			return repository.ErrReferenced
+		}
+	}
+	delete(r.store.projects, id)
+	delete(r.store.workflows, id)
+	delete(r.store.boards, id)
+	delete(r.store.members, id)
+	r.store.deleteAttachments(domain.AttachmentOwner{ProjectID: id})
+	r.store.deleteLabels(id)
+	r.store.deleteSprints(id)
+	r.store.deleteMilestones(id)
+	return nil
+}
+
+func (r *ProjectRepository) DeleteWithTasks(ctx context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.projects[id]; !ok {
+		return repository.ErrNotFound
+	}
+	for taskID, task := range r.store.tasks {
+		if task.ProjectID == id {
+			r.store.deleteTask(taskID)
+		}
+	}
+	delete(r.store.projects, id)
+	delete(r.store.workflows, id)
+	delete(r.store.boards, id)
+	delete(r.store.members, id)
+	r.store.deleteAttachments(domain.AttachmentOwner{ProjectID: id})
+	r.store.deleteLabels(id)
+	r.store.deleteSprints(id)
+	r.store.deleteMilestones(id)
+	return nil
+}
@@ -0,0 +1,121 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+type SessionRepository struct {
+	store *Store
+}
+
+func NewSessionRepository(store *Store) *SessionRepository {
+	return &SessionRepository{store: store}
+}
+
+func (r *SessionRepository) Create(ctx context.Context, session *domain.Session, token *domain.RefreshToken) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[session.UserID]; !ok {
+		return repository.ErrReferenced
+	}
+	if _, ok := r.store.sessions[session.ID]; ok {
+		return repository.ErrDuplicate
+	}
+	if _, ok := r.store.refreshTokens[token.Hash]; ok {
+		return repository.ErrDuplicate
+	}
+
+	r.store.sessions[session.ID] = *session
+	r.store.refreshTokens[token.Hash] = *token
+	return nil
+}
+
+func (r *SessionRepository) Get(ctx context.Context, id uuid.UUID) (*domain.Session, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	session, ok := r.store.sessions[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &session, nil
+}
+
+func (r *SessionRepository) GetRefreshToken(ctx context.Context, hash string) (*domain.RefreshToken, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	token, ok := r.store.refreshTokens[hash]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &token, nil
+}
+
+func (r *SessionRepository) Rotate(ctx context.Context, hash string, usedAt time.Time, next *domain.RefreshToken) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	token, ok := r.store.refreshTokens[hash]
+	if !ok || !token.UsedAt.IsZero() {
+		return repository.ErrNotFound
+	}
+	if _, ok := r.store.refreshTokens[next.Hash]; ok {
+		return repository.ErrDuplicate
+	}
+
+	token.UsedAt = usedAt
+	r.store.refreshTokens[hash] = token
+	r.store.refreshTokens[next.Hash] = *next
+	return nil
+}
+
+func (r *SessionRepository) Revoke(ctx context.Context, id uuid.UUID, at time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	session, ok := r.store.sessions[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if session.RevokedAt.IsZero() {
+		session.RevokedAt = at
+		r.store.sessions[id] = session
+	}
+	return nil
+}
+
+func (r *SessionRepository) RevokeUser(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, session := range r.store.sessions {
+		if session.UserID == userID && session.RevokedAt.IsZero() {
+			session.RevokedAt = at
+			r.store.sessions[id] = session
+		}
+	}
+	return nil
+}
+
+// deleteSessions mirrors ON DELETE CASCADE from users to sessions and from
+// sessions to refresh tokens. The caller holds the write lock.
+func (s *Store) deleteSessions(userID uuid.UUID) {
+	for id, session := range s.sessions {
+		if session.UserID != userID {
+			continue
+		}
+		for hash, token := range s.refreshTokens {
+			if token.SessionID == id {
+				delete(s.refreshTokens, hash)
+			}
+		}
+		delete(s.sessions, id)
+	}
+}
@@ -0,0 +1,180 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+type SprintRepository struct {
+	store *Store
+}
+
+func NewSprintRepository(store *Store) *SprintRepository {
+	return &SprintRepository{store: store}
+}
+
+func (r *SprintRepository) List(ctx context.Context, projectID uuid.UUID) ([]domain.Sprint, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	sprints := []domain.Sprint{}
+	for _, sprint := range r.store.sprints {
+		if sprint.ProjectID == projectID {
+			sprints = append(sprints, sprint)
+		}
+	}
+	sort.Slice(sprints, func(i, j int) bool {
+		if !sprints[i].StartDate.Equal(sprints[j].StartDate) {
+			return sprints[i].StartDate.Before(sprints[j].StartDate)
+		}
+		return sprints[i].ID.String() < sprints[j].ID.String()
+	})
+	return sprints, nil
+}
+
+func (r *SprintRepository) Get(ctx context.Context, id uuid.UUID) (*domain.Sprint, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	sprint, ok := r.store.sprints[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &sprint, nil
+}
+
+func (r *SprintRepository) Create(ctx context.Context, sprint *domain.Sprint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.sprints[sprint.ID]; ok {
+		return repository.ErrDuplicate
+	}
+	if _, ok := r.store.projects[sprint.ProjectID]; !ok {
+		return repository.ErrReferenced
+	}
+	if _, ok := r.store.sprints[sprint.RolledOverTo]; sprint.RolledOverTo != uuid.Nil && !ok {
+		return repository.ErrReferenced
+	}
+	if r.store.otherActiveSprint(sprint) {
+		return repository.ErrDuplicate
+	}
+
+	r.store.sprints[sprint.ID] = *sprint
+	return nil
+}
+
+func (r *SprintRepository) Update(ctx context.Context, sprint *domain.Sprint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.store.updateSprint(sprint)
+}
+
+// updateSprint stores a sprint in its current project. The caller holds
+// the write lock.
+func (s *Store) updateSprint(sprint *domain.Sprint) error {
+	current, ok := s.sprints[sprint.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if _, ok := s.sprints[sprint.RolledOverTo]; sprint.RolledOverTo != uuid.Nil && !ok {
+		return repository.ErrReferenced
+	}
+	updated := *sprint
+	updated.ProjectID = current.ProjectID
+	if s.otherActiveSprint(&updated) {
+		return repository.ErrDuplicate
+	}
+
+	s.sprints[sprint.ID] = updated
+	return nil
+}
+
+// otherActiveSprint mirrors the unique index on the active sprint of each
+// project. The caller holds a lock.
+func (s *Store) otherActiveSprint(sprint *domain.Sprint) bool {
+	if sprint.State != domain.SprintActive {
+		return false
+	}
+	for _, other := range s.sprints {
+		if other.ID != sprint.ID && other.ProjectID == sprint.ProjectID && other.State == domain.SprintActive {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *SprintRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.sprints[id]; !ok {
+		return repository.ErrNotFound
+	}
+	r.store.deleteSprint(id)
+	return nil
+}
+
+func (r *SprintRepository) Close(ctx context.Context, sprint *domain.Sprint,
+	tally func(tasks []domain.Task) (completed, unfinished domain.SprintWork)) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	tasks := []domain.Task{}
+	for _, task := range r.store.tasks {
+		if task.SprintID == sprint.ID {
+			tasks = append(tasks, task)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		if !tasks[i].CreatedAt.Equal(tasks[j].CreatedAt) {
+			return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+		}
+		return tasks[i].ID.String() < tasks[j].ID.String()
+	})
+	sprint.Completed, sprint.Unfinished = tally(tasks)
+	if err := r.store.updateSprint(sprint); err != nil {
+		return err
+	}
+	for id, task := range r.store.tasks {
+		if task.SprintID == sprint.ID && task.CompletedAt.IsZero() {
+			task.SprintID = sprint.RolledOverTo
+			r.store.tasks[id] = task
+		}
+	}
+	return nil
+}
+
+// deleteSprint removes a sprint and, like ON DELETE SET NULL, moves its
+// tasks to the backlog and clears it from the sprints rolled over to it.
+// The caller holds the write lock.
+func (s *Store) deleteSprint(id uuid.UUID) {
+	for taskID, task := range s.tasks {
+		if task.SprintID == id {
+			task.SprintID = uuid.Nil
+			s.tasks[taskID] = task
+		}
+	}
+	for sprintID, sprint := range s.sprints {
+		if sprint.RolledOverTo == id {
+			sprint.RolledOverTo = uuid.Nil
+			s.sprints[sprintID] = sprint
+		}
+	}
+	delete(s.sprints, id)
+}
+
+// deleteSprints mirrors ON DELETE CASCADE from projects to their sprints.
+// The caller holds the write lock.
+func (s *Store) deleteSprints(projectID uuid.UUID) {
+	for id, sprint := range s.sprints {
+		if sprint.ProjectID == projectID {
+			s.deleteSprint(id)
+		}
+	}
+}
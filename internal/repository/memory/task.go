@@ -0,0 +1,314 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+type TaskRepository struct {
+	store *Store
+}
+
+func NewTaskRepository(store *Store) *TaskRepository {
+	return &TaskRepository{store: store}
+}
+
+func (r *TaskRepository) GetAll(ctx context.Context, query repository.TaskQuery) (*repository.Page[domain.Task], error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var tasks []domain.Task
+	for _, task := range r.store.tasks {
+		if matchTask(&task, &query) && r.store.matchLabels(task.ID, &query) &&
+			(query.Unreminded == "" || !r.store.reminded(&task, query.Unreminded)) {
+			tasks = append(tasks, r.store.withLabels(task))
+		}
+	}
+
+	return paginate(tasks, repository.TaskSorting, query.Sort, query.Limit, query.Cursor)
+}
+
+func (r *TaskRepository) Count(ctx context.Context, query repository.TaskQuery) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	n := 0
+	for _, task := range r.store.tasks {
+		if matchTask(&task, &query) && r.store.matchLabels(task.ID, &query) &&
+			(query.Unreminded == "" || !r.store.reminded(&task, query.Unreminded)) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func matchTask(task *domain.Task, query *repository.TaskQuery) bool {
+	if query.OrganizationID != uuid.Nil && task.OrganizationID != query.OrganizationID {
+		return false
+	}
+	if len(query.States) > 0 && !slices.Contains(query.States, task.State) {
+		return false
+	}
+	if len(query.Priorities) > 0 && !slices.Contains(query.Priorities, task.Priority) {
+		return false
+	}
+	if query.Assignee != uuid.Nil && task.Assignee != query.Assignee {
+		return false
+	}
+	if query.ProjectID != uuid.Nil && task.ProjectID != query.ProjectID {
+		return false
+	}
+	if query.ParentID != uuid.Nil && task.ParentID != query.ParentID {
+		return false
+	}
+	if query.SprintID != uuid.Nil && task.SprintID != query.SprintID {
+		return false
+	}
+	if query.MilestoneID != uuid.Nil && task.MilestoneID != query.MilestoneID {
+		return false
+	}
+	if query.Backlog && task.SprintID != uuid.Nil {
+		return false
+	}
+	if !inRange(task.CreatedAt, query.CreatedAfter, query.CreatedBefore) {
+		return false
+	}
+	if !query.CompletedAfter.IsZero() || !query.CompletedBefore.IsZero() {
+		if task.CompletedAt.IsZero() || !inRange(task.CompletedAt, query.CompletedAfter, query.CompletedBefore) {
+			return false
+		}
+	}
+	if !query.DueAfter.IsZero() || !query.DueBefore.IsZero() {
+		if task.DueDate.IsZero() || !inRange(task.DueDate, query.DueAfter, query.DueBefore) {
+			return false
+		}
+	}
+	if query.Open && !task.CompletedAt.IsZero() {
+		return false
+	}
+	if !query.OverdueAt.IsZero() && !task.Overdue(query.OverdueAt) {
+		return false
+	}
+	return true
+}
+
+func (r *TaskRepository) Create(ctx context.Context, task *domain.Task) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.create(task)
+}
+
+func (r *TaskRepository) CreateWithin(ctx context.Context, task *domain.Task, limits []repository.WIPLimit) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.overLimit(limits, map[uuid.UUID]domain.Task{task.ID: *task}) {
+		return repository.ErrLimitReached
+	}
+	return r.create(task)
+}
+
+// create stores a new task. The caller holds the write lock.
+func (r *TaskRepository) create(task *domain.Task) error {
+	if _, ok := r.store.tasks[task.ID]; ok {
+		return repository.ErrDuplicate
+	}
+	if _, ok := r.store.organizations[task.OrganizationID]; !ok {
+		return repository.ErrReferenced
+	}
+	if _, ok := r.store.tasks[task.ParentID]; task.ParentID != uuid.Nil && !ok {
+		return repository.ErrReferenced
+	}
+	if _, ok := r.store.sprints[task.SprintID]; task.SprintID != uuid.Nil && !ok {
+		return repository.ErrReferenced
+	}
+	if _, ok := r.store.milestones[task.MilestoneID]; task.MilestoneID != uuid.Nil && !ok {
+		return repository.ErrReferenced
+	}
+	if !r.store.labelsExist(task.Labels) {
+		return repository.ErrReferenced
+	}
+
+	stored := *task
+	stored.Labels = nil
+	r.store.tasks[task.ID] = stored
+	r.store.setTaskLabels(task.ID, task.Labels)
+	return nil
+}
+
+func (r *TaskRepository) Get(ctx context.Context, id uuid.UUID) (*domain.Task, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	task, ok := r.store.tasks[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	task = r.store.withLabels(task)
+	return &task, nil
+}
+
+func (r *TaskRepository) Update(ctx context.Context, task *domain.Task) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.update(task)
+}
+
+// update replaces a task, carrying its subtasks along to its project. The
+// caller holds the write lock.
+func (r *TaskRepository) update(task *domain.Task) error {
+	current, ok := r.store.tasks[task.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+
+	if _, ok := r.store.tasks[task.ParentID]; task.ParentID != uuid.Nil && !ok {
+		return repository.ErrReferenced
+	}
+	if _, ok := r.store.sprints[task.SprintID]; task.SprintID != uuid.Nil && !ok {
+		return repository.ErrReferenced
+	}
+	if _, ok := r.store.milestones[task.MilestoneID]; task.MilestoneID != uuid.Nil && !ok {
+		return repository.ErrReferenced
+	}
+	if !r.store.labelsExist(task.Labels) {
+		return repository.ErrReferenced
+	}
+
+	updated := *task
+	updated.Labels = nil
+	updated.OrganizationID = current.OrganizationID
+	r.store.tasks[task.ID] = updated
+	r.store.setTaskLabels(task.ID, task.Labels)
+	for _, subtask := range r.store.subtasks(task.ID) {
+		if subtask.ProjectID != task.ProjectID {
+			subtask.SprintID = uuid.Nil
+			subtask.MilestoneID = uuid.Nil
+			subtask.Rank = ""
+		}
+		subtask.ProjectID = task.ProjectID
+		r.store.tasks[subtask.ID] = subtask
+		for id := range r.store.taskLabels[subtask.ID] {
+			if r.store.labels[id].ProjectID != task.ProjectID {
+				delete(r.store.taskLabels[subtask.ID], id)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *TaskRepository) Move(ctx context.Context, task *domain.Task, ranks map[uuid.UUID]string,
+	limits []repository.WIPLimit) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.tasks[task.ID]; !ok {
+		return repository.ErrNotFound
+	}
+	for id := range ranks {
+		if _, ok := r.store.tasks[id]; !ok {
+			return repository.ErrNotFound
+		}
+	}
+	// The limits are checked against the tasks as they will be: the task as
+	// given, and its subtasks in its project.
+	changed := map[uuid.UUID]domain.Task{task.ID: *task}
+	for _, subtask := range r.store.subtasks(task.ID) {
+		subtask.ProjectID = task.ProjectID
+		changed[subtask.ID] = subtask
+	}
+	if r.overLimit(limits, changed) {
+		return repository.ErrLimitReached
+	}
+
+	if err := r.update(task); err != nil {
+		return err
+	}
+	for id, rank := range ranks {
+		t := r.store.tasks[id]
+		t.Rank = rank
+		r.store.tasks[id] = t
+	}
+	return nil
+}
+
+// overLimit reports whether the tasks, with those in changed as given
+// there, leave more tasks in the states of one of limits than it allows.
+// The caller holds the lock.
+func (r *TaskRepository) overLimit(limits []repository.WIPLimit, changed map[uuid.UUID]domain.Task) bool {
+	for _, limit := range limits {
+		n := 0
+		count := func(t domain.Task) {
+			if t.ProjectID == limit.ProjectID && slices.Contains(limit.States, t.State) {
+				n++
+			}
+		}
+		for id, t := range r.store.tasks {
+			if _, ok := changed[id]; !ok {
+				count(t)
+			}
+		}
+		for _, t := range changed {
+			count(t)
+		}
+		if n > limit.Max {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *TaskRepository) Subtasks(ctx context.Context, id uuid.UUID) ([]domain.Task, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tasks := r.store.subtasks(id)
+	for i := range tasks {
+		tasks[i] = r.store.withLabels(tasks[i])
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		if !tasks[i].CreatedAt.Equal(tasks[j].CreatedAt) {
+			return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+		}
+		return tasks[i].ID.String() < tasks[j].ID.String()
+	})
+	return tasks, nil
+}
+
+// subtasks returns the tasks below a task, at any depth. The caller holds a
+// lock.
+func (s *Store) subtasks(id uuid.UUID) []domain.Task {
+	children := make(map[uuid.UUID][]domain.Task)
+	for _, task := range s.tasks {
+		if task.ParentID != uuid.Nil {
+			children[task.ParentID] = append(children[task.ParentID], task)
+		}
+	}
+
+	tasks := []domain.Task{}
+	for queue := []uuid.UUID{id}; len(queue) > 0; queue = queue[1:] {
+		for _, child := range children[queue[0]] {
+			tasks = append(tasks, child)
+			queue = append(queue, child.ID)
+		}
+	}
+	return tasks
+}
+
+func (r *TaskRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.tasks[id]; !ok {
+		return repository.ErrNotFound
+	}
+	r.store.deleteTask(id)
+	return nil
+}
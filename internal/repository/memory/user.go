@@ -0,0 +1,218 @@
+package memory
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+type UserRepository struct {
+	store *Store
+}
+
+func NewUserRepository(store *Store) *UserRepository {
+	return &UserRepository{store: store}
+}
+
+func (r *UserRepository) GetAll(ctx context.Context, query repository.UserQuery) (*repository.Page[domain.User], error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var users []domain.User
+	for _, user := range r.store.users {
+		if query.OrganizationID != uuid.Nil {
+			member, ok := r.store.orgMembers[query.OrganizationID][user.ID]
+			if !ok || (query.Role != "" && member.Role != query.Role) {
+				continue
+			}
+			user.Role = member.Role
+		}
+		users = append(users, user)
+	}
+
+	return paginate(users, repository.UserSorting, query.Sort, query.Limit, query.Cursor)
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.create(user)
+}
+
+func (r *UserRepository) CreateWithMembership(ctx context.Context, user *domain.User, member *domain.OrganizationMember) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.organizations[member.OrganizationID]; !ok || member.UserID != user.ID {
+		return repository.ErrReferenced
+	}
+	if err := r.create(user); err != nil {
+		return err
+	}
+	r.store.setOrganizationMember(*member)
+	return nil
+}
+
+// create stores a user without its role, which lives on its organization
+// memberships. The caller holds the write lock.
+func (r *UserRepository) create(user *domain.User) error {
+	if _, ok := r.store.users[user.ID]; ok {
+		return repository.ErrDuplicate
+	}
+	if r.emailTaken(user.Email, user.ID) || r.handleTaken(user.Handle, user.ID) {
+		return repository.ErrDuplicate
+	}
+
+	stored := *user
+	stored.Role = ""
+	r.store.users[user.ID] = stored
+	return nil
+}
+
+func (r *UserRepository) Get(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, user := range r.store.users {
+		if strings.EqualFold(user.Email, email) {
+			return &user, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *UserRepository) GetByHandle(ctx context.Context, handle string) (*domain.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, user := range r.store.users {
+		if user.Handle != "" && user.Handle == strings.ToLower(handle) {
+			return &user, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[user.ID]; !ok {
+		return repository.ErrNotFound
+	}
+	if r.emailTaken(user.Email, user.ID) || r.handleTaken(user.Handle, user.ID) {
+		return repository.ErrDuplicate
+	}
+
+	stored := *user
+	stored.Role = ""
+	r.store.users[user.ID] = stored
+	return nil
+}
+
+func (r *UserRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[id]; !ok {
+		return repository.ErrNotFound
+	}
+	for _, task := range r.store.tasks {
+		if task.Assignee == id {
+			return repository.ErrReferenced
+		}
+	}
+	for _, project := range r.store.projects {
+		if project.ManagerID == id {
+			return repository.ErrReferenced
+		}
+	}
+	r.store.deleteSessions(id)
+	r.store.deleteMemberships(id)
+	r.store.deleteAPITokens(id)
+	r.store.deleteOrganizationMemberships(id)
+	r.store.detachCommentUser(id)
+	r.store.detachUploader(id)
+	r.store.deleteNotifications(id)
+	r.store.detachWorklogUser(id)
+	delete(r.store.users, id)
+	return nil
+}
+
+func (r *UserRepository) DeleteReassigning(ctx context.Context, id, assignee uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[id]; !ok {
+		return repository.ErrNotFound
+	}
+	if _, ok := r.store.users[assignee]; assignee != uuid.Nil && !ok {
+		return repository.ErrReferenced
+	}
+
+	for taskID, task := range r.store.tasks {
+		if task.Assignee != id {
+			continue
+		}
+		if assignee != uuid.Nil && task.CompletedAt.IsZero() {
+			r.store.joinProject(task.ProjectID, assignee)
+		}
+		task.Assignee = uuid.Nil
+		if task.CompletedAt.IsZero() {
+			task.Assignee = assignee
+		}
+		r.store.tasks[taskID] = task
+	}
+	for projectID, project := range r.store.projects {
+		if project.ManagerID == id {
+			project.ManagerID = uuid.Nil
+			r.store.projects[projectID] = project
+		}
+	}
+	r.store.deleteSessions(id)
+	r.store.deleteMemberships(id)
+	r.store.deleteAPITokens(id)
+	r.store.deleteOrganizationMemberships(id)
+	r.store.detachCommentUser(id)
+	r.store.detachUploader(id)
+	r.store.deleteNotifications(id)
+	r.store.detachWorklogUser(id)
+	delete(r.store.users, id)
+	return nil
+}
+
+// emailTaken mirrors the case-insensitive unique index on users.email.
+func (r *UserRepository) emailTaken(email string, except uuid.UUID) bool {
+	for id, user := range r.store.users {
+		if id != except && strings.EqualFold(user.Email, email) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleTaken mirrors the unique index on users.handle, which leaves out
+// users without a handle.
+func (r *UserRepository) handleTaken(handle string, except uuid.UUID) bool {
+	for id, user := range r.store.users {
+		if id != except && handle != "" && user.Handle == handle {
+			return true
+		}
+	}
+	return false
+}
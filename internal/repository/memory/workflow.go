@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+type WorkflowRepository struct {
+	store *Store
+}
+
+func NewWorkflowRepository(store *Store) *WorkflowRepository {
+	return &WorkflowRepository{store: store}
+}
+
+func (r *WorkflowRepository) Get(ctx context.Context, projectID uuid.UUID) (*domain.Workflow, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	workflow, ok := r.store.workflows[projectID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return cloneWorkflow(workflow), nil
+}
+
+func (r *WorkflowRepository) Save(ctx context.Context, workflow *domain.Workflow) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.workflows[workflow.ProjectID] = *cloneWorkflow(*workflow)
+	return nil
+}
+
+// cloneWorkflow copies the slices so callers cannot mutate stored state.
+func cloneWorkflow(workflow domain.Workflow) *domain.Workflow {
+	workflow.States = append([]domain.WorkflowState(nil), workflow.States...)
+	workflow.Transitions = append([]domain.Transition(nil), workflow.Transitions...)
+	return &workflow
+}
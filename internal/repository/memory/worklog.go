@@ -0,0 +1,191 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+type WorklogRepository struct {
+	store *Store
+}
+
+func NewWorklogRepository(store *Store) *WorklogRepository {
+	return &WorklogRepository{store: store}
+}
+
+func (r *WorklogRepository) List(ctx context.Context, query repository.WorklogQuery) ([]domain.Worklog, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	worklogs := []domain.Worklog{}
+	for _, worklog := range r.store.worklogs {
+		if r.store.matchWorklog(&worklog, &query) {
+			worklogs = append(worklogs, worklog)
+		}
+	}
+	sort.Slice(worklogs, func(i, j int) bool {
+		if !worklogs[i].StartedAt.Equal(worklogs[j].StartedAt) {
+			return worklogs[i].StartedAt.Before(worklogs[j].StartedAt)
+		}
+		return worklogs[i].ID.String() < worklogs[j].ID.String()
+	})
+	return worklogs, nil
+}
+
+// matchWorklog applies the filters of query to a worklog. The caller holds
+// a lock.
+func (s *Store) matchWorklog(worklog *domain.Worklog, query *repository.WorklogQuery) bool {
+	task := s.tasks[worklog.TaskID]
+	switch {
+	case query.OrganizationID != uuid.Nil && task.OrganizationID != query.OrganizationID:
+		return false
+	case query.ProjectID != uuid.Nil && task.ProjectID != query.ProjectID:
+		return false
+	case query.TaskID != uuid.Nil && worklog.TaskID != query.TaskID:
+		return false
+	case query.UserID != uuid.Nil && worklog.UserID != query.UserID:
+		return false
+	case !query.StartedAfter.IsZero() && worklog.StartedAt.Before(query.StartedAfter):
+		return false
+	case !query.StartedBefore.IsZero() && !worklog.StartedAt.Before(query.StartedBefore):
+		return false
+	case query.Finished && worklog.Running():
+		return false
+	}
+	return true
+}
+
+func (r *WorklogRepository) Get(ctx context.Context, id uuid.UUID) (*domain.Worklog, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	worklog, ok := r.store.worklogs[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &worklog, nil
+}
+
+func (r *WorklogRepository) Running(ctx context.Context, userID uuid.UUID) (*domain.Worklog, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	if worklog := r.store.runningTimer(userID); worklog != nil {
+		return worklog, nil
+	}
+	return nil, repository.ErrNotFound
+}
+
+// runningTimer returns the timer a user runs, or nil. The caller holds a
+// lock.
+func (s *Store) runningTimer(userID uuid.UUID) *domain.Worklog {
+	if userID == uuid.Nil {
+		return nil
+	}
+	for _, worklog := range s.worklogs {
+		if worklog.UserID == userID && worklog.Running() {
+			return &worklog
+		}
+	}
+	return nil
+}
+
+func (r *WorklogRepository) Create(ctx context.Context, worklog *domain.Worklog,
+	logWork func(task *domain.Task, seconds int64)) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.worklogs[worklog.ID]; ok {
+		return repository.ErrDuplicate
+	}
+	if _, ok := r.store.tasks[worklog.TaskID]; !ok {
+		return repository.ErrReferenced
+	}
+	if _, ok := r.store.users[worklog.UserID]; !ok && worklog.UserID != uuid.Nil {
+		return repository.ErrReferenced
+	}
+	if worklog.Running() && r.store.runningTimer(worklog.UserID) != nil {
+		return repository.ErrDuplicate
+	}
+
+	r.store.worklogs[worklog.ID] = *worklog
+	r.store.logWork(worklog.TaskID, worklog.Seconds, logWork)
+	return nil
+}
+
+func (r *WorklogRepository) Update(ctx context.Context, worklog *domain.Worklog,
+	logWork func(task *domain.Task, seconds int64)) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	current, ok := r.store.worklogs[worklog.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if worklog.Running() {
+		if running := r.store.runningTimer(current.UserID); running != nil && running.ID != current.ID {
+			return repository.ErrDuplicate
+		}
+	}
+
+	r.store.logWork(current.TaskID, worklog.Seconds-current.Seconds, logWork)
+	current.StartedAt = worklog.StartedAt
+	current.EndedAt = worklog.EndedAt
+	current.Seconds = worklog.Seconds
+	current.Note = worklog.Note
+	r.store.worklogs[worklog.ID] = current
+	return nil
+}
+
+func (r *WorklogRepository) Delete(ctx context.Context, id uuid.UUID,
+	logWork func(task *domain.Task, seconds int64)) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	worklog, ok := r.store.worklogs[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	r.store.logWork(worklog.TaskID, -worklog.Seconds, logWork)
+	delete(r.store.worklogs, id)
+	return nil
+}
+
+// logWork calls logWork with a task and the change in the seconds logged
+// on it, keeping the remaining estimate it leaves. The caller holds the
+// write lock.
+func (s *Store) logWork(taskID uuid.UUID, seconds int64, logWork func(task *domain.Task, seconds int64)) {
+	task, ok := s.tasks[taskID]
+	if !ok || seconds == 0 {
+		return
+	}
+	changed := task
+	logWork(&changed, seconds)
+	task.RemainingEstimate = changed.RemainingEstimate
+	s.tasks[taskID] = task
+}
+
+// deleteWorklogs mirrors ON DELETE CASCADE from tasks to their worklogs.
+// The caller holds the write lock.
+func (s *Store) deleteWorklogs(taskID uuid.UUID) {
+	for id, worklog := range s.worklogs {
+		if worklog.TaskID == taskID {
+			delete(s.worklogs, id)
+		}
+	}
+}
+
+// detachWorklogUser mirrors ON DELETE SET NULL from worklogs to users. The
+// caller holds the write lock.
+func (s *Store) detachWorklogUser(userID uuid.UUID) {
+	for id, worklog := range s.worklogs {
+		if worklog.UserID == userID {
+			worklog.UserID = uuid.Nil
+			s.worklogs[id] = worklog
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 200
+)
+
+// ErrInvalidCursor is returned for cursors that were not produced by a list
+// with the same sort order.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Page is one slice of a list. NextCursor is empty on the last page.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// SortField orders a list by one field.
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// Sorting describes the fields a list of T can be ordered by. Lists are
+// always ordered by ID last, so that every item has a unique position and
+// cursors stay stable while items are added.
+type Sorting[T any] struct {
+	// keys extract the value of each sortable field: an int, a string or a
+	// time.Time.
+	keys map[string]func(*T) any
+	// descending lists the fields that sort in descending order unless
+	// reversed with "-".
+	descending  map[string]bool
+	defaultSort []SortField
+	id          func(*T) uuid.UUID
+}
+
+var TaskSorting = Sorting[domain.Task]{
+	keys: map[string]func(*domain.Task) any{
+		"priority":     func(t *domain.Task) any { return t.Priority.Rank() },
+		"created_at":   func(t *domain.Task) any { return t.CreatedAt },
+		"completed_at": func(t *domain.Task) any { return t.CompletedAt },
+		"due_date":     func(t *domain.Task) any { return t.DueDate },
+		"title":        func(t *domain.Task) any { return t.Title },
+	},
+	descending:  map[string]bool{"priority": true},
+	defaultSort: []SortField{{Field: "created_at"}},
+	id:          func(t *domain.Task) uuid.UUID { return t.ID },
+}
+
+var ProjectSorting = Sorting[domain.Entity]{
+	keys: map[string]func(*domain.Entity) any{
+		"title":      func(p *domain.Entity) any { return p.Title },
+		"start_date": func(p *domain.Entity) any { return p.StartDate },
+		"end_date":   func(p *domain.Entity) any { return p.EndDate },
+	},
+	defaultSort: []SortField{{Field: "start_date"}},
+	id:          func(p *domain.Entity) uuid.UUID { return p.ID },
+}
+
+var UserSorting = Sorting[domain.User]{
+	keys: map[string]func(*domain.User) any{
+		"full_name":    func(u *domain.User) any { return u.FullName },
+		"email":        func(u *domain.User) any { return u.Email },
+		"registration": func(u *domain.User) any { return u.Registration },
+	},
+	defaultSort: []SortField{{Field: "registration"}},
+	id:          func(u *domain.User) uuid.UUID { return u.ID },
+}
+
+// Parse reads a comma-separated list of field names, each optionally
+// prefixed with "-" to reverse its order.
+func (s Sorting[T]) Parse(str string) ([]SortField, error) {
+	if str == "" {
+		return nil, nil
+	}
+
+	var sort []SortField
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(str, ",") {
+		part = strings.TrimSpace(part)
+		name := strings.TrimPrefix(part, "-")
+		if _, ok := s.keys[name]; !ok {
+			return nil, fmt.Errorf("cannot sort by %q", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("sort field %q is given twice", name)
+		}
+		seen[name] = true
+		sort = append(sort, SortField{Field: name, Desc: s.descending[name] != strings.HasPrefix(part, "-")})
+	}
+	return sort, nil
+}
+
+// OrDefault returns sort, or the default order when it is empty.
+func (s Sorting[T]) OrDefault(sort []SortField) []SortField {
+	if len(sort) == 0 {
+		return s.defaultSort
+	}
+	return sort
+}
+
+// Key returns the values item is ordered by, followed by its ID.
+func (s Sorting[T]) Key(item *T, sort []SortField) []any {
+	key := make([]any, 0, len(sort)+1)
+	for _, field := range sort {
+		key = append(key, s.keys[field.Field](item))
+	}
+	return append(key, s.id(item))
+}
+
+// Compare orders two items the way a list sorted by sort would.
+func (s Sorting[T]) Compare(a, b *T, sort []SortField) int {
+	return compareKeys(s.Key(a, sort), s.Key(b, sort), sort)
+}
+
+// CompareKey compares an item with a key returned by DecodeCursor.
+func (s Sorting[T]) CompareKey(item *T, sort []SortField, key []any) int {
+	return compareKeys(s.Key(item, sort), key, sort)
+}
+
+// Cursor encodes the position just after item.
+func (s Sorting[T]) Cursor(item *T, sort []SortField) string {
+	key := s.Key(item, sort)
+	values := make([]string, len(key))
+	for i, v := range key {
+		switch v := v.(type) {
+		case int:
+			values[i] = strconv.Itoa(v)
+		case time.Time:
+			values[i] = v.UTC().Format(time.RFC3339Nano)
+		case uuid.UUID:
+			values[i] = v.String()
+		default:
+			values[i] = fmt.Sprint(v)
+		}
+	}
+
+	data, _ := json.Marshal(cursor{Sort: sortKey(sort), Values: values})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor returns the key stored in a cursor, typed like the values
+// returned by Key.
+func (s Sorting[T]) DecodeCursor(str string, sort []SortField) ([]any, error) {
+	data, err := base64.RawURLEncoding.DecodeString(str)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if c.Sort != sortKey(sort) || len(c.Values) != len(sort)+1 {
+		return nil, ErrInvalidCursor
+	}
+
+	var zero T
+	key := make([]any, len(c.Values))
+	for i, field := range sort {
+		switch s.keys[field.Field](&zero).(type) {
+		case int:
+			key[i], err = strconv.Atoi(c.Values[i])
+		case time.Time:
+			key[i], err = time.Parse(time.RFC3339Nano, c.Values[i])
+		default:
+			key[i] = c.Values[i]
+		}
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+	}
+	if key[len(sort)], err = uuid.Parse(c.Values[len(sort)]); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return key, nil
+}
+
+type cursor struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+}
+
+func sortKey(sort []SortField) string {
+	parts := make([]string, len(sort))
+	for i, field := range sort {
+		parts[i] = field.Field
+		if field.Desc {
+			parts[i] = "-" + field.Field
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func compareKeys(a, b []any, sort []SortField) int {
+	for i := range a {
+		c := compareValues(a[i], b[i])
+		if i < len(sort) && sort[i].Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func compareValues(a, b any) int {
+	switch a := a.(type) {
+	case int:
+		return a - b.(int)
+	case string:
+		return strings.Compare(a, b.(string))
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	case uuid.UUID:
+		return strings.Compare(a.String(), b.(uuid.UUID).String())
+	}
+	return 0
+}
+
+// Limit clamps a requested page size to [1, MaxLimit], using DefaultLimit
+// when none was requested.
+func Limit(limit int) int {
+	switch {
+	case limit <= 0:
+		return DefaultLimit
+	case limit > MaxLimit:
+		return MaxLimit
+	default:
+		return limit
+	}
+}
+
+// TaskQuery selects a page of tasks. Zero-valued filters are ignored; time
+// windows include their After bound and exclude their Before bound.
+type TaskQuery struct {
+	OrganizationID uuid.UUID
+	States         []string
+	Priorities     []domain.Priority
+	Assignee       uuid.UUID
+	ProjectID      uuid.UUID
+	ParentID       uuid.UUID
+	SprintID       uuid.UUID
+	MilestoneID    uuid.UUID
+	// Backlog keeps only the tasks that are in no sprint.
+	Backlog         bool
+	CreatedAfter    time.Time
+	CreatedBefore   time.Time
+	CompletedAfter  time.Time
+	CompletedBefore time.Time
+	// DueAfter and DueBefore only match tasks that have a due date.
+	DueAfter  time.Time
+	DueBefore time.Time
+	// Open keeps only the tasks that are not completed.
+	Open bool
+	// OverdueAt keeps the open tasks that were due before it.
+	OverdueAt time.Time
+	// Unreminded keeps the tasks that have no notification of this kind
+	// about their current due date.
+	Unreminded domain.NotificationKind
+	// Labels keeps the tasks that have any of the named labels, or all of
+	// them with AllLabels. Names match in any letter case.
+	Labels    []string
+	AllLabels bool
+
+	Sort   []SortField
+	Limit  int
+	Cursor string
+}
+
+// WorklogQuery selects worklogs. Zero-valued filters are ignored;
+// StartedAfter is inclusive and StartedBefore exclusive.
+type WorklogQuery struct {
+	// OrganizationID and ProjectID filter on the worklogs' tasks.
+	OrganizationID uuid.UUID
+	ProjectID      uuid.UUID
+	TaskID         uuid.UUID
+	UserID         uuid.UUID
+	StartedAfter   time.Time
+	StartedBefore  time.Time
+	// Finished leaves out running timers.
+	Finished bool
+}
+
+// ProjectQuery selects a page of projects.
+type ProjectQuery struct {
+	OrganizationID uuid.UUID
+	ManagerID      uuid.UUID
+	MemberID       uuid.UUID
+	StartsAfter    time.Time
+	StartsBefore   time.Time
+	EndsAfter      time.Time
+	EndsBefore     time.Time
+
+	Sort   []SortField
+	Limit  int
+	Cursor string
+}
+
+// UserQuery selects a page of users. With an OrganizationID, only its
+// members are listed, with their role in it; Role filters on that role.
+type UserQuery struct {
+	OrganizationID uuid.UUID
+	Role           domain.Role
+
+	Sort   []SortField
+	Limit  int
+	Cursor string
+}
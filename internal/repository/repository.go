@@ -0,0 +1,347 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+)
+
+var (
+	// ErrNotFound is returned when the requested record does not exist.
+	ErrNotFound = errors.New("record not found")
+	// ErrDuplicate is returned when a record violates a uniqueness constraint.
+	ErrDuplicate = errors.New("record already exists")
+	// ErrReferenced is returned when deleting a record that other records
+	// still refer to.
+	ErrReferenced = errors.New("record is still referenced")
+	// ErrLimitReached is returned when a write would take more records past
+	// a limit.
+	ErrLimitReached = errors.New("limit reached")
+)
+
+// UserRepository stores users. A user's role is kept on its organization
+// memberships, so only GetAll with an organization fills in User.Role.
+type UserRepository interface {
+	GetAll(ctx context.Context, query UserQuery) (*Page[domain.User], error)
+	Get(ctx context.Context, id uuid.UUID) (*domain.User, error)
+	// GetByEmail matches the email case-insensitively.
+	GetByEmail(ctx context.Context, email string) (*domain.User, error)
+	GetByHandle(ctx context.Context, handle string) (*domain.User, error)
+	Create(ctx context.Context, user *domain.User) error
+	// CreateWithMembership stores a new user together with its first
+	// organization membership, in one transaction.
+	CreateWithMembership(ctx context.Context, user *domain.User, member *domain.OrganizationMember) error
+	Update(ctx context.Context, user *domain.User) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// DeleteReassigning deletes the user in one transaction, handing its open
+	// tasks to assignee, or leaving them unassigned when assignee is uuid.Nil.
+	// Its completed tasks are unassigned and the projects it managed lose
+	// their manager. The new assignee joins the projects of the tasks it
+	// receives as a contributor, or becomes one if it observes them.
+	DeleteReassigning(ctx context.Context, id, assignee uuid.UUID) error
+}
+
+// TaskRepository stores tasks. Like projects, tasks keep the organization
+// they were created in. Subtasks stay in the project of their parent. Tasks
+// are read with their labels, which are saved along with them.
+type TaskRepository interface {
+	GetAll(ctx context.Context, query TaskQuery) (*Page[domain.Task], error)
+	Get(ctx context.Context, id uuid.UUID) (*domain.Task, error)
+	// Count returns the number of tasks matching the filters of query.
+	Count(ctx context.Context, query TaskQuery) (int, error)
+	// Subtasks returns the tasks below a task, at any depth, oldest first.
+	Subtasks(ctx context.Context, id uuid.UUID) ([]domain.Task, error)
+	// Create returns ErrReferenced when the parent, the sprint, the
+	// milestone or a label does not exist.
+	Create(ctx context.Context, task *domain.Task) error
+	// CreateWithin creates the task like Create, and returns ErrLimitReached,
+	// writing nothing, when it would leave more tasks in the states of one
+	// of limits than it allows.
+	CreateWithin(ctx context.Context, task *domain.Task, limits []WIPLimit) error
+	// Update moves the task's subtasks along when it changes project, in one
+	// transaction. The subtasks lose the labels, sprints, milestones and board
+	// ranks of their old project.
+	Update(ctx context.Context, task *domain.Task) error
+	// Move updates the task like Update and sets the board ranks of other
+	// tasks, in one transaction. It returns ErrNotFound when one of them does
+	// not exist, and ErrLimitReached, writing nothing, when the move would
+	// leave more tasks in the states of one of limits than it allows. Writes
+	// into the same project with limits are counted one after the other.
+	Move(ctx context.Context, task *domain.Task, ranks map[uuid.UUID]string, limits []WIPLimit) error
+	// Delete deletes the task's subtasks with it.
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// WIPLimit caps the tasks of a project in the states of a board column.
+type WIPLimit struct {
+	ProjectID uuid.UUID
+	Column    string
+	States    []string
+	Max       int
+}
+
+// ProjectRepository stores projects. A project's organization is set when it
+// is created; Update leaves it alone.
+type ProjectRepository interface {
+	GetAll(ctx context.Context, query ProjectQuery) (*Page[domain.Entity], error)
+	Get(ctx context.Context, id uuid.UUID) (*domain.Entity, error)
+	Create(ctx context.Context, project *domain.Entity) error
+	Update(ctx context.Context, project *domain.Entity) error
+	// Delete returns ErrReferenced while the project still has tasks.
+	Delete(ctx context.Context, id uuid.UUID) error
+	// DeleteWithTasks deletes the project and all of its tasks in one
+	// transaction.
+	DeleteWithTasks(ctx context.Context, id uuid.UUID) error
+}
+
+// OrganizationRepository stores organizations and who belongs to them.
+// Memberships go away with their user.
+type OrganizationRepository interface {
+	// ListForUser returns the organizations a user belongs to, in the order
+	// it joined them.
+	ListForUser(ctx context.Context, userID uuid.UUID) ([]domain.Organization, error)
+	Get(ctx context.Context, id uuid.UUID) (*domain.Organization, error)
+	// Create stores a new organization together with its first member, in
+	// one transaction.
+	Create(ctx context.Context, organization *domain.Organization, member *domain.OrganizationMember) error
+	Update(ctx context.Context, organization *domain.Organization) error
+	// ListMembers returns the members of an organization in the order they
+	// joined.
+	ListMembers(ctx context.Context, organizationID uuid.UUID) ([]domain.OrganizationMember, error)
+	GetMember(ctx context.Context, organizationID, userID uuid.UUID) (*domain.OrganizationMember, error)
+	// AddMember returns ErrReferenced when the organization or user does not
+	// exist.
+	AddMember(ctx context.Context, member *domain.OrganizationMember) error
+	// UpdateMember changes the role of a member.
+	UpdateMember(ctx context.Context, member *domain.OrganizationMember) error
+	// RemoveMember takes the user out of the organization and off its
+	// projects, in one transaction.
+	RemoveMember(ctx context.Context, organizationID, userID uuid.UUID) error
+}
+
+// APITokenRepository stores personal access tokens. Tokens go away with
+// their user.
+type APITokenRepository interface {
+	// List returns the tokens of a user, newest first.
+	List(ctx context.Context, userID uuid.UUID) ([]domain.APIToken, error)
+	Get(ctx context.Context, id uuid.UUID) (*domain.APIToken, error)
+	GetByHash(ctx context.Context, hash string) (*domain.APIToken, error)
+	Create(ctx context.Context, token *domain.APIToken) error
+	// Touch records that the token was used at the given time.
+	Touch(ctx context.Context, id uuid.UUID, at time.Time) error
+	// Revoke disables a token. A token that is already revoked keeps its
+	// original RevokedAt.
+	Revoke(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+// MemberRepository stores who takes part in which project. Members go away
+// with their project or user.
+type MemberRepository interface {
+	// List returns the members of a project in the order they joined.
+	List(ctx context.Context, projectID uuid.UUID) ([]domain.Member, error)
+	Get(ctx context.Context, projectID, userID uuid.UUID) (*domain.Member, error)
+	// Add returns ErrReferenced when the project or user does not exist.
+	Add(ctx context.Context, member *domain.Member) error
+	// Update changes the role of a member.
+	Update(ctx context.Context, member *domain.Member) error
+	Remove(ctx context.Context, projectID, userID uuid.UUID) error
+}
+
+// CommentRepository stores the discussion on tasks. Comments go away with
+// their task; deleting a user only clears it as author, editor or mention.
+type CommentRepository interface {
+	// List returns the comments on a task, oldest first.
+	List(ctx context.Context, taskID uuid.UUID) ([]domain.Comment, error)
+	Get(ctx context.Context, id uuid.UUID) (*domain.Comment, error)
+	// Create stores a comment and its mentions in one transaction. It
+	// returns ErrReferenced when the task, parent or a mentioned user does
+	// not exist.
+	Create(ctx context.Context, comment *domain.Comment) error
+	// Update saves a new body and its mentions together with revision,
+	// which keeps the body it replaces, in one transaction.
+	Update(ctx context.Context, comment *domain.Comment, revision *domain.CommentRevision) error
+	// Delete empties a comment and drops its mentions and revisions, leaving
+	// it in its thread marked as deleted at the given time.
+	Delete(ctx context.Context, id uuid.UUID, at time.Time) error
+	// ListRevisions returns the earlier versions of a comment, oldest first.
+	ListRevisions(ctx context.Context, commentID uuid.UUID) ([]domain.CommentRevision, error)
+}
+
+// AttachmentRepository stores what describes attached files; their content
+// is kept in blob storage. Attachments go away with their task or project;
+// deleting the uploader only clears UploaderID.
+type AttachmentRepository interface {
+	// List returns the files attached to owner, oldest first.
+	List(ctx context.Context, owner domain.AttachmentOwner) ([]domain.Attachment, error)
+	// ListInProject returns the files attached to a project and to any of
+	// its tasks.
+	ListInProject(ctx context.Context, projectID uuid.UUID) ([]domain.Attachment, error)
+	Get(ctx context.Context, id uuid.UUID) (*domain.Attachment, error)
+	// Create returns ErrReferenced when the task, project or uploader does
+	// not exist.
+	Create(ctx context.Context, attachment *domain.Attachment) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// DependencyRepository stores which tasks block which. Links go away with
+// either of their tasks.
+type DependencyRepository interface {
+	// Blockers returns the links from a task to every task it waits on,
+	// directly or through other tasks, oldest first.
+	Blockers(ctx context.Context, taskID uuid.UUID) ([]domain.Dependency, error)
+	// Dependents returns the links to a task from every task waiting on it,
+	// directly or through other tasks, oldest first.
+	Dependents(ctx context.Context, taskID uuid.UUID) ([]domain.Dependency, error)
+	// Add returns ErrReferenced when either task does not exist. In the same
+	// transaction, it first calls check with the links from the blocker to
+	// every task it waits on, and adds nothing when check fails, returning
+	// its error: links added meanwhile cannot close a cycle that check looks
+	// for.
+	Add(ctx context.Context, dependency *domain.Dependency, check func(blockers []domain.Dependency) error) error
+	Remove(ctx context.Context, taskID, blockerID uuid.UUID) error
+}
+
+// LabelRepository stores the label catalogs of projects. Labels go away
+// with their project, and come off their tasks when deleted.
+type LabelRepository interface {
+	// List returns the labels of a project, ordered by name.
+	List(ctx context.Context, projectID uuid.UUID) ([]domain.Label, error)
+	Get(ctx context.Context, id uuid.UUID) (*domain.Label, error)
+	// Create returns ErrDuplicate when the project already has a label with
+	// the same name in any letter case, and ErrReferenced when the project
+	// does not exist.
+	Create(ctx context.Context, label *domain.Label) error
+	// Update changes the name, color and description of a label. It returns
+	// ErrDuplicate like Create.
+	Update(ctx context.Context, label *domain.Label) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// Merge puts the target label on every task that has the source label
+	// and deletes the source, in one transaction.
+	Merge(ctx context.Context, sourceID, targetID uuid.UUID) error
+}
+
+// SprintRepository stores the sprints of projects. Sprints go away with
+// their project; deleting one moves its tasks to the backlog.
+type SprintRepository interface {
+	// List returns the sprints of a project, ordered by start date.
+	List(ctx context.Context, projectID uuid.UUID) ([]domain.Sprint, error)
+	Get(ctx context.Context, id uuid.UUID) (*domain.Sprint, error)
+	// Create returns ErrDuplicate when the sprint is active and its project
+	// already runs another one, and ErrReferenced when the project or the
+	// sprint it rolled over to does not exist.
+	Create(ctx context.Context, sprint *domain.Sprint) error
+	// Update changes everything but the project of a sprint. It returns
+	// ErrDuplicate and ErrReferenced like Create.
+	Update(ctx context.Context, sprint *domain.Sprint) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// Close sets the completed and unfinished work of the sprint to what
+	// tally makes of the tasks in it, oldest first and without their
+	// labels, updates it like Update and moves its open tasks to the sprint
+	// it rolls over to, or to the backlog, in one transaction. It returns
+	// ErrReferenced when that sprint does not exist.
+	Close(ctx context.Context, sprint *domain.Sprint,
+		tally func(tasks []domain.Task) (completed, unfinished domain.SprintWork)) error
+}
+
+// MilestoneRepository stores the milestones of projects. Milestones go away
+// with their project; deleting one unlinks its tasks.
+type MilestoneRepository interface {
+	// List returns the milestones of a project, ordered by target date.
+	List(ctx context.Context, projectID uuid.UUID) ([]domain.Milestone, error)
+	// Upcoming returns the milestones with a target date at or after from,
+	// and before before unless it is zero, of the projects of an
+	// organization that a user is a member of, ordered by target date.
+	Upcoming(ctx context.Context, organizationID, userID uuid.UUID, from, before time.Time) ([]domain.Milestone, error)
+	Get(ctx context.Context, id uuid.UUID) (*domain.Milestone, error)
+	// Create returns ErrReferenced when the project does not exist.
+	Create(ctx context.Context, milestone *domain.Milestone) error
+	// Update changes the title, description and target date of a milestone.
+	Update(ctx context.Context, milestone *domain.Milestone) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// CountTasks counts the tasks in each of the milestones ids, in one
+	// query. Milestones without tasks are left out.
+	CountTasks(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]TaskCount, error)
+}
+
+// TaskCount is the number of tasks in a group, and of those still open.
+type TaskCount struct {
+	Total int
+	Open  int
+}
+
+// WorklogRepository stores the time logged on tasks. Worklogs go away with
+// their task; deleting a user only clears it from its worklogs. Creating,
+// updating and deleting worklogs calls logWork in the same transaction with
+// their task, without its labels, and the change in the seconds logged on
+// it, unless there is none, and saves the remaining estimate logWork leaves
+// the task with.
+type WorklogRepository interface {
+	// List returns the worklogs matching query, ordered by when they
+	// started.
+	List(ctx context.Context, query WorklogQuery) ([]domain.Worklog, error)
+	Get(ctx context.Context, id uuid.UUID) (*domain.Worklog, error)
+	// Running returns the timer a user is running, or ErrNotFound.
+	Running(ctx context.Context, userID uuid.UUID) (*domain.Worklog, error)
+	// Create returns ErrDuplicate when the worklog is a running timer and
+	// its user already runs one, and ErrReferenced when the task or user
+	// does not exist.
+	Create(ctx context.Context, worklog *domain.Worklog, logWork func(task *domain.Task, seconds int64)) error
+	// Update changes the times, duration and note of a worklog. It returns
+	// ErrDuplicate like Create.
+	Update(ctx context.Context, worklog *domain.Worklog, logWork func(task *domain.Task, seconds int64)) error
+	Delete(ctx context.Context, id uuid.UUID, logWork func(task *domain.Task, seconds int64)) error
+}
+
+// NotificationRepository stores what users are told about their tasks.
+// Notifications go away with their user or task.
+type NotificationRepository interface {
+	// List returns the notifications of a user, newest first; with unread
+	// set, only those that were not read yet. With an organizationID, only
+	// the notifications about its tasks are listed.
+	List(ctx context.Context, organizationID, userID uuid.UUID, unread bool) ([]domain.Notification, error)
+	Get(ctx context.Context, id uuid.UUID) (*domain.Notification, error)
+	// Create returns ErrDuplicate when the user already has a notification
+	// of the same kind for the same task and due date, and ErrReferenced
+	// when the user or task does not exist.
+	Create(ctx context.Context, notification *domain.Notification) error
+	// MarkRead records when a notification was read. A notification that is
+	// already read keeps its original ReadAt.
+	MarkRead(ctx context.Context, id uuid.UUID, at time.Time) error
+	// MarkAllRead marks every unread notification of a user as read, or
+	// only those about the tasks of organizationID unless it is uuid.Nil.
+	MarkAllRead(ctx context.Context, organizationID, userID uuid.UUID, at time.Time) error
+}
+
+// WorkflowRepository stores per-project workflows. Get returns ErrNotFound
+// for projects that use the default workflow.
+type WorkflowRepository interface {
+	Get(ctx context.Context, projectID uuid.UUID) (*domain.Workflow, error)
+	Save(ctx context.Context, workflow *domain.Workflow) error
+}
+
+// BoardRepository stores per-project boards. Get returns ErrNotFound for
+// projects that use the default board.
+type BoardRepository interface {
+	Get(ctx context.Context, projectID uuid.UUID) (*domain.Board, error)
+	Save(ctx context.Context, board *domain.Board) error
+}
+
+// SessionRepository stores login sessions and their refresh tokens.
+type SessionRepository interface {
+	// Create stores a new session together with its first refresh token.
+	Create(ctx context.Context, session *domain.Session, token *domain.RefreshToken) error
+	Get(ctx context.Context, id uuid.UUID) (*domain.Session, error)
+	GetRefreshToken(ctx context.Context, hash string) (*domain.RefreshToken, error)
+	// Rotate marks the refresh token with the given hash as used and stores
+	// next in its place, in one transaction. It returns ErrNotFound if the
+	// token does not exist or has been used already.
+	Rotate(ctx context.Context, hash string, usedAt time.Time, next *domain.RefreshToken) error
+	// Revoke ends a session. A session that is already revoked keeps its
+	// original RevokedAt.
+	Revoke(ctx context.Context, id uuid.UUID, at time.Time) error
+	// RevokeUser ends every active session of a user.
+	RevokeUser(ctx context.Context, userID uuid.UUID, at time.Time) error
+}
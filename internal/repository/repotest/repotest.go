@@ -0,0 +1,2556 @@
+// Package repotest is a conformance suite for repository implementations.
+// Every storage backend is expected to pass it, which keeps Postgres, SQLite
+// and the in-memory store behaving the same way:
+//
+//	func TestConformance(t *testing.T) {
+//		repotest.Run(t, func(t *testing.T) repotest.Repositories {
+//			store := memory.NewStore()
+//			return repotest.Repositories{Users: memory.NewUserRepository(store), ...}
+//		})
+//	}
+package repotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+// Repositories is one backend's set of repositories, all sharing a single
+// empty store.
+type Repositories struct {
+	Users         repository.UserRepository
+	Tasks         repository.TaskRepository
+	Projects      repository.ProjectRepository
+	Workflows     repository.WorkflowRepository
+	Boards        repository.BoardRepository
+	Sessions      repository.SessionRepository
+	Members       repository.MemberRepository
+	APITokens     repository.APITokenRepository
+	Organizations repository.OrganizationRepository
+	Comments      repository.CommentRepository
+	Attachments   repository.AttachmentRepository
+	Dependencies  repository.DependencyRepository
+	Labels        repository.LabelRepository
+	Notifications repository.NotificationRepository
+	Worklogs      repository.WorklogRepository
+	Sprints       repository.SprintRepository
+	Milestones    repository.MilestoneRepository
+}
+
+// Run executes the suite. open must return repositories backed by a fresh,
+// empty store each time it is called.
+func Run(t *testing.T, open func(t *testing.T) Repositories) {
+	// Projects and tasks belong to an organization, which seeded stores
+	// provide.
+	seeded := func(t *testing.T) Repositories {
+		repos := open(t)
+		seedOrganization(t, repos)
+		return repos
+	}
+
+	t.Run("Users", func(t *testing.T) { testUsers(t, open(t)) })
+	t.Run("Projects", func(t *testing.T) { testProjects(t, seeded(t)) })
+	t.Run("Tasks", func(t *testing.T) { testTasks(t, seeded(t)) })
+	t.Run("Subtasks", func(t *testing.T) { testSubtasks(t, seeded(t)) })
+	t.Run("TaskQueries", func(t *testing.T) { testTaskQueries(t, seeded(t)) })
+	t.Run("Pagination", func(t *testing.T) { testPagination(t, seeded(t)) })
+	t.Run("ProjectQueries", func(t *testing.T) { testProjectQueries(t, seeded(t)) })
+	t.Run("UserQueries", func(t *testing.T) { testUserQueries(t, open(t)) })
+	t.Run("Workflows", func(t *testing.T) { testWorkflows(t, seeded(t)) })
+	t.Run("Boards", func(t *testing.T) { testBoards(t, seeded(t)) })
+	t.Run("ProjectDeletes", func(t *testing.T) { testProjectDeletes(t, seeded(t)) })
+	t.Run("UserDeletes", func(t *testing.T) { testUserDeletes(t, seeded(t)) })
+	t.Run("Sessions", func(t *testing.T) { testSessions(t, open(t)) })
+	t.Run("Members", func(t *testing.T) { testMembers(t, seeded(t)) })
+	t.Run("APITokens", func(t *testing.T) { testAPITokens(t, open(t)) })
+	t.Run("Organizations", func(t *testing.T) { testOrganizations(t, open(t)) })
+	t.Run("Isolation", func(t *testing.T) { testIsolation(t, open(t)) })
+	t.Run("Comments", func(t *testing.T) { testComments(t, seeded(t)) })
+	t.Run("Attachments", func(t *testing.T) { testAttachments(t, seeded(t)) })
+	t.Run("Dependencies", func(t *testing.T) { testDependencies(t, seeded(t)) })
+	t.Run("Labels", func(t *testing.T) { testLabels(t, seeded(t)) })
+	t.Run("Notifications", func(t *testing.T) { testNotifications(t, seeded(t)) })
+	t.Run("Worklogs", func(t *testing.T) { testWorklogs(t, seeded(t)) })
+	t.Run("Sprints", func(t *testing.T) { testSprints(t, seeded(t)) })
+	t.Run("Milestones", func(t *testing.T) { testMilestones(t, seeded(t)) })
+}
+
+// testOrganization is the organization of seeded stores, where newProject
+// and newTask put their records.
+var testOrganization = uuid.MustParse("0f0f0f0f-0000-4000-8000-000000000001")
+
+func seedOrganization(t *testing.T, repos Repositories) {
+	t.Helper()
+	ctx := context.Background()
+	founder := newUser("founder@example.com")
+	must(t, repos.Users.Create(ctx, founder))
+	must(t, repos.Organizations.Create(ctx, newOrganization(testOrganization), &domain.OrganizationMember{
+		OrganizationID: testOrganization,
+		UserID:         founder.ID,
+		Role:           domain.RoleAdmin,
+		JoinedAt:       now(),
+	}))
+}
+
+func newOrganization(id uuid.UUID) *domain.Organization {
+	return &domain.Organization{ID: id, Name: "Test Organization", CreatedAt: now()}
+}
+
+// now is truncated to microseconds, the precision Postgres keeps.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+func newUser(email string) *domain.User {
+	return &domain.User{
+		ID:           uuid.New(),
+		FullName:     "Test User",
+		Email:        email,
+		Registration: now(),
+	}
+}
+
+func newProject(managerID uuid.UUID) *domain.Entity {
+	return &domain.Entity{
+		ID:             uuid.New(),
+		Title:          "Test Project",
+		Description:    "A project",
+		StartDate:      now(),
+		EndDate:        now().Add(30 * 24 * time.Hour),
+		ManagerID:      managerID,
+		OrganizationID: testOrganization,
+	}
+}
+
+func newTask(projectID, assignee uuid.UUID) *domain.Task {
+	return &domain.Task{
+		ID:             uuid.New(),
+		Title:          "Test Task",
+		Description:    "A task",
+		Priority:       domain.PriorityHigh,
+		State:          "todo",
+		Assignee:       assignee,
+		ProjectID:      projectID,
+		OrganizationID: testOrganization,
+		CreatedAt:      now(),
+	}
+}
+
+func expectErr(t *testing.T, err, want error) {
+	t.Helper()
+	if !errors.Is(err, want) {
+		t.Fatalf("expected %v, got %v", want, err)
+	}
+}
+
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func testUsers(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	users := repos.Users
+
+	user := newUser("ada@example.com")
+	user.Handle = "ada"
+	must(t, users.Create(ctx, user))
+
+	got, err := users.Get(ctx, user.ID)
+	must(t, err)
+	if got.ID != user.ID || got.FullName != user.FullName || got.Email != user.Email ||
+		got.Handle != user.Handle || got.Role != user.Role || !got.Registration.Equal(user.Registration) {
+		t.Fatalf("user did not round-trip: got %+v, want %+v", got, user)
+	}
+
+	expectErr(t, users.Create(ctx, user), repository.ErrDuplicate)
+	expectErr(t, users.Create(ctx, newUser("ADA@example.com")), repository.ErrDuplicate)
+
+	byEmail, err := users.GetByEmail(ctx, "ADA@Example.com")
+	must(t, err)
+	if byEmail.ID != user.ID {
+		t.Fatalf("GetByEmail found %s, want %s", byEmail.ID, user.ID)
+	}
+	_, err = users.GetByEmail(ctx, "nobody@example.com")
+	expectErr(t, err, repository.ErrNotFound)
+
+	byHandle, err := users.GetByHandle(ctx, "ADA")
+	must(t, err)
+	if byHandle.ID != user.ID {
+		t.Fatalf("GetByHandle found %s, want %s", byHandle.ID, user.ID)
+	}
+	_, err = users.GetByHandle(ctx, "nobody")
+	expectErr(t, err, repository.ErrNotFound)
+	duplicate := newUser("lovelace@example.com")
+	duplicate.Handle = "ada"
+	expectErr(t, users.Create(ctx, duplicate), repository.ErrDuplicate)
+
+	// Users without a handle are not found by the empty one.
+	other := newUser("grace@example.com")
+	must(t, users.Create(ctx, other))
+	_, err = users.GetByHandle(ctx, "")
+	expectErr(t, err, repository.ErrNotFound)
+
+	other.Email = "Ada@Example.com"
+	expectErr(t, users.Update(ctx, other), repository.ErrDuplicate)
+	other.Email = "grace@example.com"
+	other.Handle = "ada"
+	expectErr(t, users.Update(ctx, other), repository.ErrDuplicate)
+
+	other.Handle = "grace"
+	other.FullName = "Grace Hopper"
+	other.PasswordHash = "hash"
+	must(t, users.Update(ctx, other))
+	got, err = users.Get(ctx, other.ID)
+	must(t, err)
+	if got.FullName != "Grace Hopper" || got.Handle != "grace" || got.PasswordHash != "hash" {
+		t.Fatalf("update was not persisted: %+v", got)
+	}
+
+	all, err := users.GetAll(ctx, repository.UserQuery{})
+	must(t, err)
+	if len(all.Items) != 2 {
+		t.Fatalf("expected 2 users, got %d", len(all.Items))
+	}
+
+	missing := uuid.New()
+	_, err = users.Get(ctx, missing)
+	expectErr(t, err, repository.ErrNotFound)
+	expectErr(t, users.Update(ctx, &domain.User{ID: missing, Email: "nobody@example.com"}), repository.ErrNotFound)
+	expectErr(t, users.Delete(ctx, missing), repository.ErrNotFound)
+
+	must(t, users.Delete(ctx, other.ID))
+	_, err = users.Get(ctx, other.ID)
+	expectErr(t, err, repository.ErrNotFound)
+}
+
+func testProjects(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	manager := newUser("manager@example.com")
+	must(t, repos.Users.Create(ctx, manager))
+
+	project := newProject(manager.ID)
+	must(t, repos.Projects.Create(ctx, project))
+	expectErr(t, repos.Projects.Create(ctx, project), repository.ErrDuplicate)
+
+	got, err := repos.Projects.Get(ctx, project.ID)
+	must(t, err)
+	if got.Title != project.Title || got.Description != project.Description || got.ManagerID != manager.ID ||
+		!got.StartDate.Equal(project.StartDate) || !got.EndDate.Equal(project.EndDate) {
+		t.Fatalf("project did not round-trip: got %+v, want %+v", got, project)
+	}
+
+	unmanaged := newProject(uuid.Nil)
+	must(t, repos.Projects.Create(ctx, unmanaged))
+	got, err = repos.Projects.Get(ctx, unmanaged.ID)
+	must(t, err)
+	if got.ManagerID != uuid.Nil {
+		t.Fatalf("expected no manager, got %s", got.ManagerID)
+	}
+
+	project.Title = "Renamed"
+	must(t, repos.Projects.Update(ctx, project))
+	got, err = repos.Projects.Get(ctx, project.ID)
+	must(t, err)
+	if got.Title != "Renamed" {
+		t.Fatalf("update was not persisted: %+v", got)
+	}
+
+	all, err := repos.Projects.GetAll(ctx, repository.ProjectQuery{})
+	must(t, err)
+	if len(all.Items) != 2 {
+		t.Fatalf("expected 2 projects, got %d", len(all.Items))
+	}
+
+	missing := uuid.New()
+	_, err = repos.Projects.Get(ctx, missing)
+	expectErr(t, err, repository.ErrNotFound)
+	expectErr(t, repos.Projects.Update(ctx, newProject(uuid.Nil)), repository.ErrNotFound)
+	expectErr(t, repos.Projects.Delete(ctx, missing), repository.ErrNotFound)
+
+	must(t, repos.Projects.Delete(ctx, unmanaged.ID))
+	_, err = repos.Projects.Get(ctx, unmanaged.ID)
+	expectErr(t, err, repository.ErrNotFound)
+}
+
+func testTasks(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	assignee := newUser("assignee@example.com")
+	must(t, repos.Users.Create(ctx, assignee))
+	project := newProject(uuid.Nil)
+	must(t, repos.Projects.Create(ctx, project))
+
+	task := newTask(project.ID, assignee.ID)
+	task.OriginalEstimate = 8 * 3600
+	task.RemainingEstimate = 5 * 3600
+	must(t, repos.Tasks.Create(ctx, task))
+	expectErr(t, repos.Tasks.Create(ctx, task), repository.ErrDuplicate)
+
+	got, err := repos.Tasks.Get(ctx, task.ID)
+	must(t, err)
+	if got.Title != task.Title || got.Priority != task.Priority || got.State != task.State ||
+		got.Assignee != assignee.ID || got.ProjectID != project.ID ||
+		got.OriginalEstimate != task.OriginalEstimate || got.RemainingEstimate != task.RemainingEstimate ||
+		!got.CreatedAt.Equal(task.CreatedAt) || !got.CompletedAt.IsZero() {
+		t.Fatalf("task did not round-trip: got %+v, want %+v", got, task)
+	}
+
+	unassigned := newTask(project.ID, uuid.Nil)
+	must(t, repos.Tasks.Create(ctx, unassigned))
+
+	task.State = "done"
+	task.CompletedAt = now()
+	task.RemainingEstimate = 0
+	must(t, repos.Tasks.Update(ctx, task))
+	got, err = repos.Tasks.Get(ctx, task.ID)
+	must(t, err)
+	if got.State != "done" || !got.CompletedAt.Equal(task.CompletedAt) || got.RemainingEstimate != 0 {
+		t.Fatalf("update was not persisted: %+v", got)
+	}
+
+	all, err := repos.Tasks.GetAll(ctx, repository.TaskQuery{})
+	must(t, err)
+	if len(all.Items) != 2 {
+		t.Fatalf("expected 2 tasks, got %d", len(all.Items))
+	}
+
+	missing := uuid.New()
+	_, err = repos.Tasks.Get(ctx, missing)
+	expectErr(t, err, repository.ErrNotFound)
+	expectErr(t, repos.Tasks.Update(ctx, newTask(project.ID, uuid.Nil)), repository.ErrNotFound)
+	expectErr(t, repos.Tasks.Delete(ctx, missing), repository.ErrNotFound)
+
+	must(t, repos.Tasks.Delete(ctx, unassigned.ID))
+	_, err = repos.Tasks.Get(ctx, unassigned.ID)
+	expectErr(t, err, repository.ErrNotFound)
+}
+
+func testWorkflows(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	project := newProject(uuid.Nil)
+	must(t, repos.Projects.Create(ctx, project))
+
+	_, err := repos.Workflows.Get(ctx, project.ID)
+	expectErr(t, err, repository.ErrNotFound)
+
+	workflow := domain.DefaultWorkflow(project.ID)
+	must(t, repos.Workflows.Save(ctx, workflow))
+
+	workflow.InitialState = "in_progress"
+	workflow.States = workflow.States[1:]
+	workflow.Transitions = workflow.Transitions[2:]
+	must(t, repos.Workflows.Save(ctx, workflow))
+
+	got, err := repos.Workflows.Get(ctx, project.ID)
+	must(t, err)
+	if got.InitialState != "in_progress" || len(got.States) != len(workflow.States) ||
+		len(got.Transitions) != len(workflow.Transitions) || got.States[2] != workflow.States[2] {
+		t.Fatalf("workflow did not round-trip: got %+v, want %+v", got, workflow)
+	}
+
+	must(t, repos.Projects.Delete(ctx, project.ID))
+	_, err = repos.Workflows.Get(ctx, project.ID)
+	expectErr(t, err, repository.ErrNotFound)
+}
+
+func testBoards(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	boards, tasks := repos.Boards, repos.Tasks
+
+	project := newProject(uuid.Nil)
+	must(t, repos.Projects.Create(ctx, project))
+	other := newProject(uuid.Nil)
+	must(t, repos.Projects.Create(ctx, other))
+
+	_, err := boards.Get(ctx, project.ID)
+	expectErr(t, err, repository.ErrNotFound)
+
+	board := domain.DefaultBoard(domain.DefaultWorkflow(project.ID))
+	must(t, boards.Save(ctx, board))
+	board.Columns = []domain.BoardColumn{
+		{Name: "To do", States: []string{"todo"}, WIPPolicy: domain.WIPWarn},
+		{Name: "Doing", States: []string{"in_progress", "review"}, WIPLimit: 3, WIPPolicy: domain.WIPReject},
+	}
+	must(t, boards.Save(ctx, board))
+	expectErr(t, boards.Save(ctx, &domain.Board{ProjectID: uuid.New(), Columns: board.Columns}),
+		repository.ErrReferenced)
+
+	got, err := boards.Get(ctx, project.ID)
+	must(t, err)
+	if fmt.Sprint(got.Columns) != fmt.Sprint(board.Columns) {
+		t.Fatalf("board did not round-trip: got %+v, want %+v", got.Columns, board.Columns)
+	}
+
+	// Tasks keep their rank, which Move sets for many at once.
+	first := newTask(project.ID, uuid.Nil)
+	first.Rank = "i"
+	must(t, tasks.Create(ctx, first))
+	second := newTask(project.ID, uuid.Nil)
+	must(t, tasks.Create(ctx, second))
+	expectRank := func(taskID uuid.UUID, want string) {
+		t.Helper()
+		got, err := tasks.Get(ctx, taskID)
+		must(t, err)
+		if got.Rank != want {
+			t.Fatalf("task %s should have rank %q, got %q", taskID, want, got.Rank)
+		}
+	}
+	expectRank(first.ID, "i")
+	expectRank(second.ID, "")
+	first.Rank = "r"
+	must(t, tasks.Update(ctx, first))
+	expectRank(first.ID, "r")
+
+	third := newTask(project.ID, uuid.Nil)
+	must(t, tasks.Create(ctx, third))
+	third.Rank = "m"
+	must(t, tasks.Move(ctx, third, map[uuid.UUID]string{first.ID: "9", second.ID: "i"}, nil))
+	expectRank(first.ID, "9")
+	expectRank(second.ID, "i")
+	expectRank(third.ID, "m")
+	third.Rank = "n"
+	expectErr(t, tasks.Move(ctx, third, map[uuid.UUID]string{first.ID: "z", uuid.New(): "zi"}, nil),
+		repository.ErrNotFound)
+	expectRank(first.ID, "9")
+	expectRank(third.ID, "m")
+
+	// A move that would take a column past its limit writes nothing.
+	full := repository.WIPLimit{ProjectID: project.ID, Column: "Doing", States: []string{"in_progress", "review"}}
+	third.State = "in_progress"
+	expectErr(t, tasks.Move(ctx, third, map[uuid.UUID]string{first.ID: "z"}, []repository.WIPLimit{full}),
+		repository.ErrLimitReached)
+	expectRank(first.ID, "9")
+	got3, err := tasks.Get(ctx, third.ID)
+	must(t, err)
+	if got3.State != "todo" || got3.Rank != "m" {
+		t.Fatalf("refused move should leave the task alone, got state %q and rank %q", got3.State, got3.Rank)
+	}
+	full.Max = 1
+	must(t, tasks.Move(ctx, third, nil, []repository.WIPLimit{full}))
+	expectRank(third.ID, "n")
+	second.State = "review"
+	expectErr(t, tasks.Move(ctx, second, nil, []repository.WIPLimit{full}), repository.ErrLimitReached)
+	fourth := newTask(project.ID, uuid.Nil)
+	fourth.State = "review"
+	expectErr(t, tasks.CreateWithin(ctx, fourth, []repository.WIPLimit{full}), repository.ErrLimitReached)
+	expectErr(t, tasks.Delete(ctx, fourth.ID), repository.ErrNotFound)
+	full.Max = 2
+	must(t, tasks.CreateWithin(ctx, fourth, []repository.WIPLimit{full}))
+
+	// Subtasks moving with their parent lose their place on the old board.
+	child := newTask(project.ID, uuid.Nil)
+	child.ParentID = first.ID
+	child.Rank = "r"
+	must(t, tasks.Create(ctx, child))
+	first.ProjectID = other.ID
+	first.Rank = "i"
+	must(t, tasks.Update(ctx, first))
+	expectRank(first.ID, "i")
+	expectRank(child.ID, "")
+
+	// Boards go away with their project.
+	must(t, repos.Projects.DeleteWithTasks(ctx, project.ID))
+	_, err = boards.Get(ctx, project.ID)
+	expectErr(t, err, repository.ErrNotFound)
+}
+
+func taskTitles(page *repository.Page[domain.Task]) []string {
+	titles := make([]string, len(page.Items))
+	for i, task := range page.Items {
+		titles[i] = task.Title
+	}
+	return titles
+}
+
+func expectTitles(t *testing.T, got, want []string) {
+	t.Helper()
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func testTaskQueries(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	user := newUser("filter@example.com")
+	must(t, repos.Users.Create(ctx, user))
+	project := newProject(uuid.Nil)
+	must(t, repos.Projects.Create(ctx, project))
+	other := newProject(uuid.Nil)
+	must(t, repos.Projects.Create(ctx, other))
+
+	base := now().Add(-time.Hour)
+	specs := []struct {
+		title     string
+		priority  domain.Priority
+		state     string
+		assignee  uuid.UUID
+		project   uuid.UUID
+		completed bool
+		// due is how long after base the task is due; 0 for no due date.
+		due time.Duration
+	}{
+		{"a", domain.PriorityLow, "todo", user.ID, project.ID, false, 10 * time.Minute},
+		{"b", domain.PriorityCritical, "done", uuid.Nil, project.ID, true, 5 * time.Minute},
+		{"c", domain.PriorityMedium, "in_progress", user.ID, other.ID, false, 0},
+		{"d", domain.PriorityCritical, "done", user.ID, project.ID, true, 20 * time.Minute},
+	}
+	for i, spec := range specs {
+		task := newTask(spec.project, spec.assignee)
+		task.Title = spec.title
+		task.Priority = spec.priority
+		task.State = spec.state
+		task.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		if spec.completed {
+			task.CompletedAt = base.Add(time.Duration(i)*time.Minute + 30*time.Second)
+		}
+		if spec.due != 0 {
+			task.DueDate = base.Add(spec.due)
+		}
+		must(t, repos.Tasks.Create(ctx, task))
+	}
+
+	list := func(query repository.TaskQuery) []string {
+		t.Helper()
+		page, err := repos.Tasks.GetAll(ctx, query)
+		must(t, err)
+		return taskTitles(page)
+	}
+	sortBy := func(s string) []repository.SortField {
+		sort, err := repository.TaskSorting.Parse(s)
+		must(t, err)
+		return sort
+	}
+
+	expectTitles(t, list(repository.TaskQuery{}), []string{"a", "b", "c", "d"})
+	expectTitles(t, list(repository.TaskQuery{Sort: sortBy("priority,title")}), []string{"b", "d", "c", "a"})
+	expectTitles(t, list(repository.TaskQuery{Sort: sortBy("priority,-created_at")}), []string{"d", "b", "c", "a"})
+	expectTitles(t, list(repository.TaskQuery{Sort: sortBy("-title")}), []string{"d", "c", "b", "a"})
+
+	expectTitles(t, list(repository.TaskQuery{States: []string{"todo", "in_progress"}}), []string{"a", "c"})
+	expectTitles(t, list(repository.TaskQuery{Priorities: []domain.Priority{domain.PriorityCritical}}), []string{"b", "d"})
+	expectTitles(t, list(repository.TaskQuery{Assignee: user.ID}), []string{"a", "c", "d"})
+	expectTitles(t, list(repository.TaskQuery{ProjectID: project.ID, Assignee: user.ID}), []string{"a", "d"})
+	expectTitles(t, list(repository.TaskQuery{
+		CreatedAfter:  base.Add(time.Minute),
+		CreatedBefore: base.Add(3 * time.Minute),
+	}), []string{"b", "c"})
+	expectTitles(t, list(repository.TaskQuery{CompletedBefore: base.Add(2 * time.Minute)}), []string{"b"})
+	expectTitles(t, list(repository.TaskQuery{CompletedAfter: base.Add(2 * time.Minute)}), []string{"d"})
+
+	expectTitles(t, list(repository.TaskQuery{Sort: sortBy("due_date")}), []string{"c", "b", "a", "d"})
+	expectTitles(t, list(repository.TaskQuery{DueAfter: base.Add(6 * time.Minute)}), []string{"a", "d"})
+	expectTitles(t, list(repository.TaskQuery{DueBefore: base.Add(15 * time.Minute)}), []string{"a", "b"})
+	expectTitles(t, list(repository.TaskQuery{OverdueAt: base.Add(15 * time.Minute)}), []string{"a"})
+	expectTitles(t, list(repository.TaskQuery{OverdueAt: base.Add(10 * time.Minute)}), []string{})
+	expectTitles(t, list(repository.TaskQuery{Open: true, DueAfter: base, DueBefore: base.Add(time.Hour)}),
+		[]string{"a"})
+}
+
+func testPagination(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	project := newProject(uuid.Nil)
+	must(t, repos.Projects.Create(ctx, project))
+
+	// Tasks share creation times and priorities, so pages must fall back to
+	// the ID to keep a stable order.
+	created := now()
+	var want []string
+	for i := 0; i < 7; i++ {
+		task := newTask(project.ID, uuid.Nil)
+		task.Title = string(rune('a' + i))
+		task.CreatedAt = created.Add(time.Duration(i/2) * time.Second)
+		task.Priority = domain.Priorities()[i%3]
+		must(t, repos.Tasks.Create(ctx, task))
+	}
+
+	for _, sortBy := range []string{"", "priority", "-created_at,title"} {
+		sort, err := repository.TaskSorting.Parse(sortBy)
+		must(t, err)
+
+		all, err := repos.Tasks.GetAll(ctx, repository.TaskQuery{Sort: sort})
+		must(t, err)
+		want = taskTitles(all)
+		if all.NextCursor != "" {
+			t.Fatal("a list that fits in one page should have no next cursor")
+		}
+
+		var got []string
+		query := repository.TaskQuery{Sort: sort, Limit: 3}
+		for pages := 0; ; pages++ {
+			if pages > 3 {
+				t.Fatal("pagination did not terminate")
+			}
+			page, err := repos.Tasks.GetAll(ctx, query)
+			must(t, err)
+			if len(page.Items) > 3 {
+				t.Fatalf("page has %d items, limit is 3", len(page.Items))
+			}
+			got = append(got, taskTitles(page)...)
+			if page.NextCursor == "" {
+				break
+			}
+			query.Cursor = page.NextCursor
+		}
+		expectTitles(t, got, want)
+	}
+
+	first, err := repos.Tasks.GetAll(ctx, repository.TaskQuery{Limit: 2})
+	must(t, err)
+	priority, err := repository.TaskSorting.Parse("priority")
+	must(t, err)
+	_, err = repos.Tasks.GetAll(ctx, repository.TaskQuery{Sort: priority, Cursor: first.NextCursor})
+	expectErr(t, err, repository.ErrInvalidCursor)
+	_, err = repos.Tasks.GetAll(ctx, repository.TaskQuery{Cursor: "not-a-cursor"})
+	expectErr(t, err, repository.ErrInvalidCursor)
+}
+
+func testProjectQueries(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	manager := newUser("projects@example.com")
+	must(t, repos.Users.Create(ctx, manager))
+
+	base := now()
+	titles := []string{"q1", "q2", "q3"}
+	for i, title := range titles {
+		project := newProject(uuid.Nil)
+		if i != 1 {
+			project.ManagerID = manager.ID
+		}
+		project.Title = title
+		project.StartDate = base.Add(time.Duration(i) * 24 * time.Hour)
+		project.EndDate = project.StartDate.Add(time.Duration(3-i) * 48 * time.Hour)
+		must(t, repos.Projects.Create(ctx, project))
+	}
+
+	list := func(query repository.ProjectQuery) []string {
+		t.Helper()
+		page, err := repos.Projects.GetAll(ctx, query)
+		must(t, err)
+		var got []string
+		for _, project := range page.Items {
+			got = append(got, project.Title)
+		}
+		return got
+	}
+
+	expectTitles(t, list(repository.ProjectQuery{}), titles)
+	expectTitles(t, list(repository.ProjectQuery{ManagerID: manager.ID}), []string{"q1", "q3"})
+	expectTitles(t, list(repository.ProjectQuery{StartsAfter: base.Add(time.Hour)}), []string{"q2", "q3"})
+	expectTitles(t, list(repository.ProjectQuery{EndsBefore: base.Add(5*24*time.Hour + time.Minute)}), []string{"q2", "q3"})
+
+	sort, err := repository.ProjectSorting.Parse("end_date")
+	must(t, err)
+	expectTitles(t, list(repository.ProjectQuery{Sort: sort}), []string{"q3", "q2", "q1"})
+}
+
+func testUserQueries(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	organization := newOrganization(uuid.New())
+	other := newOrganization(uuid.New())
+	for i, spec := range []struct {
+		name         string
+		organization *domain.Organization
+		role         domain.Role
+	}{
+		{"Carol", organization, domain.RoleAdmin},
+		{"Alice", organization, domain.RoleMember},
+		{"Bob", organization, domain.RoleAdmin},
+		{"Dave", other, domain.RoleAdmin},
+	} {
+		user := newUser(strings.ToLower(spec.name) + "@example.com")
+		user.FullName = spec.name
+		member := &domain.OrganizationMember{
+			OrganizationID: spec.organization.ID,
+			UserID:         user.ID,
+			Role:           spec.role,
+			JoinedAt:       now().Add(time.Duration(i) * time.Second),
+		}
+		if _, err := repos.Organizations.Get(ctx, spec.organization.ID); errors.Is(err, repository.ErrNotFound) {
+			must(t, repos.Users.Create(ctx, user))
+			must(t, repos.Organizations.Create(ctx, spec.organization, member))
+		} else {
+			must(t, repos.Users.CreateWithMembership(ctx, user, member))
+		}
+	}
+
+	sort, err := repository.UserSorting.Parse("full_name")
+	must(t, err)
+	list := func(query repository.UserQuery) []string {
+		t.Helper()
+		query.Sort = sort
+		page, err := repos.Users.GetAll(ctx, query)
+		must(t, err)
+		var got []string
+		for _, user := range page.Items {
+			if query.OrganizationID != uuid.Nil && user.Role == "" {
+				t.Fatalf("%s was listed without its role", user.FullName)
+			}
+			got = append(got, user.FullName)
+		}
+		return got
+	}
+
+	expectTitles(t, list(repository.UserQuery{}), []string{"Alice", "Bob", "Carol", "Dave"})
+	expectTitles(t, list(repository.UserQuery{OrganizationID: organization.ID}), []string{"Alice", "Bob", "Carol"})
+	expectTitles(t, list(repository.UserQuery{OrganizationID: organization.ID, Role: "admin"}), []string{"Bob", "Carol"})
+	expectTitles(t, list(repository.UserQuery{OrganizationID: other.ID, Role: "member"}), nil)
+}
+
+func testProjectDeletes(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	project := newProject(uuid.Nil)
+	must(t, repos.Projects.Create(ctx, project))
+	other := newProject(uuid.Nil)
+	must(t, repos.Projects.Create(ctx, other))
+	must(t, repos.Workflows.Save(ctx, domain.DefaultWorkflow(project.ID)))
+
+	open := newTask(project.ID, uuid.Nil)
+	must(t, repos.Tasks.Create(ctx, open))
+	done := newTask(project.ID, uuid.Nil)
+	done.CompletedAt = now()
+	must(t, repos.Tasks.Create(ctx, done))
+	kept := newTask(other.ID, uuid.Nil)
+	must(t, repos.Tasks.Create(ctx, kept))
+
+	n, err := repos.Tasks.Count(ctx, repository.TaskQuery{ProjectID: project.ID})
+	must(t, err)
+	if n != 2 {
+		t.Fatalf("expected 2 tasks, got %d", n)
+	}
+	n, err = repos.Tasks.Count(ctx, repository.TaskQuery{ProjectID: project.ID, Open: true})
+	must(t, err)
+	if n != 1 {
+		t.Fatalf("expected 1 open task, got %d", n)
+	}
+
+	expectErr(t, repos.Projects.Delete(ctx, project.ID), repository.ErrReferenced)
+	if _, err := repos.Projects.Get(ctx, project.ID); err != nil {
+		t.Fatalf("a refused delete removed the project: %v", err)
+	}
+
+	must(t, repos.Projects.DeleteWithTasks(ctx, project.ID))
+	_, err = repos.Projects.Get(ctx, project.ID)
+	expectErr(t, err, repository.ErrNotFound)
+	_, err = repos.Tasks.Get(ctx, open.ID)
+	expectErr(t, err, repository.ErrNotFound)
+	_, err = repos.Workflows.Get(ctx, project.ID)
+	expectErr(t, err, repository.ErrNotFound)
+	if _, err := repos.Tasks.Get(ctx, kept.ID); err != nil {
+		t.Fatalf("task of another project was deleted: %v", err)
+	}
+	expectErr(t, repos.Projects.DeleteWithTasks(ctx, project.ID), repository.ErrNotFound)
+}
+
+func testUserDeletes(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	leaving := newUser("leaving@example.com")
+	must(t, repos.Users.Create(ctx, leaving))
+	heir := newUser("heir@example.com")
+	must(t, repos.Users.Create(ctx, heir))
+	project := newProject(leaving.ID)
+	must(t, repos.Projects.Create(ctx, project))
+
+	open := newTask(project.ID, leaving.ID)
+	must(t, repos.Tasks.Create(ctx, open))
+	done := newTask(project.ID, leaving.ID)
+	done.CompletedAt = now()
+	must(t, repos.Tasks.Create(ctx, done))
+
+	expectErr(t, repos.Users.Delete(ctx, leaving.ID), repository.ErrReferenced)
+
+	must(t, repos.Users.DeleteReassigning(ctx, leaving.ID, heir.ID))
+	_, err := repos.Users.Get(ctx, leaving.ID)
+	expectErr(t, err, repository.ErrNotFound)
+
+	got, err := repos.Tasks.Get(ctx, open.ID)
+	must(t, err)
+	if got.Assignee != heir.ID {
+		t.Fatalf("open task should move to %s, got %s", heir.ID, got.Assignee)
+	}
+	got, err = repos.Tasks.Get(ctx, done.ID)
+	must(t, err)
+	if got.Assignee != uuid.Nil {
+		t.Fatalf("completed task should be unassigned, got %s", got.Assignee)
+	}
+	member, err := repos.Members.Get(ctx, project.ID, heir.ID)
+	must(t, err)
+	if member.Role != domain.ProjectContributor {
+		t.Fatalf("heir should join the project as a contributor, got %q", member.Role)
+	}
+	managed, err := repos.Projects.Get(ctx, project.ID)
+	must(t, err)
+	if managed.ManagerID != uuid.Nil {
+		t.Fatalf("project should lose its manager, got %s", managed.ManagerID)
+	}
+
+	// Without an heir the open tasks are unassigned.
+	must(t, repos.Users.DeleteReassigning(ctx, heir.ID, uuid.Nil))
+	got, err = repos.Tasks.Get(ctx, open.ID)
+	must(t, err)
+	if got.Assignee != uuid.Nil {
+		t.Fatalf("open task should be unassigned, got %s", got.Assignee)
+	}
+	expectErr(t, repos.Users.DeleteReassigning(ctx, heir.ID, uuid.Nil), repository.ErrNotFound)
+}
+
+func testSessions(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	sessions := repos.Sessions
+
+	user := newUser("session@example.com")
+	must(t, repos.Users.Create(ctx, user))
+
+	session := &domain.Session{ID: uuid.New(), UserID: user.ID, CreatedAt: now()}
+	first := &domain.RefreshToken{Hash: "first", SessionID: session.ID, ExpiresAt: now().Add(time.Hour)}
+	must(t, sessions.Create(ctx, session, first))
+
+	got, err := sessions.Get(ctx, session.ID)
+	must(t, err)
+	if got.UserID != user.ID || !got.CreatedAt.Equal(session.CreatedAt) || !got.RevokedAt.IsZero() {
+		t.Fatalf("session did not round-trip: got %+v, want %+v", got, session)
+	}
+	token, err := sessions.GetRefreshToken(ctx, "first")
+	must(t, err)
+	if token.SessionID != session.ID || !token.ExpiresAt.Equal(first.ExpiresAt) || !token.UsedAt.IsZero() {
+		t.Fatalf("refresh token did not round-trip: got %+v, want %+v", token, first)
+	}
+	_, err = sessions.GetRefreshToken(ctx, "missing")
+	expectErr(t, err, repository.ErrNotFound)
+
+	usedAt := now()
+	second := &domain.RefreshToken{Hash: "second", SessionID: session.ID, ExpiresAt: now().Add(time.Hour)}
+	must(t, sessions.Rotate(ctx, "first", usedAt, second))
+	token, err = sessions.GetRefreshToken(ctx, "first")
+	must(t, err)
+	if !token.UsedAt.Equal(usedAt) {
+		t.Fatalf("rotated token should be used at %v, got %v", usedAt, token.UsedAt)
+	}
+	if _, err := sessions.GetRefreshToken(ctx, "second"); err != nil {
+		t.Fatalf("next token was not stored: %v", err)
+	}
+	third := &domain.RefreshToken{Hash: "third", SessionID: session.ID, ExpiresAt: now().Add(time.Hour)}
+	expectErr(t, sessions.Rotate(ctx, "first", now(), third), repository.ErrNotFound)
+	expectErr(t, sessions.Rotate(ctx, "missing", now(), third), repository.ErrNotFound)
+	if _, err := sessions.GetRefreshToken(ctx, "third"); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("failed rotation should not store the next token, got %v", err)
+	}
+
+	revokedAt := now()
+	must(t, sessions.Revoke(ctx, session.ID, revokedAt))
+	must(t, sessions.Revoke(ctx, session.ID, revokedAt.Add(time.Minute)))
+	got, err = sessions.Get(ctx, session.ID)
+	must(t, err)
+	if !got.RevokedAt.Equal(revokedAt) {
+		t.Fatalf("session should be revoked at %v, got %v", revokedAt, got.RevokedAt)
+	}
+	expectErr(t, sessions.Revoke(ctx, uuid.New(), now()), repository.ErrNotFound)
+
+	a := &domain.Session{ID: uuid.New(), UserID: user.ID, CreatedAt: now()}
+	must(t, sessions.Create(ctx, a, &domain.RefreshToken{Hash: "a", SessionID: a.ID, ExpiresAt: now().Add(time.Hour)}))
+	b := &domain.Session{ID: uuid.New(), UserID: user.ID, CreatedAt: now()}
+	must(t, sessions.Create(ctx, b, &domain.RefreshToken{Hash: "b", SessionID: b.ID, ExpiresAt: now().Add(time.Hour)}))
+	must(t, sessions.RevokeUser(ctx, user.ID, now()))
+	for _, id := range []uuid.UUID{a.ID, b.ID} {
+		got, err := sessions.Get(ctx, id)
+		must(t, err)
+		if got.RevokedAt.IsZero() {
+			t.Fatalf("session %s should be revoked", id)
+		}
+	}
+
+	// Sessions go away with their user.
+	must(t, repos.Users.Delete(ctx, user.ID))
+	_, err = sessions.Get(ctx, a.ID)
+	expectErr(t, err, repository.ErrNotFound)
+	_, err = sessions.GetRefreshToken(ctx, "a")
+	expectErr(t, err, repository.ErrNotFound)
+}
+
+func testMembers(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	members := repos.Members
+
+	alice := newUser("alice@example.com")
+	must(t, repos.Users.Create(ctx, alice))
+	bob := newUser("bob@example.com")
+	must(t, repos.Users.Create(ctx, bob))
+	project := newProject(uuid.Nil)
+	must(t, repos.Projects.Create(ctx, project))
+	other := newProject(uuid.Nil)
+	must(t, repos.Projects.Create(ctx, other))
+
+	first := &domain.Member{ProjectID: project.ID, UserID: alice.ID, Role: domain.ProjectOwner, JoinedAt: now()}
+	must(t, members.Add(ctx, first))
+	second := &domain.Member{ProjectID: project.ID, UserID: bob.ID, Role: domain.ProjectObserver, JoinedAt: now().Add(time.Second)}
+	must(t, members.Add(ctx, second))
+	must(t, members.Add(ctx, &domain.Member{ProjectID: other.ID, UserID: bob.ID, Role: domain.ProjectContributor, JoinedAt: now()}))
+
+	expectErr(t, members.Add(ctx, first), repository.ErrDuplicate)
+	expectErr(t, members.Add(ctx, &domain.Member{ProjectID: uuid.New(), UserID: alice.ID, Role: domain.ProjectOwner, JoinedAt: now()}), repository.ErrReferenced)
+	expectErr(t, members.Add(ctx, &domain.Member{ProjectID: project.ID, UserID: uuid.New(), Role: domain.ProjectOwner, JoinedAt: now()}), repository.ErrReferenced)
+
+	got, err := members.Get(ctx, project.ID, alice.ID)
+	must(t, err)
+	if got.Role != domain.ProjectOwner || !got.JoinedAt.Equal(first.JoinedAt) {
+		t.Fatalf("member did not round-trip: got %+v, want %+v", got, first)
+	}
+	_, err = members.Get(ctx, other.ID, alice.ID)
+	expectErr(t, err, repository.ErrNotFound)
+
+	list, err := members.List(ctx, project.ID)
+	must(t, err)
+	if len(list) != 2 || list[0].UserID != alice.ID || list[1].UserID != bob.ID {
+		t.Fatalf("members should be listed in the order they joined: %+v", list)
+	}
+
+	second.Role = domain.ProjectContributor
+	must(t, members.Update(ctx, second))
+	got, err = members.Get(ctx, project.ID, bob.ID)
+	must(t, err)
+	if got.Role != domain.ProjectContributor || !got.JoinedAt.Equal(second.JoinedAt) {
+		t.Fatalf("update was not persisted: %+v", got)
+	}
+	expectErr(t, members.Update(ctx, &domain.Member{ProjectID: other.ID, UserID: alice.ID, Role: domain.ProjectOwner}), repository.ErrNotFound)
+
+	projects, err := repos.Projects.GetAll(ctx, repository.ProjectQuery{MemberID: bob.ID})
+	must(t, err)
+	if len(projects.Items) != 2 {
+		t.Fatalf("bob should be on 2 projects, got %d", len(projects.Items))
+	}
+	projects, err = repos.Projects.GetAll(ctx, repository.ProjectQuery{MemberID: alice.ID})
+	must(t, err)
+	if len(projects.Items) != 1 || projects.Items[0].ID != project.ID {
+		t.Fatalf("alice should only be on %s, got %+v", project.ID, projects.Items)
+	}
+
+	must(t, members.Remove(ctx, project.ID, alice.ID))
+	expectErr(t, members.Remove(ctx, project.ID, alice.ID), repository.ErrNotFound)
+
+	// Members go away with their project or user.
+	must(t, repos.Projects.Delete(ctx, other.ID))
+	list, err = members.List(ctx, other.ID)
+	must(t, err)
+	if len(list) != 0 {
+		t.Fatalf("members of a deleted project remain: %+v", list)
+	}
+	must(t, repos.Users.Delete(ctx, bob.ID))
+	_, err = members.Get(ctx, project.ID, bob.ID)
+	expectErr(t, err, repository.ErrNotFound)
+}
+
+func testAPITokens(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	tokens := repos.APITokens
+
+	user := newUser("tokens@example.com")
+	must(t, repos.Users.Create(ctx, user))
+
+	older := &domain.APIToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Name:      "ci",
+		Hash:      "older",
+		Scopes:    []string{"tasks:read", "projects:read"},
+		CreatedAt: now().Add(-time.Hour),
+		ExpiresAt: now().Add(24 * time.Hour),
+	}
+	must(t, tokens.Create(ctx, older))
+	newer := &domain.APIToken{ID: uuid.New(), UserID: user.ID, Name: "backup", Hash: "newer",
+		Scopes: []string{"tasks:read"}, CreatedAt: now()}
+	must(t, tokens.Create(ctx, newer))
+
+	got, err := tokens.Get(ctx, older.ID)
+	must(t, err)
+	if got.Name != older.Name || got.Hash != older.Hash || strings.Join(got.Scopes, ",") != "tasks:read,projects:read" ||
+		!got.CreatedAt.Equal(older.CreatedAt) || !got.ExpiresAt.Equal(older.ExpiresAt) ||
+		!got.LastUsedAt.IsZero() || !got.RevokedAt.IsZero() {
+		t.Fatalf("token did not round-trip: got %+v, want %+v", got, older)
+	}
+	got, err = tokens.GetByHash(ctx, "newer")
+	must(t, err)
+	if got.ID != newer.ID || !got.ExpiresAt.IsZero() {
+		t.Fatalf("GetByHash returned %+v, want %+v", got, newer)
+	}
+	_, err = tokens.GetByHash(ctx, "missing")
+	expectErr(t, err, repository.ErrNotFound)
+	_, err = tokens.Get(ctx, uuid.New())
+	expectErr(t, err, repository.ErrNotFound)
+
+	duplicate := *newer
+	duplicate.ID = uuid.New()
+	expectErr(t, tokens.Create(ctx, &duplicate), repository.ErrDuplicate)
+	orphan := *newer
+	orphan.ID, orphan.Hash, orphan.UserID = uuid.New(), "orphan", uuid.New()
+	expectErr(t, tokens.Create(ctx, &orphan), repository.ErrReferenced)
+
+	list, err := tokens.List(ctx, user.ID)
+	must(t, err)
+	if len(list) != 2 || list[0].ID != newer.ID || list[1].ID != older.ID {
+		t.Fatalf("List should return the newest token first, got %+v", list)
+	}
+	list, err = tokens.List(ctx, uuid.New())
+	must(t, err)
+	if len(list) != 0 {
+		t.Fatalf("List of a user without tokens returned %+v", list)
+	}
+
+	usedAt := now()
+	must(t, tokens.Touch(ctx, older.ID, usedAt))
+	expectErr(t, tokens.Touch(ctx, uuid.New(), usedAt), repository.ErrNotFound)
+	got, err = tokens.Get(ctx, older.ID)
+	must(t, err)
+	if !got.LastUsedAt.Equal(usedAt) {
+		t.Fatalf("token should be last used at %v, got %v", usedAt, got.LastUsedAt)
+	}
+
+	revokedAt := now()
+	must(t, tokens.Revoke(ctx, older.ID, revokedAt))
+	must(t, tokens.Revoke(ctx, older.ID, revokedAt.Add(time.Minute)))
+	got, err = tokens.Get(ctx, older.ID)
+	must(t, err)
+	if !got.RevokedAt.Equal(revokedAt) {
+		t.Fatalf("token should be revoked at %v, got %v", revokedAt, got.RevokedAt)
+	}
+	expectErr(t, tokens.Revoke(ctx, uuid.New(), now()), repository.ErrNotFound)
+
+	// Tokens go away with their user.
+	must(t, repos.Users.Delete(ctx, user.ID))
+	_, err = tokens.Get(ctx, newer.ID)
+	expectErr(t, err, repository.ErrNotFound)
+}
+
+func testOrganizations(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	organizations := repos.Organizations
+
+	ada := newUser("ada@example.com")
+	must(t, repos.Users.Create(ctx, ada))
+	grace := newUser("grace@example.com")
+	must(t, repos.Users.Create(ctx, grace))
+
+	first := newOrganization(uuid.New())
+	founder := &domain.OrganizationMember{OrganizationID: first.ID, UserID: ada.ID, Role: domain.RoleAdmin, JoinedAt: now()}
+	must(t, organizations.Create(ctx, first, founder))
+	expectErr(t, organizations.Create(ctx, first, founder), repository.ErrDuplicate)
+	orphan := newOrganization(uuid.New())
+	expectErr(t, organizations.Create(ctx, orphan, &domain.OrganizationMember{
+		OrganizationID: orphan.ID, UserID: uuid.New(), Role: domain.RoleAdmin, JoinedAt: now()}), repository.ErrReferenced)
+	_, err := organizations.Get(ctx, orphan.ID)
+	expectErr(t, err, repository.ErrNotFound)
+
+	got, err := organizations.Get(ctx, first.ID)
+	must(t, err)
+	if got.Name != first.Name || !got.CreatedAt.Equal(first.CreatedAt) {
+		t.Fatalf("organization did not round-trip: got %+v, want %+v", got, first)
+	}
+
+	second := newOrganization(uuid.New())
+	second.Name = "Second"
+	must(t, organizations.Create(ctx, second, &domain.OrganizationMember{
+		OrganizationID: second.ID, UserID: grace.ID, Role: domain.RoleAdmin, JoinedAt: now()}))
+	joined := &domain.OrganizationMember{OrganizationID: second.ID, UserID: ada.ID, Role: domain.RoleViewer,
+		JoinedAt: now().Add(time.Second)}
+	must(t, organizations.AddMember(ctx, joined))
+	expectErr(t, organizations.AddMember(ctx, joined), repository.ErrDuplicate)
+	expectErr(t, organizations.AddMember(ctx, &domain.OrganizationMember{OrganizationID: uuid.New(), UserID: ada.ID,
+		Role: domain.RoleMember, JoinedAt: now()}), repository.ErrReferenced)
+	expectErr(t, organizations.AddMember(ctx, &domain.OrganizationMember{OrganizationID: first.ID, UserID: uuid.New(),
+		Role: domain.RoleMember, JoinedAt: now()}), repository.ErrReferenced)
+
+	mine, err := organizations.ListForUser(ctx, ada.ID)
+	must(t, err)
+	if len(mine) != 2 || mine[0].ID != first.ID || mine[1].ID != second.ID {
+		t.Fatalf("organizations should be listed in the order they were joined: %+v", mine)
+	}
+
+	member, err := organizations.GetMember(ctx, second.ID, ada.ID)
+	must(t, err)
+	if member.Role != domain.RoleViewer || !member.JoinedAt.Equal(joined.JoinedAt) {
+		t.Fatalf("member did not round-trip: got %+v, want %+v", member, joined)
+	}
+	_, err = organizations.GetMember(ctx, first.ID, grace.ID)
+	expectErr(t, err, repository.ErrNotFound)
+	members, err := organizations.ListMembers(ctx, second.ID)
+	must(t, err)
+	if len(members) != 2 || members[0].UserID != grace.ID || members[1].UserID != ada.ID {
+		t.Fatalf("members should be listed in the order they joined: %+v", members)
+	}
+
+	joined.Role = domain.RoleManager
+	must(t, organizations.UpdateMember(ctx, joined))
+	member, err = organizations.GetMember(ctx, second.ID, ada.ID)
+	must(t, err)
+	if member.Role != domain.RoleManager {
+		t.Fatalf("update was not persisted: %+v", member)
+	}
+	expectErr(t, organizations.UpdateMember(ctx, &domain.OrganizationMember{OrganizationID: first.ID, UserID: grace.ID,
+		Role: domain.RoleAdmin}), repository.ErrNotFound)
+
+	first.Name = "Renamed"
+	must(t, organizations.Update(ctx, first))
+	got, err = organizations.Get(ctx, first.ID)
+	must(t, err)
+	if got.Name != "Renamed" {
+		t.Fatalf("update was not persisted: %+v", got)
+	}
+	expectErr(t, organizations.Update(ctx, newOrganization(uuid.New())), repository.ErrNotFound)
+
+	// A user's role is kept per organization, not on the user.
+	carol := newUser("carol@example.com")
+	must(t, repos.Users.CreateWithMembership(ctx, carol, &domain.OrganizationMember{
+		OrganizationID: first.ID, UserID: carol.ID, Role: domain.RoleMember, JoinedAt: now()}))
+	expectErr(t, repos.Users.CreateWithMembership(ctx, newUser("dave@example.com"), &domain.OrganizationMember{
+		OrganizationID: uuid.New(), Role: domain.RoleMember, JoinedAt: now()}), repository.ErrReferenced)
+	if _, err := repos.Users.GetByEmail(ctx, "dave@example.com"); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("a failed membership should not leave the user behind, got %v", err)
+	}
+	page, err := repos.Users.GetAll(ctx, repository.UserQuery{OrganizationID: first.ID})
+	must(t, err)
+	roles := map[uuid.UUID]domain.Role{}
+	for _, user := range page.Items {
+		roles[user.ID] = user.Role
+	}
+	if len(roles) != 2 || roles[ada.ID] != domain.RoleAdmin || roles[carol.ID] != domain.RoleMember {
+		t.Fatalf("members of %s should be ada and carol with their roles, got %v", first.ID, roles)
+	}
+
+	// Leaving an organization also takes the user off its projects.
+	project := newProject(uuid.Nil)
+	project.OrganizationID = second.ID
+	must(t, repos.Projects.Create(ctx, project))
+	must(t, repos.Members.Add(ctx, &domain.Member{ProjectID: project.ID, UserID: ada.ID, Role: domain.ProjectContributor,
+		JoinedAt: now()}))
+	must(t, organizations.RemoveMember(ctx, second.ID, ada.ID))
+	expectErr(t, organizations.RemoveMember(ctx, second.ID, ada.ID), repository.ErrNotFound)
+	_, err = repos.Members.Get(ctx, project.ID, ada.ID)
+	expectErr(t, err, repository.ErrNotFound)
+	mine, err = organizations.ListForUser(ctx, ada.ID)
+	must(t, err)
+	if len(mine) != 1 || mine[0].ID != first.ID {
+		t.Fatalf("ada should only be left in %s, got %+v", first.ID, mine)
+	}
+
+	// Memberships go away with their user.
+	must(t, repos.Users.Delete(ctx, grace.ID))
+	members, err = organizations.ListMembers(ctx, second.ID)
+	must(t, err)
+	if len(members) != 0 {
+		t.Fatalf("memberships of a deleted user remain: %+v", members)
+	}
+}
+
+// testIsolation checks that the organization filters, which services rely on
+// to keep organizations apart, never let another organization's records
+// through, whatever other filters are combined with them.
+func testIsolation(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+
+	type tenant struct {
+		organization *domain.Organization
+		user         *domain.User
+		project      *domain.Entity
+		task         *domain.Task
+	}
+	tenants := make([]tenant, 2)
+	for i := range tenants {
+		user := newUser(fmt.Sprintf("user%d@example.com", i))
+		must(t, repos.Users.Create(ctx, user))
+		organization := newOrganization(uuid.New())
+		must(t, repos.Organizations.Create(ctx, organization, &domain.OrganizationMember{
+			OrganizationID: organization.ID, UserID: user.ID, Role: domain.RoleAdmin, JoinedAt: now()}))
+
+		project := newProject(user.ID)
+		project.OrganizationID = organization.ID
+		must(t, repos.Projects.Create(ctx, project))
+		must(t, repos.Members.Add(ctx, &domain.Member{ProjectID: project.ID, UserID: user.ID, Role: domain.ProjectOwner,
+			JoinedAt: now()}))
+		var task *domain.Task
+		for j := 0; j < 4; j++ {
+			task = newTask(project.ID, user.ID)
+			task.OrganizationID = organization.ID
+			must(t, repos.Tasks.Create(ctx, task))
+		}
+		tenants[i] = tenant{organization, user, project, task}
+	}
+	mine, theirs := tenants[0], tenants[1]
+	id := mine.organization.ID
+
+	got, err := repos.Projects.Get(ctx, theirs.project.ID)
+	must(t, err)
+	if got.OrganizationID != theirs.organization.ID {
+		t.Fatalf("project should belong to %s, got %s", theirs.organization.ID, got.OrganizationID)
+	}
+	task, err := repos.Tasks.Get(ctx, theirs.task.ID)
+	must(t, err)
+	if task.OrganizationID != theirs.organization.ID {
+		t.Fatalf("task should belong to %s, got %s", theirs.organization.ID, task.OrganizationID)
+	}
+
+	for _, query := range []repository.ProjectQuery{
+		{OrganizationID: id},
+		{OrganizationID: id, ManagerID: theirs.user.ID},
+		{OrganizationID: id, MemberID: theirs.user.ID},
+	} {
+		page, err := repos.Projects.GetAll(ctx, query)
+		must(t, err)
+		for _, project := range page.Items {
+			if project.OrganizationID != id {
+				t.Fatalf("query %+v listed project %s of another organization", query, project.ID)
+			}
+		}
+	}
+
+	for _, spec := range []struct {
+		query repository.TaskQuery
+		want  int
+	}{
+		{repository.TaskQuery{OrganizationID: id}, 4},
+		{repository.TaskQuery{OrganizationID: id, Open: true}, 4},
+		{repository.TaskQuery{OrganizationID: id, ProjectID: theirs.project.ID}, 0},
+		{repository.TaskQuery{OrganizationID: id, Assignee: theirs.user.ID}, 0},
+	} {
+		query := spec.query
+		count, err := repos.Tasks.Count(ctx, query)
+		must(t, err)
+		if count != spec.want {
+			t.Fatalf("query %+v counted %d tasks, want %d", query, count, spec.want)
+		}
+
+		// Following cursors must not lead out of the organization either.
+		var listed int
+		query.Limit = 3
+		for pages := 0; ; pages++ {
+			if pages > 3 {
+				t.Fatal("pagination did not terminate")
+			}
+			page, err := repos.Tasks.GetAll(ctx, query)
+			must(t, err)
+			for _, task := range page.Items {
+				if task.OrganizationID != id {
+					t.Fatalf("query %+v listed task %s of another organization", query, task.ID)
+				}
+			}
+			listed += len(page.Items)
+			if page.NextCursor == "" {
+				break
+			}
+			query.Cursor = page.NextCursor
+		}
+		if listed != spec.want {
+			t.Fatalf("query %+v listed %d tasks, want %d", spec.query, listed, spec.want)
+		}
+	}
+
+	page, err := repos.Users.GetAll(ctx, repository.UserQuery{OrganizationID: id})
+	must(t, err)
+	if len(page.Items) != 1 || page.Items[0].ID != mine.user.ID {
+		t.Fatalf("members of %s should only be %s, got %+v", id, mine.user.ID, page.Items)
+	}
+	_, err = repos.Organizations.GetMember(ctx, id, theirs.user.ID)
+	expectErr(t, err, repository.ErrNotFound)
+
+	// Records keep the organization they were created in.
+	moved := *theirs.project
+	moved.OrganizationID = id
+	moved.Title = "Moved"
+	must(t, repos.Projects.Update(ctx, &moved))
+	got, err = repos.Projects.Get(ctx, moved.ID)
+	must(t, err)
+	if got.Title != "Moved" || got.OrganizationID != theirs.organization.ID {
+		t.Fatalf("update should keep the project in %s, got %+v", theirs.organization.ID, got)
+	}
+	movedTask := *theirs.task
+	movedTask.OrganizationID = id
+	must(t, repos.Tasks.Update(ctx, &movedTask))
+	task, err = repos.Tasks.Get(ctx, movedTask.ID)
+	must(t, err)
+	if task.OrganizationID != theirs.organization.ID {
+		t.Fatalf("update should keep the task in %s, got %s", theirs.organization.ID, task.OrganizationID)
+	}
+
+	orphan := newProject(uuid.Nil)
+	orphan.OrganizationID = uuid.New()
+	expectErr(t, repos.Projects.Create(ctx, orphan), repository.ErrReferenced)
+}
+
+func mentionIDs(comment *domain.Comment) string {
+	ids := make([]string, len(comment.Mentions))
+	for i, id := range comment.Mentions {
+		ids[i] = id.String()
+	}
+	return strings.Join(ids, ",")
+}
+
+func testComments(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	comments := repos.Comments
+
+	alice := newUser("alice@example.com")
+	must(t, repos.Users.Create(ctx, alice))
+	bob := newUser("bob@example.com")
+	must(t, repos.Users.Create(ctx, bob))
+	carol := newUser("carol@example.com")
+	must(t, repos.Users.Create(ctx, carol))
+	project := newProject(uuid.Nil)
+	must(t, repos.Projects.Create(ctx, project))
+	task := newTask(project.ID, uuid.Nil)
+	must(t, repos.Tasks.Create(ctx, task))
+	other := newTask(project.ID, uuid.Nil)
+	must(t, repos.Tasks.Create(ctx, other))
+
+	// Mentions come back ordered by ID.
+	mentions := []uuid.UUID{bob.ID, carol.ID}
+	if mentions[0].String() > mentions[1].String() {
+		mentions[0], mentions[1] = mentions[1], mentions[0]
+	}
+	root := &domain.Comment{ID: uuid.New(), TaskID: task.ID, AuthorID: alice.ID, Body: "Hi @bob@example.com",
+		Mentions: []uuid.UUID{mentions[1], mentions[0]}, CreatedAt: now()}
+	must(t, comments.Create(ctx, root))
+	reply := &domain.Comment{ID: uuid.New(), TaskID: task.ID, ParentID: root.ID, AuthorID: bob.ID, Body: "Hello",
+		Mentions: []uuid.UUID{}, CreatedAt: now().Add(time.Second)}
+	must(t, comments.Create(ctx, reply))
+	must(t, comments.Create(ctx, &domain.Comment{ID: uuid.New(), TaskID: other.ID, AuthorID: bob.ID, Body: "Elsewhere",
+		CreatedAt: now()}))
+
+	expectErr(t, comments.Create(ctx, root), repository.ErrDuplicate)
+	orphan := *reply
+	orphan.ID, orphan.TaskID = uuid.New(), uuid.New()
+	expectErr(t, comments.Create(ctx, &orphan), repository.ErrReferenced)
+	orphan.TaskID, orphan.ParentID = task.ID, uuid.New()
+	expectErr(t, comments.Create(ctx, &orphan), repository.ErrReferenced)
+	orphan.ParentID, orphan.Mentions = uuid.Nil, []uuid.UUID{uuid.New()}
+	expectErr(t, comments.Create(ctx, &orphan), repository.ErrReferenced)
+
+	got, err := comments.Get(ctx, root.ID)
+	must(t, err)
+	if got.TaskID != task.ID || got.ParentID != uuid.Nil || got.AuthorID != alice.ID || got.Body != root.Body ||
+		!got.CreatedAt.Equal(root.CreatedAt) || !got.EditedAt.IsZero() || !got.DeletedAt.IsZero() ||
+		mentionIDs(got) != mentions[0].String()+","+mentions[1].String() {
+		t.Fatalf("comment did not round-trip: got %+v, want %+v", got, root)
+	}
+	_, err = comments.Get(ctx, uuid.New())
+	expectErr(t, err, repository.ErrNotFound)
+
+	list, err := comments.List(ctx, task.ID)
+	must(t, err)
+	if len(list) != 2 || list[0].ID != root.ID || list[1].ID != reply.ID || list[1].ParentID != root.ID ||
+		len(list[0].Mentions) != 2 || list[1].Mentions == nil || len(list[1].Mentions) != 0 {
+		t.Fatalf("List should return the comments oldest first with their mentions, got %+v", list)
+	}
+
+	edited := now().Add(time.Minute)
+	root.Body, root.Mentions, root.EditedAt = "Hi @carol@example.com", []uuid.UUID{carol.ID}, edited
+	first := &domain.CommentRevision{ID: uuid.New(), CommentID: root.ID, Body: "Hi @bob@example.com", EditorID: alice.ID, EditedAt: edited}
+	must(t, comments.Update(ctx, root, first))
+	root.Body, root.EditedAt = "Hi all", edited.Add(time.Minute)
+	second := &domain.CommentRevision{ID: uuid.New(), CommentID: root.ID, Body: "Hi @carol@example.com", EditorID: bob.ID, EditedAt: root.EditedAt}
+	must(t, comments.Update(ctx, root, second))
+	expectErr(t, comments.Update(ctx, &domain.Comment{ID: uuid.New(), Body: "x"},
+		&domain.CommentRevision{ID: uuid.New(), EditedAt: now()}), repository.ErrNotFound)
+
+	got, err = comments.Get(ctx, root.ID)
+	must(t, err)
+	if got.Body != "Hi all" || !got.EditedAt.Equal(root.EditedAt) || mentionIDs(got) != carol.ID.String() {
+		t.Fatalf("update was not persisted: %+v", got)
+	}
+	revisions, err := comments.ListRevisions(ctx, root.ID)
+	must(t, err)
+	if len(revisions) != 2 || revisions[0].ID != first.ID || revisions[1].ID != second.ID ||
+		revisions[0].Body != first.Body || revisions[0].EditorID != alice.ID || !revisions[1].EditedAt.Equal(second.EditedAt) {
+		t.Fatalf("ListRevisions should return the revisions oldest first, got %+v", revisions)
+	}
+	revisions, err = comments.ListRevisions(ctx, reply.ID)
+	must(t, err)
+	if len(revisions) != 0 {
+		t.Fatalf("a comment that was never edited has revisions: %+v", revisions)
+	}
+
+	// Deleting a user clears it from comments without removing them.
+	must(t, repos.Users.Delete(ctx, carol.ID))
+	must(t, repos.Users.Delete(ctx, alice.ID))
+	got, err = comments.Get(ctx, root.ID)
+	must(t, err)
+	if got.AuthorID != uuid.Nil || len(got.Mentions) != 0 {
+		t.Fatalf("deleted users remain on the comment: %+v", got)
+	}
+	revisions, err = comments.ListRevisions(ctx, root.ID)
+	must(t, err)
+	if len(revisions) != 2 || revisions[0].EditorID != uuid.Nil || revisions[1].EditorID != bob.ID {
+		t.Fatalf("deleted editors remain on the revisions: %+v", revisions)
+	}
+
+	deletedAt := now()
+	must(t, comments.Delete(ctx, root.ID, deletedAt))
+	expectErr(t, comments.Delete(ctx, uuid.New(), deletedAt), repository.ErrNotFound)
+	got, err = comments.Get(ctx, root.ID)
+	must(t, err)
+	if got.Body != "" || !got.DeletedAt.Equal(deletedAt) || len(got.Mentions) != 0 {
+		t.Fatalf("deleted comment was not emptied: %+v", got)
+	}
+	revisions, err = comments.ListRevisions(ctx, root.ID)
+	must(t, err)
+	if len(revisions) != 0 {
+		t.Fatalf("revisions of a deleted comment remain: %+v", revisions)
+	}
+	list, err = comments.List(ctx, task.ID)
+	must(t, err)
+	if len(list) != 2 {
+		t.Fatalf("deleted comments should stay in the thread, got %+v", list)
+	}
+
+	// Comments go away with their task, and with the project's tasks.
+	must(t, repos.Tasks.Delete(ctx, task.ID))
+	_, err = comments.Get(ctx, reply.ID)
+	expectErr(t, err, repository.ErrNotFound)
+	must(t, repos.Projects.DeleteWithTasks(ctx, project.ID))
+	list, err = comments.List(ctx, other.ID)
+	must(t, err)
+	if len(list) != 0 {
+		t.Fatalf("comments of a deleted project remain: %+v", list)
+	}
+}
+
+func newAttachment(owner domain.AttachmentOwner, uploader uuid.UUID) *domain.Attachment {
+	id := uuid.New()
+	return &domain.Attachment{
+		ID:          id,
+		ProjectID:   owner.ProjectID,
+		TaskID:      owner.TaskID,
+		Filename:    "spec.pdf",
+		ContentType: "application/pdf",
+		Size:        1234,
+		Checksum:    "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		Key:         "attachments/" + id.String(),
+		UploaderID:  uploader,
+		CreatedAt:   now(),
+	}
+}
+
+func attachmentIDs(attachments []domain.Attachment) []uuid.UUID {
+	ids := make([]uuid.UUID, len(attachments))
+	for i, attachment := range attachments {
+		ids[i] = attachment.ID
+	}
+	return ids
+}
+
+func testAttachments(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	attachments := repos.Attachments
+
+	uploader := newUser("uploader@example.com")
+	must(t, repos.Users.Create(ctx, uploader))
+	project := newProject(uuid.Nil)
+	must(t, repos.Projects.Create(ctx, project))
+	task := newTask(project.ID, uuid.Nil)
+	must(t, repos.Tasks.Create(ctx, task))
+	other := newProject(uuid.Nil)
+	must(t, repos.Projects.Create(ctx, other))
+
+	spec := newAttachment(domain.AttachmentOwner{ProjectID: project.ID}, uploader.ID)
+	must(t, attachments.Create(ctx, spec))
+	screenshot := newAttachment(domain.AttachmentOwner{TaskID: task.ID}, uploader.ID)
+	screenshot.CreatedAt = now().Add(time.Second)
+	must(t, attachments.Create(ctx, screenshot))
+	log := newAttachment(domain.AttachmentOwner{TaskID: task.ID}, uploader.ID)
+	log.CreatedAt = now().Add(2 * time.Second)
+	must(t, attachments.Create(ctx, log))
+	must(t, attachments.Create(ctx, newAttachment(domain.AttachmentOwner{ProjectID: other.ID}, uploader.ID)))
+
+	expectErr(t, attachments.Create(ctx, spec), repository.ErrDuplicate)
+	expectErr(t, attachments.Create(ctx, newAttachment(domain.AttachmentOwner{TaskID: uuid.New()}, uploader.ID)), repository.ErrReferenced)
+	expectErr(t, attachments.Create(ctx, newAttachment(domain.AttachmentOwner{ProjectID: uuid.New()}, uploader.ID)), repository.ErrReferenced)
+	expectErr(t, attachments.Create(ctx, newAttachment(domain.AttachmentOwner{ProjectID: project.ID}, uuid.New())), repository.ErrReferenced)
+
+	got, err := attachments.Get(ctx, screenshot.ID)
+	must(t, err)
+	want := *screenshot
+	want.CreatedAt = got.CreatedAt
+	if *got != want || !got.CreatedAt.Equal(screenshot.CreatedAt) {
+		t.Fatalf("attachment did not round-trip: got %+v, want %+v", got, screenshot)
+	}
+	_, err = attachments.Get(ctx, uuid.New())
+	expectErr(t, err, repository.ErrNotFound)
+
+	list, err := attachments.List(ctx, domain.AttachmentOwner{TaskID: task.ID})
+	must(t, err)
+	if fmt.Sprint(attachmentIDs(list)) != fmt.Sprint([]uuid.UUID{screenshot.ID, log.ID}) {
+		t.Fatalf("List should return the task's attachments oldest first, got %+v", list)
+	}
+	list, err = attachments.List(ctx, domain.AttachmentOwner{ProjectID: project.ID})
+	must(t, err)
+	if len(list) != 1 || list[0].ID != spec.ID {
+		t.Fatalf("List of a project should leave out its tasks' attachments, got %+v", list)
+	}
+	list, err = attachments.ListInProject(ctx, project.ID)
+	must(t, err)
+	if fmt.Sprint(attachmentIDs(list)) != fmt.Sprint([]uuid.UUID{spec.ID, screenshot.ID, log.ID}) {
+		t.Fatalf("ListInProject should include the tasks' attachments, got %+v", list)
+	}
+
+	must(t, attachments.Delete(ctx, log.ID))
+	expectErr(t, attachments.Delete(ctx, log.ID), repository.ErrNotFound)
+
+	// Deleting the uploader only clears it.
+	must(t, repos.Users.Delete(ctx, uploader.ID))
+	got, err = attachments.Get(ctx, spec.ID)
+	must(t, err)
+	if got.UploaderID != uuid.Nil {
+		t.Fatalf("deleted uploader remains on the attachment: %+v", got)
+	}
+
+	// Attachments go away with their task or project.
+	must(t, repos.Tasks.Delete(ctx, task.ID))
+	_, err = attachments.Get(ctx, screenshot.ID)
+	expectErr(t, err, repository.ErrNotFound)
+	must(t, repos.Projects.Delete(ctx, project.ID))
+	_, err = attachments.Get(ctx, spec.ID)
+	expectErr(t, err, repository.ErrNotFound)
+	task = newTask(other.ID, uuid.Nil)
+	must(t, repos.Tasks.Create(ctx, task))
+	must(t, attachments.Create(ctx, newAttachment(domain.AttachmentOwner{TaskID: task.ID}, uuid.Nil)))
+	must(t, repos.Projects.DeleteWithTasks(ctx, other.ID))
+	list, err = attachments.ListInProject(ctx, other.ID)
+	must(t, err)
+	if len(list) != 0 {
+		t.Fatalf("attachments of a deleted project remain: %+v", list)
+	}
+}
+
+func dependencyPairs(dependencies []domain.Dependency) string {
+	pairs := make([]string, len(dependencies))
+	for i, dependency := range dependencies {
+		pairs[i] = dependency.TaskID.String() + "<-" + dependency.BlockerID.String()
+	}
+	return strings.Join(pairs, " ")
+}
+
+func testDependencies(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	dependencies := repos.Dependencies
+
+	project := newProject(uuid.Nil)
+	must(t, repos.Projects.Create(ctx, project))
+	other := newProject(uuid.Nil)
+	must(t, repos.Projects.Create(ctx, other))
+	// release waits on build, which waits on design and on docs in another
+	// project; design also blocks docs.
+	release, build, design, docs := newTask(project.ID, uuid.Nil), newTask(project.ID, uuid.Nil),
+		newTask(project.ID, uuid.Nil), newTask(other.ID, uuid.Nil)
+	for _, task := range []*domain.Task{release, build, design, docs} {
+		must(t, repos.Tasks.Create(ctx, task))
+	}
+	anything := func([]domain.Dependency) error { return nil }
+	link := func(task, blocker *domain.Task, offset time.Duration) domain.Dependency {
+		dependency := domain.Dependency{TaskID: task.ID, BlockerID: blocker.ID, CreatedAt: now().Add(offset)}
+		must(t, dependencies.Add(ctx, &dependency, anything))
+		return dependency
+	}
+	releaseBuild := link(release, build, 0)
+	buildDesign := link(build, design, time.Second)
+	buildDocs := link(build, docs, 2*time.Second)
+	docsDesign := link(docs, design, 3*time.Second)
+
+	expectErr(t, dependencies.Add(ctx, &releaseBuild, anything), repository.ErrDuplicate)
+	expectErr(t, dependencies.Add(ctx, &domain.Dependency{TaskID: release.ID, BlockerID: uuid.New(), CreatedAt: now()}, anything), repository.ErrReferenced)
+	expectErr(t, dependencies.Add(ctx, &domain.Dependency{TaskID: uuid.New(), BlockerID: release.ID, CreatedAt: now()}, anything), repository.ErrReferenced)
+
+	// Add checks the links behind the blocker before adding anything.
+	refused := errors.New("refused")
+	var checked []domain.Dependency
+	err := dependencies.Add(ctx, &domain.Dependency{TaskID: design.ID, BlockerID: build.ID, CreatedAt: now()},
+		func(blockers []domain.Dependency) error {
+			checked = blockers
+			return refused
+		})
+	expectErr(t, err, refused)
+	if want := []domain.Dependency{buildDesign, buildDocs, docsDesign}; dependencyPairs(checked) != dependencyPairs(want) {
+		t.Fatalf("Add checked %s, want the links behind the blocker %s", dependencyPairs(checked), dependencyPairs(want))
+	}
+
+	got, err := dependencies.Blockers(ctx, release.ID)
+	must(t, err)
+	if want := []domain.Dependency{releaseBuild, buildDesign, buildDocs, docsDesign}; dependencyPairs(got) != dependencyPairs(want) {
+		t.Fatalf("Blockers should walk every link behind the task oldest first, got %s, want %s", dependencyPairs(got), dependencyPairs(want))
+	}
+	if !got[0].CreatedAt.Equal(releaseBuild.CreatedAt) {
+		t.Fatalf("dependency did not round-trip: got %+v, want %+v", got[0], releaseBuild)
+	}
+	got, err = dependencies.Dependents(ctx, design.ID)
+	must(t, err)
+	if want := []domain.Dependency{releaseBuild, buildDesign, buildDocs, docsDesign}; dependencyPairs(got) != dependencyPairs(want) {
+		t.Fatalf("Dependents should walk every link in front of the task, got %s, want %s", dependencyPairs(got), dependencyPairs(want))
+	}
+	got, err = dependencies.Dependents(ctx, docs.ID)
+	must(t, err)
+	if want := []domain.Dependency{releaseBuild, buildDocs}; dependencyPairs(got) != dependencyPairs(want) {
+		t.Fatalf("Dependents of docs: got %s, want %s", dependencyPairs(got), dependencyPairs(want))
+	}
+	got, err = dependencies.Blockers(ctx, design.ID)
+	must(t, err)
+	if len(got) != 0 {
+		t.Fatalf("design waits on nothing, got %s, even after a refused link", dependencyPairs(got))
+	}
+
+	must(t, dependencies.Remove(ctx, docs.ID, design.ID))
+	expectErr(t, dependencies.Remove(ctx, docs.ID, design.ID), repository.ErrNotFound)
+
+	// Links go away with either of their tasks.
+	must(t, repos.Tasks.Delete(ctx, build.ID))
+	got, err = dependencies.Dependents(ctx, design.ID)
+	must(t, err)
+	if len(got) != 0 {
+		t.Fatalf("links of a deleted task remain: %s", dependencyPairs(got))
+	}
+	got, err = dependencies.Blockers(ctx, release.ID)
+	must(t, err)
+	if len(got) != 0 {
+		t.Fatalf("links of a deleted task remain: %s", dependencyPairs(got))
+	}
+	link(release, docs, 4*time.Second)
+	must(t, repos.Projects.DeleteWithTasks(ctx, other.ID))
+	got, err = dependencies.Blockers(ctx, release.ID)
+	must(t, err)
+	if len(got) != 0 {
+		t.Fatalf("links to the tasks of a deleted project remain: %s", dependencyPairs(got))
+	}
+}
+
+func taskIDs(tasks []domain.Task) []uuid.UUID {
+	ids := make([]uuid.UUID, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	return ids
+}
+
+func testSubtasks(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	tasks := repos.Tasks
+
+	project := newProject(uuid.Nil)
+	must(t, repos.Projects.Create(ctx, project))
+	other := newProject(uuid.Nil)
+	must(t, repos.Projects.Create(ctx, other))
+
+	// epic has two stories; the first story has a sub-task.
+	epic := newTask(project.ID, uuid.Nil)
+	must(t, tasks.Create(ctx, epic))
+	story := newTask(project.ID, uuid.Nil)
+	story.ParentID = epic.ID
+	story.CreatedAt = now().Add(time.Second)
+	must(t, tasks.Create(ctx, story))
+	subtask := newTask(project.ID, uuid.Nil)
+	subtask.ParentID = story.ID
+	subtask.CreatedAt = now().Add(2 * time.Second)
+	must(t, tasks.Create(ctx, subtask))
+	second := newTask(project.ID, uuid.Nil)
+	second.ParentID = epic.ID
+	second.CreatedAt = now().Add(3 * time.Second)
+	must(t, tasks.Create(ctx, second))
+
+	orphan := newTask(project.ID, uuid.Nil)
+	orphan.ParentID = uuid.New()
+	expectErr(t, tasks.Create(ctx, orphan), repository.ErrReferenced)
+
+	got, err := tasks.Get(ctx, subtask.ID)
+	must(t, err)
+	if got.ParentID != story.ID {
+		t.Fatalf("parent did not round-trip: got %+v", got)
+	}
+
+	list, err := tasks.Subtasks(ctx, epic.ID)
+	must(t, err)
+	if fmt.Sprint(taskIDs(list)) != fmt.Sprint([]uuid.UUID{story.ID, subtask.ID, second.ID}) {
+		t.Fatalf("Subtasks should return every task below the epic oldest first, got %v", taskIDs(list))
+	}
+	list, err = tasks.Subtasks(ctx, subtask.ID)
+	must(t, err)
+	if len(list) != 0 {
+		t.Fatalf("a task without subtasks has none, got %v", taskIDs(list))
+	}
+	page, err := tasks.GetAll(ctx, repository.TaskQuery{ParentID: epic.ID})
+	must(t, err)
+	if fmt.Sprint(taskIDs(page.Items)) != fmt.Sprint([]uuid.UUID{story.ID, second.ID}) {
+		t.Fatalf("filtering by parent should return the direct subtasks, got %v", taskIDs(page.Items))
+	}
+
+	// Moving a task takes its subtasks along; detaching it from its parent
+	// leaves the parent's other subtasks alone.
+	story.ProjectID = other.ID
+	story.ParentID = uuid.Nil
+	must(t, tasks.Update(ctx, story))
+	for _, id := range []uuid.UUID{story.ID, subtask.ID} {
+		got, err := tasks.Get(ctx, id)
+		must(t, err)
+		if got.ProjectID != other.ID {
+			t.Fatalf("task %s did not move along with its parent: %+v", id, got)
+		}
+	}
+	got, err = tasks.Get(ctx, second.ID)
+	must(t, err)
+	if got.ProjectID != project.ID {
+		t.Fatalf("a sibling moved along: %+v", got)
+	}
+	list, err = tasks.Subtasks(ctx, epic.ID)
+	must(t, err)
+	if len(list) != 1 || list[0].ID != second.ID {
+		t.Fatalf("a detached task is still below its old parent: %v", taskIDs(list))
+	}
+
+	// Subtasks go away with their parent.
+	must(t, tasks.Delete(ctx, story.ID))
+	_, err = tasks.Get(ctx, subtask.ID)
+	expectErr(t, err, repository.ErrNotFound)
+	must(t, repos.Projects.DeleteWithTasks(ctx, project.ID))
+	_, err = tasks.Get(ctx, second.ID)
+	expectErr(t, err, repository.ErrNotFound)
+}
+
+func newLabel(projectID uuid.UUID, name string) *domain.Label {
+	return &domain.Label{
+		ID:          uuid.New(),
+		ProjectID:   projectID,
+		Name:        name,
+		Color:       domain.DefaultLabelColor,
+		Description: "A label",
+		CreatedAt:   now(),
+	}
+}
+
+func labelIDs(labels []domain.Label) []uuid.UUID {
+	ids := make([]uuid.UUID, len(labels))
+	for i, label := range labels {
+		ids[i] = label.ID
+	}
+	return ids
+}
+
+func testLabels(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	labels, tasks := repos.Labels, repos.Tasks
+
+	project := newProject(uuid.Nil)
+	must(t, repos.Projects.Create(ctx, project))
+	other := newProject(uuid.Nil)
+	must(t, repos.Projects.Create(ctx, other))
+
+	bug := newLabel(project.ID, "bug")
+	must(t, labels.Create(ctx, bug))
+	frontend := newLabel(project.ID, "frontend")
+	must(t, labels.Create(ctx, frontend))
+	defect := newLabel(project.ID, "defect")
+	must(t, labels.Create(ctx, defect))
+	elsewhere := newLabel(other.ID, "Bug")
+	must(t, labels.Create(ctx, elsewhere))
+
+	expectErr(t, labels.Create(ctx, newLabel(project.ID, "BUG")), repository.ErrDuplicate)
+	expectErr(t, labels.Create(ctx, newLabel(uuid.New(), "bug")), repository.ErrReferenced)
+
+	got, err := labels.Get(ctx, bug.ID)
+	must(t, err)
+	want := *bug
+	want.CreatedAt = got.CreatedAt
+	if *got != want || !got.CreatedAt.Equal(bug.CreatedAt) {
+		t.Fatalf("label did not round-trip: got %+v, want %+v", got, bug)
+	}
+	list, err := labels.List(ctx, project.ID)
+	must(t, err)
+	if fmt.Sprint(labelIDs(list)) != fmt.Sprint([]uuid.UUID{bug.ID, defect.ID, frontend.ID}) {
+		t.Fatalf("List should return the project's labels by name, got %v", labelIDs(list))
+	}
+
+	// Tasks are read with their labels, ordered by name.
+	both := newTask(project.ID, uuid.Nil)
+	both.Labels = []uuid.UUID{frontend.ID, bug.ID}
+	must(t, tasks.Create(ctx, both))
+	onlyDefect := newTask(project.ID, uuid.Nil)
+	onlyDefect.Labels = []uuid.UUID{defect.ID}
+	onlyDefect.CreatedAt = now().Add(time.Second)
+	must(t, tasks.Create(ctx, onlyDefect))
+	plain := newTask(project.ID, uuid.Nil)
+	plain.CreatedAt = now().Add(2 * time.Second)
+	must(t, tasks.Create(ctx, plain))
+	broken := newTask(project.ID, uuid.Nil)
+	broken.Labels = []uuid.UUID{uuid.New()}
+	expectErr(t, tasks.Create(ctx, broken), repository.ErrReferenced)
+
+	expectLabels := func(taskID uuid.UUID, want ...uuid.UUID) {
+		t.Helper()
+		got, err := tasks.Get(ctx, taskID)
+		must(t, err)
+		if fmt.Sprint(got.Labels) != fmt.Sprint(append([]uuid.UUID{}, want...)) {
+			t.Fatalf("task %s should have labels %v, got %v", taskID, want, got.Labels)
+		}
+	}
+	expectLabels(both.ID, bug.ID, frontend.ID)
+	expectLabels(plain.ID)
+
+	filter := func(all bool, names ...string) []uuid.UUID {
+		t.Helper()
+		query := repository.TaskQuery{Labels: names, AllLabels: all}
+		page, err := tasks.GetAll(ctx, query)
+		must(t, err)
+		n, err := tasks.Count(ctx, query)
+		must(t, err)
+		if n != len(page.Items) {
+			t.Fatalf("Count gave %d tasks for labels %v, GetAll %d", n, names, len(page.Items))
+		}
+		return taskIDs(page.Items)
+	}
+	if got := filter(false, "BUG", "defect"); fmt.Sprint(got) != fmt.Sprint([]uuid.UUID{both.ID, onlyDefect.ID}) {
+		t.Fatalf("filtering by any label should match either, got %v", got)
+	}
+	if got := filter(true, "bug", "Frontend", "bug"); fmt.Sprint(got) != fmt.Sprint([]uuid.UUID{both.ID}) {
+		t.Fatalf("filtering by all labels should match both, got %v", got)
+	}
+	if got := filter(true, "bug", "defect"); len(got) != 0 {
+		t.Fatalf("no task has both bug and defect, got %v", got)
+	}
+	page, err := tasks.GetAll(ctx, repository.TaskQuery{ProjectID: project.ID})
+	must(t, err)
+	if len(page.Items) != 3 || fmt.Sprint(page.Items[0].Labels) != fmt.Sprint([]uuid.UUID{bug.ID, frontend.ID}) {
+		t.Fatalf("listed tasks should carry their labels, got %+v", page.Items)
+	}
+
+	// Renaming a label shows on its tasks at once and reorders them.
+	bug.Name = "zz-bug"
+	bug.Color = "#d73a4a"
+	must(t, labels.Update(ctx, bug))
+	expectLabels(both.ID, frontend.ID, bug.ID)
+	defect.Name = "Frontend"
+	expectErr(t, labels.Update(ctx, defect), repository.ErrDuplicate)
+	defect.Name = "defect"
+	expectErr(t, labels.Update(ctx, newLabel(project.ID, "new")), repository.ErrNotFound)
+
+	// Updating a task replaces its labels.
+	plain.Labels = []uuid.UUID{defect.ID, bug.ID}
+	must(t, tasks.Update(ctx, plain))
+	expectLabels(plain.ID, defect.ID, bug.ID)
+
+	// Merging moves the source's tasks onto the target, once each.
+	must(t, labels.Merge(ctx, defect.ID, bug.ID))
+	_, err = labels.Get(ctx, defect.ID)
+	expectErr(t, err, repository.ErrNotFound)
+	expectLabels(onlyDefect.ID, bug.ID)
+	expectLabels(plain.ID, bug.ID)
+	expectErr(t, labels.Merge(ctx, defect.ID, bug.ID), repository.ErrNotFound)
+	expectErr(t, labels.Merge(ctx, frontend.ID, uuid.New()), repository.ErrNotFound)
+	expectLabels(both.ID, frontend.ID, bug.ID)
+
+	// Deleting a label takes it off its tasks.
+	must(t, labels.Delete(ctx, frontend.ID))
+	expectLabels(both.ID, bug.ID)
+	expectErr(t, labels.Delete(ctx, frontend.ID), repository.ErrNotFound)
+
+	// Subtasks moving with their parent lose the labels of the old project.
+	child := newTask(project.ID, uuid.Nil)
+	child.ParentID = both.ID
+	child.Labels = []uuid.UUID{bug.ID}
+	must(t, tasks.Create(ctx, child))
+	both.ProjectID = other.ID
+	both.Labels = []uuid.UUID{elsewhere.ID}
+	must(t, tasks.Update(ctx, both))
+	expectLabels(both.ID, elsewhere.ID)
+	expectLabels(child.ID)
+
+	// Labels go away with their project.
+	must(t, repos.Projects.DeleteWithTasks(ctx, project.ID))
+	_, err = labels.Get(ctx, bug.ID)
+	expectErr(t, err, repository.ErrNotFound)
+	list, err = labels.List(ctx, other.ID)
+	must(t, err)
+	if len(list) != 1 {
+		t.Fatalf("another project's labels went away, got %v", labelIDs(list))
+	}
+}
+
+func testNotifications(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	notifications := repos.Notifications
+
+	user := newUser("notified@example.com")
+	must(t, repos.Users.Create(ctx, user))
+	project := newProject(uuid.Nil)
+	must(t, repos.Projects.Create(ctx, project))
+	task := newTask(project.ID, user.ID)
+	task.DueDate = now().Add(time.Hour)
+	must(t, repos.Tasks.Create(ctx, task))
+	other := newTask(project.ID, user.ID)
+	other.DueDate = now().Add(-time.Hour)
+	must(t, repos.Tasks.Create(ctx, other))
+
+	newNotification := func(task *domain.Task, kind domain.NotificationKind, createdAt time.Time) *domain.Notification {
+		return &domain.Notification{
+			ID:        uuid.New(),
+			UserID:    user.ID,
+			TaskID:    task.ID,
+			Kind:      kind,
+			DueDate:   task.DueDate,
+			Message:   "reminder",
+			CreatedAt: createdAt,
+		}
+	}
+	dueSoon := newNotification(task, domain.NotificationDueSoon, now().Add(-time.Minute))
+	must(t, notifications.Create(ctx, dueSoon))
+	overdue := newNotification(other, domain.NotificationOverdue, now())
+	must(t, notifications.Create(ctx, overdue))
+
+	got, err := notifications.Get(ctx, dueSoon.ID)
+	must(t, err)
+	if got.UserID != user.ID || got.TaskID != task.ID || got.Kind != domain.NotificationDueSoon ||
+		!got.DueDate.Equal(task.DueDate) || got.Message != "reminder" ||
+		!got.CreatedAt.Equal(dueSoon.CreatedAt) || !got.ReadAt.IsZero() {
+		t.Fatalf("notification did not round-trip: got %+v, want %+v", got, dueSoon)
+	}
+	_, err = notifications.Get(ctx, uuid.New())
+	expectErr(t, err, repository.ErrNotFound)
+
+	// A task is reminded of once per kind and due date.
+	expectErr(t, notifications.Create(ctx, newNotification(task, domain.NotificationDueSoon, now())),
+		repository.ErrDuplicate)
+	moved := *task
+	moved.DueDate = task.DueDate.Add(time.Hour)
+	must(t, notifications.Create(ctx, newNotification(&moved, domain.NotificationDueSoon, now().Add(-time.Hour))))
+	orphan := newNotification(task, domain.NotificationOverdue, now())
+	orphan.UserID = uuid.New()
+	expectErr(t, notifications.Create(ctx, orphan), repository.ErrReferenced)
+	expectErr(t, notifications.Create(ctx, newNotification(newTask(project.ID, user.ID), domain.NotificationOverdue, now())),
+		repository.ErrReferenced)
+
+	// Tasks can be told apart by whether they were reminded of their current
+	// due date.
+	unreminded := func(kind domain.NotificationKind) []uuid.UUID {
+		t.Helper()
+		page, err := repos.Tasks.GetAll(ctx, repository.TaskQuery{ProjectID: project.ID, Unreminded: kind})
+		must(t, err)
+		ids := []uuid.UUID{}
+		for _, task := range page.Items {
+			ids = append(ids, task.ID)
+		}
+		return ids
+	}
+	if got := unreminded(domain.NotificationDueSoon); fmt.Sprint(got) != fmt.Sprint([]uuid.UUID{other.ID}) {
+		t.Fatalf("tasks not reminded of being due soon: got %v, want %v", got, other.ID)
+	}
+	if got := unreminded(domain.NotificationOverdue); fmt.Sprint(got) != fmt.Sprint([]uuid.UUID{task.ID}) {
+		t.Fatalf("tasks not reminded of being overdue: got %v, want %v", got, task.ID)
+	}
+
+	list, err := notifications.List(ctx, uuid.Nil, user.ID, false)
+	must(t, err)
+	if len(list) != 3 || list[0].ID != overdue.ID || list[1].ID != dueSoon.ID {
+		t.Fatalf("List should return the newest notification first, got %+v", list)
+	}
+	list, err = notifications.List(ctx, testOrganization, user.ID, false)
+	must(t, err)
+	if len(list) != 3 {
+		t.Fatalf("List in the tasks' organization returned %+v", list)
+	}
+	list, err = notifications.List(ctx, uuid.New(), user.ID, false)
+	must(t, err)
+	if len(list) != 0 {
+		t.Fatalf("List in another organization returned %+v", list)
+	}
+
+	readAt := now()
+	must(t, notifications.MarkRead(ctx, overdue.ID, readAt))
+	must(t, notifications.MarkRead(ctx, overdue.ID, readAt.Add(time.Minute)))
+	got, err = notifications.Get(ctx, overdue.ID)
+	must(t, err)
+	if !got.ReadAt.Equal(readAt) {
+		t.Fatalf("notification should be read at %v, got %v", readAt, got.ReadAt)
+	}
+	expectErr(t, notifications.MarkRead(ctx, uuid.New(), now()), repository.ErrNotFound)
+	list, err = notifications.List(ctx, uuid.Nil, user.ID, true)
+	must(t, err)
+	if len(list) != 2 || list[0].ID != dueSoon.ID {
+		t.Fatalf("List of unread notifications returned %+v", list)
+	}
+
+	must(t, notifications.MarkAllRead(ctx, uuid.New(), user.ID, readAt.Add(time.Hour)))
+	list, err = notifications.List(ctx, uuid.Nil, user.ID, true)
+	must(t, err)
+	if len(list) != 2 {
+		t.Fatalf("MarkAllRead in another organization should leave them unread, got %+v", list)
+	}
+	must(t, notifications.MarkAllRead(ctx, testOrganization, user.ID, readAt.Add(time.Hour)))
+	list, err = notifications.List(ctx, uuid.Nil, user.ID, true)
+	must(t, err)
+	if len(list) != 0 {
+		t.Fatalf("no notification should be unread, got %+v", list)
+	}
+	got, err = notifications.Get(ctx, overdue.ID)
+	must(t, err)
+	if !got.ReadAt.Equal(readAt) {
+		t.Fatalf("MarkAllRead should keep when a notification was first read, got %v", got.ReadAt)
+	}
+
+	// Notifications go away with their task and their user.
+	must(t, repos.Tasks.Delete(ctx, other.ID))
+	_, err = notifications.Get(ctx, overdue.ID)
+	expectErr(t, err, repository.ErrNotFound)
+	must(t, repos.Users.DeleteReassigning(ctx, user.ID, uuid.Nil))
+	_, err = notifications.Get(ctx, dueSoon.ID)
+	expectErr(t, err, repository.ErrNotFound)
+}
+
+func worklogIDs(worklogs []domain.Worklog) []uuid.UUID {
+	ids := make([]uuid.UUID, len(worklogs))
+	for i, worklog := range worklogs {
+		ids[i] = worklog.ID
+	}
+	return ids
+}
+
+func testWorklogs(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	worklogs := repos.Worklogs
+	// logWork records the changes in seconds logged it is called with and
+	// takes them off the remaining estimate as they are.
+	var logged []int64
+	logWork := func(task *domain.Task, seconds int64) {
+		logged = append(logged, seconds)
+		task.RemainingEstimate -= seconds
+	}
+
+	ada := newUser("ada@example.com")
+	must(t, repos.Users.Create(ctx, ada))
+	bob := newUser("bob@example.com")
+	must(t, repos.Users.Create(ctx, bob))
+	project := newProject(uuid.Nil)
+	must(t, repos.Projects.Create(ctx, project))
+	task := newTask(project.ID, ada.ID)
+	must(t, repos.Tasks.Create(ctx, task))
+	otherProject := newProject(uuid.Nil)
+	must(t, repos.Projects.Create(ctx, otherProject))
+	other := newTask(otherProject.ID, ada.ID)
+	must(t, repos.Tasks.Create(ctx, other))
+
+	day := time.Now().UTC().Truncate(24 * time.Hour).Add(-48 * time.Hour)
+	newWorklog := func(task *domain.Task, user *domain.User, startedAt time.Time, seconds int64) *domain.Worklog {
+		worklog := &domain.Worklog{
+			ID:        uuid.New(),
+			TaskID:    task.ID,
+			UserID:    user.ID,
+			StartedAt: startedAt,
+			Seconds:   seconds,
+			Note:      "work",
+			CreatedAt: now(),
+		}
+		if seconds > 0 {
+			worklog.EndedAt = startedAt.Add(time.Duration(seconds) * time.Second)
+		}
+		return worklog
+	}
+	first := newWorklog(task, ada, day.Add(9*time.Hour), 3600)
+	must(t, worklogs.Create(ctx, first, logWork))
+	expectErr(t, worklogs.Create(ctx, first, logWork), repository.ErrDuplicate)
+	second := newWorklog(other, ada, day.Add(24*time.Hour), 1800)
+	must(t, worklogs.Create(ctx, second, logWork))
+	third := newWorklog(task, bob, day.Add(12*time.Hour), 600)
+	must(t, worklogs.Create(ctx, third, logWork))
+
+	got, err := worklogs.Get(ctx, first.ID)
+	must(t, err)
+	if got.TaskID != task.ID || got.UserID != ada.ID || !got.StartedAt.Equal(first.StartedAt) ||
+		!got.EndedAt.Equal(first.EndedAt) || got.Seconds != 3600 || got.Note != "work" ||
+		!got.CreatedAt.Equal(first.CreatedAt) || got.Running() {
+		t.Fatalf("worklog did not round-trip: got %+v, want %+v", got, first)
+	}
+	_, err = worklogs.Get(ctx, uuid.New())
+	expectErr(t, err, repository.ErrNotFound)
+	expectErr(t, worklogs.Create(ctx, newWorklog(newTask(project.ID, ada.ID), ada, day, 60), logWork),
+		repository.ErrReferenced)
+	expectErr(t, worklogs.Create(ctx, newWorklog(task, newUser("ghost@example.com"), day, 60), logWork),
+		repository.ErrReferenced)
+
+	// Each user runs at most one timer; finished worklogs do not count.
+	_, err = worklogs.Running(ctx, ada.ID)
+	expectErr(t, err, repository.ErrNotFound)
+	timer := newWorklog(task, ada, now().Truncate(time.Second), 0)
+	must(t, worklogs.Create(ctx, timer, logWork))
+	expectErr(t, worklogs.Create(ctx, newWorklog(other, ada, now().Truncate(time.Second), 0), logWork),
+		repository.ErrDuplicate)
+	must(t, worklogs.Create(ctx, newWorklog(task, bob, now().Truncate(time.Second), 0), logWork))
+	got, err = worklogs.Running(ctx, ada.ID)
+	must(t, err)
+	if got.ID != timer.ID || !got.Running() {
+		t.Fatalf("Running returned %+v, want %+v", got, timer)
+	}
+
+	lists := []struct {
+		name  string
+		query repository.WorklogQuery
+		want  []uuid.UUID
+	}{
+		{"task", repository.WorklogQuery{TaskID: task.ID, UserID: ada.ID}, []uuid.UUID{first.ID, timer.ID}},
+		{"organization", repository.WorklogQuery{OrganizationID: testOrganization, UserID: ada.ID, Finished: true},
+			[]uuid.UUID{first.ID, second.ID}},
+		{"other organization", repository.WorklogQuery{OrganizationID: uuid.New()}, []uuid.UUID{}},
+		{"project", repository.WorklogQuery{ProjectID: project.ID, Finished: true}, []uuid.UUID{first.ID, third.ID}},
+		{"range", repository.WorklogQuery{StartedAfter: day.Add(9 * time.Hour), StartedBefore: day.Add(24 * time.Hour)},
+			[]uuid.UUID{first.ID, third.ID}},
+	}
+	for _, list := range lists {
+		got, err := worklogs.List(ctx, list.query)
+		must(t, err)
+		if fmt.Sprint(worklogIDs(got)) != fmt.Sprint(list.want) {
+			t.Fatalf("%s: List returned %v, want %v", list.name, worklogIDs(got), list.want)
+		}
+	}
+
+	// Stopping the timer frees the user to start another.
+	timer.EndedAt = timer.StartedAt.Add(90 * time.Second)
+	timer.Seconds = 90
+	timer.Note = "stopped"
+	must(t, worklogs.Update(ctx, timer, logWork))
+	got, err = worklogs.Get(ctx, timer.ID)
+	must(t, err)
+	if !got.EndedAt.Equal(timer.EndedAt) || got.Seconds != 90 || got.Note != "stopped" {
+		t.Fatalf("update was not persisted: %+v", got)
+	}
+	_, err = worklogs.Running(ctx, ada.ID)
+	expectErr(t, err, repository.ErrNotFound)
+	must(t, worklogs.Create(ctx, newWorklog(other, ada, now().Truncate(time.Second), 0), logWork))
+	expectErr(t, worklogs.Update(ctx, newWorklog(task, ada, day, 60), logWork), repository.ErrNotFound)
+
+	// Changes to the seconds logged on a task are handed to logWork with
+	// the task, and the remaining estimate it leaves is kept.
+	estimated := newTask(project.ID, ada.ID)
+	estimated.OriginalEstimate, estimated.RemainingEstimate = 3600, 3600
+	must(t, repos.Tasks.Create(ctx, estimated))
+	remaining := func(step string, want int64, changes ...int64) {
+		t.Helper()
+		got, err := repos.Tasks.Get(ctx, estimated.ID)
+		must(t, err)
+		if got.RemainingEstimate != want {
+			t.Fatalf("%s: remaining estimate is %d, want %d", step, got.RemainingEstimate, want)
+		}
+		if fmt.Sprint(logged) != fmt.Sprint(changes) {
+			t.Fatalf("%s: logWork was called with %v, want %v", step, logged, changes)
+		}
+		logged = nil
+	}
+	logged = nil
+	estimate := newWorklog(estimated, bob, day, 1800)
+	must(t, worklogs.Create(ctx, estimate, func(task *domain.Task, seconds int64) {
+		if task.ID != estimated.ID || task.OriginalEstimate != 3600 || task.RemainingEstimate != 3600 {
+			t.Errorf("logWork was called with %+v, want the task %s", task, estimated.ID)
+		}
+		logWork(task, seconds)
+	}))
+	remaining("create", 1800, 1800)
+	estimate.Seconds = 2400
+	must(t, worklogs.Update(ctx, estimate, logWork))
+	remaining("update", 1200, 600)
+	estimate.Note = "noted"
+	must(t, worklogs.Update(ctx, estimate, logWork))
+	remaining("update the note", 1200)
+	cy := newUser("cy@example.com")
+	must(t, repos.Users.Create(ctx, cy))
+	must(t, worklogs.Create(ctx, newWorklog(estimated, cy, now().Truncate(time.Second), 0), logWork))
+	remaining("start a timer", 1200)
+	must(t, worklogs.Delete(ctx, estimate.ID, logWork))
+	remaining("delete", 3600, -2400)
+
+	must(t, worklogs.Delete(ctx, third.ID, logWork))
+	_, err = worklogs.Get(ctx, third.ID)
+	expectErr(t, err, repository.ErrNotFound)
+	expectErr(t, worklogs.Delete(ctx, third.ID, logWork), repository.ErrNotFound)
+
+	// Worklogs go away with their task and outlive their user.
+	must(t, repos.Tasks.Delete(ctx, other.ID))
+	_, err = worklogs.Get(ctx, second.ID)
+	expectErr(t, err, repository.ErrNotFound)
+	must(t, repos.Users.DeleteReassigning(ctx, ada.ID, uuid.Nil))
+	got, err = worklogs.Get(ctx, first.ID)
+	must(t, err)
+	if got.UserID != uuid.Nil {
+		t.Fatalf("worklog should be detached from its deleted user, got %v", got.UserID)
+	}
+}
+
+func newSprint(projectID uuid.UUID, name string, start time.Time) *domain.Sprint {
+	return &domain.Sprint{
+		ID:        uuid.New(),
+		ProjectID: projectID,
+		Name:      name,
+		Goal:      "A goal",
+		StartDate: start,
+		EndDate:   start.Add(14 * 24 * time.Hour),
+		State:     domain.SprintPlanned,
+		CreatedAt: now(),
+	}
+}
+
+func sprintIDs(sprints []domain.Sprint) []uuid.UUID {
+	ids := make([]uuid.UUID, len(sprints))
+	for i, sprint := range sprints {
+		ids[i] = sprint.ID
+	}
+	return ids
+}
+
+func testSprints(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	sprints, tasks := repos.Sprints, repos.Tasks
+
+	project := newProject(uuid.Nil)
+	must(t, repos.Projects.Create(ctx, project))
+	other := newProject(uuid.Nil)
+	must(t, repos.Projects.Create(ctx, other))
+
+	start := now().Truncate(24 * time.Hour)
+	second := newSprint(project.ID, "Sprint 2", start.Add(14*24*time.Hour))
+	must(t, sprints.Create(ctx, second))
+	first := newSprint(project.ID, "Sprint 1", start)
+	must(t, sprints.Create(ctx, first))
+	third := newSprint(project.ID, "Sprint 3", start.Add(28*24*time.Hour))
+	must(t, sprints.Create(ctx, third))
+	elsewhere := newSprint(other.ID, "Sprint 1", start)
+	must(t, sprints.Create(ctx, elsewhere))
+	expectErr(t, sprints.Create(ctx, first), repository.ErrDuplicate)
+	expectErr(t, sprints.Create(ctx, newSprint(uuid.New(), "Sprint", start)), repository.ErrReferenced)
+
+	got, err := sprints.Get(ctx, first.ID)
+	must(t, err)
+	if got.ProjectID != project.ID || got.Name != "Sprint 1" || got.Goal != "A goal" ||
+		!got.StartDate.Equal(first.StartDate) || !got.EndDate.Equal(first.EndDate) ||
+		got.State != domain.SprintPlanned || got.RolledOverTo != uuid.Nil ||
+		!got.StartedAt.IsZero() || !got.ClosedAt.IsZero() || !got.CreatedAt.Equal(first.CreatedAt) {
+		t.Fatalf("sprint did not round-trip: got %+v, want %+v", got, first)
+	}
+	_, err = sprints.Get(ctx, uuid.New())
+	expectErr(t, err, repository.ErrNotFound)
+	list, err := sprints.List(ctx, project.ID)
+	must(t, err)
+	if fmt.Sprint(sprintIDs(list)) != fmt.Sprint([]uuid.UUID{first.ID, second.ID, third.ID}) {
+		t.Fatalf("List should return the project's sprints by start date, got %v", sprintIDs(list))
+	}
+
+	// Tasks are in a sprint or in the backlog.
+	done := newTask(project.ID, uuid.Nil)
+	done.SprintID = first.ID
+	done.CompletedAt = now()
+	must(t, tasks.Create(ctx, done))
+	open := newTask(project.ID, uuid.Nil)
+	open.SprintID = first.ID
+	open.CreatedAt = now().Add(time.Second)
+	must(t, tasks.Create(ctx, open))
+	backlog := newTask(project.ID, uuid.Nil)
+	backlog.CreatedAt = now().Add(2 * time.Second)
+	must(t, tasks.Create(ctx, backlog))
+	broken := newTask(project.ID, uuid.Nil)
+	broken.SprintID = uuid.New()
+	expectErr(t, tasks.Create(ctx, broken), repository.ErrReferenced)
+
+	filter := func(query repository.TaskQuery) []uuid.UUID {
+		t.Helper()
+		page, err := tasks.GetAll(ctx, query)
+		must(t, err)
+		n, err := tasks.Count(ctx, query)
+		must(t, err)
+		if n != len(page.Items) {
+			t.Fatalf("Count gave %d tasks for %+v, GetAll %d", n, query, len(page.Items))
+		}
+		return taskIDs(page.Items)
+	}
+	expectSprint := func(taskID, want uuid.UUID) {
+		t.Helper()
+		got, err := tasks.Get(ctx, taskID)
+		must(t, err)
+		if got.SprintID != want {
+			t.Fatalf("task %s should be in sprint %v, got %v", taskID, want, got.SprintID)
+		}
+	}
+	expectSprint(open.ID, first.ID)
+	expectSprint(backlog.ID, uuid.Nil)
+	if got := filter(repository.TaskQuery{SprintID: first.ID}); fmt.Sprint(got) != fmt.Sprint([]uuid.UUID{done.ID, open.ID}) {
+		t.Fatalf("filtering by sprint should match its tasks, got %v", got)
+	}
+	if got := filter(repository.TaskQuery{ProjectID: project.ID, Backlog: true}); fmt.Sprint(got) != fmt.Sprint([]uuid.UUID{backlog.ID}) {
+		t.Fatalf("filtering by backlog should match the tasks in no sprint, got %v", got)
+	}
+
+	// A project runs at most one sprint at a time.
+	first.State = domain.SprintActive
+	first.Committed = domain.SprintWork{Tasks: 2, Estimate: 3600}
+	first.StartedAt = now()
+	must(t, sprints.Update(ctx, first))
+	second.State = domain.SprintActive
+	expectErr(t, sprints.Update(ctx, second), repository.ErrDuplicate)
+	second.State = domain.SprintPlanned
+	active := newSprint(project.ID, "Sprint 4", start)
+	active.State = domain.SprintActive
+	expectErr(t, sprints.Create(ctx, active), repository.ErrDuplicate)
+	elsewhere.State = domain.SprintActive
+	must(t, sprints.Update(ctx, elsewhere))
+	got, err = sprints.Get(ctx, first.ID)
+	must(t, err)
+	if got.State != domain.SprintActive || got.Committed != first.Committed || !got.StartedAt.Equal(first.StartedAt) {
+		t.Fatalf("update was not persisted: %+v", got)
+	}
+	expectErr(t, sprints.Update(ctx, newSprint(project.ID, "Sprint", start)), repository.ErrNotFound)
+
+	// Closing tallies the tasks in the sprint, moves the open ones to the
+	// next sprint and frees the project to start another.
+	first.State = domain.SprintClosed
+	first.RolledOverTo = second.ID
+	first.ClosedAt = now()
+	must(t, sprints.Close(ctx, first, func(tasks []domain.Task) (completed, unfinished domain.SprintWork) {
+		if ids := taskIDs(tasks); fmt.Sprint(ids) != fmt.Sprint([]uuid.UUID{done.ID, open.ID}) {
+			t.Errorf("tally was called with tasks %v, want %v", ids, []uuid.UUID{done.ID, open.ID})
+		}
+		return domain.SprintWork{Tasks: 1}, domain.SprintWork{Tasks: 1, Estimate: 600}
+	}))
+	if first.Completed != (domain.SprintWork{Tasks: 1}) || first.Unfinished != (domain.SprintWork{Tasks: 1, Estimate: 600}) {
+		t.Fatalf("closing did not tally the sprint: %+v", first)
+	}
+	expectSprint(done.ID, first.ID)
+	expectSprint(open.ID, second.ID)
+	got, err = sprints.Get(ctx, first.ID)
+	must(t, err)
+	if got.State != domain.SprintClosed || got.RolledOverTo != second.ID || got.Completed != first.Completed ||
+		got.Unfinished != first.Unfinished || !got.ClosedAt.Equal(first.ClosedAt) {
+		t.Fatalf("closing was not persisted: %+v", got)
+	}
+	second.State = domain.SprintActive
+	must(t, sprints.Update(ctx, second))
+
+	// Without a sprint to roll over to, they go to the backlog.
+	second.State = domain.SprintClosed
+	must(t, sprints.Close(ctx, second, func(tasks []domain.Task) (completed, unfinished domain.SprintWork) {
+		return domain.SprintWork{}, domain.SprintWork{Tasks: len(tasks)}
+	}))
+	expectSprint(open.ID, uuid.Nil)
+
+	// Deleting a sprint sends its tasks to the backlog and clears it from
+	// the sprints rolled over to it.
+	open.SprintID = third.ID
+	must(t, tasks.Update(ctx, open))
+	expectSprint(open.ID, third.ID)
+	must(t, sprints.Delete(ctx, second.ID))
+	got, err = sprints.Get(ctx, first.ID)
+	must(t, err)
+	if got.RolledOverTo != uuid.Nil {
+		t.Fatalf("deleted sprint should be cleared from rolled_over_to, got %v", got.RolledOverTo)
+	}
+	must(t, sprints.Delete(ctx, third.ID))
+	expectSprint(open.ID, uuid.Nil)
+	expectErr(t, sprints.Delete(ctx, third.ID), repository.ErrNotFound)
+
+	// Subtasks moving with their parent leave the sprints of the old project.
+	child := newTask(project.ID, uuid.Nil)
+	child.ParentID = done.ID
+	child.SprintID = first.ID
+	must(t, tasks.Create(ctx, child))
+	done.ProjectID = other.ID
+	done.SprintID = elsewhere.ID
+	must(t, tasks.Update(ctx, done))
+	expectSprint(done.ID, elsewhere.ID)
+	expectSprint(child.ID, uuid.Nil)
+
+	// Sprints go away with their project.
+	must(t, repos.Projects.DeleteWithTasks(ctx, project.ID))
+	_, err = sprints.Get(ctx, first.ID)
+	expectErr(t, err, repository.ErrNotFound)
+	list, err = sprints.List(ctx, other.ID)
+	must(t, err)
+	if len(list) != 1 {
+		t.Fatalf("another project's sprints went away, got %v", sprintIDs(list))
+	}
+}
+
+func newMilestone(projectID uuid.UUID, title string, target time.Time) *domain.Milestone {
+	return &domain.Milestone{
+		ID:          uuid.New(),
+		ProjectID:   projectID,
+		Title:       title,
+		Description: "A milestone",
+		TargetDate:  target,
+		CreatedAt:   now(),
+	}
+}
+
+func sameMilestone(a, b *domain.Milestone) bool {
+	return a.ID == b.ID && a.ProjectID == b.ProjectID && a.Title == b.Title && a.Description == b.Description &&
+		a.TargetDate.Equal(b.TargetDate) && a.CreatedAt.Equal(b.CreatedAt)
+}
+
+func milestoneIDs(milestones []domain.Milestone) []uuid.UUID {
+	ids := make([]uuid.UUID, len(milestones))
+	for i, milestone := range milestones {
+		ids[i] = milestone.ID
+	}
+	return ids
+}
+
+func testMilestones(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	milestones, tasks := repos.Milestones, repos.Tasks
+
+	ada := newUser("ada@example.com")
+	must(t, repos.Users.Create(ctx, ada))
+	project := newProject(uuid.Nil)
+	must(t, repos.Projects.Create(ctx, project))
+	other := newProject(uuid.Nil)
+	must(t, repos.Projects.Create(ctx, other))
+	must(t, repos.Members.Add(ctx, &domain.Member{ProjectID: project.ID, UserID: ada.ID, Role: domain.ProjectContributor,
+		JoinedAt: now()}))
+
+	start := now().Truncate(24 * time.Hour)
+	beta := newMilestone(project.ID, "Beta", start.Add(20*24*time.Hour))
+	must(t, milestones.Create(ctx, beta))
+	alpha := newMilestone(project.ID, "Alpha", start.Add(10*24*time.Hour))
+	must(t, milestones.Create(ctx, alpha))
+	past := newMilestone(project.ID, "Kickoff", start.Add(-24*time.Hour))
+	must(t, milestones.Create(ctx, past))
+	elsewhere := newMilestone(other.ID, "Alpha", start.Add(5*24*time.Hour))
+	must(t, milestones.Create(ctx, elsewhere))
+	expectErr(t, milestones.Create(ctx, alpha), repository.ErrDuplicate)
+	expectErr(t, milestones.Create(ctx, newMilestone(uuid.New(), "Alpha", start)), repository.ErrReferenced)
+
+	got, err := milestones.Get(ctx, alpha.ID)
+	must(t, err)
+	if !sameMilestone(got, alpha) {
+		t.Fatalf("milestone did not round-trip: got %+v, want %+v", got, alpha)
+	}
+	_, err = milestones.Get(ctx, uuid.New())
+	expectErr(t, err, repository.ErrNotFound)
+	list, err := milestones.List(ctx, project.ID)
+	must(t, err)
+	if fmt.Sprint(milestoneIDs(list)) != fmt.Sprint([]uuid.UUID{past.ID, alpha.ID, beta.ID}) {
+		t.Fatalf("List should return the project's milestones by target date, got %v", milestoneIDs(list))
+	}
+
+	// Update changes the title, description and target date only.
+	alpha.Title = "Alpha release"
+	alpha.Description = "First release"
+	alpha.TargetDate = start.Add(12 * 24 * time.Hour)
+	moved := *alpha
+	moved.ProjectID = other.ID
+	moved.CreatedAt = now().Add(time.Hour)
+	must(t, milestones.Update(ctx, &moved))
+	got, err = milestones.Get(ctx, alpha.ID)
+	must(t, err)
+	if !sameMilestone(got, alpha) {
+		t.Fatalf("update was not persisted as expected: got %+v, want %+v", got, alpha)
+	}
+	expectErr(t, milestones.Update(ctx, newMilestone(project.ID, "Alpha", start)), repository.ErrNotFound)
+
+	// Upcoming milestones are those due from a time on, in the projects of
+	// the organization that the user is a member of.
+	upcoming := func(from, before time.Time) []uuid.UUID {
+		t.Helper()
+		list, err := milestones.Upcoming(ctx, testOrganization, ada.ID, from, before)
+		must(t, err)
+		return milestoneIDs(list)
+	}
+	if got := upcoming(start, time.Time{}); fmt.Sprint(got) != fmt.Sprint([]uuid.UUID{alpha.ID, beta.ID}) {
+		t.Fatalf("Upcoming should return the milestones due from now in ada's projects, got %v", got)
+	}
+	if got := upcoming(start, start.Add(20*24*time.Hour)); fmt.Sprint(got) != fmt.Sprint([]uuid.UUID{alpha.ID}) {
+		t.Fatalf("Upcoming should leave out the milestones due at before or later, got %v", got)
+	}
+	if list, err := milestones.Upcoming(ctx, uuid.New(), ada.ID, start, time.Time{}); err != nil || len(list) != 0 {
+		t.Fatalf("Upcoming should only return milestones of the organization, got %v, %v", milestoneIDs(list), err)
+	}
+
+	// Tasks are linked to a milestone, or to none.
+	done := newTask(project.ID, uuid.Nil)
+	done.MilestoneID = alpha.ID
+	done.CompletedAt = now()
+	must(t, tasks.Create(ctx, done))
+	open := newTask(project.ID, uuid.Nil)
+	open.MilestoneID = alpha.ID
+	open.CreatedAt = now().Add(time.Second)
+	must(t, tasks.Create(ctx, open))
+	unlinked := newTask(project.ID, uuid.Nil)
+	must(t, tasks.Create(ctx, unlinked))
+	broken := newTask(project.ID, uuid.Nil)
+	broken.MilestoneID = uuid.New()
+	expectErr(t, tasks.Create(ctx, broken), repository.ErrReferenced)
+
+	expectMilestone := func(taskID, want uuid.UUID) {
+		t.Helper()
+		got, err := tasks.Get(ctx, taskID)
+		must(t, err)
+		if got.MilestoneID != want {
+			t.Fatalf("task %s should be linked to milestone %v, got %v", taskID, want, got.MilestoneID)
+		}
+	}
+	expectMilestone(open.ID, alpha.ID)
+	expectMilestone(unlinked.ID, uuid.Nil)
+	query := repository.TaskQuery{MilestoneID: alpha.ID}
+	page, err := tasks.GetAll(ctx, query)
+	must(t, err)
+	if fmt.Sprint(taskIDs(page.Items)) != fmt.Sprint([]uuid.UUID{done.ID, open.ID}) {
+		t.Fatalf("filtering by milestone should match its tasks, got %v", taskIDs(page.Items))
+	}
+	query.Open = true
+	if n, err := tasks.Count(ctx, query); err != nil || n != 1 {
+		t.Fatalf("milestone should have 1 open task, got %d, %v", n, err)
+	}
+	unlinked.MilestoneID = beta.ID
+	must(t, tasks.Update(ctx, unlinked))
+	expectMilestone(unlinked.ID, beta.ID)
+
+	// Tasks are counted by milestone in one go.
+	counts, err := milestones.CountTasks(ctx, []uuid.UUID{alpha.ID, beta.ID, past.ID})
+	must(t, err)
+	want := map[uuid.UUID]repository.TaskCount{alpha.ID: {Total: 2, Open: 1}, beta.ID: {Total: 1, Open: 1}}
+	if len(counts) != len(want) || counts[alpha.ID] != want[alpha.ID] || counts[beta.ID] != want[beta.ID] {
+		t.Fatalf("CountTasks returned %v, want %v", counts, want)
+	}
+	counts, err = milestones.CountTasks(ctx, nil)
+	if err != nil || len(counts) != 0 {
+		t.Fatalf("CountTasks of no milestones returned %v, %v", counts, err)
+	}
+
+	// Deleting a milestone unlinks its tasks.
+	must(t, milestones.Delete(ctx, beta.ID))
+	expectMilestone(unlinked.ID, uuid.Nil)
+	expectErr(t, milestones.Delete(ctx, beta.ID), repository.ErrNotFound)
+
+	// Subtasks moving with their parent leave the milestones of the old
+	// project.
+	child := newTask(project.ID, uuid.Nil)
+	child.ParentID = done.ID
+	child.MilestoneID = alpha.ID
+	must(t, tasks.Create(ctx, child))
+	done.ProjectID = other.ID
+	done.MilestoneID = elsewhere.ID
+	must(t, tasks.Update(ctx, done))
+	expectMilestone(done.ID, elsewhere.ID)
+	expectMilestone(child.ID, uuid.Nil)
+
+	// Milestones go away with their project.
+	must(t, repos.Projects.DeleteWithTasks(ctx, project.ID))
+	_, err = milestones.Get(ctx, alpha.ID)
+	expectErr(t, err, repository.ErrNotFound)
+	list, err = milestones.List(ctx, other.ID)
+	must(t, err)
+	if len(list) != 1 {
+		t.Fatalf("another project's milestones went away, got %v", milestoneIDs(list))
+	}
+}
@@ -0,0 +1,95 @@
+package sqlstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+type APITokenRepository struct {
+	db *DB
+}
+
+func NewAPITokenRepository(db *DB) *APITokenRepository {
+	return &APITokenRepository{db: db}
+}
+
+const selectAPITokens = "SELECT id, user_id, name, token_hash, scopes, created_at, expires_at, last_used_at, revoked_at FROM api_tokens"
+
+func scanAPIToken(row scanner) (domain.APIToken, error) {
+	var token domain.APIToken
+	var scopes string
+	err := row.Scan(&token.ID, &token.UserID, &token.Name, &token.Hash, &scopes,
+		&token.CreatedAt, &token.ExpiresAt, &token.LastUsedAt, &token.RevokedAt)
+	if err != nil {
+		return token, err
+	}
+	return token, json.Unmarshal([]byte(scopes), &token.Scopes)
+}
+
+func (r *APITokenRepository) List(ctx context.Context, userID uuid.UUID) ([]domain.APIToken, error) {
+	rows, err := r.db.query(ctx, selectAPITokens+" WHERE user_id = $1 ORDER BY created_at DESC, id", userID)
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	defer rows.Close()
+
+	tokens := []domain.APIToken{}
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
+
+func (r *APITokenRepository) Get(ctx context.Context, id uuid.UUID) (*domain.APIToken, error) {
+	token, err := scanAPIToken(r.db.queryRow(ctx, selectAPITokens+" WHERE id = $1", id))
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	return &token, nil
+}
+
+func (r *APITokenRepository) GetByHash(ctx context.Context, hash string) (*domain.APIToken, error) {
+	token, err := scanAPIToken(r.db.queryRow(ctx, selectAPITokens+" WHERE token_hash = $1", hash))
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	return &token, nil
+}
+
+func (r *APITokenRepository) Create(ctx context.Context, token *domain.APIToken) error {
+	scopes, err := json.Marshal(token.Scopes)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.exec(ctx,
+		`INSERT INTO api_tokens (id, user_id, name, token_hash, scopes, created_at, expires_at, last_used_at, revoked_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		token.ID, token.UserID, token.Name, token.Hash, string(scopes),
+		token.CreatedAt, token.ExpiresAt, token.LastUsedAt, token.RevokedAt,
+	)
+	return r.db.translate(err)
+}
+
+func (r *APITokenRepository) Touch(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return r.db.checkAffected(r.db.exec(ctx, "UPDATE api_tokens SET last_used_at = $1 WHERE id = $2", at, id))
+}
+
+func (r *APITokenRepository) Revoke(ctx context.Context, id uuid.UUID, at time.Time) error {
+	err := r.db.checkAffected(r.db.exec(ctx,
+		"UPDATE api_tokens SET revoked_at = $1 WHERE id = $2 AND revoked_at = $3", at, id, time.Time{}))
+	if errors.Is(err, repository.ErrNotFound) {
+		// The token is either missing or already revoked.
+		_, err = r.Get(ctx, id)
+	}
+	return err
+}
@@ -0,0 +1,82 @@
+package sqlstore
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+)
+
+type AttachmentRepository struct {
+	db *DB
+}
+
+func NewAttachmentRepository(db *DB) *AttachmentRepository {
+	return &AttachmentRepository{db: db}
+}
+
+const selectAttachments = `SELECT id, project_id, task_id, filename, content_type, size, checksum, storage_key,
+	uploader_id, created_at FROM attachments`
+
+func scanAttachment(row scanner) (domain.Attachment, error) {
+	var attachment domain.Attachment
+	err := row.Scan(&attachment.ID, &attachment.ProjectID, &attachment.TaskID, &attachment.Filename,
+		&attachment.ContentType, &attachment.Size, &attachment.Checksum, &attachment.Key,
+		&attachment.UploaderID, &attachment.CreatedAt)
+	return attachment, err
+}
+
+func (r *AttachmentRepository) List(ctx context.Context, owner domain.AttachmentOwner) ([]domain.Attachment, error) {
+	if owner.TaskID != uuid.Nil {
+		return r.list(ctx, selectAttachments+" WHERE task_id = $1 ORDER BY created_at, id", owner.TaskID)
+	}
+	return r.list(ctx, selectAttachments+" WHERE project_id = $1 ORDER BY created_at, id", owner.ProjectID)
+}
+
+func (r *AttachmentRepository) ListInProject(ctx context.Context, projectID uuid.UUID) ([]domain.Attachment, error) {
+	return r.list(ctx, selectAttachments+`
+		WHERE project_id = $1 OR task_id IN (SELECT id FROM tasks WHERE project_id = $1)
+		ORDER BY created_at, id`, projectID)
+}
+
+func (r *AttachmentRepository) list(ctx context.Context, query string, args ...any) ([]domain.Attachment, error) {
+	rows, err := r.db.query(ctx, query, args...)
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	defer rows.Close()
+
+	attachments := []domain.Attachment{}
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+	return attachments, rows.Err()
+}
+
+func (r *AttachmentRepository) Get(ctx context.Context, id uuid.UUID) (*domain.Attachment, error) {
+	attachment, err := scanAttachment(r.db.queryRow(ctx, selectAttachments+" WHERE id = $1", id))
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	return &attachment, nil
+}
+
+func (r *AttachmentRepository) Create(ctx context.Context, attachment *domain.Attachment) error {
+	_, err := r.db.exec(ctx,
+		`INSERT INTO attachments (id, project_id, task_id, filename, content_type, size, checksum, storage_key,
+			uploader_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		attachment.ID, nullUUID(attachment.ProjectID), nullUUID(attachment.TaskID), attachment.Filename,
+		attachment.ContentType, attachment.Size, attachment.Checksum, attachment.Key,
+		nullUUID(attachment.UploaderID), attachment.CreatedAt,
+	)
+	return r.db.translate(err)
+}
+
+func (r *AttachmentRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.checkAffected(r.db.exec(ctx, "DELETE FROM attachments WHERE id = $1", id))
+}
@@ -0,0 +1,45 @@
+package sqlstore
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+)
+
+type BoardRepository struct {
+	db *DB
+}
+
+func NewBoardRepository(db *DB) *BoardRepository {
+	return &BoardRepository{db: db}
+}
+
+func (r *BoardRepository) Get(ctx context.Context, projectID uuid.UUID) (*domain.Board, error) {
+	var columns string
+	board := domain.Board{ProjectID: projectID}
+	err := r.db.queryRow(ctx, "SELECT columns FROM boards WHERE project_id = $1", projectID).Scan(&columns)
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+
+	if err := json.Unmarshal([]byte(columns), &board.Columns); err != nil {
+		return nil, err
+	}
+	return &board, nil
+}
+
+func (r *BoardRepository) Save(ctx context.Context, board *domain.Board) error {
+	columns, err := json.Marshal(board.Columns)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.exec(ctx,
+		`INSERT INTO boards (project_id, columns) VALUES ($1, $2)
+		ON CONFLICT (project_id) DO UPDATE SET columns = $2`,
+		board.ProjectID, string(columns),
+	)
+	return r.db.translate(err)
+}
@@ -0,0 +1,175 @@
+package sqlstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+)
+
+type CommentRepository struct {
+	db *DB
+}
+
+func NewCommentRepository(db *DB) *CommentRepository {
+	return &CommentRepository{db: db}
+}
+
+const selectComments = "SELECT id, task_id, parent_id, author_id, body, created_at, edited_at, deleted_at FROM comments"
+
+func scanComment(row scanner) (domain.Comment, error) {
+	comment := domain.Comment{Mentions: []uuid.UUID{}}
+	err := row.Scan(&comment.ID, &comment.TaskID, &comment.ParentID, &comment.AuthorID, &comment.Body,
+		&comment.CreatedAt, &comment.EditedAt, &comment.DeletedAt)
+	return comment, err
+}
+
+func (r *CommentRepository) List(ctx context.Context, taskID uuid.UUID) ([]domain.Comment, error) {
+	rows, err := r.db.query(ctx, selectComments+" WHERE task_id = $1 ORDER BY created_at, id", taskID)
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	defer rows.Close()
+
+	comments := []domain.Comment{}
+	index := make(map[uuid.UUID]int)
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		index[comment.ID] = len(comments)
+		comments = append(comments, comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	mentions, err := r.mentions(ctx,
+		`SELECT m.comment_id, m.user_id FROM comment_mentions m JOIN comments c ON c.id = m.comment_id
+		WHERE c.task_id = $1 ORDER BY m.user_id`, taskID)
+	if err != nil {
+		return nil, err
+	}
+	for commentID, users := range mentions {
+		comments[index[commentID]].Mentions = users
+	}
+	return comments, nil
+}
+
+func (r *CommentRepository) Get(ctx context.Context, id uuid.UUID) (*domain.Comment, error) {
+	comment, err := scanComment(r.db.queryRow(ctx, selectComments+" WHERE id = $1", id))
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	mentions, err := r.mentions(ctx,
+		"SELECT comment_id, user_id FROM comment_mentions WHERE comment_id = $1 ORDER BY user_id", id)
+	if err != nil {
+		return nil, err
+	}
+	if users, ok := mentions[id]; ok {
+		comment.Mentions = users
+	}
+	return &comment, nil
+}
+
+// mentions runs a query for (comment_id, user_id) pairs and groups the users
+// by comment.
+func (r *CommentRepository) mentions(ctx context.Context, query string, args ...any) (map[uuid.UUID][]uuid.UUID, error) {
+	rows, err := r.db.query(ctx, query, args...)
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	defer rows.Close()
+
+	mentions := make(map[uuid.UUID][]uuid.UUID)
+	for rows.Next() {
+		var commentID, userID uuid.UUID
+		if err := rows.Scan(&commentID, &userID); err != nil {
+			return nil, err
+		}
+		mentions[commentID] = append(mentions[commentID], userID)
+	}
+	return mentions, rows.Err()
+}
+
+func (r *CommentRepository) Create(ctx context.Context, comment *domain.Comment) error {
+	return r.db.inTx(ctx, func(tx *Tx) error {
+		_, err := tx.exec(ctx,
+			`INSERT INTO comments (id, task_id, parent_id, author_id, body, created_at, edited_at, deleted_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			comment.ID, comment.TaskID, nullUUID(comment.ParentID), nullUUID(comment.AuthorID), comment.Body,
+			comment.CreatedAt, comment.EditedAt, comment.DeletedAt,
+		)
+		if err != nil {
+			return r.db.translate(err)
+		}
+		return r.insertMentions(ctx, tx, comment)
+	})
+}
+
+func (r *CommentRepository) Update(ctx context.Context, comment *domain.Comment, revision *domain.CommentRevision) error {
+	return r.db.inTx(ctx, func(tx *Tx) error {
+		err := r.db.checkAffected(tx.exec(ctx, "UPDATE comments SET body = $1, edited_at = $2 WHERE id = $3",
+			comment.Body, comment.EditedAt, comment.ID))
+		if err != nil {
+			return err
+		}
+		_, err = tx.exec(ctx,
+			"INSERT INTO comment_revisions (id, comment_id, body, editor_id, edited_at) VALUES ($1, $2, $3, $4, $5)",
+			revision.ID, revision.CommentID, revision.Body, nullUUID(revision.EditorID), revision.EditedAt,
+		)
+		if err != nil {
+			return r.db.translate(err)
+		}
+		if _, err := tx.exec(ctx, "DELETE FROM comment_mentions WHERE comment_id = $1", comment.ID); err != nil {
+			return r.db.translate(err)
+		}
+		return r.insertMentions(ctx, tx, comment)
+	})
+}
+
+func (r *CommentRepository) insertMentions(ctx context.Context, tx *Tx, comment *domain.Comment) error {
+	for _, userID := range comment.Mentions {
+		_, err := tx.exec(ctx, "INSERT INTO comment_mentions (comment_id, user_id) VALUES ($1, $2)", comment.ID, userID)
+		if err != nil {
+			return r.db.translate(err)
+		}
+	}
+	return nil
+}
+
+func (r *CommentRepository) Delete(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return r.db.inTx(ctx, func(tx *Tx) error {
+		err := r.db.checkAffected(tx.exec(ctx, "UPDATE comments SET body = '', deleted_at = $1 WHERE id = $2", at, id))
+		if err != nil {
+			return err
+		}
+		if _, err := tx.exec(ctx, "DELETE FROM comment_mentions WHERE comment_id = $1", id); err != nil {
+			return r.db.translate(err)
+		}
+		_, err = tx.exec(ctx, "DELETE FROM comment_revisions WHERE comment_id = $1", id)
+		return r.db.translate(err)
+	})
+}
+
+func (r *CommentRepository) ListRevisions(ctx context.Context, commentID uuid.UUID) ([]domain.CommentRevision, error) {
+	rows, err := r.db.query(ctx,
+		"SELECT id, comment_id, body, editor_id, edited_at FROM comment_revisions WHERE comment_id = $1 ORDER BY edited_at, id",
+		commentID)
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	defer rows.Close()
+
+	revisions := []domain.CommentRevision{}
+	for rows.Next() {
+		var revision domain.CommentRevision
+		if err := rows.Scan(&revision.ID, &revision.CommentID, &revision.Body, &revision.EditorID, &revision.EditedAt); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions, rows.Err()
+}
@@ -0,0 +1,108 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+type DependencyRepository struct {
+	db *DB
+}
+
+func NewDependencyRepository(db *DB) *DependencyRepository {
+	return &DependencyRepository{db: db}
+}
+
+// The walks below follow links away from a task with a recursive query.
+// UNION drops links that were reached already, so they end even if the
+// links were to form a cycle.
+
+const selectBlockers = `WITH RECURSIVE reached (task_id, blocker_id, created_at) AS (
+		SELECT task_id, blocker_id, created_at FROM task_dependencies WHERE task_id = $1
+		UNION
+		SELECT d.task_id, d.blocker_id, d.created_at
+		FROM task_dependencies d JOIN reached r ON d.task_id = r.blocker_id
+	)
+	SELECT task_id, blocker_id, created_at FROM reached ORDER BY created_at, task_id, blocker_id`
+
+const selectDependents = `WITH RECURSIVE reached (task_id, blocker_id, created_at) AS (
+		SELECT task_id, blocker_id, created_at FROM task_dependencies WHERE blocker_id = $1
+		UNION
+		SELECT d.task_id, d.blocker_id, d.created_at
+		FROM task_dependencies d JOIN reached r ON d.blocker_id = r.task_id
+	)
+	SELECT task_id, blocker_id, created_at FROM reached ORDER BY created_at, task_id, blocker_id`
+
+func (r *DependencyRepository) Blockers(ctx context.Context, taskID uuid.UUID) ([]domain.Dependency, error) {
+	return r.list(ctx, r.db, selectBlockers, taskID)
+}
+
+func (r *DependencyRepository) Dependents(ctx context.Context, taskID uuid.UUID) ([]domain.Dependency, error) {
+	return r.list(ctx, r.db, selectDependents, taskID)
+}
+
+func (r *DependencyRepository) list(ctx context.Context, db querier, query string, args ...any) ([]domain.Dependency, error) {
+	rows, err := db.query(ctx, query, args...)
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	defer rows.Close()
+
+	dependencies := []domain.Dependency{}
+	for rows.Next() {
+		var dependency domain.Dependency
+		if err := rows.Scan(&dependency.TaskID, &dependency.BlockerID, &dependency.CreatedAt); err != nil {
+			return nil, err
+		}
+		dependencies = append(dependencies, dependency)
+	}
+	return dependencies, rows.Err()
+}
+
+func (r *DependencyRepository) Add(ctx context.Context, dependency *domain.Dependency,
+	check func(blockers []domain.Dependency) error) error {
+	return r.db.inTx(ctx, func(tx *Tx) error {
+		if err := r.lockOrganization(ctx, tx, dependency.TaskID); err != nil {
+			return err
+		}
+		blockers, err := r.list(ctx, tx, selectBlockers, dependency.BlockerID)
+		if err != nil {
+			return err
+		}
+		if err := check(blockers); err != nil {
+			return err
+		}
+		_, err = tx.exec(ctx,
+			"INSERT INTO task_dependencies (task_id, blocker_id, created_at) VALUES ($1, $2, $3)",
+			dependency.TaskID, dependency.BlockerID, dependency.CreatedAt,
+		)
+		return r.db.translate(err)
+	})
+}
+
+// lockOrganization keeps links from being added to the tasks of the
+// organization of a task until tx ends, on Postgres; SQLite writes one
+// transaction at a time. Locking the two tasks of a link would not do:
+// links closing a cycle together can have no task in common.
+func (r *DependencyRepository) lockOrganization(ctx context.Context, tx *Tx, taskID uuid.UUID) error {
+	if r.db.dialect != Postgres {
+		return nil
+	}
+	var id uuid.UUID
+	err := tx.queryRow(ctx, `SELECT id FROM organizations
+		WHERE id = (SELECT organization_id FROM tasks WHERE id = $1) FOR UPDATE`, taskID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repository.ErrReferenced
+	}
+	return r.db.translate(err)
+}
+
+func (r *DependencyRepository) Remove(ctx context.Context, taskID, blockerID uuid.UUID) error {
+	return r.db.checkAffected(r.db.exec(ctx,
+		"DELETE FROM task_dependencies WHERE task_id = $1 AND blocker_id = $2", taskID, blockerID))
+}
@@ -0,0 +1,98 @@
+package sqlstore
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+type LabelRepository struct {
+	db *DB
+}
+
+func NewLabelRepository(db *DB) *LabelRepository {
+	return &LabelRepository{db: db}
+}
+
+const selectLabels = "SELECT id, project_id, name, color, description, created_at FROM labels"
+
+func scanLabel(row scanner) (domain.Label, error) {
+	var label domain.Label
+	err := row.Scan(&label.ID, &label.ProjectID, &label.Name, &label.Color, &label.Description, &label.CreatedAt)
+	return label, err
+}
+
+func (r *LabelRepository) List(ctx context.Context, projectID uuid.UUID) ([]domain.Label, error) {
+	rows, err := r.db.query(ctx, selectLabels+" WHERE project_id = $1 ORDER BY name, id", projectID)
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	defer rows.Close()
+
+	labels := []domain.Label{}
+	for rows.Next() {
+		label, err := scanLabel(rows)
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, label)
+	}
+	return labels, rows.Err()
+}
+
+func (r *LabelRepository) Get(ctx context.Context, id uuid.UUID) (*domain.Label, error) {
+	label, err := scanLabel(r.db.queryRow(ctx, selectLabels+" WHERE id = $1", id))
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	return &label, nil
+}
+
+func (r *LabelRepository) Create(ctx context.Context, label *domain.Label) error {
+	_, err := r.db.exec(ctx,
+		"INSERT INTO labels (id, project_id, name, color, description, created_at) VALUES ($1, $2, $3, $4, $5, $6)",
+		label.ID, label.ProjectID, label.Name, label.Color, label.Description, label.CreatedAt,
+	)
+	return r.db.translate(err)
+}
+
+func (r *LabelRepository) Update(ctx context.Context, label *domain.Label) error {
+	return r.db.checkAffected(r.db.exec(ctx,
+		"UPDATE labels SET name = $1, color = $2, description = $3 WHERE id = $4",
+		label.Name, label.Color, label.Description, label.ID,
+	))
+}
+
+func (r *LabelRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.checkAffected(r.db.exec(ctx, "DELETE FROM labels WHERE id = $1", id))
+}
+
+func (r *LabelRepository) Merge(ctx context.Context, sourceID, targetID uuid.UUID) error {
+	return r.db.inTx(ctx, func(tx *Tx) error {
+		rows, err := tx.query(ctx, "SELECT id FROM labels WHERE id = $1", targetID)
+		if err != nil {
+			return r.db.translate(err)
+		}
+		found := rows.Next()
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return err
+		}
+		if !found {
+			return repository.ErrNotFound
+		}
+
+		_, err = tx.exec(ctx,
+			`INSERT INTO task_labels (task_id, label_id)
+			SELECT task_id, $2 FROM task_labels WHERE label_id = $1
+			AND task_id NOT IN (SELECT task_id FROM task_labels WHERE label_id = $2)`,
+			sourceID, targetID)
+		if err != nil {
+			return r.db.translate(err)
+		}
+		return r.db.checkAffected(tx.exec(ctx, "DELETE FROM labels WHERE id = $1", sourceID))
+	})
+}
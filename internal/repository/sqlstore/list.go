@@ -0,0 +1,167 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+// scanner is implemented by both *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// listQuery accumulates the WHERE clause of a list query and its arguments.
+type listQuery struct {
+	where []string
+	args  []any
+}
+
+func (q *listQuery) arg(v any) string {
+	q.args = append(q.args, v)
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+// add appends a condition, replacing each "?" in cond with a placeholder for
+// the next of args.
+func (q *listQuery) add(cond string, args ...any) {
+	for _, a := range args {
+		cond = strings.Replace(cond, "?", q.arg(a), 1)
+	}
+	q.where = append(q.where, cond)
+}
+
+func (q *listQuery) addIn(column string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	placeholders := make([]string, len(values))
+	for i, v := range values {
+		placeholders[i] = q.arg(v)
+	}
+	q.where = append(q.where, column+" IN ("+strings.Join(placeholders, ", ")+")")
+}
+
+// keyset restricts the list to rows positioned after key in sort order:
+// (a > x) OR (a = x AND b > y) OR (a = x AND b = y AND id > z).
+func (q *listQuery) keyset(columns map[string]string, sort []repository.SortField, key []any) {
+	names := make([]string, 0, len(sort)+1)
+	for _, field := range sort {
+		names = append(names, columns[field.Field])
+	}
+	names = append(names, "id")
+
+	placeholders := make([]string, len(key))
+	for i, v := range key {
+		placeholders[i] = q.arg(v)
+	}
+
+	var or []string
+	for i := range names {
+		var and []string
+		for j := 0; j < i; j++ {
+			and = append(and, names[j]+" = "+placeholders[j])
+		}
+		op := " > "
+		if i < len(sort) && sort[i].Desc {
+			op = " < "
+		}
+		and = append(and, names[i]+op+placeholders[i])
+		or = append(or, "("+strings.Join(and, " AND ")+")")
+	}
+	q.where = append(q.where, "("+strings.Join(or, " OR ")+")")
+}
+
+func (q *listQuery) writeWhere(b *strings.Builder) {
+	if len(q.where) > 0 {
+		b.WriteString(" WHERE ")
+		b.WriteString(strings.Join(q.where, " AND "))
+	}
+}
+
+func (q *listQuery) build(selectFrom string, columns map[string]string, sort []repository.SortField, limit int) string {
+	var b strings.Builder
+	b.WriteString(selectFrom)
+	q.writeWhere(&b)
+
+	b.WriteString(" ORDER BY ")
+	for _, field := range sort {
+		b.WriteString(columns[field.Field])
+		if field.Desc {
+			b.WriteString(" DESC")
+		}
+		b.WriteString(", ")
+	}
+	b.WriteString("id LIMIT ")
+	b.WriteString(q.arg(limit))
+	return b.String()
+}
+
+// rowQuerier and querier are implemented by both *DB and *Tx.
+type rowQuerier interface {
+	queryRow(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type querier interface {
+	query(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// count runs a COUNT(*) query over table with the accumulated conditions.
+func (q *listQuery) count(ctx context.Context, db rowQuerier, table string) (int, error) {
+	var b strings.Builder
+	b.WriteString("SELECT COUNT(*) FROM ")
+	b.WriteString(table)
+	q.writeWhere(&b)
+
+	var n int
+	err := db.queryRow(ctx, b.String(), q.args...).Scan(&n)
+	return n, err
+}
+
+// list runs a paginated query. columns maps the fields of sorting onto SQL
+// columns; one row more than the page size is fetched to learn whether
+// another page follows.
+func list[T any](
+	ctx context.Context, db *DB, q *listQuery, selectFrom string,
+	sorting repository.Sorting[T], columns map[string]string,
+	sort []repository.SortField, limit int, cursor string,
+	scan func(scanner) (T, error),
+) (*repository.Page[T], error) {
+	sort = sorting.OrDefault(sort)
+	limit = repository.Limit(limit)
+
+	if cursor != "" {
+		key, err := sorting.DecodeCursor(cursor, sort)
+		if err != nil {
+			return nil, err
+		}
+		q.keyset(columns, sort, key)
+	}
+
+	rows, err := db.query(ctx, q.build(selectFrom, columns, sort, limit+1), q.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &repository.Page[T]{Items: []T{}}
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		page.Items = append(page.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(page.Items) > limit {
+		page.Items = page.Items[:limit]
+		page.NextCursor = sorting.Cursor(&page.Items[limit-1], sort)
+	}
+	return page, nil
+}
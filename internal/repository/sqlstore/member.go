@@ -0,0 +1,70 @@
+package sqlstore
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+)
+
+type MemberRepository struct {
+	db *DB
+}
+
+func NewMemberRepository(db *DB) *MemberRepository {
+	return &MemberRepository{db: db}
+}
+
+const selectMembers = "SELECT project_id, user_id, role, joined_at FROM project_members"
+
+func scanMember(row scanner) (domain.Member, error) {
+	var member domain.Member
+	err := row.Scan(&member.ProjectID, &member.UserID, &member.Role, &member.JoinedAt)
+	return member, err
+}
+
+func (r *MemberRepository) List(ctx context.Context, projectID uuid.UUID) ([]domain.Member, error) {
+	rows, err := r.db.query(ctx, selectMembers+" WHERE project_id = $1 ORDER BY joined_at, user_id", projectID)
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	defer rows.Close()
+
+	members := []domain.Member{}
+	for rows.Next() {
+		member, err := scanMember(rows)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	return members, rows.Err()
+}
+
+func (r *MemberRepository) Get(ctx context.Context, projectID, userID uuid.UUID) (*domain.Member, error) {
+	member, err := scanMember(r.db.queryRow(ctx, selectMembers+" WHERE project_id = $1 AND user_id = $2", projectID, userID))
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	return &member, nil
+}
+
+func (r *MemberRepository) Add(ctx context.Context, member *domain.Member) error {
+	_, err := r.db.exec(ctx,
+		"INSERT INTO project_members (project_id, user_id, role, joined_at) VALUES ($1, $2, $3, $4)",
+		member.ProjectID, member.UserID, member.Role, member.JoinedAt,
+	)
+	return r.db.translate(err)
+}
+
+func (r *MemberRepository) Update(ctx context.Context, member *domain.Member) error {
+	return r.db.checkAffected(r.db.exec(ctx,
+		"UPDATE project_members SET role = $1 WHERE project_id = $2 AND user_id = $3",
+		member.Role, member.ProjectID, member.UserID,
+	))
+}
+
+func (r *MemberRepository) Remove(ctx context.Context, projectID, userID uuid.UUID) error {
+	return r.db.checkAffected(r.db.exec(ctx,
+		"DELETE FROM project_members WHERE project_id = $1 AND user_id = $2", projectID, userID))
+}
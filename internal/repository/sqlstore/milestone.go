@@ -0,0 +1,132 @@
+package sqlstore
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+type MilestoneRepository struct {
+	db *DB
+}
+
+func NewMilestoneRepository(db *DB) *MilestoneRepository {
+	return &MilestoneRepository{db: db}
+}
+
+const selectMilestones = "SELECT id, project_id, title, description, target_date, created_at FROM milestones"
+
+func scanMilestone(row scanner) (domain.Milestone, error) {
+	var milestone domain.Milestone
+	err := row.Scan(&milestone.ID, &milestone.ProjectID, &milestone.Title, &milestone.Description,
+		&milestone.TargetDate, &milestone.CreatedAt)
+	return milestone, err
+}
+
+func (r *MilestoneRepository) List(ctx context.Context, projectID uuid.UUID) ([]domain.Milestone, error) {
+	var q listQuery
+	q.add("project_id = ?", projectID)
+	return r.list(ctx, &q)
+}
+
+func (r *MilestoneRepository) Upcoming(ctx context.Context, organizationID, userID uuid.UUID, from, before time.Time) ([]domain.Milestone, error) {
+	var q listQuery
+	q.add("project_id IN (SELECT id FROM projects WHERE organization_id = ?)", organizationID)
+	q.add("project_id IN (SELECT project_id FROM project_members WHERE user_id = ?)", userID)
+	q.add("target_date >= ?", from)
+	if !before.IsZero() {
+		q.add("target_date < ?", before)
+	}
+	return r.list(ctx, &q)
+}
+
+// list returns the milestones matching q, ordered by target date.
+func (r *MilestoneRepository) list(ctx context.Context, q *listQuery) ([]domain.Milestone, error) {
+	var b strings.Builder
+	b.WriteString(selectMilestones)
+	q.writeWhere(&b)
+	b.WriteString(" ORDER BY target_date, id")
+
+	rows, err := r.db.query(ctx, b.String(), q.args...)
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	defer rows.Close()
+
+	milestones := []domain.Milestone{}
+	for rows.Next() {
+		milestone, err := scanMilestone(rows)
+		if err != nil {
+			return nil, err
+		}
+		milestones = append(milestones, milestone)
+	}
+	return milestones, rows.Err()
+}
+
+func (r *MilestoneRepository) Get(ctx context.Context, id uuid.UUID) (*domain.Milestone, error) {
+	milestone, err := scanMilestone(r.db.queryRow(ctx, selectMilestones+" WHERE id = $1", id))
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	return &milestone, nil
+}
+
+func (r *MilestoneRepository) Create(ctx context.Context, milestone *domain.Milestone) error {
+	_, err := r.db.exec(ctx,
+		`INSERT INTO milestones (id, project_id, title, description, target_date, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		milestone.ID, milestone.ProjectID, milestone.Title, milestone.Description, milestone.TargetDate,
+		milestone.CreatedAt,
+	)
+	return r.db.translate(err)
+}
+
+func (r *MilestoneRepository) Update(ctx context.Context, milestone *domain.Milestone) error {
+	return r.db.checkAffected(r.db.exec(ctx,
+		"UPDATE milestones SET title = $1, description = $2, target_date = $3 WHERE id = $4",
+		milestone.Title, milestone.Description, milestone.TargetDate, milestone.ID,
+	))
+}
+
+func (r *MilestoneRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.checkAffected(r.db.exec(ctx, "DELETE FROM milestones WHERE id = $1", id))
+}
+
+func (r *MilestoneRepository) CountTasks(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]repository.TaskCount, error) {
+	counts := make(map[uuid.UUID]repository.TaskCount)
+	if len(ids) == 0 {
+		return counts, nil
+	}
+	var q listQuery
+	open := q.arg(time.Time{})
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = id.String()
+	}
+	q.addIn("milestone_id", values)
+
+	var b strings.Builder
+	b.WriteString("SELECT milestone_id, COUNT(*), SUM(CASE WHEN completed_at = " + open + " THEN 1 ELSE 0 END) FROM tasks")
+	q.writeWhere(&b)
+	b.WriteString(" GROUP BY milestone_id")
+	rows, err := r.db.query(ctx, b.String(), q.args...)
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uuid.UUID
+		var count repository.TaskCount
+		if err := rows.Scan(&id, &count.Total, &count.Open); err != nil {
+			return nil, err
+		}
+		counts[id] = count
+	}
+	return counts, rows.Err()
+}
@@ -0,0 +1,101 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+type NotificationRepository struct {
+	db *DB
+}
+
+func NewNotificationRepository(db *DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+const selectNotifications = "SELECT id, user_id, task_id, kind, due_date, message, created_at, read_at FROM notifications"
+
+func scanNotification(row scanner) (domain.Notification, error) {
+	var notification domain.Notification
+	err := row.Scan(&notification.ID, &notification.UserID, &notification.TaskID, &notification.Kind,
+		&notification.DueDate, &notification.Message, &notification.CreatedAt, &notification.ReadAt)
+	return notification, err
+}
+
+// notificationFilter filters on the organization of the notifications' tasks,
+// unless organizationID is uuid.Nil.
+func notificationFilter(organizationID uuid.UUID, args []any) (string, []any) {
+	if organizationID == uuid.Nil {
+		return "", args
+	}
+	args = append(args, organizationID)
+	return fmt.Sprintf(" AND task_id IN (SELECT id FROM tasks WHERE organization_id = $%d)", len(args)), args
+}
+
+func (r *NotificationRepository) List(ctx context.Context, organizationID, userID uuid.UUID,
+	unread bool) ([]domain.Notification, error) {
+	query := selectNotifications + " WHERE user_id = $1"
+	args := []any{userID}
+	if unread {
+		query += " AND read_at = $2"
+		args = append(args, time.Time{})
+	}
+	filter, args := notificationFilter(organizationID, args)
+	rows, err := r.db.query(ctx, query+filter+" ORDER BY created_at DESC, id", args...)
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	defer rows.Close()
+
+	notifications := []domain.Notification{}
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, notification)
+	}
+	return notifications, rows.Err()
+}
+
+func (r *NotificationRepository) Get(ctx context.Context, id uuid.UUID) (*domain.Notification, error) {
+	notification, err := scanNotification(r.db.queryRow(ctx, selectNotifications+" WHERE id = $1", id))
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	return &notification, nil
+}
+
+func (r *NotificationRepository) Create(ctx context.Context, notification *domain.Notification) error {
+	_, err := r.db.exec(ctx,
+		`INSERT INTO notifications (id, user_id, task_id, kind, due_date, message, created_at, read_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		notification.ID, notification.UserID, notification.TaskID, notification.Kind,
+		notification.DueDate, notification.Message, notification.CreatedAt, notification.ReadAt,
+	)
+	return r.db.translate(err)
+}
+
+func (r *NotificationRepository) MarkRead(ctx context.Context, id uuid.UUID, at time.Time) error {
+	err := r.db.checkAffected(r.db.exec(ctx,
+		"UPDATE notifications SET read_at = $1 WHERE id = $2 AND read_at = $3", at, id, time.Time{}))
+	if errors.Is(err, repository.ErrNotFound) {
+		// The notification is either missing or already read.
+		_, err = r.Get(ctx, id)
+	}
+	return err
+}
+
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, organizationID, userID uuid.UUID,
+	at time.Time) error {
+	filter, args := notificationFilter(organizationID, []any{at, userID, time.Time{}})
+	_, err := r.db.exec(ctx,
+		"UPDATE notifications SET read_at = $1 WHERE user_id = $2 AND read_at = $3"+filter, args...)
+	return r.db.translate(err)
+}
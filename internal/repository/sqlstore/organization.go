@@ -0,0 +1,130 @@
+package sqlstore
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+)
+
+type OrganizationRepository struct {
+	db *DB
+}
+
+func NewOrganizationRepository(db *DB) *OrganizationRepository {
+	return &OrganizationRepository{db: db}
+}
+
+const (
+	selectOrganizations       = "SELECT id, name, created_at FROM organizations"
+	selectOrganizationMembers = "SELECT organization_id, user_id, role, joined_at FROM organization_members"
+	insertOrganizationMember  = "INSERT INTO organization_members (organization_id, user_id, role, joined_at) VALUES ($1, $2, $3, $4)"
+)
+
+func scanOrganization(row scanner) (domain.Organization, error) {
+	var organization domain.Organization
+	err := row.Scan(&organization.ID, &organization.Name, &organization.CreatedAt)
+	return organization, err
+}
+
+func scanOrganizationMember(row scanner) (domain.OrganizationMember, error) {
+	var member domain.OrganizationMember
+	err := row.Scan(&member.OrganizationID, &member.UserID, &member.Role, &member.JoinedAt)
+	return member, err
+}
+
+func (r *OrganizationRepository) ListForUser(ctx context.Context, userID uuid.UUID) ([]domain.Organization, error) {
+	rows, err := r.db.query(ctx, `SELECT o.id, o.name, o.created_at FROM organizations o
+		JOIN organization_members m ON m.organization_id = o.id
+		WHERE m.user_id = $1 ORDER BY m.joined_at, o.id`, userID)
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	defer rows.Close()
+
+	organizations := []domain.Organization{}
+	for rows.Next() {
+		organization, err := scanOrganization(rows)
+		if err != nil {
+			return nil, err
+		}
+		organizations = append(organizations, organization)
+	}
+	return organizations, rows.Err()
+}
+
+func (r *OrganizationRepository) Get(ctx context.Context, id uuid.UUID) (*domain.Organization, error) {
+	organization, err := scanOrganization(r.db.queryRow(ctx, selectOrganizations+" WHERE id = $1", id))
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	return &organization, nil
+}
+
+func (r *OrganizationRepository) Create(ctx context.Context, organization *domain.Organization, member *domain.OrganizationMember) error {
+	return r.db.inTx(ctx, func(tx *Tx) error {
+		_, err := tx.exec(ctx, "INSERT INTO organizations (id, name, created_at) VALUES ($1, $2, $3)",
+			organization.ID, organization.Name, organization.CreatedAt)
+		if err != nil {
+			return r.db.translate(err)
+		}
+		_, err = tx.exec(ctx, insertOrganizationMember, member.OrganizationID, member.UserID, member.Role, member.JoinedAt)
+		return r.db.translate(err)
+	})
+}
+
+func (r *OrganizationRepository) Update(ctx context.Context, organization *domain.Organization) error {
+	return r.db.checkAffected(r.db.exec(ctx, "UPDATE organizations SET name = $1 WHERE id = $2",
+		organization.Name, organization.ID))
+}
+
+func (r *OrganizationRepository) ListMembers(ctx context.Context, organizationID uuid.UUID) ([]domain.OrganizationMember, error) {
+	rows, err := r.db.query(ctx, selectOrganizationMembers+" WHERE organization_id = $1 ORDER BY joined_at, user_id", organizationID)
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	defer rows.Close()
+
+	members := []domain.OrganizationMember{}
+	for rows.Next() {
+		member, err := scanOrganizationMember(rows)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	return members, rows.Err()
+}
+
+func (r *OrganizationRepository) GetMember(ctx context.Context, organizationID, userID uuid.UUID) (*domain.OrganizationMember, error) {
+	member, err := scanOrganizationMember(r.db.queryRow(ctx,
+		selectOrganizationMembers+" WHERE organization_id = $1 AND user_id = $2", organizationID, userID))
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	return &member, nil
+}
+
+func (r *OrganizationRepository) AddMember(ctx context.Context, member *domain.OrganizationMember) error {
+	_, err := r.db.exec(ctx, insertOrganizationMember, member.OrganizationID, member.UserID, member.Role, member.JoinedAt)
+	return r.db.translate(err)
+}
+
+func (r *OrganizationRepository) UpdateMember(ctx context.Context, member *domain.OrganizationMember) error {
+	return r.db.checkAffected(r.db.exec(ctx,
+		"UPDATE organization_members SET role = $1 WHERE organization_id = $2 AND user_id = $3",
+		member.Role, member.OrganizationID, member.UserID,
+	))
+}
+
+func (r *OrganizationRepository) RemoveMember(ctx context.Context, organizationID, userID uuid.UUID) error {
+	return r.db.inTx(ctx, func(tx *Tx) error {
+		_, err := tx.exec(ctx, `DELETE FROM project_members WHERE user_id = $1
+			AND project_id IN (SELECT id FROM projects WHERE organization_id = $2)`, userID, organizationID)
+		if err != nil {
+			return r.db.translate(err)
+		}
+		return r.db.checkAffected(tx.exec(ctx,
+			"DELETE FROM organization_members WHERE organization_id = $1 AND user_id = $2", organizationID, userID))
+	})
+}
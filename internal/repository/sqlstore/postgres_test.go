@@ -0,0 +1,59 @@
+package sqlstore_test
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/config"
+	"github.com/yelnar0112/project-management/internal/repository/repotest"
+	"github.com/yelnar0112/project-management/internal/repository/sqlstore"
+)
+
+// TestPostgres runs against the database at TEST_POSTGRES_DSN, giving each
+// store a schema of its own that is dropped afterwards.
+func TestPostgres(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+	admin, err := sql.Open(config.DriverPostgres, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer admin.Close()
+
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		schema := "test_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+		if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { admin.Exec("DROP SCHEMA " + schema + " CASCADE") })
+
+		db, err := sql.Open(config.DriverPostgres, withSearchPath(dsn, schema))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+		return open(t, db, sqlstore.Postgres)
+	})
+}
+
+// withSearchPath adds a search_path run-time parameter to a URL or
+// key=value connection string.
+func withSearchPath(dsn, schema string) string {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err == nil {
+			query := u.Query()
+			query.Set("search_path", schema)
+			u.RawQuery = query.Encode()
+			return u.String()
+		}
+	}
+	return fmt.Sprintf("%s search_path=%s", dsn, schema)
+}
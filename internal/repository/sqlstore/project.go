@@ -0,0 +1,95 @@
+package sqlstore
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+type ProjectRepository struct {
+	db *DB
+}
+
+func NewProjectRepository(db *DB) *ProjectRepository {
+	return &ProjectRepository{db: db}
+}
+
+const selectProjects = "SELECT id, title, description, start_date, end_date, manager_id, organization_id FROM projects"
+
+var projectColumns = map[string]string{
+	"title":      "title",
+	"start_date": "start_date",
+	"end_date":   "end_date",
+}
+
+func scanProject(row scanner) (domain.Entity, error) {
+	var project domain.Entity
+	err := row.Scan(&project.ID, &project.Title, &project.Description, &project.StartDate, &project.EndDate, &project.ManagerID, &project.OrganizationID)
+	return project, err
+}
+
+func (r *ProjectRepository) GetAll(ctx context.Context, query repository.ProjectQuery) (*repository.Page[domain.Entity], error) {
+	var q listQuery
+	if query.OrganizationID != uuid.Nil {
+		q.add("organization_id = ?", query.OrganizationID)
+	}
+	if query.ManagerID != uuid.Nil {
+		q.add("manager_id = ?", query.ManagerID)
+	}
+	if query.MemberID != uuid.Nil {
+		q.add("id IN (SELECT project_id FROM project_members WHERE user_id = ?)", query.MemberID)
+	}
+	if !query.StartsAfter.IsZero() {
+		q.add("start_date >= ?", query.StartsAfter)
+	}
+	if !query.StartsBefore.IsZero() {
+		q.add("start_date < ?", query.StartsBefore)
+	}
+	if !query.EndsAfter.IsZero() {
+		q.add("end_date >= ?", query.EndsAfter)
+	}
+	if !query.EndsBefore.IsZero() {
+		q.add("end_date < ?", query.EndsBefore)
+	}
+
+	return list(ctx, r.db, &q, selectProjects, repository.ProjectSorting, projectColumns,
+		query.Sort, query.Limit, query.Cursor, scanProject)
+}
+
+func (r *ProjectRepository) Create(ctx context.Context, project *domain.Entity) error {
+	_, err := r.db.exec(ctx,
+		"INSERT INTO projects (id, title, description, start_date, end_date, manager_id, organization_id) VALUES ($1, $2, $3, $4, $5, $6, $7)",
+		project.ID, project.Title, project.Description, project.StartDate, project.EndDate, nullUUID(project.ManagerID), project.OrganizationID,
+	)
+	return r.db.translate(err)
+}
+
+func (r *ProjectRepository) Get(ctx context.Context, id uuid.UUID) (*domain.Entity, error) {
+	project, err := scanProject(r.db.queryRow(ctx, selectProjects+" WHERE id = $1", id))
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	return &project, nil
+}
+
+func (r *ProjectRepository) Update(ctx context.Context, project *domain.Entity) error {
+	return r.db.checkAffected(r.db.exec(ctx,
+		"UPDATE projects SET title = $1, description = $2, start_date = $3, end_date = $4, manager_id = $5 WHERE id = $6",
+		project.Title, project.Description, project.StartDate, project.EndDate, nullUUID(project.ManagerID), project.ID,
+	))
+}
+
+func (r *ProjectRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.checkAffected(r.db.exec(ctx, "DELETE FROM projects WHERE id = $1", id))
+}
+
+func (r *ProjectRepository) DeleteWithTasks(ctx context.Context, id uuid.UUID) error {
+	return r.db.inTx(ctx, func(tx *Tx) error {
+		if _, err := tx.exec(ctx, "DELETE FROM tasks WHERE project_id = $1", id); err != nil {
+			return r.db.translate(err)
+		}
+		return r.db.checkAffected(tx.exec(ctx, "DELETE FROM projects WHERE id = $1", id))
+	})
+}
@@ -0,0 +1,81 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+type SessionRepository struct {
+	db *DB
+}
+
+func NewSessionRepository(db *DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+const insertRefreshToken = "INSERT INTO refresh_tokens (token_hash, session_id, expires_at, used_at) VALUES ($1, $2, $3, $4)"
+
+func (r *SessionRepository) Create(ctx context.Context, session *domain.Session, token *domain.RefreshToken) error {
+	return r.db.inTx(ctx, func(tx *Tx) error {
+		_, err := tx.exec(ctx, "INSERT INTO sessions (id, user_id, created_at, revoked_at) VALUES ($1, $2, $3, $4)",
+			session.ID, session.UserID, session.CreatedAt, session.RevokedAt)
+		if err != nil {
+			return r.db.translate(err)
+		}
+		_, err = tx.exec(ctx, insertRefreshToken, token.Hash, token.SessionID, token.ExpiresAt, token.UsedAt)
+		return r.db.translate(err)
+	})
+}
+
+func (r *SessionRepository) Get(ctx context.Context, id uuid.UUID) (*domain.Session, error) {
+	var session domain.Session
+	err := r.db.queryRow(ctx, "SELECT id, user_id, created_at, revoked_at FROM sessions WHERE id = $1", id).
+		Scan(&session.ID, &session.UserID, &session.CreatedAt, &session.RevokedAt)
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	return &session, nil
+}
+
+func (r *SessionRepository) GetRefreshToken(ctx context.Context, hash string) (*domain.RefreshToken, error) {
+	var token domain.RefreshToken
+	err := r.db.queryRow(ctx, "SELECT token_hash, session_id, expires_at, used_at FROM refresh_tokens WHERE token_hash = $1", hash).
+		Scan(&token.Hash, &token.SessionID, &token.ExpiresAt, &token.UsedAt)
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	return &token, nil
+}
+
+func (r *SessionRepository) Rotate(ctx context.Context, hash string, usedAt time.Time, next *domain.RefreshToken) error {
+	return r.db.inTx(ctx, func(tx *Tx) error {
+		// Only one of two concurrent rotations of the same token can match.
+		err := r.db.checkAffected(tx.exec(ctx,
+			"UPDATE refresh_tokens SET used_at = $1 WHERE token_hash = $2 AND used_at = $3", usedAt, hash, time.Time{}))
+		if err != nil {
+			return err
+		}
+		_, err = tx.exec(ctx, insertRefreshToken, next.Hash, next.SessionID, next.ExpiresAt, next.UsedAt)
+		return r.db.translate(err)
+	})
+}
+
+func (r *SessionRepository) Revoke(ctx context.Context, id uuid.UUID, at time.Time) error {
+	err := r.db.checkAffected(r.db.exec(ctx,
+		"UPDATE sessions SET revoked_at = $1 WHERE id = $2 AND revoked_at = $3", at, id, time.Time{}))
+	if errors.Is(err, repository.ErrNotFound) {
+		// The session is either missing or already revoked.
+		_, err = r.Get(ctx, id)
+	}
+	return err
+}
+
+func (r *SessionRepository) RevokeUser(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	_, err := r.db.exec(ctx, "UPDATE sessions SET revoked_at = $1 WHERE user_id = $2 AND revoked_at = $3", at, userID, time.Time{})
+	return r.db.translate(err)
+}
@@ -0,0 +1,135 @@
+package sqlstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+)
+
+type SprintRepository struct {
+	db *DB
+}
+
+func NewSprintRepository(db *DB) *SprintRepository {
+	return &SprintRepository{db: db}
+}
+
+const selectSprints = `SELECT id, project_id, name, goal, start_date, end_date, state,
+	committed_tasks, committed_estimate, completed_tasks, completed_estimate, unfinished_tasks, unfinished_estimate,
+	rolled_over_to, started_at, closed_at, created_at FROM sprints`
+
+func scanSprint(row scanner) (domain.Sprint, error) {
+	var sprint domain.Sprint
+	err := row.Scan(&sprint.ID, &sprint.ProjectID, &sprint.Name, &sprint.Goal, &sprint.StartDate, &sprint.EndDate,
+		&sprint.State, &sprint.Committed.Tasks, &sprint.Committed.Estimate, &sprint.Completed.Tasks,
+		&sprint.Completed.Estimate, &sprint.Unfinished.Tasks, &sprint.Unfinished.Estimate,
+		&sprint.RolledOverTo, &sprint.StartedAt, &sprint.ClosedAt, &sprint.CreatedAt)
+	return sprint, err
+}
+
+func (r *SprintRepository) List(ctx context.Context, projectID uuid.UUID) ([]domain.Sprint, error) {
+	rows, err := r.db.query(ctx, selectSprints+" WHERE project_id = $1 ORDER BY start_date, id", projectID)
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	defer rows.Close()
+
+	sprints := []domain.Sprint{}
+	for rows.Next() {
+		sprint, err := scanSprint(rows)
+		if err != nil {
+			return nil, err
+		}
+		sprints = append(sprints, sprint)
+	}
+	return sprints, rows.Err()
+}
+
+func (r *SprintRepository) Get(ctx context.Context, id uuid.UUID) (*domain.Sprint, error) {
+	sprint, err := scanSprint(r.db.queryRow(ctx, selectSprints+" WHERE id = $1", id))
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	return &sprint, nil
+}
+
+func (r *SprintRepository) Create(ctx context.Context, sprint *domain.Sprint) error {
+	_, err := r.db.exec(ctx,
+		`INSERT INTO sprints (id, project_id, name, goal, start_date, end_date, state,
+		committed_tasks, committed_estimate, completed_tasks, completed_estimate, unfinished_tasks, unfinished_estimate,
+		rolled_over_to, started_at, closed_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)`,
+		sprint.ID, sprint.ProjectID, sprint.Name, sprint.Goal, sprint.StartDate, sprint.EndDate, sprint.State,
+		sprint.Committed.Tasks, sprint.Committed.Estimate, sprint.Completed.Tasks, sprint.Completed.Estimate,
+		sprint.Unfinished.Tasks, sprint.Unfinished.Estimate, nullUUID(sprint.RolledOverTo), sprint.StartedAt, sprint.ClosedAt, sprint.CreatedAt,
+	)
+	return r.db.translate(err)
+}
+
+func (r *SprintRepository) Update(ctx context.Context, sprint *domain.Sprint) error {
+	return r.db.inTx(ctx, func(tx *Tx) error {
+		return r.update(ctx, tx, sprint)
+	})
+}
+
+func (r *SprintRepository) update(ctx context.Context, tx *Tx, sprint *domain.Sprint) error {
+	return r.db.checkAffected(tx.exec(ctx,
+		`UPDATE sprints SET name = $1, goal = $2, start_date = $3, end_date = $4, state = $5,
+		committed_tasks = $6, committed_estimate = $7, completed_tasks = $8, completed_estimate = $9,
+		unfinished_tasks = $10, unfinished_estimate = $11, rolled_over_to = $12, started_at = $13, closed_at = $14
+		WHERE id = $15`,
+		sprint.Name, sprint.Goal, sprint.StartDate, sprint.EndDate, sprint.State,
+		sprint.Committed.Tasks, sprint.Committed.Estimate, sprint.Completed.Tasks, sprint.Completed.Estimate,
+		sprint.Unfinished.Tasks, sprint.Unfinished.Estimate, nullUUID(sprint.RolledOverTo), sprint.StartedAt,
+		sprint.ClosedAt, sprint.ID,
+	))
+}
+
+func (r *SprintRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.checkAffected(r.db.exec(ctx, "DELETE FROM sprints WHERE id = $1", id))
+}
+
+func (r *SprintRepository) Close(ctx context.Context, sprint *domain.Sprint,
+	tally func(tasks []domain.Task) (completed, unfinished domain.SprintWork)) error {
+	return r.db.inTx(ctx, func(tx *Tx) error {
+		tasks, err := r.lockTasks(ctx, tx, sprint.ID)
+		if err != nil {
+			return err
+		}
+		sprint.Completed, sprint.Unfinished = tally(tasks)
+		if err := r.update(ctx, tx, sprint); err != nil {
+			return err
+		}
+		_, err = tx.exec(ctx, "UPDATE tasks SET sprint_id = $1 WHERE sprint_id = $2 AND completed_at = $3",
+			nullUUID(sprint.RolledOverTo), sprint.ID, time.Time{})
+		return r.db.translate(err)
+	})
+}
+
+// lockTasks returns the tasks in a sprint, without their labels, as they
+// are within tx. On Postgres they stay locked until tx ends, so that the
+// tasks completed are the ones left in the sprint; SQLite writes one
+// transaction at a time.
+func (r *SprintRepository) lockTasks(ctx context.Context, tx *Tx, id uuid.UUID) ([]domain.Task, error) {
+	query := selectTasks + " WHERE sprint_id = $1 ORDER BY created_at, id"
+	if r.db.dialect == Postgres {
+		query += " FOR UPDATE"
+	}
+	rows, err := tx.query(ctx, query, id)
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	defer rows.Close()
+
+	tasks := []domain.Task{}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, rows.Err()
+}
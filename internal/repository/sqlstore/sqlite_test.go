@@ -0,0 +1,61 @@
+package sqlstore_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/yelnar0112/project-management/internal/config"
+	"github.com/yelnar0112/project-management/internal/migrate"
+	"github.com/yelnar0112/project-management/internal/repository/repotest"
+	"github.com/yelnar0112/project-management/internal/repository/sqlstore"
+)
+
+func TestSQLite(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		db, err := sql.Open(config.DriverSQLite, config.SQLiteDSN(filepath.Join(t.TempDir(), "test.db")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+		return open(t, db, sqlstore.SQLite)
+	})
+}
+
+// open migrates an empty database and builds the repositories on it.
+func open(t *testing.T, db *sql.DB, dialect sqlstore.Dialect) repotest.Repositories {
+	t.Helper()
+	driver := config.DriverPostgres
+	if dialect == sqlstore.SQLite {
+		driver = config.DriverSQLite
+	}
+	m, err := migrate.New(db, driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	store := sqlstore.New(db, dialect)
+	return repotest.Repositories{
+		Users:         sqlstore.NewUserRepository(store),
+		Tasks:         sqlstore.NewTaskRepository(store),
+		Projects:      sqlstore.NewProjectRepository(store),
+		Workflows:     sqlstore.NewWorkflowRepository(store),
+		Boards:        sqlstore.NewBoardRepository(store),
+		Sessions:      sqlstore.NewSessionRepository(store),
+		Members:       sqlstore.NewMemberRepository(store),
+		APITokens:     sqlstore.NewAPITokenRepository(store),
+		Organizations: sqlstore.NewOrganizationRepository(store),
+		Comments:      sqlstore.NewCommentRepository(store),
+		Attachments:   sqlstore.NewAttachmentRepository(store),
+		Dependencies:  sqlstore.NewDependencyRepository(store),
+		Labels:        sqlstore.NewLabelRepository(store),
+		Notifications: sqlstore.NewNotificationRepository(store),
+		Worklogs:      sqlstore.NewWorklogRepository(store),
+		Sprints:       sqlstore.NewSprintRepository(store),
+		Milestones:    sqlstore.NewMilestoneRepository(store),
+	}
+}
@@ -0,0 +1,143 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/yelnar0112/project-management/internal/repository"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Dialect identifies the SQL database behind a DB. Queries are written once
+// with $N placeholders, which both Postgres and SQLite understand; the
+// dialect only decides how arguments are encoded and errors are classified.
+type Dialect int
+
+const (
+	Postgres Dialect = iota
+	SQLite
+)
+
+// sqliteTime is a fixed-width UTC layout, so that timestamps stored as text
+// in SQLite compare correctly as strings.
+const sqliteTime = "2006-01-02 15:04:05.000000000+00:00"
+
+// DB wraps a database handle together with its dialect.
+type DB struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+func New(db *sql.DB, dialect Dialect) *DB {
+	return &DB{db: db, dialect: dialect}
+}
+
+func (d *DB) exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return d.db.ExecContext(ctx, query, d.bind(args)...)
+}
+
+func (d *DB) query(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return d.db.QueryContext(ctx, query, d.bind(args)...)
+}
+
+func (d *DB) queryRow(ctx context.Context, query string, args ...any) *sql.Row {
+	return d.db.QueryRowContext(ctx, query, d.bind(args)...)
+}
+
+// Tx is a transaction on a DB. Its methods encode arguments like those of DB.
+type Tx struct {
+	tx *sql.Tx
+	db *DB
+}
+
+func (t *Tx) exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return t.tx.ExecContext(ctx, query, t.db.bind(args)...)
+}
+
+func (t *Tx) query(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return t.tx.QueryContext(ctx, query, t.db.bind(args)...)
+}
+
+func (t *Tx) queryRow(ctx context.Context, query string, args ...any) *sql.Row {
+	return t.tx.QueryRowContext(ctx, query, t.db.bind(args)...)
+}
+
+// inTx runs fn in a transaction, which is committed if fn returns nil and
+// rolled back otherwise.
+func (d *DB) inTx(ctx context.Context, fn func(tx *Tx) error) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(&Tx{tx: tx, db: d}); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (d *DB) bind(args []any) []any {
+	if d.dialect != SQLite {
+		return args
+	}
+	for i, arg := range args {
+		if t, ok := arg.(time.Time); ok {
+			args[i] = t.UTC().Format(sqliteTime)
+		}
+	}
+	return args
+}
+
+// translate maps driver errors onto the repository error values.
+func (d *DB) translate(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return repository.ErrNotFound
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "23505":
+			return repository.ErrDuplicate
+		case "23503":
+			return repository.ErrReferenced
+		}
+	}
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.Code() {
+		case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+			return repository.ErrDuplicate
+		case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+			return repository.ErrReferenced
+		}
+	}
+
+	return err
+}
+
+// checkAffected reports ErrNotFound when a statement touched no rows.
+func (d *DB) checkAffected(result sql.Result, err error) error {
+	if err != nil {
+		return d.translate(err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// nullUUID stores an unset reference as NULL so that optional foreign keys
+// are not checked against the zero UUID.
+func nullUUID(id uuid.UUID) uuid.NullUUID {
+	return uuid.NullUUID{UUID: id, Valid: id != uuid.Nil}
+}
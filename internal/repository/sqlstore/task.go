@@ -0,0 +1,339 @@
+package sqlstore
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+type TaskRepository struct {
+	db *DB
+}
+
+func NewTaskRepository(db *DB) *TaskRepository {
+	return &TaskRepository{db: db}
+}
+
+const taskFields = "id, title, description, priority, state, assignee, project_id, parent_id, sprint_id, milestone_id, board_rank, start_date, due_date, original_estimate, remaining_estimate, created_at, completed_at, organization_id"
+
+const selectTasks = "SELECT " + taskFields + " FROM tasks"
+
+var taskColumns = map[string]string{
+	"priority":     "priority_rank",
+	"created_at":   "created_at",
+	"completed_at": "completed_at",
+	"due_date":     "due_date",
+	"title":        "title",
+}
+
+func scanTask(row scanner) (domain.Task, error) {
+	task := domain.Task{Labels: []uuid.UUID{}}
+	err := row.Scan(&task.ID, &task.Title, &task.Description, &task.Priority, &task.State, &task.Assignee, &task.ProjectID, &task.ParentID, &task.SprintID, &task.MilestoneID, &task.Rank, &task.StartDate, &task.DueDate, &task.OriginalEstimate, &task.RemainingEstimate, &task.CreatedAt, &task.CompletedAt, &task.OrganizationID)
+	return task, err
+}
+
+func (r *TaskRepository) GetAll(ctx context.Context, query repository.TaskQuery) (*repository.Page[domain.Task], error) {
+	q := taskFilters(&query)
+	page, err := list(ctx, r.db, q, selectTasks, repository.TaskSorting, taskColumns,
+		query.Sort, query.Limit, query.Cursor, scanTask)
+	if err != nil {
+		return nil, err
+	}
+	return page, r.loadLabels(ctx, page.Items)
+}
+
+// loadLabels fills in the labels of tasks with a second query.
+func (r *TaskRepository) loadLabels(ctx context.Context, tasks []domain.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	var q listQuery
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID.String()
+	}
+	q.addIn("tl.task_id", ids)
+
+	var b strings.Builder
+	b.WriteString("SELECT tl.task_id, tl.label_id FROM task_labels tl JOIN labels l ON l.id = tl.label_id")
+	q.writeWhere(&b)
+	b.WriteString(" ORDER BY l.name, l.id")
+	rows, err := r.db.query(ctx, b.String(), q.args...)
+	if err != nil {
+		return r.db.translate(err)
+	}
+	defer rows.Close()
+
+	index := make(map[uuid.UUID]int, len(tasks))
+	for i, task := range tasks {
+		index[task.ID] = i
+	}
+	for rows.Next() {
+		var taskID, labelID uuid.UUID
+		if err := rows.Scan(&taskID, &labelID); err != nil {
+			return err
+		}
+		if i, ok := index[taskID]; ok {
+			tasks[i].Labels = append(tasks[i].Labels, labelID)
+		}
+	}
+	return rows.Err()
+}
+
+func (r *TaskRepository) Count(ctx context.Context, query repository.TaskQuery) (int, error) {
+	return taskFilters(&query).count(ctx, r.db, "tasks")
+}
+
+func taskFilters(query *repository.TaskQuery) *listQuery {
+	var q listQuery
+	if query.OrganizationID != uuid.Nil {
+		q.add("organization_id = ?", query.OrganizationID)
+	}
+	q.addIn("state", query.States)
+	priorities := make([]string, len(query.Priorities))
+	for i, p := range query.Priorities {
+		priorities[i] = string(p)
+	}
+	q.addIn("priority", priorities)
+	if query.Assignee != uuid.Nil {
+		q.add("assignee = ?", query.Assignee)
+	}
+	if query.ProjectID != uuid.Nil {
+		q.add("project_id = ?", query.ProjectID)
+	}
+	if query.ParentID != uuid.Nil {
+		q.add("parent_id = ?", query.ParentID)
+	}
+	if query.SprintID != uuid.Nil {
+		q.add("sprint_id = ?", query.SprintID)
+	}
+	if query.MilestoneID != uuid.Nil {
+		q.add("milestone_id = ?", query.MilestoneID)
+	}
+	if query.Backlog {
+		q.add("sprint_id IS NULL")
+	}
+	if !query.CreatedAfter.IsZero() {
+		q.add("created_at >= ?", query.CreatedAfter)
+	}
+	if !query.CreatedBefore.IsZero() {
+		q.add("created_at < ?", query.CreatedBefore)
+	}
+	if !query.CompletedAfter.IsZero() {
+		q.add("completed_at >= ?", query.CompletedAfter)
+	}
+	if !query.CompletedBefore.IsZero() {
+		// Open tasks store the zero time, which is before any bound.
+		q.add("completed_at < ? AND completed_at > ?", query.CompletedBefore, time.Time{})
+	}
+	if !query.DueAfter.IsZero() {
+		q.add("due_date >= ?", query.DueAfter)
+	}
+	if !query.DueBefore.IsZero() {
+		// Tasks without a due date store the zero time.
+		q.add("due_date < ? AND due_date > ?", query.DueBefore, time.Time{})
+	}
+	if query.Open {
+		q.add("completed_at = ?", time.Time{})
+	}
+	if !query.OverdueAt.IsZero() {
+		q.add("completed_at = ? AND due_date > ? AND due_date < ?", time.Time{}, time.Time{}, query.OverdueAt)
+	}
+	if query.Unreminded != "" {
+		q.add(`NOT EXISTS (SELECT 1 FROM notifications n
+			WHERE n.task_id = tasks.id AND n.kind = ? AND n.due_date = tasks.due_date)`, query.Unreminded)
+	}
+	if len(query.Labels) > 0 {
+		names := make(map[string]bool)
+		var in listQuery
+		for _, name := range query.Labels {
+			name = strings.ToLower(name)
+			if !names[name] {
+				names[name] = true
+				in.args = append(in.args, name)
+			}
+		}
+		placeholders := make([]string, len(in.args))
+		for i := range placeholders {
+			placeholders[i] = "?"
+		}
+		labeled := `SELECT COUNT(*) FROM task_labels tl JOIN labels l ON l.id = tl.label_id
+			WHERE tl.task_id = tasks.id AND lower(l.name) IN (` + strings.Join(placeholders, ", ") + ")"
+		if query.AllLabels {
+			// A project has at most one label by each name.
+			q.add("("+labeled+") = ?", append(in.args, len(in.args))...)
+		} else {
+			q.add("("+labeled+") > 0", in.args...)
+		}
+	}
+	return &q
+}
+
+func (r *TaskRepository) Create(ctx context.Context, task *domain.Task) error {
+	return r.db.inTx(ctx, func(tx *Tx) error {
+		return r.create(ctx, tx, task)
+	})
+}
+
+func (r *TaskRepository) CreateWithin(ctx context.Context, task *domain.Task, limits []repository.WIPLimit) error {
+	return r.db.inTx(ctx, func(tx *Tx) error {
+		if err := r.lockProject(ctx, tx, task.ProjectID, limits); err != nil {
+			return err
+		}
+		if err := r.create(ctx, tx, task); err != nil {
+			return err
+		}
+		return r.checkLimits(ctx, tx, limits)
+	})
+}
+
+// create inserts a new task within tx.
+func (r *TaskRepository) create(ctx context.Context, tx *Tx, task *domain.Task) error {
+	_, err := tx.exec(ctx,
+		"INSERT INTO tasks (id, title, description, priority, priority_rank, state, assignee, project_id, parent_id, sprint_id, milestone_id, board_rank, start_date, due_date, original_estimate, remaining_estimate, created_at, completed_at, organization_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)",
+		task.ID, task.Title, task.Description, task.Priority, task.Priority.Rank(), task.State, nullUUID(task.Assignee), task.ProjectID, nullUUID(task.ParentID), nullUUID(task.SprintID), nullUUID(task.MilestoneID), task.Rank, task.StartDate, task.DueDate, task.OriginalEstimate, task.RemainingEstimate, task.CreatedAt, task.CompletedAt, task.OrganizationID,
+	)
+	if err != nil {
+		return r.db.translate(err)
+	}
+	return r.insertLabels(ctx, tx, task)
+}
+
+// lockProject makes writes into a project that are checked against limits
+// wait on each other, so that each counts the tasks the ones before it
+// wrote. SQLite transactions already take the write lock when they begin;
+// on Postgres, they wait on the project's row.
+func (r *TaskRepository) lockProject(ctx context.Context, tx *Tx, projectID uuid.UUID, limits []repository.WIPLimit) error {
+	if len(limits) == 0 || r.db.dialect != Postgres {
+		return nil
+	}
+	var id uuid.UUID
+	err := tx.queryRow(ctx, "SELECT id FROM projects WHERE id = $1 FOR UPDATE", projectID).Scan(&id)
+	return r.db.translate(err)
+}
+
+// checkLimits counts the tasks in the states of each of limits as written
+// so far by tx, returning ErrLimitReached when there are too many.
+func (r *TaskRepository) checkLimits(ctx context.Context, tx *Tx, limits []repository.WIPLimit) error {
+	for _, limit := range limits {
+		n, err := taskFilters(&repository.TaskQuery{ProjectID: limit.ProjectID, States: limit.States}).
+			count(ctx, tx, "tasks")
+		if err != nil {
+			return err
+		}
+		if n > limit.Max {
+			return repository.ErrLimitReached
+		}
+	}
+	return nil
+}
+
+func (r *TaskRepository) insertLabels(ctx context.Context, tx *Tx, task *domain.Task) error {
+	for _, labelID := range task.Labels {
+		_, err := tx.exec(ctx, "INSERT INTO task_labels (task_id, label_id) VALUES ($1, $2)", task.ID, labelID)
+		if err != nil {
+			return r.db.translate(err)
+		}
+	}
+	return nil
+}
+
+func (r *TaskRepository) Get(ctx context.Context, id uuid.UUID) (*domain.Task, error) {
+	task, err := scanTask(r.db.queryRow(ctx, selectTasks+" WHERE id = $1", id))
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	tasks := []domain.Task{task}
+	if err := r.loadLabels(ctx, tasks); err != nil {
+		return nil, err
+	}
+	return &tasks[0], nil
+}
+
+// selectSubtasks walks down from a task with a recursive query.
+const selectSubtasks = `WITH RECURSIVE subtasks (id) AS (
+		SELECT id FROM tasks WHERE parent_id = $1
+		UNION
+		SELECT t.id FROM tasks t JOIN subtasks s ON t.parent_id = s.id
+	)`
+
+func (r *TaskRepository) Subtasks(ctx context.Context, id uuid.UUID) ([]domain.Task, error) {
+	rows, err := r.db.query(ctx, selectSubtasks+" "+selectTasks+" WHERE id IN (SELECT id FROM subtasks) ORDER BY created_at, id", id)
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	defer rows.Close()
+
+	tasks := []domain.Task{}
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return tasks, r.loadLabels(ctx, tasks)
+}
+
+func (r *TaskRepository) Update(ctx context.Context, task *domain.Task) error {
+	return r.db.inTx(ctx, func(tx *Tx) error {
+		return r.update(ctx, tx, task)
+	})
+}
+
+// update replaces a task within tx, carrying its subtasks along to its
+// project.
+func (r *TaskRepository) update(ctx context.Context, tx *Tx, task *domain.Task) error {
+	err := r.db.checkAffected(tx.exec(ctx,
+		"UPDATE tasks SET title = $1, description = $2, priority = $3, priority_rank = $4, state = $5, assignee = $6, project_id = $7, parent_id = $8, sprint_id = $9, milestone_id = $10, board_rank = $11, start_date = $12, due_date = $13, original_estimate = $14, remaining_estimate = $15, created_at = $16, completed_at = $17 WHERE id = $18",
+		task.Title, task.Description, task.Priority, task.Priority.Rank(), task.State, nullUUID(task.Assignee), task.ProjectID, nullUUID(task.ParentID), nullUUID(task.SprintID), nullUUID(task.MilestoneID), task.Rank, task.StartDate, task.DueDate, task.OriginalEstimate, task.RemainingEstimate, task.CreatedAt, task.CompletedAt, task.ID,
+	))
+	if err != nil {
+		return err
+	}
+	if _, err := tx.exec(ctx, "DELETE FROM task_labels WHERE task_id = $1", task.ID); err != nil {
+		return r.db.translate(err)
+	}
+	if err := r.insertLabels(ctx, tx, task); err != nil {
+		return err
+	}
+	_, err = tx.exec(ctx, selectSubtasks+" UPDATE tasks SET project_id = $2, sprint_id = NULL, milestone_id = NULL, board_rank = '' WHERE id IN (SELECT id FROM subtasks) AND project_id <> $2",
+		task.ID, task.ProjectID)
+	if err != nil {
+		return r.db.translate(err)
+	}
+	_, err = tx.exec(ctx, selectSubtasks+` DELETE FROM task_labels WHERE task_id IN (SELECT id FROM subtasks)
+		AND label_id NOT IN (SELECT id FROM labels WHERE project_id = $2)`,
+		task.ID, task.ProjectID)
+	return r.db.translate(err)
+}
+
+func (r *TaskRepository) Move(ctx context.Context, task *domain.Task, ranks map[uuid.UUID]string,
+	limits []repository.WIPLimit) error {
+	return r.db.inTx(ctx, func(tx *Tx) error {
+		if err := r.lockProject(ctx, tx, task.ProjectID, limits); err != nil {
+			return err
+		}
+		if err := r.update(ctx, tx, task); err != nil {
+			return err
+		}
+		for id, rank := range ranks {
+			err := r.db.checkAffected(tx.exec(ctx, "UPDATE tasks SET board_rank = $1 WHERE id = $2", rank, id))
+			if err != nil {
+				return err
+			}
+		}
+		return r.checkLimits(ctx, tx, limits)
+	})
+}
+
+func (r *TaskRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.checkAffected(r.db.exec(ctx, "DELETE FROM tasks WHERE id = $1", id))
+}
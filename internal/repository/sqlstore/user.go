@@ -0,0 +1,159 @@
+package sqlstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+type UserRepository struct {
+	db *DB
+}
+
+func NewUserRepository(db *DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+const selectUsers = "SELECT id, full_name, email, handle, registration, password_hash, '' FROM users"
+
+// selectOrganizationUsers lists users with their role in an organization,
+// which is chosen by a condition on m.organization_id.
+const selectOrganizationUsers = "SELECT id, full_name, email, handle, registration, password_hash, m.role FROM users JOIN organization_members m ON m.user_id = id"
+
+var userColumns = map[string]string{
+	"full_name":    "full_name",
+	"email":        "email",
+	"registration": "registration",
+}
+
+func scanUser(row scanner) (domain.User, error) {
+	var user domain.User
+	err := row.Scan(&user.ID, &user.FullName, &user.Email, &user.Handle, &user.Registration, &user.PasswordHash, &user.Role)
+	return user, err
+}
+
+func (r *UserRepository) GetAll(ctx context.Context, query repository.UserQuery) (*repository.Page[domain.User], error) {
+	var q listQuery
+	selectFrom := selectUsers
+	if query.OrganizationID != uuid.Nil {
+		selectFrom = selectOrganizationUsers
+		q.add("m.organization_id = ?", query.OrganizationID)
+		if query.Role != "" {
+			q.add("m.role = ?", query.Role)
+		}
+	}
+
+	return list(ctx, r.db, &q, selectFrom, repository.UserSorting, userColumns,
+		query.Sort, query.Limit, query.Cursor, scanUser)
+}
+
+const insertUser = "INSERT INTO users (id, full_name, email, handle, registration, password_hash) VALUES ($1, $2, $3, $4, $5, $6)"
+
+func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
+	_, err := r.db.exec(ctx, insertUser, user.ID, user.FullName, user.Email, user.Handle, user.Registration, user.PasswordHash)
+	return r.db.translate(err)
+}
+
+func (r *UserRepository) CreateWithMembership(ctx context.Context, user *domain.User, member *domain.OrganizationMember) error {
+	return r.db.inTx(ctx, func(tx *Tx) error {
+		_, err := tx.exec(ctx, insertUser, user.ID, user.FullName, user.Email, user.Handle, user.Registration, user.PasswordHash)
+		if err != nil {
+			return r.db.translate(err)
+		}
+		_, err = tx.exec(ctx, insertOrganizationMember, member.OrganizationID, member.UserID, member.Role, member.JoinedAt)
+		return r.db.translate(err)
+	})
+}
+
+func (r *UserRepository) Get(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	user, err := scanUser(r.db.queryRow(ctx, selectUsers+" WHERE id = $1", id))
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	user, err := scanUser(r.db.queryRow(ctx, selectUsers+" WHERE lower(email) = lower($1)", email))
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) GetByHandle(ctx context.Context, handle string) (*domain.User, error) {
+	user, err := scanUser(r.db.queryRow(ctx, selectUsers+" WHERE handle = lower($1) AND handle <> ''", handle))
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
+	return r.db.checkAffected(r.db.exec(ctx, "UPDATE users SET full_name = $1, email = $2, handle = $3, registration = $4, password_hash = $5 WHERE id = $6",
+		user.FullName, user.Email, user.Handle, user.Registration, user.PasswordHash, user.ID))
+}
+
+func (r *UserRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.checkAffected(r.db.exec(ctx, "DELETE FROM users WHERE id = $1", id))
+}
+
+func (r *UserRepository) DeleteReassigning(ctx context.Context, id, assignee uuid.UUID) error {
+	return r.db.inTx(ctx, func(tx *Tx) error {
+		statements := []struct {
+			query string
+			args  []any
+		}{
+			{"UPDATE tasks SET assignee = $1 WHERE assignee = $2 AND completed_at = $3", []any{nullUUID(assignee), id, time.Time{}}},
+			{"UPDATE tasks SET assignee = NULL WHERE assignee = $1", []any{id}},
+			{"UPDATE projects SET manager_id = NULL WHERE manager_id = $1", []any{id}},
+		}
+		if assignee != uuid.Nil {
+			if err := r.joinProjects(ctx, tx, id, assignee); err != nil {
+				return err
+			}
+		}
+		for _, stmt := range statements {
+			if _, err := tx.exec(ctx, stmt.query, stmt.args...); err != nil {
+				return r.db.translate(err)
+			}
+		}
+		return r.db.checkAffected(tx.exec(ctx, "DELETE FROM users WHERE id = $1", id))
+	})
+}
+
+// joinProjects makes assignee a contributor of every project in which from
+// has open tasks, so that it can take them over.
+func (r *UserRepository) joinProjects(ctx context.Context, tx *Tx, from, assignee uuid.UUID) error {
+	rows, err := tx.query(ctx, "SELECT DISTINCT project_id FROM tasks WHERE assignee = $1 AND completed_at = $2", from, time.Time{})
+	if err != nil {
+		return r.db.translate(err)
+	}
+	var projectIDs []uuid.UUID
+	for rows.Next() {
+		var projectID uuid.UUID
+		if err := rows.Scan(&projectID); err != nil {
+			rows.Close()
+			return err
+		}
+		projectIDs = append(projectIDs, projectID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	joinedAt := time.Now().UTC()
+	for _, projectID := range projectIDs {
+		_, err := tx.exec(ctx, `INSERT INTO project_members (project_id, user_id, role, joined_at) VALUES ($1, $2, $3, $4)
+			ON CONFLICT (project_id, user_id) DO UPDATE SET role = $5 WHERE project_members.role = $6`,
+			projectID, assignee, domain.ProjectContributor, joinedAt, domain.ProjectContributor, domain.ProjectObserver)
+		if err != nil {
+			return r.db.translate(err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package sqlstore
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+)
+
+type WorkflowRepository struct {
+	db *DB
+}
+
+func NewWorkflowRepository(db *DB) *WorkflowRepository {
+	return &WorkflowRepository{db: db}
+}
+
+func (r *WorkflowRepository) Get(ctx context.Context, projectID uuid.UUID) (*domain.Workflow, error) {
+	var states, transitions string
+	workflow := domain.Workflow{ProjectID: projectID}
+	err := r.db.queryRow(ctx,
+		"SELECT initial_state, states, transitions FROM workflows WHERE project_id = $1", projectID,
+	).Scan(&workflow.InitialState, &states, &transitions)
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+
+	if err := json.Unmarshal([]byte(states), &workflow.States); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(transitions), &workflow.Transitions); err != nil {
+		return nil, err
+	}
+	return &workflow, nil
+}
+
+func (r *WorkflowRepository) Save(ctx context.Context, workflow *domain.Workflow) error {
+	states, err := json.Marshal(workflow.States)
+	if err != nil {
+		return err
+	}
+	transitions, err := json.Marshal(workflow.Transitions)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.exec(ctx,
+		`INSERT INTO workflows (project_id, initial_state, states, transitions) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (project_id) DO UPDATE SET initial_state = $2, states = $3, transitions = $4`,
+		workflow.ProjectID, workflow.InitialState, string(states), string(transitions),
+	)
+	return r.db.translate(err)
+}
@@ -0,0 +1,166 @@
+package sqlstore
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+type WorklogRepository struct {
+	db *DB
+}
+
+func NewWorklogRepository(db *DB) *WorklogRepository {
+	return &WorklogRepository{db: db}
+}
+
+const selectWorklogs = "SELECT id, task_id, user_id, started_at, ended_at, seconds, note, created_at FROM worklogs"
+
+func scanWorklog(row scanner) (domain.Worklog, error) {
+	var worklog domain.Worklog
+	err := row.Scan(&worklog.ID, &worklog.TaskID, &worklog.UserID, &worklog.StartedAt, &worklog.EndedAt,
+		&worklog.Seconds, &worklog.Note, &worklog.CreatedAt)
+	return worklog, err
+}
+
+func (r *WorklogRepository) List(ctx context.Context, query repository.WorklogQuery) ([]domain.Worklog, error) {
+	var q listQuery
+	if query.OrganizationID != uuid.Nil {
+		q.add("task_id IN (SELECT id FROM tasks WHERE organization_id = ?)", query.OrganizationID)
+	}
+	if query.ProjectID != uuid.Nil {
+		q.add("task_id IN (SELECT id FROM tasks WHERE project_id = ?)", query.ProjectID)
+	}
+	if query.TaskID != uuid.Nil {
+		q.add("task_id = ?", query.TaskID)
+	}
+	if query.UserID != uuid.Nil {
+		q.add("user_id = ?", query.UserID)
+	}
+	if !query.StartedAfter.IsZero() {
+		q.add("started_at >= ?", query.StartedAfter)
+	}
+	if !query.StartedBefore.IsZero() {
+		q.add("started_at < ?", query.StartedBefore)
+	}
+	if query.Finished {
+		q.add("ended_at > ?", time.Time{})
+	}
+
+	var b strings.Builder
+	b.WriteString(selectWorklogs)
+	q.writeWhere(&b)
+	b.WriteString(" ORDER BY started_at, id")
+	rows, err := r.db.query(ctx, b.String(), q.args...)
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	defer rows.Close()
+
+	worklogs := []domain.Worklog{}
+	for rows.Next() {
+		worklog, err := scanWorklog(rows)
+		if err != nil {
+			return nil, err
+		}
+		worklogs = append(worklogs, worklog)
+	}
+	return worklogs, rows.Err()
+}
+
+func (r *WorklogRepository) Get(ctx context.Context, id uuid.UUID) (*domain.Worklog, error) {
+	worklog, err := scanWorklog(r.db.queryRow(ctx, selectWorklogs+" WHERE id = $1", id))
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	return &worklog, nil
+}
+
+func (r *WorklogRepository) Running(ctx context.Context, userID uuid.UUID) (*domain.Worklog, error) {
+	worklog, err := scanWorklog(r.db.queryRow(ctx, selectWorklogs+" WHERE user_id = $1 AND ended_at = $2",
+		userID, time.Time{}))
+	if err != nil {
+		return nil, r.db.translate(err)
+	}
+	return &worklog, nil
+}
+
+func (r *WorklogRepository) Create(ctx context.Context, worklog *domain.Worklog,
+	logWork func(task *domain.Task, seconds int64)) error {
+	return r.db.inTx(ctx, func(tx *Tx) error {
+		_, err := tx.exec(ctx,
+			`INSERT INTO worklogs (id, task_id, user_id, started_at, ended_at, seconds, note, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			worklog.ID, worklog.TaskID, nullUUID(worklog.UserID), worklog.StartedAt, worklog.EndedAt,
+			worklog.Seconds, worklog.Note, worklog.CreatedAt,
+		)
+		if err != nil {
+			return r.db.translate(err)
+		}
+		return r.logWork(ctx, tx, worklog.TaskID, worklog.Seconds, logWork)
+	})
+}
+
+func (r *WorklogRepository) Update(ctx context.Context, worklog *domain.Worklog,
+	logWork func(task *domain.Task, seconds int64)) error {
+	return r.db.inTx(ctx, func(tx *Tx) error {
+		var taskID uuid.UUID
+		var seconds int64
+		err := tx.queryRow(ctx, "SELECT task_id, seconds FROM worklogs WHERE id = $1"+r.forUpdate(), worklog.ID).
+			Scan(&taskID, &seconds)
+		if err != nil {
+			return r.db.translate(err)
+		}
+		err = r.db.checkAffected(tx.exec(ctx,
+			"UPDATE worklogs SET started_at = $1, ended_at = $2, seconds = $3, note = $4 WHERE id = $5",
+			worklog.StartedAt, worklog.EndedAt, worklog.Seconds, worklog.Note, worklog.ID,
+		))
+		if err != nil {
+			return err
+		}
+		return r.logWork(ctx, tx, taskID, worklog.Seconds-seconds, logWork)
+	})
+}
+
+func (r *WorklogRepository) Delete(ctx context.Context, id uuid.UUID,
+	logWork func(task *domain.Task, seconds int64)) error {
+	return r.db.inTx(ctx, func(tx *Tx) error {
+		var taskID uuid.UUID
+		var seconds int64
+		err := tx.queryRow(ctx, "DELETE FROM worklogs WHERE id = $1 RETURNING task_id, seconds", id).Scan(&taskID, &seconds)
+		if err != nil {
+			return r.db.translate(err)
+		}
+		return r.logWork(ctx, tx, taskID, -seconds, logWork)
+	})
+}
+
+// logWork calls logWork within tx with a task and the change in the seconds
+// logged on it, and saves the remaining estimate it leaves. On Postgres the
+// task's row stays locked until tx ends, so that changes to its worklogs
+// adjust it one after the other; SQLite writes one transaction at a time.
+func (r *WorklogRepository) logWork(ctx context.Context, tx *Tx, taskID uuid.UUID, seconds int64,
+	logWork func(task *domain.Task, seconds int64)) error {
+	if seconds == 0 {
+		return nil
+	}
+	task, err := scanTask(tx.queryRow(ctx, selectTasks+" WHERE id = $1"+r.forUpdate(), taskID))
+	if err != nil {
+		return r.db.translate(err)
+	}
+	logWork(&task, seconds)
+	return r.db.checkAffected(tx.exec(ctx, "UPDATE tasks SET remaining_estimate = $1 WHERE id = $2",
+		task.RemainingEstimate, taskID))
+}
+
+// forUpdate locks the rows a query selects on Postgres.
+func (r *WorklogRepository) forUpdate() string {
+	if r.db.dialect == Postgres {
+		return " FOR UPDATE"
+	}
+	return ""
+}
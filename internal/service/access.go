@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/auth"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+// authorizeProject checks that the caller may take permission on the
+// project, or on a task in it assigned to assignee.
+func authorizeProject(ctx context.Context, members repository.MemberRepository, permission auth.Permission,
+	project *domain.Entity, assignee uuid.UUID) error {
+	target, err := projectTarget(ctx, members, project)
+	if err != nil {
+		return err
+	}
+	target.Assignee = assignee
+	return auth.Authorize(ctx, permission, target)
+}
+
+// projectTarget describes the project, or something in it, as a target of
+// the caller's permissions. Owners of the project are treated like its
+// manager.
+func projectTarget(ctx context.Context, members repository.MemberRepository, project *domain.Entity) (auth.Target, error) {
+	target := auth.Target{ManagerID: project.ManagerID}
+	if principal := auth.PrincipalFrom(ctx); principal != nil {
+		member, err := members.Get(ctx, project.ID, principal.User.ID)
+		switch {
+		case err == nil:
+			target.ProjectRole = member.Role
+		case !errors.Is(err, repository.ErrNotFound):
+			return target, err
+		}
+	}
+	return target, nil
+}
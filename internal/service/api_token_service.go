@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/auth"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+// APITokenService lets users manage their own personal access tokens.
+type APITokenService struct {
+	apiTokens repository.APITokenRepository
+}
+
+func NewAPITokenService(apiTokens repository.APITokenRepository) *APITokenService {
+	return &APITokenService{apiTokens: apiTokens}
+}
+
+// List returns the caller's tokens, newest first.
+func (s *APITokenService) List(ctx context.Context) ([]domain.APIToken, error) {
+	principal := auth.PrincipalFrom(ctx)
+	if principal == nil {
+		return nil, domain.Unauthorized("missing_token", "authentication is required")
+	}
+	return s.apiTokens.List(ctx, principal.User.ID)
+}
+
+// Create issues a token for the caller and returns its secret, which is not
+// stored and cannot be shown again. Tokens can only be created from a
+// password session, so that a leaked token cannot mint others.
+func (s *APITokenService) Create(ctx context.Context, token *domain.APIToken) (string, error) {
+	principal := auth.PrincipalFrom(ctx)
+	if principal == nil {
+		return "", domain.Unauthorized("missing_token", "authentication is required")
+	}
+	if principal.SessionID == uuid.Nil {
+		return "", domain.Forbidden("session_required", "API tokens can only be created after logging in with a password")
+	}
+	if err := normalizeScopes(principal.User, token); err != nil {
+		return "", err
+	}
+
+	now := time.Now().UTC()
+	if !token.ExpiresAt.IsZero() && !token.ExpiresAt.After(now) {
+		return "", domain.Validation("invalid_expiry", "expires_at must be in the future")
+	}
+
+	secret, hash, err := auth.NewAPIToken()
+	if err != nil {
+		return "", err
+	}
+	token.UserID = principal.User.ID
+	token.Hash = hash
+	token.CreatedAt = now
+	token.ExpiresAt = token.ExpiresAt.UTC()
+	token.LastUsedAt = time.Time{}
+	token.RevokedAt = time.Time{}
+	if err := s.apiTokens.Create(ctx, token); err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+// Revoke disables one of the caller's tokens and returns it. Revoking a
+// token twice is not an error. Like creating them, revoking tokens takes a
+// password session, which token scopes cannot stand in for.
+func (s *APITokenService) Revoke(ctx context.Context, id uuid.UUID) (*domain.APIToken, error) {
+	principal := auth.PrincipalFrom(ctx)
+	if principal == nil {
+		return nil, domain.Unauthorized("missing_token", "authentication is required")
+	}
+	if principal.SessionID == uuid.Nil {
+		return nil, domain.Forbidden("session_required", "API tokens can only be revoked after logging in with a password")
+	}
+	token, err := s.apiTokens.Get(ctx, id)
+	// Other users' tokens are reported as missing, so that their IDs cannot
+	// be probed.
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && token.UserID != principal.User.ID) {
+		return nil, domain.NotFound("api_token_not_found", "API token %s not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.apiTokens.Revoke(ctx, id, time.Now().UTC()); err != nil {
+		return nil, fromStore(err, "api_token", id)
+	}
+	token, err = s.apiTokens.Get(ctx, id)
+	return token, fromStore(err, "api_token", id)
+}
+
+// normalizeScopes checks that a token asks for at least one permission and
+// only for permissions the user's role holds. Duplicates are dropped.
+func normalizeScopes(user *domain.User, token *domain.APIToken) error {
+	var held []auth.Permission
+	for _, permission := range auth.Permissions() {
+		if auth.Has(user, permission) {
+			held = append(held, permission)
+		}
+	}
+	if len(token.Scopes) == 0 {
+		return domain.Validation("invalid_scope", "at least one scope is required").With("allowed", held)
+	}
+
+	scopes := make([]string, 0, len(token.Scopes))
+	for _, scope := range token.Scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if !slices.Contains(held, auth.Permission(scope)) {
+			return domain.Validation("invalid_scope", "scope %q is not a permission of role %q", scope, user.Role).
+				With("allowed", held)
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	token.Scopes = scopes
+	return nil
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/auth"
+	"github.com/yelnar0112/project-management/internal/blob"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+// maxFilenameLength is the longest filename kept, in characters.
+const maxFilenameLength = 255
+
+// AttachmentLimits bound what can be uploaded.
+type AttachmentLimits struct {
+	// MaxSize is the largest file accepted, in bytes.
+	MaxSize int64
+	// Types are the accepted content types. An entry such as "image/*"
+	// accepts every subtype.
+	Types []string
+}
+
+func (l AttachmentLimits) accepts(contentType string) bool {
+	for _, accepted := range l.Types {
+		if accepted == contentType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(accepted, "*"); ok && strings.HasSuffix(prefix, "/") &&
+			strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// AttachmentService manages files attached to tasks and projects. Anyone
+// who can read a task or project can download its files; uploading and
+// deleting them takes the attachments:* permissions, scoped to the uploader
+// and the project.
+type AttachmentService struct {
+	attachments repository.AttachmentRepository
+	blobs       blob.Store
+	tasks       repository.TaskRepository
+	projects    repository.ProjectRepository
+	members     repository.MemberRepository
+	limits      AttachmentLimits
+}
+
+func NewAttachmentService(attachments repository.AttachmentRepository, blobs blob.Store,
+	tasks repository.TaskRepository, projects repository.ProjectRepository, members repository.MemberRepository,
+	limits AttachmentLimits) *AttachmentService {
+	return &AttachmentService{
+		attachments: attachments,
+		blobs:       blobs,
+		tasks:       tenantTasks{tasks},
+		projects:    tenantProjects{projects},
+		members:     members,
+		limits:      limits,
+	}
+}
+
+// Limits returns what can be uploaded.
+func (s *AttachmentService) Limits() AttachmentLimits {
+	return s.limits
+}
+
+// List returns the files attached to a task or project, oldest first.
+func (s *AttachmentService) List(ctx context.Context, owner domain.AttachmentOwner) ([]domain.Attachment, error) {
+	if _, err := s.project(ctx, owner); err != nil {
+		return nil, err
+	}
+	return s.attachments.List(ctx, owner)
+}
+
+func (s *AttachmentService) Get(ctx context.Context, owner domain.AttachmentOwner, id uuid.UUID) (*domain.Attachment, error) {
+	if _, err := s.project(ctx, owner); err != nil {
+		return nil, err
+	}
+	return s.get(ctx, owner, id)
+}
+
+// Open returns an attachment together with its content, which the caller
+// must close.
+func (s *AttachmentService) Open(ctx context.Context, owner domain.AttachmentOwner, id uuid.UUID) (*domain.Attachment, io.ReadCloser, error) {
+	attachment, err := s.Get(ctx, owner, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	content, err := s.blobs.Get(ctx, attachment.Key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening attachment %s: %w", id, err)
+	}
+	return attachment, content, nil
+}
+
+// Create stores content, attachment.Size bytes long, and records it as
+// uploaded by the caller. The content type the client declared is trusted
+// when given; otherwise it is guessed from the filename or the content.
+func (s *AttachmentService) Create(ctx context.Context, attachment *domain.Attachment, content io.Reader) error {
+	project, err := s.project(ctx, attachment.Owner())
+	if err != nil {
+		return err
+	}
+	target, err := projectTarget(ctx, s.members, project)
+	if err != nil {
+		return err
+	}
+	if err := auth.Authorize(ctx, auth.AttachmentsCreate, target); err != nil {
+		return err
+	}
+	if attachment.Size > s.limits.MaxSize {
+		return domain.TooLarge("attachment_too_large", "file is %d bytes, the limit is %d",
+			attachment.Size, s.limits.MaxSize).With("max_size", s.limits.MaxSize)
+	}
+	if attachment.Filename, err = cleanFilename(attachment.Filename); err != nil {
+		return err
+	}
+	reader := bufio.NewReader(content)
+	attachment.ContentType = contentType(attachment.ContentType, attachment.Filename, reader)
+	if !s.limits.accepts(attachment.ContentType) {
+		return domain.Unsupported("unsupported_type", "files of type %s are not accepted", attachment.ContentType).
+			With("allowed", s.limits.Types)
+	}
+
+	attachment.Key = "attachments/" + attachment.ID.String()
+	hash := sha256.New()
+	if err := s.blobs.Put(ctx, attachment.Key, io.TeeReader(reader, hash), attachment.Size, attachment.ContentType); err != nil {
+		return err
+	}
+	attachment.Checksum = hex.EncodeToString(hash.Sum(nil))
+	attachment.UploaderID = auth.PrincipalFrom(ctx).User.ID
+	attachment.CreatedAt = time.Now().UTC()
+	if err := s.attachments.Create(ctx, attachment); err != nil {
+		s.discard(ctx, []domain.Attachment{*attachment})
+		return fromStore(err, "attachment", attachment.ID)
+	}
+	return nil
+}
+
+// Delete removes an attachment and its content.
+func (s *AttachmentService) Delete(ctx context.Context, owner domain.AttachmentOwner, id uuid.UUID) error {
+	project, err := s.project(ctx, owner)
+	if err != nil {
+		return err
+	}
+	attachment, err := s.get(ctx, owner, id)
+	if err != nil {
+		return err
+	}
+	target, err := projectTarget(ctx, s.members, project)
+	if err != nil {
+		return err
+	}
+	target.UserID = attachment.UploaderID
+	if err := auth.Authorize(ctx, auth.AttachmentsDelete, target); err != nil {
+		return err
+	}
+	if err := s.attachments.Delete(ctx, id); err != nil {
+		return fromStore(err, "attachment", id)
+	}
+	s.discard(ctx, []domain.Attachment{*attachment})
+	return nil
+}
+
+// project returns the project that owner is, or that its task is in.
+func (s *AttachmentService) project(ctx context.Context, owner domain.AttachmentOwner) (*domain.Entity, error) {
+	projectID := owner.ProjectID
+	if owner.TaskID != uuid.Nil {
+		task, err := s.tasks.Get(ctx, owner.TaskID)
+		if err != nil {
+			return nil, fromStore(err, "task", owner.TaskID)
+		}
+		projectID = task.ProjectID
+	}
+	project, err := s.projects.Get(ctx, projectID)
+	return project, fromStore(err, "project", projectID)
+}
+
+// get returns an attachment of owner, reporting those of others as not
+// found.
+func (s *AttachmentService) get(ctx context.Context, owner domain.AttachmentOwner, id uuid.UUID) (*domain.Attachment, error) {
+	attachment, err := s.attachments.Get(ctx, id)
+	if err == nil && attachment.Owner() != owner {
+		err = repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, fromStore(err, "attachment", id)
+	}
+	return attachment, nil
+}
+
+// forTask and forProject return the attachments a task, or a project with
+// its tasks, takes along when deleted, for discard to clean up after them.
+func (s *AttachmentService) forTask(ctx context.Context, taskID uuid.UUID) ([]domain.Attachment, error) {
+	return s.attachments.List(ctx, domain.AttachmentOwner{TaskID: taskID})
+}
+
+func (s *AttachmentService) forProject(ctx context.Context, projectID uuid.UUID) ([]domain.Attachment, error) {
+	return s.attachments.ListInProject(ctx, projectID)
+}
+
+// discard deletes the content of attachments whose records are gone. The
+// records are what users see, so content that cannot be deleted is only
+// logged.
+func (s *AttachmentService) discard(ctx context.Context, attachments []domain.Attachment) {
+	for _, attachment := range attachments {
+		if err := s.blobs.Delete(ctx, attachment.Key); err != nil && !errors.Is(err, blob.ErrNotFound) {
+			log.Printf("could not delete the content of attachment %s: %v", attachment.ID, err)
+		}
+	}
+}
+
+// cleanFilename keeps the last element of the name the client sent, without
+// control characters or quotes, so that it is safe to offer back in
+// downloads.
+func cleanFilename(name string) (string, error) {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == ".." || name == "/" {
+		return "", domain.Validation("invalid_filename", "filename is required")
+	}
+	if utf8.RuneCountInString(name) > maxFilenameLength {
+		return "", domain.Validation("invalid_filename", "filename is longer than %d characters", maxFilenameLength)
+	}
+	return name, nil
+}
+
+// contentType settles what a file is: the type the client declared, else
+// the one its extension implies, else what its first bytes look like.
+func contentType(declared, filename string, content *bufio.Reader) string {
+	for _, candidate := range []string{declared, mime.TypeByExtension(path.Ext(filename))} {
+		if mediaType, _, err := mime.ParseMediaType(candidate); err == nil && mediaType != "application/octet-stream" {
+			return mediaType
+		}
+	}
+	head, _ := content.Peek(512)
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	return mediaType
+}
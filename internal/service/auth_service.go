@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/auth"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+// TokenPair is what a client receives on login and refresh.
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// AuthService logs users in and out. A login opens a session; the session
+// is kept alive by exchanging its refresh token, which is single use, for a
+// new pair of tokens. Presenting a refresh token twice means it has leaked,
+// so the whole session is revoked. Scripts can skip all of this with a
+// personal API token.
+type AuthService struct {
+	users         repository.UserRepository
+	sessions      repository.SessionRepository
+	apiTokens     repository.APITokenRepository
+	organizations repository.OrganizationRepository
+	tokens        *auth.Tokens
+	refreshTTL    time.Duration
+}
+
+func NewAuthService(users repository.UserRepository, sessions repository.SessionRepository,
+	apiTokens repository.APITokenRepository, organizations repository.OrganizationRepository,
+	tokens *auth.Tokens, refreshTTL time.Duration) *AuthService {
+	return &AuthService{
+		users:         users,
+		sessions:      sessions,
+		apiTokens:     apiTokens,
+		organizations: organizations,
+		tokens:        tokens,
+		refreshTTL:    refreshTTL,
+	}
+}
+
+func (s *AuthService) Login(ctx context.Context, email, password string) (*TokenPair, error) {
+	user, err := s.users.GetByEmail(ctx, email)
+	if errors.Is(err, repository.ErrNotFound) {
+		auth.CheckPassword("", password)
+		return nil, invalidCredentials()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !auth.CheckPassword(user.PasswordHash, password) {
+		return nil, invalidCredentials()
+	}
+
+	now := time.Now().UTC()
+	session := domain.Session{ID: uuid.New(), UserID: user.ID, CreatedAt: now}
+	refreshToken, stored, err := s.newRefreshToken(session.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sessions.Create(ctx, &session, stored); err != nil {
+		return nil, err
+	}
+	return s.tokenPair(user.ID, session.ID, refreshToken, now)
+}
+
+func invalidCredentials() error {
+	return domain.Unauthorized("invalid_credentials", "email or password is incorrect")
+}
+
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	hash := auth.HashToken(refreshToken)
+	stored, err := s.sessions.GetRefreshToken(ctx, hash)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, domain.Unauthorized("invalid_refresh_token", "refresh token is not valid")
+	}
+	if err != nil {
+		return nil, err
+	}
+	session, err := s.sessions.Get(ctx, stored.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	switch {
+	case !stored.UsedAt.IsZero():
+		return nil, s.revokeReused(ctx, session.ID, now)
+	case !session.RevokedAt.IsZero():
+		return nil, domain.Unauthorized("session_revoked", "the session has been signed out")
+	case !now.Before(stored.ExpiresAt):
+		return nil, domain.Unauthorized("refresh_token_expired", "refresh token has expired, log in again")
+	}
+
+	nextToken, next, err := s.newRefreshToken(session.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	err = s.sessions.Rotate(ctx, hash, now, next)
+	if errors.Is(err, repository.ErrNotFound) {
+		// Another request exchanged the same token first.
+		return nil, s.revokeReused(ctx, session.ID, now)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.tokenPair(session.UserID, session.ID, nextToken, now)
+}
+
+func (s *AuthService) revokeReused(ctx context.Context, sessionID uuid.UUID, now time.Time) error {
+	if err := s.sessions.Revoke(ctx, sessionID, now); err != nil {
+		return err
+	}
+	return domain.Unauthorized("refresh_token_reused", "refresh token was already used, the session has been revoked")
+}
+
+// Logout revokes a session, invalidating its refresh token and every access
+// token issued for it.
+func (s *AuthService) Logout(ctx context.Context, sessionID uuid.UUID) error {
+	return fromStore(s.sessions.Revoke(ctx, sessionID, time.Now().UTC()), "session", sessionID)
+}
+
+// Authenticate resolves an access token or API token to the caller it was
+// issued to, acting in organizationID, or when it is nil in the first
+// organization the caller joined.
+func (s *AuthService) Authenticate(ctx context.Context, accessToken string, organizationID uuid.UUID) (*auth.Principal, error) {
+	principal, err := s.authenticate(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.selectOrganization(ctx, principal, organizationID); err != nil {
+		return nil, err
+	}
+	return principal, nil
+}
+
+func (s *AuthService) authenticate(ctx context.Context, accessToken string) (*auth.Principal, error) {
+	if strings.HasPrefix(accessToken, auth.APITokenPrefix) {
+		return s.authenticateAPIToken(ctx, accessToken)
+	}
+
+	userID, sessionID, err := s.tokens.Verify(accessToken)
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return nil, domain.Unauthorized("token_expired", "access token has expired, refresh it").Wrap(err)
+	}
+	if err != nil {
+		return nil, domain.Unauthorized("invalid_token", "access token is not valid").Wrap(err)
+	}
+
+	session, err := s.sessions.Get(ctx, sessionID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && !session.RevokedAt.IsZero()) {
+		return nil, domain.Unauthorized("session_revoked", "the session has been signed out")
+	}
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.users.Get(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, domain.Unauthorized("invalid_token", "access token is not valid")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &auth.Principal{User: user, SessionID: sessionID}, nil
+}
+
+// touchInterval limits how often the last use of an API token is written, so
+// that a busy script does not turn every read into a write.
+const touchInterval = time.Minute
+
+func (s *AuthService) authenticateAPIToken(ctx context.Context, secret string) (*auth.Principal, error) {
+	token, err := s.apiTokens.GetByHash(ctx, auth.HashToken(secret))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, domain.Unauthorized("invalid_token", "API token is not valid")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	switch {
+	case !token.RevokedAt.IsZero():
+		return nil, domain.Unauthorized("token_revoked", "API token has been revoked")
+	case !token.ExpiresAt.IsZero() && !now.Before(token.ExpiresAt):
+		return nil, domain.Unauthorized("token_expired", "API token has expired, create a new one")
+	}
+
+	user, err := s.users.Get(ctx, token.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if now.Sub(token.LastUsedAt) >= touchInterval {
+		if err := s.apiTokens.Touch(ctx, token.ID, now); err != nil {
+			return nil, err
+		}
+	}
+
+	scopes := make([]auth.Permission, 0, len(token.Scopes))
+	for _, scope := range token.Scopes {
+		scopes = append(scopes, auth.Permission(scope))
+	}
+	return &auth.Principal{User: user, TokenID: token.ID, Scopes: scopes}, nil
+}
+
+// selectOrganization sets the organization the caller acts in and its role
+// there. Users who belong to no organization act in none, and may do little
+// more than create one.
+func (s *AuthService) selectOrganization(ctx context.Context, principal *auth.Principal, organizationID uuid.UUID) error {
+	userID := principal.User.ID
+	if organizationID == uuid.Nil {
+		organizations, err := s.organizations.ListForUser(ctx, userID)
+		if err != nil || len(organizations) == 0 {
+			return err
+		}
+		organizationID = organizations[0].ID
+	}
+
+	member, err := s.organizations.GetMember(ctx, organizationID, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return organizationNotFound(organizationID).Wrap(err)
+	}
+	if err != nil {
+		return err
+	}
+	principal.OrganizationID = organizationID
+	principal.User.Role = member.Role
+	return nil
+}
+
+func (s *AuthService) newRefreshToken(sessionID uuid.UUID, now time.Time) (string, *domain.RefreshToken, error) {
+	token, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return "", nil, err
+	}
+	return token, &domain.RefreshToken{Hash: hash, SessionID: sessionID, ExpiresAt: now.Add(s.refreshTTL)}, nil
+}
+
+func (s *AuthService) tokenPair(userID, sessionID uuid.UUID, refreshToken string, now time.Time) (*TokenPair, error) {
+	accessToken, err := s.tokens.Issue(userID, sessionID, now)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPair{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.tokens.TTL().Seconds()),
+		RefreshToken: refreshToken,
+	}, nil
+}
@@ -0,0 +1,146 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/auth"
+)
+
+// TestRefreshTokens checks that a refresh token is exchanged once, and that
+// presenting it again revokes its session along with every token issued
+// for it.
+func TestRefreshTokens(t *testing.T) {
+	s := newServices(t)
+	_, alice := s.addOrganization(t, "alice@example.com", "secret123")
+	ctx := context.Background()
+
+	_, err := s.auth.Login(ctx, "alice@example.com", "wrong")
+	expectProblem(t, "log in with a wrong password", "invalid_credentials", err)
+	_, err = s.auth.Login(ctx, "nobody@example.com", "secret123")
+	expectProblem(t, "log in as nobody", "invalid_credentials", err)
+
+	login, err := s.auth.Login(ctx, "alice@example.com", "secret123")
+	must(t, err)
+	principal, err := s.auth.Authenticate(ctx, login.AccessToken, uuid.Nil)
+	must(t, err)
+	if principal.User.ID != alice.ID || principal.SessionID == uuid.Nil {
+		t.Fatalf("access token resolved to %+v", principal)
+	}
+
+	refreshed, err := s.auth.Refresh(ctx, login.RefreshToken)
+	must(t, err)
+	if refreshed.RefreshToken == login.RefreshToken || refreshed.AccessToken == login.AccessToken {
+		t.Fatalf("refreshing returned the same tokens")
+	}
+	again, err := s.auth.Authenticate(ctx, refreshed.AccessToken, uuid.Nil)
+	must(t, err)
+	if again.SessionID != principal.SessionID {
+		t.Fatalf("refreshing moved to session %s, want %s", again.SessionID, principal.SessionID)
+	}
+
+	// The first token was used; presenting it again means it leaked.
+	_, err = s.auth.Refresh(ctx, login.RefreshToken)
+	expectProblem(t, "reuse a refresh token", "refresh_token_reused", err)
+	_, err = s.auth.Refresh(ctx, refreshed.RefreshToken)
+	expectProblem(t, "refresh after reuse", "session_revoked", err)
+	for _, token := range []string{login.AccessToken, refreshed.AccessToken} {
+		_, err = s.auth.Authenticate(ctx, token, uuid.Nil)
+		expectProblem(t, "authenticate after reuse", "session_revoked", err)
+	}
+
+	_, err = s.auth.Refresh(ctx, "not-a-refresh-token")
+	expectProblem(t, "refresh with an unknown token", "invalid_refresh_token", err)
+}
+
+// TestLogout checks that the access and refresh tokens of a session are
+// refused once it is revoked.
+func TestLogout(t *testing.T) {
+	s := newServices(t)
+	s.addOrganization(t, "alice@example.com", "secret123")
+	ctx := context.Background()
+	login, err := s.auth.Login(ctx, "alice@example.com", "secret123")
+	must(t, err)
+	other, err := s.auth.Login(ctx, "alice@example.com", "secret123")
+	must(t, err)
+	principal, err := s.auth.Authenticate(ctx, login.AccessToken, uuid.Nil)
+	must(t, err)
+
+	must(t, s.auth.Logout(ctx, principal.SessionID))
+	_, err = s.auth.Authenticate(ctx, login.AccessToken, uuid.Nil)
+	expectProblem(t, "authenticate after logging out", "session_revoked", err)
+	_, err = s.auth.Refresh(ctx, login.RefreshToken)
+	expectProblem(t, "refresh after logging out", "session_revoked", err)
+
+	// Other sessions of the user are left alone.
+	_, err = s.auth.Authenticate(ctx, other.AccessToken, uuid.Nil)
+	must(t, err)
+	_, err = s.auth.Refresh(ctx, other.RefreshToken)
+	must(t, err)
+}
+
+// TestAccessTokens checks that access tokens that expired, were not signed
+// with the server's secret or name no session are refused.
+func TestAccessTokens(t *testing.T) {
+	s := newServices(t)
+	_, alice := s.addOrganization(t, "alice@example.com", "secret123")
+	ctx := context.Background()
+	login, err := s.auth.Login(ctx, "alice@example.com", "secret123")
+	must(t, err)
+	principal, err := s.auth.Authenticate(ctx, login.AccessToken, uuid.Nil)
+	must(t, err)
+	session := principal.SessionID
+
+	issue := func(secret string, issuedAt time.Time, sessionID uuid.UUID) string {
+		t.Helper()
+		token, err := auth.NewTokens([]byte(secret), time.Minute).Issue(alice.ID, sessionID, issuedAt)
+		must(t, err)
+		return token
+	}
+	sign := func(method jwt.SigningMethod, key any) string {
+		t.Helper()
+		now := time.Now()
+		token, err := jwt.NewWithClaims(method, auth.Claims{
+			SessionID: session,
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    "project-management",
+				Subject:   alice.ID.String(),
+				IssuedAt:  jwt.NewNumericDate(now),
+				ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+			},
+		}).SignedString(key)
+		must(t, err)
+		return token
+	}
+
+	// Changing the payload of a token breaks its signature.
+	parts := strings.Split(login.AccessToken, ".")
+	claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	must(t, err)
+	parts[1] = base64.RawURLEncoding.EncodeToString(
+		bytes.Replace(claims, []byte(alice.ID.String()), []byte(uuid.NewString()), 1))
+	tampered := strings.Join(parts, ".")
+
+	_, err = s.auth.Authenticate(ctx, issue("secret", time.Now(), session), uuid.Nil)
+	must(t, err)
+	for _, token := range []struct {
+		name, token, code string
+	}{
+		{"expired", issue("secret", time.Now().Add(-2*time.Minute), session), "token_expired"},
+		{"signed with another secret", issue("other", time.Now(), session), "invalid_token"},
+		{"signed with another algorithm", sign(jwt.SigningMethodHS384, []byte("secret")), "invalid_token"},
+		{"unsigned", sign(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType), "invalid_token"},
+		{"tampered with", tampered, "invalid_token"},
+		{"malformed", "not-a-token", "invalid_token"},
+		{"of an unknown session", issue("secret", time.Now(), uuid.New()), "session_revoked"},
+	} {
+		_, err := s.auth.Authenticate(ctx, token.token, uuid.Nil)
+		expectProblem(t, "authenticate with a token "+token.name, token.code, err)
+	}
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/auth"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+// BoardService lays out the boards of projects. Anyone who can read a
+// project's workflow can see its board; setting up its columns takes
+// workflows:update. Moving tasks on a board is part of updating them.
+type BoardService struct {
+	boards    repository.BoardRepository
+	tasks     repository.TaskRepository
+	projects  repository.ProjectRepository
+	members   repository.MemberRepository
+	workflows *WorkflowService
+}
+
+func NewBoardService(boards repository.BoardRepository, tasks repository.TaskRepository,
+	projects repository.ProjectRepository, members repository.MemberRepository,
+	workflows *WorkflowService) *BoardService {
+	return &BoardService{
+		boards:    boards,
+		tasks:     tenantTasks{tasks},
+		projects:  tenantProjects{projects},
+		members:   members,
+		workflows: workflows,
+	}
+}
+
+// Get returns the board of an existing project with the tasks in each of
+// its columns, in board order.
+func (s *BoardService) Get(ctx context.Context, projectID uuid.UUID) (*domain.BoardView, error) {
+	if _, err := s.projects.Get(ctx, projectID); err != nil {
+		return nil, fromStore(err, "project", projectID)
+	}
+	board, err := s.forProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	var states []string
+	for _, column := range board.Columns {
+		states = append(states, column.States...)
+	}
+	tasks, err := s.allTasks(ctx, repository.TaskQuery{ProjectID: projectID, States: states})
+	if err != nil {
+		return nil, err
+	}
+	domain.SortBoard(tasks)
+
+	view := &domain.BoardView{ProjectID: projectID, Columns: make([]domain.BoardColumnView, len(board.Columns))}
+	for i, column := range board.Columns {
+		view.Columns[i] = domain.BoardColumnView{BoardColumn: column, Tasks: []domain.Task{}}
+	}
+	for _, task := range tasks {
+		for i := range view.Columns {
+			if view.Columns[i].Maps(task.State) {
+				view.Columns[i].Tasks = append(view.Columns[i].Tasks, task)
+			}
+		}
+	}
+	for i := range view.Columns {
+		column := &view.Columns[i]
+		column.Count = len(column.Tasks)
+		column.OverLimit = column.WIPLimit > 0 && column.Count > column.WIPLimit
+	}
+	return view, nil
+}
+
+// Update replaces the columns of a project's board, which must map states
+// of its workflow.
+func (s *BoardService) Update(ctx context.Context, board *domain.Board) error {
+	project, err := s.projects.Get(ctx, board.ProjectID)
+	if err != nil {
+		return fromStore(err, "project", board.ProjectID)
+	}
+	if err := authorizeProject(ctx, s.members, auth.WorkflowsUpdate, project, uuid.Nil); err != nil {
+		return err
+	}
+	workflow, err := s.workflows.forProject(ctx, board.ProjectID)
+	if err != nil {
+		return err
+	}
+	if err := board.Validate(workflow); err != nil {
+		return domain.Validation("invalid_board", "%v", err)
+	}
+	return fromStore(s.boards.Save(ctx, board), "project", board.ProjectID)
+}
+
+// forProject falls back to the default board for projects that have not
+// set up their own.
+func (s *BoardService) forProject(ctx context.Context, projectID uuid.UUID) (*domain.Board, error) {
+	board, err := s.boards.Get(ctx, projectID)
+	if errors.Is(err, repository.ErrNotFound) {
+		workflow, err := s.workflows.forProject(ctx, projectID)
+		if err != nil {
+			return nil, err
+		}
+		return domain.DefaultBoard(workflow), nil
+	}
+	return board, err
+}
+
+// allTasks returns every task matching query.
+func (s *BoardService) allTasks(ctx context.Context, query repository.TaskQuery) ([]domain.Task, error) {
+	query.Limit = repository.MaxLimit
+	tasks := []domain.Task{}
+	for {
+		page, err := s.tasks.GetAll(ctx, query)
+		if err != nil {
+			return nil, fromStore(err, "task", uuid.Nil)
+		}
+		tasks = append(tasks, page.Items...)
+		if page.NextCursor == "" {
+			return tasks, nil
+		}
+		query.Cursor = page.NextCursor
+	}
+}
+
+// checkWIP checks the WIP limit of the board column that a task of the
+// project enters by moving to state from state from, which is empty for
+// tasks new to the project. A full column with a "reject" policy refuses
+// the task; with "warn", the task goes in and a warning is returned. The
+// limits of the columns that reject are returned too, for the write to
+// check them again as it moves the task.
+func (s *BoardService) checkWIP(ctx context.Context, projectID uuid.UUID, state, from string) (*domain.WIPWarning,
+	[]repository.WIPLimit, error) {
+	board, err := s.boards.Get(ctx, projectID)
+	if errors.Is(err, repository.ErrNotFound) {
+		// The default board has no limits.
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	column := board.ColumnOf(state)
+	if column == nil || column.WIPLimit == 0 || from != "" && column.Maps(from) {
+		return nil, nil, nil
+	}
+
+	count, err := s.tasks.Count(ctx, repository.TaskQuery{ProjectID: projectID, States: column.States})
+	if err != nil {
+		return nil, nil, err
+	}
+	if column.WIPPolicy == domain.WIPReject {
+		if count >= column.WIPLimit {
+			return nil, nil, domain.Conflict("wip_limit_exceeded", "column %q already holds %d tasks, its WIP limit",
+				column.Name, count).With("column", column.Name).With("wip_limit", column.WIPLimit).With("count", count)
+		}
+		return nil, []repository.WIPLimit{wipLimit(projectID, column)}, nil
+	}
+	if count < column.WIPLimit {
+		return nil, nil, nil
+	}
+	return &domain.WIPWarning{
+		Code:     "wip_limit_exceeded",
+		Column:   column.Name,
+		WIPLimit: column.WIPLimit,
+		Count:    count + 1,
+	}, nil, nil
+}
+
+// checkWIPEntering checks the WIP limits of the board columns that tasks in
+// states enter together, as they move into the project. The move is
+// refused when it would take a column that rejects more past its limit;
+// columns that warn let the tasks in. The limits of the columns entered
+// that reject are returned, like by checkWIP.
+func (s *BoardService) checkWIPEntering(ctx context.Context, projectID uuid.UUID, states []string) ([]repository.WIPLimit, error) {
+	board, err := s.boards.Get(ctx, projectID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	entering := make(map[string]int)
+	for _, state := range states {
+		if column := board.ColumnOf(state); column != nil {
+			entering[column.Name]++
+		}
+	}
+	var limits []repository.WIPLimit
+	for i := range board.Columns {
+		column := &board.Columns[i]
+		n := entering[column.Name]
+		if n == 0 || column.WIPLimit == 0 || column.WIPPolicy != domain.WIPReject {
+			continue
+		}
+		count, err := s.tasks.Count(ctx, repository.TaskQuery{ProjectID: projectID, States: column.States})
+		if err != nil {
+			return nil, err
+		}
+		if count+n > column.WIPLimit {
+			return nil, domain.Conflict("wip_limit_exceeded", "column %q holds %d tasks and cannot take %d more, its WIP limit is %d",
+				column.Name, count, n, column.WIPLimit).
+				With("column", column.Name).With("wip_limit", column.WIPLimit).With("count", count)
+		}
+		limits = append(limits, wipLimit(projectID, column))
+	}
+	return limits, nil
+}
+
+// wipLimit is the WIP limit of a board column of the project.
+func wipLimit(projectID uuid.UUID, column *domain.BoardColumn) repository.WIPLimit {
+	return repository.WIPLimit{ProjectID: projectID, Column: column.Name, States: column.States, Max: column.WIPLimit}
+}
+
+// wipExceeded reports that tasks moved by others in the meantime left no
+// room under one of limits, which the write found out.
+func wipExceeded(limits []repository.WIPLimit) error {
+	if len(limits) == 1 {
+		return domain.Conflict("wip_limit_exceeded", "column %q reached its WIP limit of %d", limits[0].Column,
+			limits[0].Max).With("column", limits[0].Column).With("wip_limit", limits[0].Max)
+	}
+	columns := make([]string, len(limits))
+	for i, limit := range limits {
+		columns[i] = limit.Column
+	}
+	return domain.Conflict("wip_limit_exceeded", "one of columns %q reached its WIP limit", columns).
+		With("columns", columns)
+}
+
+// place returns the rank that puts a task in a board column, right before
+// or after another task in it, or else at its top. When the ranks around
+// that place leave no room, the column is to be ranked anew, and the new
+// ranks of its other tasks are returned as well, for the move to write.
+func (s *BoardService) place(ctx context.Context, task *domain.Task, column *domain.BoardColumn,
+	before, after uuid.UUID) (string, map[uuid.UUID]string, error) {
+	tasks, err := s.allTasks(ctx, repository.TaskQuery{ProjectID: task.ProjectID, States: column.States})
+	if err != nil {
+		return "", nil, err
+	}
+	domain.SortBoard(tasks)
+	tasks = slices.DeleteFunc(tasks, func(t domain.Task) bool { return t.ID == task.ID })
+
+	at, neighbour := 0, before
+	if after != uuid.Nil {
+		neighbour = after
+	}
+	if neighbour != uuid.Nil {
+		at = slices.IndexFunc(tasks, func(t domain.Task) bool { return t.ID == neighbour })
+		if at < 0 {
+			return "", nil, domain.Validation("invalid_move", "task %s is not in column %q", neighbour, column.Name)
+		}
+		if after != uuid.Nil {
+			at++
+		}
+	}
+
+	var low, high string
+	if at > 0 {
+		low = tasks[at-1].Rank
+	}
+	if at < len(tasks) {
+		high = tasks[at].Rank
+	}
+	// Tasks without a rank sort last: a task can go before them, but not
+	// after one.
+	if at == 0 || low != "" {
+		if rank, ok := domain.RankBetween(low, high); ok {
+			return rank, nil, nil
+		}
+	}
+
+	ranks := domain.SpreadRanks(len(tasks) + 1)
+	reranked := make(map[uuid.UUID]string, len(tasks))
+	for i, t := range tasks {
+		if i < at {
+			reranked[t.ID] = ranks[i]
+		} else {
+			reranked[t.ID] = ranks[i+1]
+		}
+	}
+	return ranks[at], reranked, nil
+}
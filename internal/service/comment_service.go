@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/auth"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/markdown"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+// maxCommentLength is the longest comment body accepted, in characters.
+const maxCommentLength = 10000
+
+// CommentService manages the discussion on tasks. Anyone who can read a
+// task can read its comments; writing them takes the comments:*
+// permissions, scoped to the author and the task's project.
+type CommentService struct {
+	comments repository.CommentRepository
+	tasks    repository.TaskRepository
+	projects repository.ProjectRepository
+	users    repository.UserRepository
+	members  repository.MemberRepository
+}
+
+func NewCommentService(comments repository.CommentRepository, tasks repository.TaskRepository,
+	projects repository.ProjectRepository, users repository.UserRepository,
+	organizations repository.OrganizationRepository, members repository.MemberRepository) *CommentService {
+	return &CommentService{
+		comments: comments,
+		tasks:    tenantTasks{tasks},
+		projects: tenantProjects{projects},
+		users:    tenantUsers{users: users, organizations: organizations},
+		members:  members,
+	}
+}
+
+// List returns the comments on a task, oldest first. Replies follow the
+// comment they answer in time order, so clients group them by ParentID.
+func (s *CommentService) List(ctx context.Context, taskID uuid.UUID) ([]domain.Comment, error) {
+	if _, err := s.tasks.Get(ctx, taskID); err != nil {
+		return nil, fromStore(err, "task", taskID)
+	}
+	comments, err := s.comments.List(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range comments {
+		render(&comments[i])
+	}
+	return comments, nil
+}
+
+func (s *CommentService) Get(ctx context.Context, taskID, id uuid.UUID) (*domain.Comment, error) {
+	if _, err := s.tasks.Get(ctx, taskID); err != nil {
+		return nil, fromStore(err, "task", taskID)
+	}
+	comment, err := s.get(ctx, taskID, id)
+	if err != nil {
+		return nil, err
+	}
+	render(comment)
+	return comment, nil
+}
+
+// Create posts a comment by the caller. A reply to a reply joins the thread
+// of the comment it answers.
+func (s *CommentService) Create(ctx context.Context, comment *domain.Comment) error {
+	task, err := s.tasks.Get(ctx, comment.TaskID)
+	if err != nil {
+		return fromStore(err, "task", comment.TaskID)
+	}
+	if err := s.authorize(ctx, auth.CommentsCreate, task, uuid.Nil); err != nil {
+		return err
+	}
+	if comment.ParentID != uuid.Nil {
+		parent, err := s.get(ctx, task.ID, comment.ParentID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return domain.Validation("unknown_parent", "comment %s is not on task %s", comment.ParentID, task.ID).Wrap(err)
+		}
+		if err != nil {
+			return err
+		}
+		if parent.ParentID != uuid.Nil {
+			comment.ParentID = parent.ParentID
+		}
+	}
+	if err := s.setBody(ctx, comment, comment.Body); err != nil {
+		return err
+	}
+
+	comment.AuthorID = auth.PrincipalFrom(ctx).User.ID
+	comment.CreatedAt = time.Now().UTC()
+	comment.EditedAt = time.Time{}
+	comment.DeletedAt = time.Time{}
+	if err := s.comments.Create(ctx, comment); err != nil {
+		return fromStore(err, "comment", comment.ID)
+	}
+	render(comment)
+	return nil
+}
+
+// Update replaces the body of a comment, keeping the previous one as a
+// revision. Deleted comments cannot be edited.
+func (s *CommentService) Update(ctx context.Context, taskID, id uuid.UUID, body string) (*domain.Comment, error) {
+	task, err := s.tasks.Get(ctx, taskID)
+	if err != nil {
+		return nil, fromStore(err, "task", taskID)
+	}
+	comment, err := s.get(ctx, taskID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, auth.CommentsUpdate, task, comment.AuthorID); err != nil {
+		return nil, err
+	}
+	if !comment.DeletedAt.IsZero() {
+		return nil, domain.Conflict("comment_deleted", "comment %s has been deleted", id)
+	}
+
+	previous := comment.Body
+	if err := s.setBody(ctx, comment, body); err != nil {
+		return nil, err
+	}
+	if comment.Body != previous {
+		comment.EditedAt = time.Now().UTC()
+		revision := &domain.CommentRevision{
+			ID:        uuid.New(),
+			CommentID: comment.ID,
+			Body:      previous,
+			EditorID:  auth.PrincipalFrom(ctx).User.ID,
+			EditedAt:  comment.EditedAt,
+		}
+		if err := s.comments.Update(ctx, comment, revision); err != nil {
+			return nil, fromStore(err, "comment", id)
+		}
+	}
+	render(comment)
+	return comment, nil
+}
+
+// Delete empties a comment, which stays in its thread so that replies keep
+// their context. Deleting it again does nothing.
+func (s *CommentService) Delete(ctx context.Context, taskID, id uuid.UUID) error {
+	task, err := s.tasks.Get(ctx, taskID)
+	if err != nil {
+		return fromStore(err, "task", taskID)
+	}
+	comment, err := s.get(ctx, taskID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.authorize(ctx, auth.CommentsDelete, task, comment.AuthorID); err != nil {
+		return err
+	}
+	if !comment.DeletedAt.IsZero() {
+		return nil
+	}
+	return fromStore(s.comments.Delete(ctx, id, time.Now().UTC()), "comment", id)
+}
+
+// ListRevisions returns the earlier versions of a comment, oldest first.
+func (s *CommentService) ListRevisions(ctx context.Context, taskID, id uuid.UUID) ([]domain.CommentRevision, error) {
+	if _, err := s.tasks.Get(ctx, taskID); err != nil {
+		return nil, fromStore(err, "task", taskID)
+	}
+	if _, err := s.get(ctx, taskID, id); err != nil {
+		return nil, err
+	}
+	return s.comments.ListRevisions(ctx, id)
+}
+
+// get returns a comment on the task, reporting comments on other tasks as
+// not found.
+func (s *CommentService) get(ctx context.Context, taskID, id uuid.UUID) (*domain.Comment, error) {
+	comment, err := s.comments.Get(ctx, id)
+	if err == nil && comment.TaskID != taskID {
+		err = repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, fromStore(err, "comment", id)
+	}
+	return comment, nil
+}
+
+// authorize checks that the caller may take permission on a comment by
+// author on the task.
+func (s *CommentService) authorize(ctx context.Context, permission auth.Permission, task *domain.Task, author uuid.UUID) error {
+	project, err := s.projects.Get(ctx, task.ProjectID)
+	if err != nil {
+		return fromStore(err, "project", task.ProjectID)
+	}
+	target, err := projectTarget(ctx, s.members, project)
+	if err != nil {
+		return err
+	}
+	target.UserID = author
+	return auth.Authorize(ctx, permission, target)
+}
+
+// setBody validates body and records who it mentions, by email or handle.
+// Mentions of addresses and handles that belong to nobody in the
+// organization are left as plain text.
+func (s *CommentService) setBody(ctx context.Context, comment *domain.Comment, body string) error {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return domain.Validation("invalid_comment", "body is required")
+	}
+	if n := utf8.RuneCountInString(body); n > maxCommentLength {
+		return domain.Validation("invalid_comment", "body is %d characters long, the limit is %d", n, maxCommentLength)
+	}
+
+	mentions := []uuid.UUID{}
+	for _, mention := range markdown.Mentions(body) {
+		var user *domain.User
+		var err error
+		if strings.Contains(mention, "@") {
+			user, err = s.users.GetByEmail(ctx, mention)
+		} else {
+			user, err = s.users.GetByHandle(ctx, mention)
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(mentions, user.ID) {
+			mentions = append(mentions, user.ID)
+		}
+	}
+	sort.Slice(mentions, func(i, j int) bool { return mentions[i].String() < mentions[j].String() })
+	comment.Body = body
+	comment.Mentions = mentions
+	return nil
+}
+
+// render fills in the HTML of a comment from its body.
+func render(comment *domain.Comment) {
+	comment.HTML = ""
+	if comment.Body != "" {
+		comment.HTML = markdown.Render(comment.Body)
+	}
+}
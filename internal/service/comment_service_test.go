@@ -0,0 +1,60 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+)
+
+// TestMentions checks that comments mention users of the organization by
+// email or handle, and nobody else.
+func TestMentions(t *testing.T) {
+	s := newServices(t)
+	admin, alice := s.addOrganization(t, "alice@example.com", "secret123")
+	theirs, mallory := s.addOrganization(t, "mallory@example.com", "secret123")
+	mallory.Handle = "mallory"
+	must(t, s.users.Update(context.Background(), mallory))
+
+	bob := &domain.User{ID: uuid.New(), FullName: "Bob", Email: "bob@example.com", Role: domain.RoleMember}
+	must(t, s.userService.Create(admin, bob, ""))
+	if bob.Handle != "bob" {
+		t.Errorf("want the handle of bob's email, got %q", bob.Handle)
+	}
+	carol := &domain.User{ID: uuid.New(), FullName: "Carol", Email: "carol@example.com", Handle: "@Cee",
+		Role: domain.RoleMember}
+	must(t, s.userService.Create(admin, carol, ""))
+	if carol.Handle != "cee" {
+		t.Errorf("want carol's handle lowercased, got %q", carol.Handle)
+	}
+	// Handles are unique across organizations.
+	var problem *domain.Error
+	taken := &domain.User{ID: uuid.New(), FullName: "Dan", Email: "dan@example.com", Handle: "bob",
+		Role: domain.RoleMember}
+	if err := s.userService.Create(theirs, taken, ""); !errors.As(err, &problem) || problem.Code != "handle_taken" {
+		t.Errorf("want handle_taken, got %v", err)
+	}
+	invalid := &domain.User{ID: uuid.New(), FullName: "Dan", Email: "dan@example.com", Handle: "dan!",
+		Role: domain.RoleMember}
+	if err := s.userService.Create(admin, invalid, ""); !errors.As(err, &problem) || problem.Code != "invalid_handle" {
+		t.Errorf("want invalid_handle, got %v", err)
+	}
+
+	project := &domain.Entity{ID: uuid.New(), Title: "Apollo", ManagerID: alice.ID}
+	must(t, s.projects.Create(admin, project))
+	task := &domain.Task{ID: uuid.New(), Title: "Launch", ProjectID: project.ID}
+	must(t, s.tasks.Create(admin, task))
+
+	comment := &domain.Comment{ID: uuid.New(), TaskID: task.ID,
+		Body: "@Bob and @cee, with @carol@example.com and @mallory. `@alice` @nobody"}
+	must(t, s.comments.Create(admin, comment))
+	want := []uuid.UUID{bob.ID, carol.ID}
+	slices.SortFunc(want, func(a, b uuid.UUID) int { return strings.Compare(a.String(), b.String()) })
+	if !slices.Equal(comment.Mentions, want) {
+		t.Errorf("want mentions of bob and carol, got %v", comment.Mentions)
+	}
+}
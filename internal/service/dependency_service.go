@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/auth"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+// DependencyService links tasks that block one another. Anyone who can read
+// a task can see its dependencies; linking or unlinking takes tasks:update
+// on the task that is blocked.
+type DependencyService struct {
+	dependencies repository.DependencyRepository
+	tasks        repository.TaskRepository
+	projects     repository.ProjectRepository
+	members      repository.MemberRepository
+	workflows    *WorkflowService
+}
+
+func NewDependencyService(dependencies repository.DependencyRepository, tasks repository.TaskRepository,
+	projects repository.ProjectRepository, members repository.MemberRepository,
+	workflows *WorkflowService) *DependencyService {
+	return &DependencyService{
+		dependencies: dependencies,
+		tasks:        tenantTasks{tasks},
+		projects:     tenantProjects{projects},
+		members:      members,
+		workflows:    workflows,
+	}
+}
+
+// Graph returns the tasks a task waits on and those waiting on it, directly
+// or through other tasks, with the links between them.
+func (s *DependencyService) Graph(ctx context.Context, taskID uuid.UUID) (*domain.DependencyGraph, error) {
+	if _, err := s.tasks.Get(ctx, taskID); err != nil {
+		return nil, fromStore(err, "task", taskID)
+	}
+	blockers, err := s.dependencies.Blockers(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	dependents, err := s.dependencies.Dependents(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &domain.DependencyGraph{
+		TaskID:       taskID,
+		BlockedBy:    []uuid.UUID{},
+		Blocks:       []uuid.UUID{},
+		OpenBlockers: []uuid.UUID{},
+		Tasks:        []domain.Task{},
+		Dependencies: append(blockers, dependents...),
+	}
+	tasks := map[uuid.UUID]*domain.Task{}
+	for _, dependency := range graph.Dependencies {
+		switch taskID {
+		case dependency.TaskID:
+			graph.BlockedBy = append(graph.BlockedBy, dependency.BlockerID)
+		case dependency.BlockerID:
+			graph.Blocks = append(graph.Blocks, dependency.TaskID)
+		}
+		for _, id := range []uuid.UUID{dependency.TaskID, dependency.BlockerID} {
+			if _, ok := tasks[id]; ok || id == taskID {
+				continue
+			}
+			task, err := s.tasks.Get(ctx, id)
+			if err != nil {
+				return nil, fromStore(err, "task", id)
+			}
+			tasks[id] = task
+			graph.Tasks = append(graph.Tasks, *task)
+		}
+	}
+
+	var waited []domain.Task
+	seen := make(map[uuid.UUID]bool)
+	for _, dependency := range blockers {
+		if !seen[dependency.BlockerID] && dependency.BlockerID != taskID {
+			seen[dependency.BlockerID] = true
+			waited = append(waited, *tasks[dependency.BlockerID])
+		}
+	}
+	open, err := s.openTasks(ctx, waited)
+	if err != nil {
+		return nil, err
+	}
+	graph.OpenBlockers = append(graph.OpenBlockers, open...)
+	return graph, nil
+}
+
+// Add links a task to another one, which blocks it or, when blocks is set,
+// is blocked by it. Links that would close a cycle are refused.
+func (s *DependencyService) Add(ctx context.Context, taskID, otherID uuid.UUID, blocks bool) (*domain.Dependency, error) {
+	if _, err := s.tasks.Get(ctx, taskID); err != nil {
+		return nil, fromStore(err, "task", taskID)
+	}
+	switch {
+	case otherID == uuid.Nil:
+		return nil, domain.Validation("missing_task", "blocked_by or blocks is required")
+	case otherID == taskID:
+		return nil, domain.Validation("invalid_dependency", "a task cannot block itself")
+	}
+	if err := checkExists(ctx, s.tasks.Get, otherID, "unknown_task", "task"); err != nil {
+		return nil, err
+	}
+
+	dependency := &domain.Dependency{TaskID: taskID, BlockerID: otherID}
+	if blocks {
+		dependency = &domain.Dependency{TaskID: otherID, BlockerID: taskID}
+	}
+	blocked, err := s.tasks.Get(ctx, dependency.TaskID)
+	if err != nil {
+		return nil, fromStore(err, "task", dependency.TaskID)
+	}
+	if err := s.authorize(ctx, blocked); err != nil {
+		return nil, err
+	}
+
+	dependency.CreatedAt = time.Now().UTC()
+	err = s.dependencies.Add(ctx, dependency, func(blockers []domain.Dependency) error {
+		return checkCycle(dependency, blockers)
+	})
+	if errors.Is(err, repository.ErrDuplicate) {
+		return nil, domain.Conflict("dependency_exists", "task %s is already blocked by task %s",
+			dependency.TaskID, dependency.BlockerID).Wrap(err)
+	}
+	if err != nil {
+		return nil, fromStore(err, "task", dependency.TaskID)
+	}
+	return dependency, nil
+}
+
+// Remove unlinks two tasks, whichever of them is blocked by the other.
+func (s *DependencyService) Remove(ctx context.Context, taskID, otherID uuid.UUID) error {
+	if _, err := s.tasks.Get(ctx, taskID); err != nil {
+		return fromStore(err, "task", taskID)
+	}
+	if _, err := s.tasks.Get(ctx, otherID); err != nil {
+		return fromStore(err, "task", otherID)
+	}
+	dependency, err := s.find(ctx, taskID, otherID)
+	if err != nil {
+		return err
+	}
+	task, err := s.tasks.Get(ctx, dependency.TaskID)
+	if err != nil {
+		return fromStore(err, "task", dependency.TaskID)
+	}
+	if err := s.authorize(ctx, task); err != nil {
+		return err
+	}
+	return s.notLinked(s.dependencies.Remove(ctx, dependency.TaskID, dependency.BlockerID), taskID, otherID)
+}
+
+// find returns the link between two tasks, in whichever direction it goes.
+func (s *DependencyService) find(ctx context.Context, a, b uuid.UUID) (*domain.Dependency, error) {
+	for _, taskID := range []uuid.UUID{a, b} {
+		blockers, err := s.dependencies.Blockers(ctx, taskID)
+		if err != nil {
+			return nil, err
+		}
+		for _, dependency := range blockers {
+			if dependency.TaskID == taskID && (dependency.BlockerID == a || dependency.BlockerID == b) {
+				return &dependency, nil
+			}
+		}
+	}
+	return nil, s.notLinked(repository.ErrNotFound, a, b)
+}
+
+func (s *DependencyService) notLinked(err error, a, b uuid.UUID) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return domain.NotFound("dependency_not_found", "tasks %s and %s are not linked", a, b).Wrap(err)
+	}
+	return err
+}
+
+func (s *DependencyService) authorize(ctx context.Context, task *domain.Task) error {
+	project, err := s.projects.Get(ctx, task.ProjectID)
+	if err != nil {
+		return fromStore(err, "project", task.ProjectID)
+	}
+	return authorizeProject(ctx, s.members, auth.TasksUpdate, project, task.Assignee)
+}
+
+// checkCycle reports a link whose blocker already waits on its task, given
+// the links from the blocker to every task it waits on. The problem lists
+// the tasks of the cycle it would close, starting and ending with the task.
+func checkCycle(dependency *domain.Dependency, blockers []domain.Dependency) error {
+	next := make(map[uuid.UUID][]uuid.UUID)
+	for _, link := range blockers {
+		next[link.TaskID] = append(next[link.TaskID], link.BlockerID)
+	}
+
+	// Walk breadth first from the blocker, so the shortest cycle is shown.
+	previous := map[uuid.UUID]uuid.UUID{dependency.BlockerID: uuid.Nil}
+	queue := []uuid.UUID{dependency.BlockerID}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, id := range next[current] {
+			if _, ok := previous[id]; ok {
+				continue
+			}
+			previous[id] = current
+			if id != dependency.TaskID {
+				queue = append(queue, id)
+				continue
+			}
+
+			cycle := []uuid.UUID{dependency.TaskID}
+			for step := id; step != uuid.Nil; step = previous[step] {
+				cycle = append(cycle, step)
+			}
+			// The walk is collected backwards, from the task to the blocker.
+			for i, j := 1, len(cycle)-1; i < j; i, j = i+1, j-1 {
+				cycle[i], cycle[j] = cycle[j], cycle[i]
+			}
+			return domain.Conflict("dependency_cycle", "task %s already waits on task %s",
+				dependency.BlockerID, dependency.TaskID).With("cycle", cycle)
+		}
+	}
+	return nil
+}
+
+// checkUnblocked reports a task that still waits on open tasks, directly or
+// through other tasks, which keep it from being completed.
+func (s *DependencyService) checkUnblocked(ctx context.Context, taskID uuid.UUID) error {
+	blockers, err := s.dependencies.Blockers(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	var waited []domain.Task
+	seen := make(map[uuid.UUID]bool)
+	for _, dependency := range blockers {
+		if seen[dependency.BlockerID] {
+			continue
+		}
+		seen[dependency.BlockerID] = true
+		blocker, err := s.tasks.Get(ctx, dependency.BlockerID)
+		if err != nil {
+			return fromStore(err, "task", dependency.BlockerID)
+		}
+		waited = append(waited, *blocker)
+	}
+
+	open, err := s.openTasks(ctx, waited)
+	if err != nil || len(open) == 0 {
+		return err
+	}
+	return domain.Conflict("task_blocked", "task %s is blocked by %d open task(s)", taskID, len(open)).
+		With("blockers", open)
+}
+
+// openTasks returns the IDs of the tasks that are not in a terminal state of
+// their project's workflow.
+func (s *DependencyService) openTasks(ctx context.Context, tasks []domain.Task) ([]uuid.UUID, error) {
+	workflows := make(map[uuid.UUID]*domain.Workflow)
+	open := []uuid.UUID{}
+	for _, task := range tasks {
+		workflow, ok := workflows[task.ProjectID]
+		if !ok {
+			var err error
+			if workflow, err = s.workflows.forProject(ctx, task.ProjectID); err != nil {
+				return nil, err
+			}
+			workflows[task.ProjectID] = workflow
+		}
+		if !workflow.IsTerminal(task.State) {
+			open = append(open, task.ID)
+		}
+	}
+	return open, nil
+}
@@ -0,0 +1,126 @@
+package service_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+)
+
+// TestDependencyCycles checks that links closing a cycle are refused with
+// the cycle they would close, even when added at the same time.
+func TestDependencyCycles(t *testing.T) {
+	s := newServices(t)
+	admin, alice := s.addOrganization(t, "alice@example.com", "secret123")
+	project := &domain.Entity{ID: uuid.New(), Title: "Apollo", ManagerID: alice.ID}
+	must(t, s.projects.Create(admin, project))
+	tasks := make([]uuid.UUID, 4)
+	for i := range tasks {
+		task := &domain.Task{ID: uuid.New(), Title: fmt.Sprint("Task ", i), ProjectID: project.ID}
+		must(t, s.tasks.Create(admin, task))
+		tasks[i] = task.ID
+	}
+	a, b, c, d := tasks[0], tasks[1], tasks[2], tasks[3]
+
+	_, err := s.dependencies.Add(admin, a, a, false)
+	expectProblem(t, "link a task to itself", "invalid_dependency", err)
+
+	// a waits on b, which waits on c.
+	_, err = s.dependencies.Add(admin, a, b, false)
+	must(t, err)
+	_, err = s.dependencies.Add(admin, c, b, true)
+	must(t, err)
+	_, err = s.dependencies.Add(admin, a, b, false)
+	expectProblem(t, "link twice", "dependency_exists", err)
+
+	_, err = s.dependencies.Add(admin, c, a, false)
+	problem := expectProblem(t, "close a cycle", "dependency_cycle", err)
+	if got, want := fmt.Sprint(problem.Fields["cycle"]), fmt.Sprint([]uuid.UUID{c, a, b, c}); got != want {
+		t.Errorf("cycle: want %s, got %s", want, got)
+	}
+	_, err = s.dependencies.Add(admin, a, c, true)
+	expectProblem(t, "close a cycle from the other end", "dependency_cycle", err)
+
+	// Of two links closing a cycle together, one is refused.
+	errs := make([]error, 2)
+	var wg sync.WaitGroup
+	for i, link := range [][2]uuid.UUID{{c, d}, {d, c}} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = s.dependencies.Add(admin, link[0], link[1], false)
+		}()
+	}
+	wg.Wait()
+	if (errs[0] == nil) == (errs[1] == nil) {
+		t.Fatalf("want exactly one of two links closing a cycle added, got %v and %v", errs[0], errs[1])
+	}
+	for _, err := range errs {
+		if err != nil {
+			expectProblem(t, "close a cycle concurrently", "dependency_cycle", err)
+		}
+	}
+}
+
+// TestBlockedCompletion checks that a task cannot be completed while a task
+// it waits on, directly or not, is open.
+func TestBlockedCompletion(t *testing.T) {
+	s := newServices(t)
+	admin, alice := s.addOrganization(t, "alice@example.com", "secret123")
+	project := &domain.Entity{ID: uuid.New(), Title: "Apollo", ManagerID: alice.ID}
+	must(t, s.projects.Create(admin, project))
+	newTask := func(title string) uuid.UUID {
+		task := &domain.Task{ID: uuid.New(), Title: title, ProjectID: project.ID}
+		must(t, s.tasks.Create(admin, task))
+		return task.ID
+	}
+	complete := func(id uuid.UUID) error {
+		t.Helper()
+		for _, state := range []string{"in_progress", "review"} {
+			if _, err := s.tasks.Transition(admin, id, state); err != nil {
+				return err
+			}
+		}
+		_, err := s.tasks.Transition(admin, id, "done")
+		return err
+	}
+	blockers := func(what string, err error, want ...uuid.UUID) {
+		t.Helper()
+		problem := expectProblem(t, what, "task_blocked", err)
+		if got := fmt.Sprint(problem.Fields["blockers"]); got != fmt.Sprint(want) {
+			t.Errorf("%s: want blockers %v, got %s", what, want, got)
+		}
+	}
+
+	launch, build, design := newTask("Launch"), newTask("Build"), newTask("Design")
+	_, err := s.dependencies.Add(admin, launch, build, false)
+	must(t, err)
+	_, err = s.dependencies.Add(admin, build, design, false)
+	must(t, err)
+
+	blockers("complete launch", complete(launch), build, design)
+	blockers("complete build", complete(build), design)
+	must(t, complete(design))
+	// Launch is left in review, from where it only needs to enter done.
+	_, err = s.tasks.Transition(admin, launch, "done")
+	blockers("complete launch after design", err, build)
+	_, err = s.tasks.Transition(admin, build, "done")
+	must(t, err)
+	_, err = s.tasks.Transition(admin, launch, "done")
+	must(t, err)
+
+	// A completed blocker that waits on an open task still blocks.
+	report, audit := newTask("Report"), newTask("Audit")
+	_, err = s.dependencies.Add(admin, report, build, false)
+	must(t, err)
+	_, err = s.dependencies.Add(admin, build, audit, false)
+	must(t, err)
+	blockers("complete report", complete(report), audit)
+	graph, err := s.dependencies.Graph(admin, report)
+	must(t, err)
+	if fmt.Sprint(graph.OpenBlockers) != fmt.Sprint([]uuid.UUID{audit}) {
+		t.Errorf("open blockers of report: want %v, got %v", audit, graph.OpenBlockers)
+	}
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+// fromStore turns the errors a repository reports about one resource into
+// domain errors. Other errors are returned unchanged.
+func fromStore(err error, resource string, id uuid.UUID) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, repository.ErrNotFound):
+		return domain.NotFound(resource+"_not_found", "%s %s not found", resource, id).Wrap(err)
+	case errors.Is(err, repository.ErrDuplicate):
+		return domain.Conflict(resource+"_exists", "%s %s already exists", resource, id).Wrap(err)
+	case errors.Is(err, repository.ErrReferenced):
+		return domain.Conflict(resource+"_referenced", "%s %s is still referenced", resource, id).Wrap(err)
+	case errors.Is(err, repository.ErrInvalidCursor):
+		return domain.Validation("invalid_cursor", "cursor is malformed or was issued for another sort order").Wrap(err)
+	}
+	return err
+}
+
+// checkExists reports a missing reference from the record being saved as a
+// validation error, under code.
+func checkExists[T any](ctx context.Context, get func(context.Context, uuid.UUID) (*T, error), id uuid.UUID, code, resource string) error {
+	if id == uuid.Nil {
+		return nil
+	}
+	_, err := get(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return domain.Validation(code, "%s %s does not exist", resource, id).Wrap(err)
+	}
+	return err
+}
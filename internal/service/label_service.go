@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/auth"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+// LabelService manages the label catalogs of projects. Anyone who can read
+// a project can see its labels; changing the catalog takes projects:update.
+// Putting labels on tasks is part of updating the task.
+type LabelService struct {
+	labels   repository.LabelRepository
+	projects repository.ProjectRepository
+	members  repository.MemberRepository
+}
+
+func NewLabelService(labels repository.LabelRepository, projects repository.ProjectRepository,
+	members repository.MemberRepository) *LabelService {
+	return &LabelService{
+		labels:   labels,
+		projects: tenantProjects{projects},
+		members:  members,
+	}
+}
+
+// List returns the labels of a project, ordered by name.
+func (s *LabelService) List(ctx context.Context, projectID uuid.UUID) ([]domain.Label, error) {
+	if _, err := s.projects.Get(ctx, projectID); err != nil {
+		return nil, fromStore(err, "project", projectID)
+	}
+	return s.labels.List(ctx, projectID)
+}
+
+func (s *LabelService) Get(ctx context.Context, projectID, id uuid.UUID) (*domain.Label, error) {
+	if _, err := s.projects.Get(ctx, projectID); err != nil {
+		return nil, fromStore(err, "project", projectID)
+	}
+	return s.find(ctx, projectID, id)
+}
+
+// Create adds a label to the project's catalog, in DefaultLabelColor unless
+// another color is given.
+func (s *LabelService) Create(ctx context.Context, label *domain.Label) error {
+	if err := s.authorize(ctx, label.ProjectID); err != nil {
+		return err
+	}
+	if label.Color == "" {
+		label.Color = domain.DefaultLabelColor
+	}
+	if err := label.Normalize(); err != nil {
+		return domain.Validation("invalid_label", "%v", err)
+	}
+
+	label.CreatedAt = time.Now().UTC()
+	return labelError(s.labels.Create(ctx, label), label)
+}
+
+// Update renames, recolors or describes a label. An empty name or color
+// keeps the current one. Tasks refer to labels by ID, so a new name shows
+// on all of them at once.
+func (s *LabelService) Update(ctx context.Context, label *domain.Label) error {
+	if err := s.authorize(ctx, label.ProjectID); err != nil {
+		return err
+	}
+	current, err := s.find(ctx, label.ProjectID, label.ID)
+	if err != nil {
+		return err
+	}
+	if label.Name == "" {
+		label.Name = current.Name
+	}
+	if label.Color == "" {
+		label.Color = current.Color
+	}
+	if err := label.Normalize(); err != nil {
+		return domain.Validation("invalid_label", "%v", err)
+	}
+
+	label.CreatedAt = current.CreatedAt
+	return labelError(s.labels.Update(ctx, label), label)
+}
+
+// Delete removes a label from the catalog and from every task that has it.
+func (s *LabelService) Delete(ctx context.Context, projectID, id uuid.UUID) error {
+	if err := s.authorize(ctx, projectID); err != nil {
+		return err
+	}
+	if _, err := s.find(ctx, projectID, id); err != nil {
+		return err
+	}
+	return fromStore(s.labels.Delete(ctx, id), "label", id)
+}
+
+// Merge folds a label into another one of the same project: every task with
+// the source label gets the target instead, and the source is deleted, all
+// at once. It returns the target.
+func (s *LabelService) Merge(ctx context.Context, projectID, sourceID, targetID uuid.UUID) (*domain.Label, error) {
+	if err := s.authorize(ctx, projectID); err != nil {
+		return nil, err
+	}
+	if _, err := s.find(ctx, projectID, sourceID); err != nil {
+		return nil, err
+	}
+	switch {
+	case targetID == uuid.Nil:
+		return nil, domain.Validation("missing_label", "into is required")
+	case targetID == sourceID:
+		return nil, domain.Validation("invalid_merge", "a label cannot be merged into itself")
+	}
+	target, err := s.labels.Get(ctx, targetID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	if err != nil || target.ProjectID != projectID {
+		return nil, domain.Validation("unknown_label", "label %s is not in project %s", targetID, projectID)
+	}
+
+	if err := s.labels.Merge(ctx, sourceID, targetID); err != nil {
+		return nil, fromStore(err, "label", sourceID)
+	}
+	return target, nil
+}
+
+// find returns a label of the project, reporting labels of other projects
+// as missing.
+func (s *LabelService) find(ctx context.Context, projectID, id uuid.UUID) (*domain.Label, error) {
+	label, err := s.labels.Get(ctx, id)
+	if err == nil && label.ProjectID != projectID {
+		err = repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, fromStore(err, "label", id)
+	}
+	return label, nil
+}
+
+// authorize checks that the caller may change the labels of an existing
+// project.
+func (s *LabelService) authorize(ctx context.Context, projectID uuid.UUID) error {
+	project, err := s.projects.Get(ctx, projectID)
+	if err != nil {
+		return fromStore(err, "project", projectID)
+	}
+	return authorizeProject(ctx, s.members, auth.ProjectsUpdate, project, uuid.Nil)
+}
+
+// checkLabels reports labels that are not in the project's catalog. It
+// returns the IDs without repeats, ordered by name like tasks list them.
+func (s *LabelService) checkLabels(ctx context.Context, projectID uuid.UUID, ids []uuid.UUID) ([]uuid.UUID, error) {
+	labels := []domain.Label{}
+	seen := make(map[uuid.UUID]bool)
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		label, err := s.labels.Get(ctx, id)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+		if err != nil || label.ProjectID != projectID {
+			return nil, domain.Validation("unknown_label", "label %s is not in project %s", id, projectID)
+		}
+		labels = append(labels, *label)
+	}
+
+	sort.Slice(labels, func(i, j int) bool {
+		if labels[i].Name != labels[j].Name {
+			return labels[i].Name < labels[j].Name
+		}
+		return labels[i].ID.String() < labels[j].ID.String()
+	})
+	checked := make([]uuid.UUID, len(labels))
+	for i, label := range labels {
+		checked[i] = label.ID
+	}
+	return checked, nil
+}
+
+func labelError(err error, label *domain.Label) error {
+	if errors.Is(err, repository.ErrDuplicate) {
+		return domain.Conflict("label_exists", "project %s already has a label named %q",
+			label.ProjectID, label.Name).Wrap(err)
+	}
+	return fromStore(err, "label", label.ID)
+}
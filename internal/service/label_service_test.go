@@ -0,0 +1,85 @@
+package service_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+)
+
+// TestMergeLabels checks that merging a label gives its tasks the target
+// label, once, and deletes it.
+func TestMergeLabels(t *testing.T) {
+	s := newServices(t)
+	admin, alice := s.addOrganization(t, "alice@example.com", "secret123")
+	apollo := &domain.Entity{ID: uuid.New(), Title: "Apollo", ManagerID: alice.ID}
+	gemini := &domain.Entity{ID: uuid.New(), Title: "Gemini", ManagerID: alice.ID}
+	for _, project := range []*domain.Entity{apollo, gemini} {
+		must(t, s.projects.Create(admin, project))
+	}
+	newLabel := func(project *domain.Entity, name string) *domain.Label {
+		t.Helper()
+		label := &domain.Label{ID: uuid.New(), ProjectID: project.ID, Name: name}
+		must(t, s.labels.Create(admin, label))
+		return label
+	}
+	bug, defect, ui := newLabel(apollo, "bug"), newLabel(apollo, "defect"), newLabel(apollo, "ui")
+	elsewhere := newLabel(gemini, "bug")
+	newTask := func(title string, labels ...*domain.Label) *domain.Task {
+		t.Helper()
+		task := &domain.Task{ID: uuid.New(), Title: title, ProjectID: apollo.ID, Labels: []uuid.UUID{}}
+		for _, label := range labels {
+			task.Labels = append(task.Labels, label.ID)
+		}
+		must(t, s.tasks.Create(admin, task))
+		return task
+	}
+	crash := newTask("Crash", bug)
+	both := newTask("Crash again", bug, defect)
+	layout := newTask("Layout", defect, ui)
+	colors := newTask("Colors", ui)
+
+	_, err := s.labels.Merge(admin, apollo.ID, defect.ID, defect.ID)
+	expectProblem(t, "merge a label into itself", "invalid_merge", err)
+	_, err = s.labels.Merge(admin, apollo.ID, defect.ID, uuid.Nil)
+	expectProblem(t, "merge a label into nothing", "missing_label", err)
+	_, err = s.labels.Merge(admin, apollo.ID, defect.ID, elsewhere.ID)
+	expectProblem(t, "merge a label into one of another project", "unknown_label", err)
+	_, err = s.labels.Merge(admin, apollo.ID, uuid.New(), bug.ID)
+	notFound(t, "merge a missing label", err)
+
+	merged, err := s.labels.Merge(admin, apollo.ID, defect.ID, bug.ID)
+	must(t, err)
+	if merged.ID != bug.ID {
+		t.Fatalf("merging returned %+v, want the target label %s", merged, bug.ID)
+	}
+	for _, task := range []struct {
+		task *domain.Task
+		want []uuid.UUID
+	}{
+		{crash, []uuid.UUID{bug.ID}},
+		{both, []uuid.UUID{bug.ID}},
+		{layout, []uuid.UUID{bug.ID, ui.ID}},
+		{colors, []uuid.UUID{ui.ID}},
+	} {
+		got, err := s.tasks.Get(admin, task.task.ID)
+		must(t, err)
+		if fmt.Sprint(got.Labels) != fmt.Sprint(task.want) {
+			t.Errorf("%s: labels are %v, want %v", task.task.Title, got.Labels, task.want)
+		}
+	}
+
+	_, err = s.labels.Get(admin, apollo.ID, defect.ID)
+	notFound(t, "get the merged label", err)
+	labels, err := s.labels.List(admin, apollo.ID)
+	must(t, err)
+	if len(labels) != 2 || labels[0].ID != bug.ID || labels[1].ID != ui.ID {
+		t.Errorf("labels left after merging: %+v", labels)
+	}
+	labels, err = s.labels.List(admin, gemini.ID)
+	must(t, err)
+	if len(labels) != 1 || labels[0].ID != elsewhere.ID {
+		t.Errorf("labels of another project after merging: %+v", labels)
+	}
+}
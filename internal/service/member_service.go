@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/auth"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+// MemberService manages who takes part in a project. The project's manager
+// is always one of its owners.
+type MemberService struct {
+	members  repository.MemberRepository
+	projects repository.ProjectRepository
+	users    repository.UserRepository
+	tasks    repository.TaskRepository
+}
+
+func NewMemberService(members repository.MemberRepository, projects repository.ProjectRepository,
+	users repository.UserRepository, organizations repository.OrganizationRepository,
+	tasks repository.TaskRepository) *MemberService {
+	return &MemberService{
+		members:  members,
+		projects: tenantProjects{projects},
+		users:    tenantUsers{users: users, organizations: organizations},
+		tasks:    tenantTasks{tasks},
+	}
+}
+
+func (s *MemberService) List(ctx context.Context, projectID uuid.UUID) ([]domain.Member, error) {
+	if _, err := s.projects.Get(ctx, projectID); err != nil {
+		return nil, fromStore(err, "project", projectID)
+	}
+	return s.members.List(ctx, projectID)
+}
+
+func (s *MemberService) Get(ctx context.Context, projectID, userID uuid.UUID) (*domain.Member, error) {
+	if _, err := s.projects.Get(ctx, projectID); err != nil {
+		return nil, fromStore(err, "project", projectID)
+	}
+	member, err := s.members.Get(ctx, projectID, userID)
+	return member, memberError(err, projectID, userID)
+}
+
+// Add puts a user on the project, as a contributor unless another role is
+// given.
+func (s *MemberService) Add(ctx context.Context, member *domain.Member) error {
+	project, err := s.authorize(ctx, member.ProjectID)
+	if err != nil {
+		return err
+	}
+	if member.UserID == uuid.Nil {
+		return domain.Validation("missing_user", "user_id is required")
+	}
+	if err := checkExists(ctx, s.users.Get, member.UserID, "unknown_user", "user"); err != nil {
+		return err
+	}
+
+	if member.Role == "" {
+		member.Role = domain.DefaultProjectRole
+	} else if err := normalizeProjectRole(member); err != nil {
+		return err
+	}
+	if member.UserID == project.ManagerID && member.Role != domain.ProjectOwner {
+		return managerMustOwn()
+	}
+
+	member.JoinedAt = time.Now().UTC()
+	err = s.members.Add(ctx, member)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return domain.Conflict("member_exists", "user %s is already a member of project %s",
+			member.UserID, member.ProjectID).Wrap(err)
+	}
+	return fromStore(err, "project", member.ProjectID)
+}
+
+// Update changes the role of a member. A member with open tasks in the
+// project cannot become an observer.
+func (s *MemberService) Update(ctx context.Context, member *domain.Member) error {
+	project, err := s.authorize(ctx, member.ProjectID)
+	if err != nil {
+		return err
+	}
+	current, err := s.members.Get(ctx, member.ProjectID, member.UserID)
+	if err != nil {
+		return memberError(err, member.ProjectID, member.UserID)
+	}
+	if err := normalizeProjectRole(member); err != nil {
+		return err
+	}
+
+	if member.UserID == project.ManagerID && member.Role != domain.ProjectOwner {
+		return managerMustOwn()
+	}
+	if !member.Role.Assignable() {
+		if err := s.checkOpenTasks(ctx, member.ProjectID, member.UserID); err != nil {
+			return err
+		}
+	}
+
+	current.Role = member.Role
+	if err := s.members.Update(ctx, current); err != nil {
+		return memberError(err, member.ProjectID, member.UserID)
+	}
+	*member = *current
+	return nil
+}
+
+// Remove takes a user off the project. The manager and members with open
+// tasks in the project stay.
+func (s *MemberService) Remove(ctx context.Context, projectID, userID uuid.UUID) error {
+	project, err := s.authorize(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.members.Get(ctx, projectID, userID); err != nil {
+		return memberError(err, projectID, userID)
+	}
+	if userID == project.ManagerID {
+		return managerMustOwn()
+	}
+	if err := s.checkOpenTasks(ctx, projectID, userID); err != nil {
+		return err
+	}
+	return memberError(s.members.Remove(ctx, projectID, userID), projectID, userID)
+}
+
+// authorize checks that the caller may change the members of an existing
+// project, which it returns.
+func (s *MemberService) authorize(ctx context.Context, projectID uuid.UUID) (*domain.Entity, error) {
+	project, err := s.projects.Get(ctx, projectID)
+	if err != nil {
+		return nil, fromStore(err, "project", projectID)
+	}
+	if err := authorizeProject(ctx, s.members, auth.ProjectsUpdate, project, uuid.Nil); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+func (s *MemberService) checkOpenTasks(ctx context.Context, projectID, userID uuid.UUID) error {
+	open, err := s.tasks.Count(ctx, repository.TaskQuery{ProjectID: projectID, Assignee: userID, Open: true})
+	if err != nil {
+		return err
+	}
+	if open > 0 {
+		return domain.Conflict("member_has_open_tasks",
+			"member still has open tasks in the project, reassign them first").
+			With("dependents", map[string]int{"open_tasks": open})
+	}
+	return nil
+}
+
+// ensureOwner makes a project's manager one of its owners.
+func ensureOwner(ctx context.Context, members repository.MemberRepository, project *domain.Entity) error {
+	if project.ManagerID == uuid.Nil {
+		return nil
+	}
+	member, err := members.Get(ctx, project.ID, project.ManagerID)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return members.Add(ctx, &domain.Member{
+			ProjectID: project.ID,
+			UserID:    project.ManagerID,
+			Role:      domain.ProjectOwner,
+			JoinedAt:  time.Now().UTC(),
+		})
+	case err != nil:
+		return err
+	case member.Role != domain.ProjectOwner:
+		member.Role = domain.ProjectOwner
+		return members.Update(ctx, member)
+	}
+	return nil
+}
+
+func managerMustOwn() error {
+	return domain.Conflict("member_is_manager", "the project's manager must stay an owner, change manager_id first")
+}
+
+func normalizeProjectRole(member *domain.Member) error {
+	role, err := domain.ParseProjectRole(string(member.Role))
+	if err != nil {
+		return domain.Validation("invalid_project_role", "%v", err).With("allowed", domain.ProjectRoles())
+	}
+	member.Role = role
+	return nil
+}
+
+func memberError(err error, projectID, userID uuid.UUID) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return domain.NotFound("member_not_found", "user %s is not a member of project %s", userID, projectID).Wrap(err)
+	}
+	return err
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/auth"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+// MilestoneService keeps the milestones of projects and tracks how they are
+// doing. Anyone who can read a project can see its milestones; setting them
+// takes projects:update. Linking tasks to a milestone is part of updating
+// the task.
+type MilestoneService struct {
+	milestones repository.MilestoneRepository
+	tasks      repository.TaskRepository
+	projects   repository.ProjectRepository
+	members    repository.MemberRepository
+}
+
+func NewMilestoneService(milestones repository.MilestoneRepository, tasks repository.TaskRepository,
+	projects repository.ProjectRepository, members repository.MemberRepository) *MilestoneService {
+	return &MilestoneService{
+		milestones: milestones,
+		tasks:      tenantTasks{tasks},
+		projects:   tenantProjects{projects},
+		members:    members,
+	}
+}
+
+// List returns the milestones of a project with their progress, ordered by
+// target date.
+func (s *MilestoneService) List(ctx context.Context, projectID uuid.UUID) ([]domain.MilestoneProgress, error) {
+	if _, err := s.projects.Get(ctx, projectID); err != nil {
+		return nil, fromStore(err, "project", projectID)
+	}
+	milestones, err := s.milestones.List(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return s.track(ctx, milestones)
+}
+
+// Get returns a milestone of a project with its progress.
+func (s *MilestoneService) Get(ctx context.Context, projectID, id uuid.UUID) (*domain.MilestoneProgress, error) {
+	if _, err := s.projects.Get(ctx, projectID); err != nil {
+		return nil, fromStore(err, "project", projectID)
+	}
+	milestone, err := s.find(ctx, projectID, id)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := s.track(ctx, []domain.Milestone{*milestone})
+	if err != nil {
+		return nil, err
+	}
+	return &progress[0], nil
+}
+
+// Upcoming returns the milestones that are not due yet, and due before
+// before unless it is zero, of the projects the caller is a member of in
+// their organization, with their progress and soonest first.
+func (s *MilestoneService) Upcoming(ctx context.Context, before time.Time) ([]domain.MilestoneProgress, error) {
+	organizationID, err := organizationOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+	milestones, err := s.milestones.Upcoming(ctx, organizationID, auth.PrincipalFrom(ctx).User.ID,
+		time.Now().UTC(), before)
+	if err != nil {
+		return nil, err
+	}
+	return s.track(ctx, milestones)
+}
+
+// Create adds a milestone to the project.
+func (s *MilestoneService) Create(ctx context.Context, milestone *domain.Milestone) error {
+	project, err := s.authorize(ctx, milestone.ProjectID)
+	if err != nil {
+		return err
+	}
+	if err := checkMilestone(project, milestone); err != nil {
+		return err
+	}
+
+	milestone.CreatedAt = time.Now().UTC()
+	return fromStore(s.milestones.Create(ctx, milestone), "milestone", milestone.ID)
+}
+
+// Update changes the title, description or target date of a milestone.
+func (s *MilestoneService) Update(ctx context.Context, milestone *domain.Milestone) error {
+	project, err := s.authorize(ctx, milestone.ProjectID)
+	if err != nil {
+		return err
+	}
+	current, err := s.find(ctx, milestone.ProjectID, milestone.ID)
+	if err != nil {
+		return err
+	}
+	if err := checkMilestone(project, milestone); err != nil {
+		return err
+	}
+
+	milestone.CreatedAt = current.CreatedAt
+	return fromStore(s.milestones.Update(ctx, milestone), "milestone", milestone.ID)
+}
+
+// Delete removes a milestone. Its tasks are kept, linked to no milestone.
+func (s *MilestoneService) Delete(ctx context.Context, projectID, id uuid.UUID) error {
+	if _, err := s.authorize(ctx, projectID); err != nil {
+		return err
+	}
+	if _, err := s.find(ctx, projectID, id); err != nil {
+		return err
+	}
+	return fromStore(s.milestones.Delete(ctx, id), "milestone", id)
+}
+
+// track counts the tasks of each milestone and the open ones among them, in
+// one query, to tell how the milestones are doing.
+func (s *MilestoneService) track(ctx context.Context, milestones []domain.Milestone) ([]domain.MilestoneProgress, error) {
+	ids := make([]uuid.UUID, len(milestones))
+	for i, milestone := range milestones {
+		ids[i] = milestone.ID
+	}
+	counts, err := s.milestones.CountTasks(ctx, ids)
+	if err != nil {
+		return nil, fromStore(err, "task", uuid.Nil)
+	}
+
+	now := time.Now().UTC()
+	progress := make([]domain.MilestoneProgress, len(milestones))
+	for i, milestone := range milestones {
+		count := counts[milestone.ID]
+		progress[i] = milestone.Track(count.Total, count.Open, now)
+	}
+	return progress, nil
+}
+
+// find returns a milestone of the project, reporting milestones of other
+// projects as missing.
+func (s *MilestoneService) find(ctx context.Context, projectID, id uuid.UUID) (*domain.Milestone, error) {
+	milestone, err := s.milestones.Get(ctx, id)
+	if err == nil && milestone.ProjectID != projectID {
+		err = repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, fromStore(err, "milestone", id)
+	}
+	return milestone, nil
+}
+
+// authorize checks that the caller may set the milestones of an existing
+// project, which it returns.
+func (s *MilestoneService) authorize(ctx context.Context, projectID uuid.UUID) (*domain.Entity, error) {
+	project, err := s.projects.Get(ctx, projectID)
+	if err != nil {
+		return nil, fromStore(err, "project", projectID)
+	}
+	return project, authorizeProject(ctx, s.members, auth.ProjectsUpdate, project, uuid.Nil)
+}
+
+// checkMilestone checks the title, description and target date of a
+// milestone, which must fall within the dates of its project.
+func checkMilestone(project *domain.Entity, milestone *domain.Milestone) error {
+	if err := milestone.Normalize(); err != nil {
+		return domain.Validation("invalid_milestone", "%v", err)
+	}
+	if !project.StartDate.IsZero() && milestone.TargetDate.Before(project.StartDate) ||
+		!project.EndDate.IsZero() && milestone.TargetDate.After(project.EndDate) {
+		return domain.Validation("date_outside_project", "milestone target date must fall within the dates of project %s",
+			project.ID).With("project_start_date", project.StartDate).With("project_end_date", project.EndDate)
+	}
+	return nil
+}
+
+// checkTaskMilestone reports a milestone that is not in the task's project.
+func (s *MilestoneService) checkTaskMilestone(ctx context.Context, task *domain.Task) error {
+	if task.MilestoneID == uuid.Nil {
+		return nil
+	}
+	milestone, err := s.milestones.Get(ctx, task.MilestoneID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	if err != nil || milestone.ProjectID != task.ProjectID {
+		return domain.Validation("unknown_milestone", "milestone %s is not in project %s",
+			task.MilestoneID, task.ProjectID)
+	}
+	return nil
+}
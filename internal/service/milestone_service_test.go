@@ -0,0 +1,97 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+)
+
+// TestMilestoneStatus checks that milestones are completed once all of
+// their tasks are, overdue past their target date, and at risk while the
+// part of their tasks completed trails the part of their time elapsed.
+func TestMilestoneStatus(t *testing.T) {
+	s := newServices(t)
+	admin, alice := s.addOrganization(t, "alice@example.com", "secret123")
+	project := &domain.Entity{ID: uuid.New(), Title: "Apollo", ManagerID: alice.ID}
+	must(t, s.projects.Create(admin, project))
+
+	// Most milestones were set 10 days ago, 20 days ahead, so that half of
+	// their time has elapsed.
+	now := time.Now().UTC()
+	complete := func(id uuid.UUID) {
+		t.Helper()
+		for _, state := range []string{"in_progress", "review", "done"} {
+			_, err := s.tasks.Transition(admin, id, state)
+			must(t, err)
+		}
+	}
+	// unfinished holds an open task of each milestone.
+	unfinished := make(map[uuid.UUID]uuid.UUID)
+	newMilestone := func(title string, createdAt, target time.Time, tasks, completed int) *domain.Milestone {
+		t.Helper()
+		milestone := &domain.Milestone{ID: uuid.New(), ProjectID: project.ID, Title: title, TargetDate: target,
+			CreatedAt: createdAt}
+		must(t, s.milestoneStore.Create(context.Background(), milestone))
+		for i := 0; i < tasks; i++ {
+			task := &domain.Task{ID: uuid.New(), Title: fmt.Sprint(title, " ", i), ProjectID: project.ID,
+				MilestoneID: milestone.ID}
+			must(t, s.tasks.Create(admin, task))
+			if i < completed {
+				complete(task.ID)
+			} else {
+				unfinished[milestone.ID] = task.ID
+			}
+		}
+		return milestone
+	}
+	set, due := now.AddDate(0, 0, -10), now.AddDate(0, 0, 10)
+	behind := newMilestone("Behind", set, due, 4, 1)
+	even := newMilestone("Even", set, due, 2, 1)
+	done := newMilestone("Done", set, due, 2, 2)
+	late := newMilestone("Late", set, now.AddDate(0, 0, -1), 2, 1)
+	doneLate := newMilestone("Done late", set, now.AddDate(0, 0, -1), 1, 1)
+	fresh := newMilestone("Fresh", now, due, 0, 0)
+
+	want := map[uuid.UUID]struct {
+		status    domain.MilestoneStatus
+		completed int
+		percent   int
+	}{
+		behind.ID:   {domain.MilestoneAtRisk, 1, 25},
+		even.ID:     {domain.MilestoneOnTrack, 1, 50},
+		done.ID:     {domain.MilestoneCompleted, 2, 100},
+		late.ID:     {domain.MilestoneOverdue, 1, 50},
+		doneLate.ID: {domain.MilestoneCompleted, 1, 100},
+		fresh.ID:    {domain.MilestoneOnTrack, 0, 0},
+	}
+	list, err := s.milestones.List(admin, project.ID)
+	must(t, err)
+	if len(list) != len(want) {
+		t.Fatalf("want %d milestones, got %d", len(want), len(list))
+	}
+	for _, progress := range list {
+		w := want[progress.ID]
+		if progress.Status != w.status || progress.Progress.Completed != w.completed || progress.Progress.Percent != w.percent {
+			t.Errorf("%s: want %s with %d completed (%d%%), got %s with %+v", progress.Title, w.status, w.completed,
+				w.percent, progress.Status, progress.Progress)
+		}
+	}
+
+	// Completing the last open task of a milestone completes it.
+	progress, err := s.milestones.Get(admin, project.ID, even.ID)
+	must(t, err)
+	if progress.Elapsed != 50 {
+		t.Errorf("half of the time of %s elapsed, got %d%%", even.Title, progress.Elapsed)
+	}
+	complete(unfinished[even.ID])
+	progress, err = s.milestones.Get(admin, project.ID, even.ID)
+	must(t, err)
+	if progress.Status != domain.MilestoneCompleted || progress.Progress.Percent != 100 {
+		t.Errorf("%s with all of its tasks completed is %s at %d%%", even.Title, progress.Status,
+			progress.Progress.Percent)
+	}
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/auth"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+// NotificationService lets users read what they were notified of about the
+// tasks of their organization, and sends the reminders about due dates. No
+// permission covers marking notifications read, so API tokens may only list
+// them.
+type NotificationService struct {
+	notifications repository.NotificationRepository
+	// tasks and projects are not scoped to an organization: reminders are
+	// sent for all of them, outside of any request.
+	tasks    repository.TaskRepository
+	projects repository.ProjectRepository
+	// lead is how long before its due date a task is reminded of.
+	lead time.Duration
+}
+
+func NewNotificationService(notifications repository.NotificationRepository, tasks repository.TaskRepository,
+	projects repository.ProjectRepository, lead time.Duration) *NotificationService {
+	return &NotificationService{
+		notifications: tenantNotifications{notifications, tasks},
+		tasks:         tasks,
+		projects:      projects,
+		lead:          lead,
+	}
+}
+
+// List returns the caller's notifications, newest first; with unread set,
+// only those that were not read yet.
+func (s *NotificationService) List(ctx context.Context, unread bool) ([]domain.Notification, error) {
+	principal := auth.PrincipalFrom(ctx)
+	if principal == nil {
+		return nil, domain.Unauthorized("missing_token", "authentication is required")
+	}
+	return s.notifications.List(ctx, uuid.Nil, principal.User.ID, unread)
+}
+
+// MarkRead marks one of the caller's notifications as read and returns it.
+// Reading a notification twice is not an error.
+func (s *NotificationService) MarkRead(ctx context.Context, id uuid.UUID) (*domain.Notification, error) {
+	principal, err := notificationReader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	notification, err := s.notifications.Get(ctx, id)
+	// Other users' notifications are reported as missing, like API tokens.
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && notification.UserID != principal.User.ID) {
+		return nil, domain.NotFound("notification_not_found", "notification %s not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.notifications.MarkRead(ctx, id, time.Now().UTC()); err != nil {
+		return nil, fromStore(err, "notification", id)
+	}
+	notification, err = s.notifications.Get(ctx, id)
+	return notification, fromStore(err, "notification", id)
+}
+
+// MarkAllRead marks every unread notification of the caller as read.
+func (s *NotificationService) MarkAllRead(ctx context.Context) error {
+	principal, err := notificationReader(ctx)
+	if err != nil {
+		return err
+	}
+	return s.notifications.MarkAllRead(ctx, uuid.Nil, principal.User.ID, time.Now().UTC())
+}
+
+// notificationReader returns the caller if it may mark its notifications
+// read, which takes a password session.
+func notificationReader(ctx context.Context) (*auth.Principal, error) {
+	principal := auth.PrincipalFrom(ctx)
+	if principal == nil {
+		return nil, domain.Unauthorized("missing_token", "authentication is required")
+	}
+	if principal.SessionID == uuid.Nil {
+		return nil, domain.Forbidden("session_required", "notifications can only be marked read after logging in with a password")
+	}
+	return principal, nil
+}
+
+// RunReminders sends reminders every interval until ctx is done.
+func (s *NotificationService) RunReminders(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			sent, err := s.Remind(ctx, now.UTC())
+			if err != nil {
+				log.Printf("could not send task reminders: %v", err)
+			}
+			if sent > 0 {
+				log.Printf("sent %d task reminders", sent)
+			}
+		}
+	}
+}
+
+// Remind notifies about the open tasks that are due within the lead time
+// of now, and about those that became overdue. Each task is reminded of
+// once per kind and due date, so moving the due date sends new reminders.
+// It returns how many notifications were sent.
+func (s *NotificationService) Remind(ctx context.Context, now time.Time) (int, error) {
+	dueSoon, err := s.remind(ctx, now, domain.NotificationDueSoon,
+		repository.TaskQuery{Open: true, DueAfter: now, DueBefore: now.Add(s.lead)})
+	if err != nil {
+		return dueSoon, err
+	}
+	overdue, err := s.remind(ctx, now, domain.NotificationOverdue, repository.TaskQuery{OverdueAt: now})
+	return dueSoon + overdue, err
+}
+
+// remind sends a notification of kind about every task matching query that
+// was not reminded of yet.
+func (s *NotificationService) remind(ctx context.Context, now time.Time, kind domain.NotificationKind,
+	query repository.TaskQuery) (int, error) {
+	query.Unreminded = kind
+	query.Limit = repository.MaxLimit
+	managers := make(map[uuid.UUID]uuid.UUID)
+	sent := 0
+	for {
+		page, err := s.tasks.GetAll(ctx, query)
+		if err != nil {
+			return sent, err
+		}
+		for _, task := range page.Items {
+			recipient, err := s.recipient(ctx, &task, managers)
+			if err != nil {
+				return sent, err
+			}
+			if recipient == uuid.Nil {
+				continue
+			}
+			notification := domain.Notification{
+				ID:        uuid.New(),
+				UserID:    recipient,
+				TaskID:    task.ID,
+				Kind:      kind,
+				DueDate:   task.DueDate,
+				Message:   reminderMessage(kind, &task),
+				CreatedAt: now,
+			}
+			err = s.notifications.Create(ctx, &notification)
+			switch {
+			case err == nil:
+				sent++
+			// Sent meanwhile, or the task or user went away.
+			case errors.Is(err, repository.ErrDuplicate), errors.Is(err, repository.ErrReferenced):
+			default:
+				return sent, err
+			}
+		}
+		if page.NextCursor == "" {
+			return sent, nil
+		}
+		query.Cursor = page.NextCursor
+	}
+}
+
+// recipient returns who is reminded of a task: its assignee, or else the
+// manager of its project. managers caches the managers by project.
+func (s *NotificationService) recipient(ctx context.Context, task *domain.Task,
+	managers map[uuid.UUID]uuid.UUID) (uuid.UUID, error) {
+	if task.Assignee != uuid.Nil {
+		return task.Assignee, nil
+	}
+	if manager, ok := managers[task.ProjectID]; ok {
+		return manager, nil
+	}
+	project, err := s.projects.Get(ctx, task.ProjectID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return uuid.Nil, nil
+	}
+	if err != nil {
+		return uuid.Nil, err
+	}
+	managers[task.ProjectID] = project.ManagerID
+	return project.ManagerID, nil
+}
+
+func reminderMessage(kind domain.NotificationKind, task *domain.Task) string {
+	due := task.DueDate.Format("2006-01-02 15:04 MST")
+	if kind == domain.NotificationOverdue {
+		return fmt.Sprintf("Task %q is overdue: it was due %s", task.Title, due)
+	}
+	return fmt.Sprintf("Task %q is due %s", task.Title, due)
+}
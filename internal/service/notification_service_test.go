@@ -0,0 +1,95 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/auth"
+	"github.com/yelnar0112/project-management/internal/domain"
+)
+
+// TestRemind checks that tasks due soon and overdue are reminded of once
+// per due date, to their assignee or else their project's manager, and that
+// users only see the reminders of the organization they act in.
+func TestRemind(t *testing.T) {
+	s := newServices(t)
+	admin, alice := s.addOrganization(t, "alice@example.com", "secret123")
+	carol := s.addUser(t, admin, "carol@example.com", domain.RoleMember)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	project := &domain.Entity{ID: uuid.New(), Title: "Apollo", ManagerID: alice.ID}
+	must(t, s.projects.Create(admin, project))
+	must(t, s.members.Add(admin, &domain.Member{ProjectID: project.ID, UserID: carol.ID,
+		Role: domain.ProjectContributor, JoinedAt: now}))
+	soon := &domain.Task{ID: uuid.New(), Title: "Launch", ProjectID: project.ID, Assignee: carol.ID,
+		DueDate: now.Add(30 * time.Minute)}
+	late := &domain.Task{ID: uuid.New(), Title: "Land", ProjectID: project.ID, DueDate: now.Add(-time.Hour)}
+	later := &domain.Task{ID: uuid.New(), Title: "Return", ProjectID: project.ID, Assignee: carol.ID,
+		DueDate: now.Add(5 * time.Hour)}
+	for _, task := range []*domain.Task{soon, late, later} {
+		must(t, s.tasks.Create(admin, task))
+	}
+
+	sent, err := s.notificationService.Remind(ctx, now)
+	must(t, err)
+	if sent != 2 {
+		t.Fatalf("first reminders: want 2 sent, got %d", sent)
+	}
+	reminded := func(user *domain.User, want ...uuid.UUID) {
+		t.Helper()
+		list, err := s.notificationService.List(as(admin, user), false)
+		must(t, err)
+		if len(list) != len(want) {
+			t.Fatalf("%s: want %d notifications, got %+v", user.Email, len(want), list)
+		}
+		for i, notification := range list {
+			if notification.TaskID != want[i] || notification.UserID != user.ID {
+				t.Errorf("%s: notification %d is %+v, want one about %s", user.Email, i, notification, want[i])
+			}
+		}
+	}
+	reminded(carol, soon.ID)
+	reminded(alice, late.ID)
+
+	// Nothing is sent twice, until a due date moves.
+	sent, err = s.notificationService.Remind(ctx, now.Add(time.Minute))
+	must(t, err)
+	if sent != 0 {
+		t.Fatalf("repeated reminders: want none sent, got %d", sent)
+	}
+	moved, err := s.taskStore.Get(ctx, soon.ID)
+	must(t, err)
+	moved.DueDate = now.Add(45 * time.Minute)
+	must(t, s.taskStore.Update(ctx, moved))
+	sent, err = s.notificationService.Remind(ctx, now.Add(2*time.Minute))
+	must(t, err)
+	if sent != 1 {
+		t.Fatalf("reminders after moving a due date: want 1 sent, got %d", sent)
+	}
+	reminded(carol, soon.ID, soon.ID)
+
+	// In another organization, Carol has no notifications.
+	mine, err := s.notificationService.List(as(admin, carol), false)
+	must(t, err)
+	other, _ := s.addOrganization(t, "bob@example.com", "secret123")
+	theirs := auth.PrincipalFrom(other).OrganizationID
+	must(t, s.organizations.AddMember(ctx, &domain.OrganizationMember{OrganizationID: theirs, UserID: carol.ID,
+		Role: domain.RoleMember, JoinedAt: now}))
+	elsewhere := as(other, carol)
+	list, err := s.notificationService.List(elsewhere, false)
+	must(t, err)
+	if len(list) != 0 {
+		t.Fatalf("notifications of another organization were listed: %+v", list)
+	}
+	_, err = s.notificationService.MarkRead(elsewhere, mine[0].ID)
+	notFound(t, "mark read from another organization", err)
+	must(t, s.notificationService.MarkAllRead(elsewhere))
+	unread, err := s.notificationService.List(as(admin, carol), true)
+	must(t, err)
+	if len(unread) != 2 {
+		t.Fatalf("marking all read in another organization should leave these unread, got %+v", unread)
+	}
+}
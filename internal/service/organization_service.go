@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/auth"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+// OrganizationService manages organizations and who belongs to them. Each
+// call acts in the organization it is about, whichever one the caller
+// selected for the request, and only members may see an organization.
+// Organization admins manage its members; every organization keeps at least
+// one admin.
+type OrganizationService struct {
+	organizations repository.OrganizationRepository
+	users         repository.UserRepository
+	projects      repository.ProjectRepository
+	tasks         repository.TaskRepository
+}
+
+func NewOrganizationService(organizations repository.OrganizationRepository, users repository.UserRepository,
+	projects repository.ProjectRepository, tasks repository.TaskRepository) *OrganizationService {
+	return &OrganizationService{
+		organizations: organizations,
+		users:         users,
+		projects:      tenantProjects{projects},
+		tasks:         tenantTasks{tasks},
+	}
+}
+
+// List returns the organizations the caller belongs to, in the order it
+// joined them.
+func (s *OrganizationService) List(ctx context.Context) ([]domain.Organization, error) {
+	principal := auth.PrincipalFrom(ctx)
+	if principal == nil {
+		return nil, domain.Unauthorized("missing_token", "authentication is required")
+	}
+	return s.organizations.ListForUser(ctx, principal.User.ID)
+}
+
+// Create stores a new organization with the caller as its admin.
+// Organizations can only be created from a password session.
+func (s *OrganizationService) Create(ctx context.Context, organization *domain.Organization) error {
+	principal := auth.PrincipalFrom(ctx)
+	if principal == nil {
+		return domain.Unauthorized("missing_token", "authentication is required")
+	}
+	if principal.SessionID == uuid.Nil {
+		return domain.Forbidden("session_required", "organizations can only be created after logging in with a password")
+	}
+	if err := normalizeOrganization(organization); err != nil {
+		return err
+	}
+
+	organization.CreatedAt = time.Now().UTC()
+	return s.organizations.Create(ctx, organization, &domain.OrganizationMember{
+		OrganizationID: organization.ID,
+		UserID:         principal.User.ID,
+		Role:           domain.RoleAdmin,
+		JoinedAt:       organization.CreatedAt,
+	})
+}
+
+func (s *OrganizationService) Get(ctx context.Context, id uuid.UUID) (*domain.Organization, error) {
+	if _, err := s.enter(ctx, id); err != nil {
+		return nil, err
+	}
+	organization, err := s.organizations.Get(ctx, id)
+	return organization, organizationError(err, id)
+}
+
+// Update renames an organization.
+func (s *OrganizationService) Update(ctx context.Context, organization *domain.Organization) error {
+	ctx, err := s.enter(ctx, organization.ID)
+	if err != nil {
+		return err
+	}
+	if err := auth.Authorize(ctx, auth.OrganizationsUpdate, auth.Target{}); err != nil {
+		return err
+	}
+	if err := normalizeOrganization(organization); err != nil {
+		return err
+	}
+	if err := s.organizations.Update(ctx, organization); err != nil {
+		return organizationError(err, organization.ID)
+	}
+	current, err := s.organizations.Get(ctx, organization.ID)
+	if err != nil {
+		return organizationError(err, organization.ID)
+	}
+	*organization = *current
+	return nil
+}
+
+func (s *OrganizationService) ListMembers(ctx context.Context, id uuid.UUID) ([]domain.OrganizationMember, error) {
+	ctx, err := s.enter(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(ctx, auth.UsersRead, auth.Target{}); err != nil {
+		return nil, err
+	}
+	return s.organizations.ListMembers(ctx, id)
+}
+
+// AddMember puts the user with the given email in the organization, as a
+// member unless another role is given.
+func (s *OrganizationService) AddMember(ctx context.Context, organizationID uuid.UUID, email string,
+	role domain.Role) (*domain.OrganizationMember, error) {
+	ctx, err := s.enter(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(ctx, auth.UsersCreate, auth.Target{}); err != nil {
+		return nil, err
+	}
+	if role, err = parseRole(role); err != nil {
+		return nil, err
+	}
+	user, err := s.users.GetByEmail(ctx, email)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, domain.Validation("unknown_user", "no user has email %q", email).Wrap(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	member := &domain.OrganizationMember{
+		OrganizationID: organizationID,
+		UserID:         user.ID,
+		Role:           role,
+		JoinedAt:       time.Now().UTC(),
+	}
+	err = s.organizations.AddMember(ctx, member)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return nil, domain.Conflict("organization_member_exists", "user %s is already a member of organization %s",
+			user.ID, organizationID).Wrap(err)
+	}
+	if err != nil {
+		return nil, organizationError(err, organizationID)
+	}
+	return member, nil
+}
+
+// UpdateMember changes the role of a member.
+func (s *OrganizationService) UpdateMember(ctx context.Context, member *domain.OrganizationMember) error {
+	ctx, err := s.enter(ctx, member.OrganizationID)
+	if err != nil {
+		return err
+	}
+	if err := auth.Authorize(ctx, auth.UsersUpdate, auth.Target{}); err != nil {
+		return err
+	}
+	current, err := s.organizations.GetMember(ctx, member.OrganizationID, member.UserID)
+	if err != nil {
+		return organizationMemberError(err, member.OrganizationID, member.UserID)
+	}
+	if member.Role, err = parseRole(member.Role); err != nil {
+		return err
+	}
+	if current.Role == domain.RoleAdmin && member.Role != domain.RoleAdmin {
+		if err := checkLastAdmin(ctx, s.organizations, member.OrganizationID, member.UserID); err != nil {
+			return err
+		}
+	}
+
+	current.Role = member.Role
+	if err := s.organizations.UpdateMember(ctx, current); err != nil {
+		return organizationMemberError(err, member.OrganizationID, member.UserID)
+	}
+	*member = *current
+	return nil
+}
+
+// RemoveMember takes a user out of the organization and off its projects.
+// Anyone may leave an organization from a password session; removing others
+// takes an admin. Members with open tasks or managed projects in the
+// organization stay until those are handed over.
+func (s *OrganizationService) RemoveMember(ctx context.Context, organizationID, userID uuid.UUID) error {
+	ctx, err := s.enter(ctx, organizationID)
+	if err != nil {
+		return err
+	}
+	if principal := auth.PrincipalFrom(ctx); principal.User.ID != userID {
+		if err := auth.Authorize(ctx, auth.UsersDelete, auth.Target{UserID: userID}); err != nil {
+			return err
+		}
+	} else if principal.SessionID == uuid.Nil {
+		return domain.Forbidden("session_required", "organizations can only be left after logging in with a password")
+	}
+	member, err := s.organizations.GetMember(ctx, organizationID, userID)
+	if err != nil {
+		return organizationMemberError(err, organizationID, userID)
+	}
+	if member.Role == domain.RoleAdmin {
+		if err := checkLastAdmin(ctx, s.organizations, organizationID, userID); err != nil {
+			return err
+		}
+	}
+
+	open, err := s.tasks.Count(ctx, repository.TaskQuery{Assignee: userID, Open: true})
+	if err != nil {
+		return err
+	}
+	if open > 0 {
+		return domain.Conflict("member_has_open_tasks",
+			"member still has open tasks in the organization, reassign them first").
+			With("dependents", map[string]int{"open_tasks": open})
+	}
+	managed, err := s.projects.GetAll(ctx, repository.ProjectQuery{ManagerID: userID, Limit: 1})
+	if err != nil {
+		return err
+	}
+	if len(managed.Items) > 0 {
+		return domain.Conflict("member_is_manager",
+			"member still manages projects in the organization, change their manager_id first")
+	}
+
+	return organizationMemberError(s.organizations.RemoveMember(ctx, organizationID, userID), organizationID, userID)
+}
+
+// enter returns ctx with the caller acting in the organization, which it
+// must belong to.
+func (s *OrganizationService) enter(ctx context.Context, organizationID uuid.UUID) (context.Context, error) {
+	principal := auth.PrincipalFrom(ctx)
+	if principal == nil {
+		return nil, domain.Unauthorized("missing_token", "authentication is required")
+	}
+	member, err := s.organizations.GetMember(ctx, organizationID, principal.User.ID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, organizationNotFound(organizationID).Wrap(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return auth.WithPrincipal(ctx, principal.In(organizationID, member.Role)), nil
+}
+
+// checkLastAdmin refuses to take the admin role from userID when no other
+// member of the organization holds it.
+func checkLastAdmin(ctx context.Context, organizations repository.OrganizationRepository, organizationID, userID uuid.UUID) error {
+	members, err := organizations.ListMembers(ctx, organizationID)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		if member.Role == domain.RoleAdmin && member.UserID != userID {
+			return nil
+		}
+	}
+	return domain.Conflict("last_admin", "user %s is the last admin of the organization, make someone else admin first", userID)
+}
+
+func normalizeOrganization(organization *domain.Organization) error {
+	organization.Name = strings.TrimSpace(organization.Name)
+	if organization.Name == "" {
+		return domain.Validation("missing_name", "name is required")
+	}
+	return nil
+}
+
+// parseRole accepts a role name in any letter case, or none for the default
+// role.
+func parseRole(role domain.Role) (domain.Role, error) {
+	if role == "" {
+		return domain.DefaultRole, nil
+	}
+	parsed, err := domain.ParseRole(string(role))
+	if err != nil {
+		return "", domain.Validation("invalid_role", "%v", err).With("allowed", domain.Roles())
+	}
+	return parsed, nil
+}
+
+func organizationNotFound(id uuid.UUID) *domain.Error {
+	return domain.NotFound("organization_not_found", "organization %s not found", id)
+}
+
+func organizationError(err error, id uuid.UUID) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return organizationNotFound(id).Wrap(err)
+	}
+	return err
+}
+
+func organizationMemberError(err error, organizationID, userID uuid.UUID) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return domain.NotFound("organization_member_not_found", "user %s is not a member of organization %s",
+			userID, organizationID).Wrap(err)
+	}
+	return err
+}
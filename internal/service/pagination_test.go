@@ -0,0 +1,116 @@
+package service_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+// TestPageLimits checks that page sizes are clamped to
+// [1, repository.MaxLimit], with repository.DefaultLimit when none is
+// asked for.
+func TestPageLimits(t *testing.T) {
+	s := newServices(t)
+	admin, alice := s.addOrganization(t, "alice@example.com", "secret123")
+	project := &domain.Entity{ID: uuid.New(), Title: "Apollo", ManagerID: alice.ID}
+	must(t, s.projects.Create(admin, project))
+	for i := 0; i <= repository.MaxLimit; i++ {
+		must(t, s.tasks.Create(admin, &domain.Task{ID: uuid.New(), Title: fmt.Sprint("Task ", i), ProjectID: project.ID}))
+	}
+
+	for _, limit := range []struct {
+		asked, want int
+	}{
+		{0, repository.DefaultLimit},
+		{-5, repository.DefaultLimit},
+		{1, 1},
+		{repository.MaxLimit, repository.MaxLimit},
+		{repository.MaxLimit + 1, repository.MaxLimit},
+		{1 << 30, repository.MaxLimit},
+	} {
+		page, err := s.tasks.GetForProject(admin, project.ID, repository.TaskQuery{Limit: limit.asked})
+		must(t, err)
+		if len(page.Items) != limit.want || page.NextCursor == "" {
+			t.Errorf("limit %d: want a page of %d tasks and a next cursor, got %d tasks and cursor %q",
+				limit.asked, limit.want, len(page.Items), page.NextCursor)
+		}
+	}
+
+	// The page after the largest one holds what is left.
+	first, err := s.tasks.GetForProject(admin, project.ID, repository.TaskQuery{Limit: repository.MaxLimit})
+	must(t, err)
+	rest, err := s.tasks.GetForProject(admin, project.ID, repository.TaskQuery{Limit: repository.MaxLimit,
+		Cursor: first.NextCursor})
+	must(t, err)
+	if len(rest.Items) != 1 || rest.NextCursor != "" {
+		t.Errorf("last page: want 1 task and no next cursor, got %d tasks and cursor %q", len(rest.Items),
+			rest.NextCursor)
+	}
+}
+
+// TestInvalidCursors checks that cursors that are malformed, tampered with
+// or issued for another list are refused as invalid input.
+func TestInvalidCursors(t *testing.T) {
+	s := newServices(t)
+	admin, alice := s.addOrganization(t, "alice@example.com", "secret123")
+	for i := 0; i < 2; i++ {
+		project := &domain.Entity{ID: uuid.New(), Title: fmt.Sprint("Project ", i), ManagerID: alice.ID}
+		must(t, s.projects.Create(admin, project))
+		for j := 0; j < 2; j++ {
+			must(t, s.tasks.Create(admin, &domain.Task{ID: uuid.New(), Title: fmt.Sprint("Task ", j),
+				ProjectID: project.ID}))
+		}
+	}
+	tasks, err := s.tasks.GetAll(admin, repository.TaskQuery{Limit: 1})
+	must(t, err)
+	projects, err := s.projects.GetAll(admin, repository.ProjectQuery{Limit: 1})
+	must(t, err)
+	_, err = s.tasks.GetAll(admin, repository.TaskQuery{Limit: 1, Cursor: tasks.NextCursor})
+	must(t, err)
+
+	// tamper decodes the next cursor of the tasks, changes it and encodes it
+	// again.
+	tamper := func(change func(cursor map[string]any)) string {
+		t.Helper()
+		data, err := base64.RawURLEncoding.DecodeString(tasks.NextCursor)
+		must(t, err)
+		var cursor map[string]any
+		must(t, json.Unmarshal(data, &cursor))
+		change(cursor)
+		data, err = json.Marshal(cursor)
+		must(t, err)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	values := func(cursor map[string]any) []any { return cursor["v"].([]any) }
+	priority, err := repository.TaskSorting.Parse("priority")
+	must(t, err)
+
+	for _, c := range []struct {
+		name   string
+		cursor string
+		sort   []repository.SortField
+	}{
+		{"that is not base64", "not a cursor!", nil},
+		{"that is not JSON", base64.RawURLEncoding.EncodeToString([]byte("not json")), nil},
+		{"of another sort order", tasks.NextCursor, priority},
+		{"of another list", projects.NextCursor, nil},
+		{"claiming another sort order", tamper(func(c map[string]any) { c["s"] = "priority" }), nil},
+		{"with a value too many", tamper(func(c map[string]any) { c["v"] = append(values(c), "extra") }), nil},
+		{"with a value missing", tamper(func(c map[string]any) { c["v"] = values(c)[1:] }), nil},
+		{"with a malformed time", tamper(func(c map[string]any) { values(c)[0] = "yesterday" }), nil},
+		{"with a malformed ID", tamper(func(c map[string]any) { values(c)[1] = "not-a-uuid" }), nil},
+	} {
+		_, err := s.tasks.GetAll(admin, repository.TaskQuery{Limit: 1, Sort: c.sort, Cursor: c.cursor})
+		expectProblem(t, "list tasks with a cursor "+c.name, "invalid_cursor", err)
+	}
+
+	_, err = s.projects.GetAll(admin, repository.ProjectQuery{Limit: 1, Cursor: tasks.NextCursor})
+	expectProblem(t, "list projects with a cursor of tasks", "invalid_cursor", err)
+	_, err = s.userService.GetAll(admin, repository.UserQuery{Limit: 1, Cursor: "not a cursor!"})
+	expectProblem(t, "list users with a malformed cursor", "invalid_cursor", err)
+}
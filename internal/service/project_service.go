@@ -1,66 +1,130 @@
 package service
 
 import (
-	"database/sql"
+	"context"
 
 	"github.com/google/uuid"
-	"github.com/yelnar0112/project-management/internal/config"
+	"github.com/yelnar0112/project-management/internal/auth"
 	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
 )
 
-func GetAllProjects() (projects []domain.Entity, err error) {
-	rows, err := config.DB.Query("SELECT id, title, description, start_date, end_date, manager_id FROM projects")
-	if err != nil {
-		return nil, err
+type ProjectService struct {
+	projects repository.ProjectRepository
+	users    repository.UserRepository
+	tasks    repository.TaskRepository
+	members  repository.MemberRepository
+	// attachments removes the files of deleted projects and their tasks.
+	attachments *AttachmentService
+}
+
+func NewProjectService(projects repository.ProjectRepository, users repository.UserRepository,
+	organizations repository.OrganizationRepository, tasks repository.TaskRepository,
+	members repository.MemberRepository, attachments *AttachmentService) *ProjectService {
+	return &ProjectService{
+		projects:    tenantProjects{projects},
+		users:       tenantUsers{users: users, organizations: organizations},
+		tasks:       tenantTasks{tasks},
+		members:     members,
+		attachments: attachments,
 	}
-	defer rows.Close()
+}
 
-	for rows.Next() {
-		var project domain.Entity
-		if err := rows.Scan(&project.ID, &project.Title, &project.Description, &project.StartDate, &project.EndDate, &project.ManagerID); err != nil {
-			return nil, err
-		}
-		projects = append(projects, project)
+func (s *ProjectService) GetAll(ctx context.Context, query repository.ProjectQuery) (*repository.Page[domain.Entity], error) {
+	projects, err := s.projects.GetAll(ctx, query)
+	return projects, fromStore(err, "project", uuid.Nil)
+}
+
+// GetForUser lists the projects an existing user is a member of. Any member
+// filter in query is replaced.
+func (s *ProjectService) GetForUser(ctx context.Context, userID uuid.UUID, query repository.ProjectQuery) (*repository.Page[domain.Entity], error) {
+	if _, err := s.users.Get(ctx, userID); err != nil {
+		return nil, fromStore(err, "user", userID)
 	}
+	query.MemberID = userID
+	return s.GetAll(ctx, query)
+}
 
-	if err = rows.Err(); err != nil {
-		return nil, err
+// Create stores the project and makes its manager an owner.
+func (s *ProjectService) Create(ctx context.Context, project *domain.Entity) error {
+	if err := auth.Authorize(ctx, auth.ProjectsCreate, auth.Target{ManagerID: project.ManagerID}); err != nil {
+		return err
+	}
+	if err := checkExists(ctx, s.users.Get, project.ManagerID, "unknown_manager", "user"); err != nil {
+		return err
 	}
+	if err := s.projects.Create(ctx, project); err != nil {
+		return fromStore(err, "project", project.ID)
+	}
+	return ensureOwner(ctx, s.members, project)
+}
 
-	return projects, nil
+func (s *ProjectService) Get(ctx context.Context, id uuid.UUID) (*domain.Entity, error) {
+	project, err := s.projects.Get(ctx, id)
+	return project, fromStore(err, "project", id)
 }
 
-func CreateProject(project *domain.Entity) error {
-	_, err := config.DB.Exec(
-		"INSERT INTO projects (id, title, description, start_date, end_date, manager_id) VALUES ($1, $2, $3, $4, $5, $6)",
-		project.ID, project.Title, project.Description, project.StartDate, project.EndDate, project.ManagerID,
-	)
-	return err
+// Update replaces the project's fields. Whoever may update the project may
+// also hand it to another manager, who becomes an owner.
+func (s *ProjectService) Update(ctx context.Context, project *domain.Entity) error {
+	current, err := s.Get(ctx, project.ID)
+	if err != nil {
+		return err
+	}
+	if err := authorizeProject(ctx, s.members, auth.ProjectsUpdate, current, uuid.Nil); err != nil {
+		return err
+	}
+	if err := checkExists(ctx, s.users.Get, project.ManagerID, "unknown_manager", "user"); err != nil {
+		return err
+	}
+	if err := s.projects.Update(ctx, project); err != nil {
+		return fromStore(err, "project", project.ID)
+	}
+	return ensureOwner(ctx, s.members, project)
 }
 
-func GetProject(id uuid.UUID) (*domain.Entity, error) {
-	var project domain.Entity
-	err := config.DB.QueryRow(
-		"SELECT id, title, description, start_date, end_date, manager_id FROM projects WHERE id = $1", id,
-	).Scan(&project.ID, &project.Title, &project.Description, &project.StartDate, &project.EndDate, &project.ManagerID)
+// Delete removes the project. Its tasks are deleted with it when cascade is
+// set; otherwise a project that still has tasks is refused with a conflict
+// listing them.
+func (s *ProjectService) Delete(ctx context.Context, id uuid.UUID, cascade bool) error {
+	project, err := s.Get(ctx, id)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil // No rows found, return nil instead of an error
+		return err
+	}
+	if err := authorizeProject(ctx, s.members, auth.ProjectsDelete, project, uuid.Nil); err != nil {
+		return err
+	}
+
+	attachments, err := s.attachments.forProject(ctx, id)
+	if err != nil {
+		return err
+	}
+	if cascade {
+		if err := s.projects.DeleteWithTasks(ctx, id); err != nil {
+			return fromStore(err, "project", id)
 		}
-		return nil, err
+		s.attachments.discard(ctx, attachments)
+		return nil
 	}
-	return &project, nil
-}
 
-func UpdateProject(project *domain.Entity) error {
-	_, err := config.DB.Exec(
-		"UPDATE projects SET title = $1, description = $2, start_date = $3, end_date = $4, manager_id = $5 WHERE id = $6",
-		project.Title, project.Description, project.StartDate, project.EndDate, project.ManagerID, project.ID,
-	)
-	return err
-}
+	tasks, err := s.tasks.Count(ctx, repository.TaskQuery{ProjectID: id})
+	if err != nil {
+		return err
+	}
+	if tasks > 0 {
+		open, err := s.tasks.Count(ctx, repository.TaskQuery{ProjectID: id, Open: true})
+		if err != nil {
+			return err
+		}
+		return domain.Conflict("project_has_tasks",
+			"project still has tasks, pass cascade=tasks to delete them too").
+			With("dependents", map[string]int{"tasks": tasks, "open_tasks": open})
+	}
 
-func DeleteProject(id uuid.UUID) error {
-	_, err := config.DB.Exec("DELETE FROM projects WHERE id = $1", id)
-	return err
+	// A task added since the count is reported as project_referenced.
+	if err := s.projects.Delete(ctx, id); err != nil {
+		return fromStore(err, "project", id)
+	}
+	s.attachments.discard(ctx, attachments)
+	return nil
 }
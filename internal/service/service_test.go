@@ -0,0 +1,175 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/auth"
+	"github.com/yelnar0112/project-management/internal/blob"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+	"github.com/yelnar0112/project-management/internal/repository/memory"
+	"github.com/yelnar0112/project-management/internal/service"
+)
+
+// services are the services under test, built on one in-memory store the
+// way main builds them.
+type services struct {
+	users          repository.UserRepository
+	organizations  repository.OrganizationRepository
+	members        repository.MemberRepository
+	taskStore      repository.TaskRepository
+	milestoneStore repository.MilestoneRepository
+	apiTokens      repository.APITokenRepository
+	notifications  repository.NotificationRepository
+
+	auth         *service.AuthService
+	projects     *service.ProjectService
+	tasks        *service.TaskService
+	boards       *service.BoardService
+	dependencies *service.DependencyService
+	workflows    *service.WorkflowService
+	userService  *service.UserService
+	comments     *service.CommentService
+	attachments  *service.AttachmentService
+	worklogs     *service.WorklogService
+	sprints      *service.SprintService
+	milestones   *service.MilestoneService
+	labels       *service.LabelService
+
+	apiTokenService     *service.APITokenService
+	notificationService *service.NotificationService
+	organizationService *service.OrganizationService
+}
+
+func newServices(t *testing.T) *services {
+	t.Helper()
+	store := memory.NewStore()
+	users := memory.NewUserRepository(store)
+	tasks := memory.NewTaskRepository(store)
+	projects := memory.NewProjectRepository(store)
+	members := memory.NewMemberRepository(store)
+	organizations := memory.NewOrganizationRepository(store)
+	sessions := memory.NewSessionRepository(store)
+	apiTokens := memory.NewAPITokenRepository(store)
+	notifications := memory.NewNotificationRepository(store)
+	blobs, err := blob.NewLocal(t.TempDir())
+	must(t, err)
+
+	workflows := service.NewWorkflowService(memory.NewWorkflowRepository(store), memory.NewBoardRepository(store),
+		tasks, projects, members)
+	attachments := service.NewAttachmentService(memory.NewAttachmentRepository(store), blobs, tasks, projects,
+		members, service.AttachmentLimits{MaxSize: 1 << 20, Types: []string{"text/*"}})
+	dependencies := service.NewDependencyService(memory.NewDependencyRepository(store), tasks, projects, members,
+		workflows)
+	labels := service.NewLabelService(memory.NewLabelRepository(store), projects, members)
+	sprints := service.NewSprintService(memory.NewSprintRepository(store), tasks, projects, members)
+	milestoneStore := memory.NewMilestoneRepository(store)
+	milestones := service.NewMilestoneService(milestoneStore, tasks, projects, members)
+	boards := service.NewBoardService(memory.NewBoardRepository(store), tasks, projects, members, workflows)
+
+	return &services{
+		users:          users,
+		organizations:  organizations,
+		members:        members,
+		taskStore:      tasks,
+		milestoneStore: milestoneStore,
+		apiTokens:      apiTokens,
+		notifications:  notifications,
+		auth: service.NewAuthService(users, sessions, apiTokens, organizations,
+			auth.NewTokens([]byte("secret"), time.Minute), time.Hour),
+		projects: service.NewProjectService(projects, users, organizations, tasks, members, attachments),
+		tasks: service.NewTaskService(tasks, projects, users, organizations, members, workflows, attachments,
+			dependencies, labels, sprints, milestones, boards),
+		boards:       boards,
+		dependencies: dependencies,
+		workflows:    workflows,
+		sprints:      sprints,
+		milestones:   milestones,
+		labels:       labels,
+		userService:  service.NewUserService(users, organizations, tasks, sessions),
+		comments:     service.NewCommentService(memory.NewCommentRepository(store), tasks, projects, users, organizations, members),
+		attachments:  attachments,
+		worklogs: service.NewWorklogService(memory.NewWorklogRepository(store), tasks, projects, users,
+			organizations, members),
+
+		apiTokenService:     service.NewAPITokenService(apiTokens),
+		notificationService: service.NewNotificationService(notifications, tasks, projects, time.Hour),
+		organizationService: service.NewOrganizationService(organizations, users, projects, tasks),
+	}
+}
+
+// addOrganization stores a new organization whose admin is a new user with
+// the given email and password, and returns a context acting as that admin
+// in it.
+func (s *services) addOrganization(t *testing.T, email, password string) (context.Context, *domain.User) {
+	t.Helper()
+	ctx := context.Background()
+	hash, err := auth.HashPassword(password)
+	must(t, err)
+	user := &domain.User{ID: uuid.New(), FullName: "Admin", Email: email, Registration: time.Now().UTC(),
+		Role: domain.RoleAdmin, PasswordHash: hash}
+	must(t, s.users.Create(ctx, user))
+	organization := &domain.Organization{ID: uuid.New(), Name: email, CreatedAt: time.Now().UTC()}
+	must(t, s.organizations.Create(ctx, organization, &domain.OrganizationMember{
+		OrganizationID: organization.ID, UserID: user.ID, Role: domain.RoleAdmin, JoinedAt: time.Now().UTC()}))
+
+	principal := &auth.Principal{User: user, SessionID: uuid.New(), OrganizationID: organization.ID}
+	return auth.WithPrincipal(ctx, principal), user
+}
+
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// notFound checks that the caller was told the record does not exist,
+// rather than that it may not touch it.
+func notFound(t *testing.T, what string, err error) {
+	t.Helper()
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("%s: want a not found error, got %v", what, err)
+	}
+}
+
+// forbidden checks that the caller was refused with code.
+func forbidden(t *testing.T, what, code string, err error) {
+	t.Helper()
+	var problem *domain.Error
+	if !errors.As(err, &problem) || !errors.Is(err, domain.ErrForbidden) || problem.Code != code {
+		t.Errorf("%s: want a %s error, got %v", what, code, err)
+	}
+}
+
+// expectProblem checks that err is a problem with code, and returns it.
+func expectProblem(t *testing.T, what, code string, err error) *domain.Error {
+	t.Helper()
+	var problem *domain.Error
+	if !errors.As(err, &problem) || problem.Code != code {
+		t.Fatalf("%s: want a %s error, got %v", what, code, err)
+	}
+	return problem
+}
+
+// addUser stores a new user with role in the organization ctx acts in.
+func (s *services) addUser(t *testing.T, ctx context.Context, email string, role domain.Role) *domain.User {
+	t.Helper()
+	user := &domain.User{ID: uuid.New(), FullName: email, Email: email, Registration: time.Now().UTC(), Role: role}
+	must(t, s.users.Create(context.Background(), user))
+	must(t, s.organizations.AddMember(context.Background(), &domain.OrganizationMember{
+		OrganizationID: auth.PrincipalFrom(ctx).OrganizationID, UserID: user.ID, Role: role,
+		JoinedAt: time.Now().UTC()}))
+	return user
+}
+
+// as returns a context acting as user, logged in with a password, in the
+// organization ctx acts in.
+func as(ctx context.Context, user *domain.User) context.Context {
+	return auth.WithPrincipal(context.Background(), &auth.Principal{User: user, SessionID: uuid.New(),
+		OrganizationID: auth.PrincipalFrom(ctx).OrganizationID})
+}
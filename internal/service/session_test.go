@@ -0,0 +1,59 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/auth"
+	"github.com/yelnar0112/project-management/internal/domain"
+)
+
+// TestSessionRequired checks that API tokens, whatever their scopes, cannot
+// take the actions that no permission covers.
+func TestSessionRequired(t *testing.T) {
+	s := newServices(t)
+	admin, alice := s.addOrganization(t, "alice@example.com", "secret123")
+	organizationID := auth.PrincipalFrom(admin).OrganizationID
+	ctx := context.Background()
+	carol := s.addUser(t, admin, "carol@example.com", domain.RoleMember)
+	session := as(admin, carol)
+
+	// The token holds every permission of Carol's role.
+	token := &domain.APIToken{ID: uuid.New(), Name: "script"}
+	for _, permission := range auth.Permissions() {
+		if auth.Has(carol, permission) {
+			token.Scopes = append(token.Scopes, string(permission))
+		}
+	}
+	secret, err := s.apiTokenService.Create(session, token)
+	must(t, err)
+	principal, err := s.auth.Authenticate(ctx, secret, uuid.Nil)
+	must(t, err)
+	script := auth.WithPrincipal(ctx, principal)
+
+	project := &domain.Entity{ID: uuid.New(), Title: "Apollo", ManagerID: alice.ID}
+	must(t, s.projects.Create(admin, project))
+	task := &domain.Task{ID: uuid.New(), Title: "Launch", ProjectID: project.ID}
+	must(t, s.tasks.Create(admin, task))
+	notification := &domain.Notification{ID: uuid.New(), UserID: carol.ID, TaskID: task.ID,
+		Kind: domain.NotificationDueSoon, DueDate: time.Now().UTC(), Message: "Launch is due", CreatedAt: time.Now().UTC()}
+	must(t, s.notifications.Create(ctx, notification))
+
+	_, err = s.apiTokenService.Revoke(script, token.ID)
+	forbidden(t, "revoke token", "session_required", err)
+	_, err = s.notificationService.MarkRead(script, notification.ID)
+	forbidden(t, "mark notification read", "session_required", err)
+	forbidden(t, "mark all notifications read", "session_required", s.notificationService.MarkAllRead(script))
+	forbidden(t, "leave organization", "session_required",
+		s.organizationService.RemoveMember(script, organizationID, carol.ID))
+
+	// The same actions are allowed from a password session.
+	_, err = s.notificationService.MarkRead(session, notification.ID)
+	must(t, err)
+	must(t, s.notificationService.MarkAllRead(session))
+	_, err = s.apiTokenService.Revoke(session, token.ID)
+	must(t, err)
+	must(t, s.organizationService.RemoveMember(session, organizationID, carol.ID))
+}
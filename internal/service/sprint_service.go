@@ -0,0 +1,349 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/auth"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+// SprintService plans the sprints of projects. Anyone who can read a
+// project can see its sprints and their reports; planning, starting and
+// closing them takes projects:update. Putting tasks in a sprint is part of
+// updating the task.
+type SprintService struct {
+	sprints  repository.SprintRepository
+	tasks    repository.TaskRepository
+	projects repository.ProjectRepository
+	members  repository.MemberRepository
+}
+
+func NewSprintService(sprints repository.SprintRepository, tasks repository.TaskRepository,
+	projects repository.ProjectRepository, members repository.MemberRepository) *SprintService {
+	return &SprintService{
+		sprints:  sprints,
+		tasks:    tenantTasks{tasks},
+		projects: tenantProjects{projects},
+		members:  members,
+	}
+}
+
+// List returns the sprints of a project, ordered by start date.
+func (s *SprintService) List(ctx context.Context, projectID uuid.UUID) ([]domain.Sprint, error) {
+	if _, err := s.projects.Get(ctx, projectID); err != nil {
+		return nil, fromStore(err, "project", projectID)
+	}
+	return s.sprints.List(ctx, projectID)
+}
+
+func (s *SprintService) Get(ctx context.Context, projectID, id uuid.UUID) (*domain.Sprint, error) {
+	if _, err := s.projects.Get(ctx, projectID); err != nil {
+		return nil, fromStore(err, "project", projectID)
+	}
+	return s.find(ctx, projectID, id)
+}
+
+// Create plans a sprint in the project.
+func (s *SprintService) Create(ctx context.Context, sprint *domain.Sprint) error {
+	project, err := s.authorize(ctx, sprint.ProjectID)
+	if err != nil {
+		return err
+	}
+	if err := checkSprint(project, sprint); err != nil {
+		return err
+	}
+
+	sprint.State = domain.SprintPlanned
+	sprint.Committed = domain.SprintWork{}
+	sprint.Completed = domain.SprintWork{}
+	sprint.Unfinished = domain.SprintWork{}
+	sprint.RolledOverTo = uuid.Nil
+	sprint.StartedAt = time.Time{}
+	sprint.ClosedAt = time.Time{}
+	sprint.CreatedAt = time.Now().UTC()
+	return fromStore(s.sprints.Create(ctx, sprint), "sprint", sprint.ID)
+}
+
+// Update renames a sprint, or changes its goal or dates. Closed sprints
+// are kept as they were.
+func (s *SprintService) Update(ctx context.Context, sprint *domain.Sprint) error {
+	project, err := s.authorize(ctx, sprint.ProjectID)
+	if err != nil {
+		return err
+	}
+	current, err := s.find(ctx, sprint.ProjectID, sprint.ID)
+	if err != nil {
+		return err
+	}
+	if current.State == domain.SprintClosed {
+		return domain.Conflict("sprint_closed", "sprint %s is closed", current.ID)
+	}
+	if err := checkSprint(project, sprint); err != nil {
+		return err
+	}
+
+	sprint.State = current.State
+	sprint.Committed = current.Committed
+	sprint.Completed = current.Completed
+	sprint.Unfinished = current.Unfinished
+	sprint.RolledOverTo = current.RolledOverTo
+	sprint.StartedAt = current.StartedAt
+	sprint.ClosedAt = current.ClosedAt
+	sprint.CreatedAt = current.CreatedAt
+	return fromStore(s.sprints.Update(ctx, sprint), "sprint", sprint.ID)
+}
+
+// Delete removes a sprint. Its tasks go back to the backlog.
+func (s *SprintService) Delete(ctx context.Context, projectID, id uuid.UUID) error {
+	if _, err := s.authorize(ctx, projectID); err != nil {
+		return err
+	}
+	if _, err := s.find(ctx, projectID, id); err != nil {
+		return err
+	}
+	return fromStore(s.sprints.Delete(ctx, id), "sprint", id)
+}
+
+// Start starts a planned sprint, committing to the work in it. A project
+// runs one sprint at a time.
+func (s *SprintService) Start(ctx context.Context, projectID, id uuid.UUID) (*domain.Sprint, error) {
+	if _, err := s.authorize(ctx, projectID); err != nil {
+		return nil, err
+	}
+	sprint, err := s.find(ctx, projectID, id)
+	if err != nil {
+		return nil, err
+	}
+	if sprint.State != domain.SprintPlanned {
+		return nil, domain.Conflict("sprint_not_planned", "sprint %s is %s, only planned sprints can be started",
+			sprint.ID, sprint.State)
+	}
+	tasks, err := s.sprintTasks(ctx, sprint.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	sprint.Committed = domain.SprintWork{}
+	for _, task := range tasks {
+		sprint.Committed.Add(&task)
+	}
+	sprint.State = domain.SprintActive
+	sprint.StartedAt = time.Now().UTC()
+	err = s.sprints.Update(ctx, sprint)
+	if errors.Is(err, repository.ErrDuplicate) {
+		conflict := domain.Conflict("sprint_active", "project %s already runs a sprint, close it first", projectID)
+		if active, err := s.active(ctx, projectID); err == nil {
+			conflict.With("sprint", active)
+		}
+		return nil, conflict.Wrap(err)
+	}
+	if err != nil {
+		return nil, fromStore(err, "sprint", sprint.ID)
+	}
+	return sprint, nil
+}
+
+// Close closes the active sprint. Its completed tasks stay in it; the
+// others roll over to the planned sprint rolloverTo or, when it is
+// uuid.Nil, to the next planned sprint of the project, going by start date.
+// With backlog set, or without a planned sprint, they go to the backlog.
+func (s *SprintService) Close(ctx context.Context, projectID, id, rolloverTo uuid.UUID, backlog bool) (*domain.Sprint, error) {
+	if _, err := s.authorize(ctx, projectID); err != nil {
+		return nil, err
+	}
+	sprint, err := s.find(ctx, projectID, id)
+	if err != nil {
+		return nil, err
+	}
+	if sprint.State != domain.SprintActive {
+		return nil, domain.Conflict("sprint_not_active", "sprint %s is %s, only active sprints can be closed",
+			sprint.ID, sprint.State)
+	}
+	if backlog && rolloverTo != uuid.Nil {
+		return nil, domain.Validation("invalid_rollover", "rollover_to and backlog cannot be combined")
+	}
+	if !backlog {
+		if rolloverTo, err = s.rolloverTarget(ctx, projectID, rolloverTo); err != nil {
+			return nil, err
+		}
+	}
+
+	sprint.RolledOverTo = rolloverTo
+	sprint.State = domain.SprintClosed
+	sprint.ClosedAt = time.Now().UTC()
+	if err := s.sprints.Close(ctx, sprint, tally); err != nil {
+		return nil, fromStore(err, "sprint", sprint.ID)
+	}
+	return sprint, nil
+}
+
+// rolloverTarget returns the sprint to roll unfinished tasks over to: id,
+// which must be a planned sprint of the project, or else the next one
+// planned, or uuid.Nil for the backlog.
+func (s *SprintService) rolloverTarget(ctx context.Context, projectID, id uuid.UUID) (uuid.UUID, error) {
+	if id != uuid.Nil {
+		target, err := s.sprints.Get(ctx, id)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return uuid.Nil, err
+		}
+		if err != nil || target.ProjectID != projectID {
+			return uuid.Nil, domain.Validation("unknown_sprint", "sprint %s is not in project %s", id, projectID)
+		}
+		if target.State != domain.SprintPlanned {
+			return uuid.Nil, domain.Validation("invalid_rollover", "sprint %s is %s, tasks only roll over to planned sprints",
+				target.ID, target.State)
+		}
+		return target.ID, nil
+	}
+
+	sprints, err := s.sprints.List(ctx, projectID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	for _, sprint := range sprints {
+		if sprint.State == domain.SprintPlanned {
+			return sprint.ID, nil
+		}
+	}
+	return uuid.Nil, nil
+}
+
+// Report compares the work committed to a sprint with the work completed
+// in it.
+func (s *SprintService) Report(ctx context.Context, projectID, id uuid.UUID) (*domain.SprintReport, error) {
+	sprint, err := s.Get(ctx, projectID, id)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := s.sprintTasks(ctx, sprint.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := domain.SprintReport{Sprint: *sprint, Tasks: tasks}
+	switch sprint.State {
+	case domain.SprintClosed:
+		report.Committed = sprint.Committed
+		report.Completed = sprint.Completed
+		report.Unfinished = sprint.Unfinished
+	case domain.SprintActive:
+		report.Committed = sprint.Committed
+		report.Completed, report.Unfinished = tally(tasks)
+	default:
+		report.Completed, report.Unfinished = tally(tasks)
+		for _, task := range tasks {
+			report.Committed.Add(&task)
+		}
+	}
+	switch {
+	case report.Committed.Estimate > 0:
+		report.Percent = int(report.Completed.Estimate * 100 / report.Committed.Estimate)
+	case report.Committed.Tasks > 0:
+		report.Percent = report.Completed.Tasks * 100 / report.Committed.Tasks
+	}
+	return &report, nil
+}
+
+// tally sums up the work in tasks that is completed and the work that is
+// not.
+func tally(tasks []domain.Task) (completed, unfinished domain.SprintWork) {
+	for _, task := range tasks {
+		if task.CompletedAt.IsZero() {
+			unfinished.Add(&task)
+		} else {
+			completed.Add(&task)
+		}
+	}
+	return completed, unfinished
+}
+
+// sprintTasks returns all of the tasks in a sprint, oldest first.
+func (s *SprintService) sprintTasks(ctx context.Context, id uuid.UUID) ([]domain.Task, error) {
+	query := repository.TaskQuery{SprintID: id, Limit: repository.MaxLimit}
+	tasks := []domain.Task{}
+	for {
+		page, err := s.tasks.GetAll(ctx, query)
+		if err != nil {
+			return nil, fromStore(err, "task", uuid.Nil)
+		}
+		tasks = append(tasks, page.Items...)
+		if page.NextCursor == "" {
+			return tasks, nil
+		}
+		query.Cursor = page.NextCursor
+	}
+}
+
+// active returns the sprint a project runs.
+func (s *SprintService) active(ctx context.Context, projectID uuid.UUID) (*domain.Sprint, error) {
+	sprints, err := s.sprints.List(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, sprint := range sprints {
+		if sprint.State == domain.SprintActive {
+			return &sprint, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+// find returns a sprint of the project, reporting sprints of other
+// projects as missing.
+func (s *SprintService) find(ctx context.Context, projectID, id uuid.UUID) (*domain.Sprint, error) {
+	sprint, err := s.sprints.Get(ctx, id)
+	if err == nil && sprint.ProjectID != projectID {
+		err = repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, fromStore(err, "sprint", id)
+	}
+	return sprint, nil
+}
+
+// authorize checks that the caller may plan the sprints of an existing
+// project, which it returns.
+func (s *SprintService) authorize(ctx context.Context, projectID uuid.UUID) (*domain.Entity, error) {
+	project, err := s.projects.Get(ctx, projectID)
+	if err != nil {
+		return nil, fromStore(err, "project", projectID)
+	}
+	return project, authorizeProject(ctx, s.members, auth.ProjectsUpdate, project, uuid.Nil)
+}
+
+// checkSprint checks the name, goal and dates of a sprint, which must fall
+// within the dates of its project.
+func checkSprint(project *domain.Entity, sprint *domain.Sprint) error {
+	if err := sprint.Normalize(); err != nil {
+		return domain.Validation("invalid_sprint", "%v", err)
+	}
+	if !project.StartDate.IsZero() && sprint.StartDate.Before(project.StartDate) ||
+		!project.EndDate.IsZero() && sprint.EndDate.After(project.EndDate) {
+		return domain.Validation("date_outside_project", "sprint dates must fall within the dates of project %s",
+			project.ID).With("project_start_date", project.StartDate).With("project_end_date", project.EndDate)
+	}
+	return nil
+}
+
+// checkTaskSprint reports a sprint that is not in the task's project, or
+// that is closed.
+func (s *SprintService) checkTaskSprint(ctx context.Context, task *domain.Task) error {
+	if task.SprintID == uuid.Nil {
+		return nil
+	}
+	sprint, err := s.sprints.Get(ctx, task.SprintID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	if err != nil || sprint.ProjectID != task.ProjectID {
+		return domain.Validation("unknown_sprint", "sprint %s is not in project %s", task.SprintID, task.ProjectID)
+	}
+	if sprint.State == domain.SprintClosed {
+		return domain.Conflict("sprint_closed", "sprint %s is closed", sprint.ID)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+)
+
+// TestCloseSprint checks that closing a sprint tallies the work completed
+// in it and rolls the rest over to the next planned sprint, the sprint
+// asked for or the backlog.
+func TestCloseSprint(t *testing.T) {
+	s := newServices(t)
+	admin, alice := s.addOrganization(t, "alice@example.com", "secret123")
+	project := &domain.Entity{ID: uuid.New(), Title: "Apollo", ManagerID: alice.ID}
+	must(t, s.projects.Create(admin, project))
+	start := time.Now().UTC().Truncate(24 * time.Hour)
+	newSprint := func(name string, weeks int) *domain.Sprint {
+		sprint := &domain.Sprint{ID: uuid.New(), ProjectID: project.ID, Name: name,
+			StartDate: start.AddDate(0, 0, 14*weeks), EndDate: start.AddDate(0, 0, 14*weeks+14)}
+		must(t, s.sprints.Create(admin, sprint))
+		return sprint
+	}
+	// Sprints roll over by start date, not by when they were planned.
+	first, _, second := newSprint("Sprint 1", 0), newSprint("Sprint 3", 2), newSprint("Sprint 2", 1)
+	newTask := func(title string, estimate int64) *domain.Task {
+		task := &domain.Task{ID: uuid.New(), Title: title, ProjectID: project.ID, SprintID: first.ID,
+			OriginalEstimate: estimate}
+		must(t, s.tasks.Create(admin, task))
+		return task
+	}
+	done, open, unestimated := newTask("Launch", 3600), newTask("Land", 1800), newTask("Return", 0)
+	inSprint := func(what string, want uuid.UUID, tasks ...*domain.Task) {
+		t.Helper()
+		for _, task := range tasks {
+			got, err := s.tasks.Get(admin, task.ID)
+			must(t, err)
+			if got.SprintID != want {
+				t.Errorf("%s: task %s is in sprint %v, want %v", what, task.Title, got.SprintID, want)
+			}
+		}
+	}
+
+	_, err := s.sprints.Close(admin, project.ID, first.ID, uuid.Nil, false)
+	expectProblem(t, "close a planned sprint", "sprint_not_active", err)
+	started, err := s.sprints.Start(admin, project.ID, first.ID)
+	must(t, err)
+	if started.Committed != (domain.SprintWork{Tasks: 3, Estimate: 5400}) {
+		t.Fatalf("committed work is %+v", started.Committed)
+	}
+	for _, state := range []string{"in_progress", "review", "done"} {
+		_, err := s.tasks.Transition(admin, done.ID, state)
+		must(t, err)
+	}
+
+	_, err = s.sprints.Close(admin, project.ID, first.ID, second.ID, true)
+	expectProblem(t, "roll over to a sprint and the backlog", "invalid_rollover", err)
+	_, err = s.sprints.Close(admin, project.ID, first.ID, first.ID, false)
+	expectProblem(t, "roll over to the sprint being closed", "invalid_rollover", err)
+	_, err = s.sprints.Close(admin, project.ID, first.ID, uuid.New(), false)
+	expectProblem(t, "roll over to a missing sprint", "unknown_sprint", err)
+
+	closed, err := s.sprints.Close(admin, project.ID, first.ID, uuid.Nil, false)
+	must(t, err)
+	switch {
+	case closed.State != domain.SprintClosed || closed.ClosedAt.IsZero():
+		t.Errorf("closed sprint is %+v", closed)
+	case closed.Completed != (domain.SprintWork{Tasks: 1, Estimate: 3600}):
+		t.Errorf("completed work is %+v", closed.Completed)
+	case closed.Unfinished != (domain.SprintWork{Tasks: 2, Estimate: 1800}):
+		t.Errorf("unfinished work is %+v", closed.Unfinished)
+	case closed.RolledOverTo != second.ID:
+		t.Errorf("rolled over to %v, want the next sprint %v", closed.RolledOverTo, second.ID)
+	}
+	inSprint("after closing", first.ID, done)
+	inSprint("after closing", second.ID, open, unestimated)
+	report, err := s.sprints.Report(admin, project.ID, first.ID)
+	must(t, err)
+	if report.Completed != closed.Completed || report.Unfinished != closed.Unfinished || report.Percent != 66 {
+		t.Errorf("report of the closed sprint is %+v", report)
+	}
+	_, err = s.sprints.Close(admin, project.ID, first.ID, uuid.Nil, false)
+	expectProblem(t, "close a sprint twice", "sprint_not_active", err)
+
+	// Tasks roll over to the sprint asked for, past the next one.
+	fourth := newSprint("Sprint 4", 3)
+	_, err = s.sprints.Start(admin, project.ID, second.ID)
+	must(t, err)
+	closed, err = s.sprints.Close(admin, project.ID, second.ID, fourth.ID, false)
+	must(t, err)
+	if closed.RolledOverTo != fourth.ID || closed.Unfinished != (domain.SprintWork{Tasks: 2, Estimate: 1800}) {
+		t.Errorf("sprint rolled over to %v: %+v", fourth.ID, closed)
+	}
+	inSprint("after rolling over past the next sprint", fourth.ID, open, unestimated)
+
+	// Or to the backlog.
+	_, err = s.sprints.Start(admin, project.ID, fourth.ID)
+	must(t, err)
+	closed, err = s.sprints.Close(admin, project.ID, fourth.ID, uuid.Nil, true)
+	must(t, err)
+	if closed.RolledOverTo != uuid.Nil || closed.Completed != (domain.SprintWork{}) {
+		t.Errorf("sprint closed to the backlog is %+v", closed)
+	}
+	inSprint("after closing to the backlog", uuid.Nil, open, unestimated)
+}
@@ -1,66 +1,806 @@
 package service
 
 import (
-	"database/sql"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
-	"github.com/yelnar0112/project-management/internal/config"
+	"github.com/yelnar0112/project-management/internal/auth"
 	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
 )
 
-func GetAllTasks() (tasks []domain.Task, err error) {
-	rows, err := config.DB.Query("SELECT id, title, description, priority, state, assignee, project_id, created_at, completed_at FROM tasks")
+type TaskService struct {
+	tasks     repository.TaskRepository
+	projects  repository.ProjectRepository
+	users     repository.UserRepository
+	members   repository.MemberRepository
+	workflows *WorkflowService
+	// attachments removes the files of deleted tasks.
+	attachments *AttachmentService
+	// dependencies keeps blocked tasks from being completed.
+	dependencies *DependencyService
+	// labels checks that tasks only get labels of their project.
+	labels *LabelService
+	// sprints checks that tasks are only put in open sprints of their
+	// project.
+	sprints *SprintService
+	// milestones checks that tasks are only linked to milestones of their
+	// project.
+	milestones *MilestoneService
+	// boards places tasks on their project's board and keeps its columns
+	// within their WIP limits.
+	boards *BoardService
+}
+
+func NewTaskService(tasks repository.TaskRepository, projects repository.ProjectRepository,
+	users repository.UserRepository, organizations repository.OrganizationRepository,
+	members repository.MemberRepository, workflows *WorkflowService, attachments *AttachmentService,
+	dependencies *DependencyService, labels *LabelService, sprints *SprintService,
+	milestones *MilestoneService, boards *BoardService) *TaskService {
+	return &TaskService{
+		tasks:        tenantTasks{tasks},
+		projects:     tenantProjects{projects},
+		users:        tenantUsers{users: users, organizations: organizations},
+		members:      members,
+		workflows:    workflows,
+		attachments:  attachments,
+		dependencies: dependencies,
+		labels:       labels,
+		sprints:      sprints,
+		milestones:   milestones,
+		boards:       boards,
+	}
+}
+
+func (s *TaskService) GetAll(ctx context.Context, query repository.TaskQuery) (*repository.Page[domain.Task], error) {
+	tasks, err := s.tasks.GetAll(ctx, query)
+	return tasks, fromStore(err, "task", uuid.Nil)
+}
+
+// GetForProject lists the tasks of an existing project. Any project filter in
+// query is replaced.
+func (s *TaskService) GetForProject(ctx context.Context, projectID uuid.UUID, query repository.TaskQuery) (*repository.Page[domain.Task], error) {
+	if err := s.checkProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+	query.ProjectID = projectID
+	return s.GetAll(ctx, query)
+}
+
+// GetForUser lists the tasks assigned to an existing user. Any assignee
+// filter in query is replaced.
+func (s *TaskService) GetForUser(ctx context.Context, userID uuid.UUID, query repository.TaskQuery) (*repository.Page[domain.Task], error) {
+	if _, err := s.users.Get(ctx, userID); err != nil {
+		return nil, fromStore(err, "user", userID)
+	}
+	query.Assignee = userID
+	return s.GetAll(ctx, query)
+}
+
+// CreateInProject creates the task in an existing project, whatever project
+// the task itself names.
+func (s *TaskService) CreateInProject(ctx context.Context, projectID uuid.UUID, task *domain.Task) error {
+	if err := s.checkProject(ctx, projectID); err != nil {
+		return err
+	}
+	task.ProjectID = projectID
+	return s.Create(ctx, task)
+}
+
+// GetSubtasks lists the tasks directly below an existing task. Any parent
+// filter in query is replaced.
+func (s *TaskService) GetSubtasks(ctx context.Context, id uuid.UUID, query repository.TaskQuery) (*repository.Page[domain.Task], error) {
+	if _, err := s.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	query.ParentID = id
+	return s.GetAll(ctx, query)
+}
+
+// CreateSubtask creates the task below an existing one, in its project,
+// whatever parent and project the task itself names.
+func (s *TaskService) CreateSubtask(ctx context.Context, parentID uuid.UUID, task *domain.Task) error {
+	parent, err := s.Get(ctx, parentID)
+	if err != nil {
+		return err
+	}
+	task.ParentID = parent.ID
+	task.ProjectID = parent.ProjectID
+	return s.Create(ctx, task)
+}
+
+// Tree returns a task with all of its subtasks, each with the progress of
+// the subtasks below it. A subtask is complete when it is in a terminal
+// state of the project's workflow.
+func (s *TaskService) Tree(ctx context.Context, id uuid.UUID) (*domain.TaskTree, error) {
+	task, err := s.Get(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	subtasks, err := s.tasks.Subtasks(ctx, id)
+	if err != nil {
+		return nil, fromStore(err, "task", id)
+	}
+	workflow, err := s.workflows.forProject(ctx, task.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[uuid.UUID][]domain.Task)
+	for _, subtask := range subtasks {
+		children[subtask.ParentID] = append(children[subtask.ParentID], subtask)
+	}
+	tree := buildTree(*task, children, workflow)
+	return &tree, nil
+}
 
-	for rows.Next() {
-		var task domain.Task
-		if err := rows.Scan(&task.ID, &task.Title, &task.Description, &task.Priority, &task.State, &task.Assignee, &task.ProjectID, &task.CreatedAt, &task.CompletedAt); err != nil {
-			return nil, err
+// buildTree arranges the subtasks of task, given by parent, below it and
+// rolls up their progress.
+func buildTree(task domain.Task, children map[uuid.UUID][]domain.Task, workflow *domain.Workflow) domain.TaskTree {
+	tree := domain.TaskTree{Task: task, Subtasks: []domain.TaskTree{}}
+	for _, child := range children[task.ID] {
+		subtree := buildTree(child, children, workflow)
+		tree.Progress.Total += subtree.Progress.Total + 1
+		tree.Progress.Completed += subtree.Progress.Completed
+		if workflow.IsTerminal(child.State) {
+			tree.Progress.Completed++
 		}
-		tasks = append(tasks, task)
+		tree.Subtasks = append(tree.Subtasks, subtree)
 	}
+	if tree.Progress.Total > 0 {
+		tree.Progress.Percent = tree.Progress.Completed * 100 / tree.Progress.Total
+	}
+	return tree
+}
 
-	if err = rows.Err(); err != nil {
-		return nil, err
+func (s *TaskService) checkProject(ctx context.Context, projectID uuid.UUID) error {
+	_, err := s.projects.Get(ctx, projectID)
+	return fromStore(err, "project", projectID)
+}
+
+// checkReferences reports a task naming a project or assignee that does not
+// exist.
+func (s *TaskService) checkReferences(ctx context.Context, task *domain.Task) error {
+	if task.ProjectID == uuid.Nil {
+		return domain.Validation("missing_project", "project_id is required")
+	}
+	if err := checkExists(ctx, s.projects.Get, task.ProjectID, "unknown_project", "project"); err != nil {
+		return err
+	}
+	return checkExists(ctx, s.users.Get, task.Assignee, "unknown_assignee", "user")
+}
+
+// checkParent reports a parent that does not exist, is in another project
+// or is the task itself or one of its subtasks.
+func (s *TaskService) checkParent(ctx context.Context, task *domain.Task) error {
+	if task.ParentID == uuid.Nil {
+		return nil
+	}
+	if task.ParentID == task.ID {
+		return domain.Validation("invalid_parent", "a task cannot be its own parent")
+	}
+	parent, err := s.tasks.Get(ctx, task.ParentID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return domain.Validation("unknown_parent", "task %s does not exist", task.ParentID).Wrap(err)
+	}
+	if err != nil {
+		return err
+	}
+	if parent.ProjectID != task.ProjectID {
+		return domain.Validation("invalid_parent", "parent task %s is in project %s, not %s",
+			parent.ID, parent.ProjectID, task.ProjectID)
+	}
+	return nil
+}
+
+// checkAssignee reports an assignee who does not work on the task's
+// project: only its owners and contributors can be assigned tasks.
+func (s *TaskService) checkAssignee(ctx context.Context, task *domain.Task) error {
+	if task.Assignee == uuid.Nil {
+		return nil
+	}
+	member, err := s.members.Get(ctx, task.ProjectID, task.Assignee)
+	if errors.Is(err, repository.ErrNotFound) {
+		return domain.Validation("assignee_not_member", "user %s is not a member of project %s",
+			task.Assignee, task.ProjectID).Wrap(err)
+	}
+	if err != nil {
+		return err
+	}
+	if !member.Role.Assignable() {
+		return domain.Validation("assignee_not_member", "user %s only observes project %s",
+			task.Assignee, task.ProjectID)
+	}
+	return nil
+}
+
+// checkDates reports a task due before it starts or whose dates fall
+// outside those of its project. Dates are kept in UTC.
+func (s *TaskService) checkDates(ctx context.Context, task *domain.Task) error {
+	task.StartDate = task.StartDate.UTC()
+	task.DueDate = task.DueDate.UTC()
+	if !task.StartDate.IsZero() && !task.DueDate.IsZero() && task.DueDate.Before(task.StartDate) {
+		return domain.Validation("invalid_dates", "due_date must not be before start_date")
+	}
+
+	project, err := s.projects.Get(ctx, task.ProjectID)
+	if err != nil {
+		return fromStore(err, "project", task.ProjectID)
+	}
+	for _, date := range []time.Time{task.StartDate, task.DueDate} {
+		if date.IsZero() {
+			continue
+		}
+		if !project.StartDate.IsZero() && date.Before(project.StartDate) ||
+			!project.EndDate.IsZero() && date.After(project.EndDate) {
+			return domain.Validation("date_outside_project", "task dates must fall within the dates of project %s",
+				project.ID).With("project_start_date", project.StartDate).With("project_end_date", project.EndDate)
+		}
+	}
+	return nil
+}
+
+// authorize checks that the caller may take permission on the task, whose
+// project must exist.
+func (s *TaskService) authorize(ctx context.Context, permission auth.Permission, task *domain.Task) error {
+	project, err := s.projects.Get(ctx, task.ProjectID)
+	if err != nil {
+		return fromStore(err, "project", task.ProjectID)
+	}
+	return authorizeProject(ctx, s.members, permission, project, task.Assignee)
+}
+
+// Create starts the task in its project's initial state unless another
+// state of that workflow is given. A task estimated without a remaining
+// estimate has all of its original estimate left.
+func (s *TaskService) Create(ctx context.Context, task *domain.Task) error {
+	if err := s.checkReferences(ctx, task); err != nil {
+		return err
+	}
+	if err := s.authorize(ctx, auth.TasksCreate, task); err != nil {
+		return err
+	}
+	if err := s.checkAssignee(ctx, task); err != nil {
+		return err
+	}
+	if err := s.checkParent(ctx, task); err != nil {
+		return err
+	}
+	if err := s.sprints.checkTaskSprint(ctx, task); err != nil {
+		return err
+	}
+	if err := s.milestones.checkTaskMilestone(ctx, task); err != nil {
+		return err
+	}
+	if err := s.checkDates(ctx, task); err != nil {
+		return err
+	}
+	labels, err := s.labels.checkLabels(ctx, task.ProjectID, task.Labels)
+	if err != nil {
+		return err
+	}
+	task.Labels = labels
+	workflow, err := s.workflows.forProject(ctx, task.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	if task.State == "" {
+		task.State = workflow.InitialState
+	} else if !workflow.HasState(task.State) {
+		return unknownState(workflow, task.State)
+	}
+	_, limits, err := s.boards.checkWIP(ctx, task.ProjectID, task.State, "")
+	if err != nil {
+		return err
+	}
+
+	if task.Priority == "" {
+		task.Priority = domain.DefaultPriority
+	} else if err := normalizePriority(task); err != nil {
+		return err
+	}
+	if err := checkEstimates(task); err != nil {
+		return err
+	}
+	if err := checkRank(task); err != nil {
+		return err
+	}
+	if task.RemainingEstimate == 0 {
+		task.RemainingEstimate = task.OriginalEstimate
+	}
+
+	if task.CreatedAt.IsZero() {
+		task.CreatedAt = time.Now().UTC()
+	}
+	task.CompletedAt = time.Time{}
+	if workflow.IsTerminal(task.State) {
+		task.CompletedAt = time.Now().UTC()
+	}
+
+	err = s.tasks.CreateWithin(ctx, task, limits)
+	if errors.Is(err, repository.ErrLimitReached) {
+		return wipExceeded(limits)
+	}
+	return fromStore(err, "task", task.ID)
+}
+
+func (s *TaskService) Get(ctx context.Context, id uuid.UUID) (*domain.Task, error) {
+	task, err := s.tasks.Get(ctx, id)
+	return task, fromStore(err, "task", id)
+}
+
+// Update replaces the task's fields. A change of state must be allowed by
+// the workflow, exactly as if it had been made through Transition, and a
+// task cannot be completed while its blockers are open or enter a board
+// column that is full and rejects more. Moving the task to another project
+// also needs the right to create tasks there, and takes its subtasks along.
+// Each of them must fit the new project as if moved on its own, with its
+// state in the project's workflow, its assignee able to work on it and its
+// dates within the project's; the move is refused with the list of those
+// that do not, or when they would overfill a board column that rejects
+// more. Without labels, the task keeps its own, unless it moves to another
+// project, whose catalog they are not part of. Subtasks moved along leave
+// their sprints and milestones, which are of the old project.
+func (s *TaskService) Update(ctx context.Context, task *domain.Task) error {
+	return s.update(ctx, task, nil)
+}
+
+// update is Update, which writes the ranks of other tasks that a move on
+// the board sets along with the task.
+func (s *TaskService) update(ctx context.Context, task *domain.Task, ranks map[uuid.UUID]string) error {
+	current, err := s.Get(ctx, task.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.authorize(ctx, auth.TasksUpdate, current); err != nil {
+		return err
+	}
+	if err := s.checkReferences(ctx, task); err != nil {
+		return err
+	}
+	if task.ProjectID != current.ProjectID {
+		if err := s.authorize(ctx, auth.TasksCreate, task); err != nil {
+			return err
+		}
+	}
+	// Tasks assigned before their assignee left the project stay valid.
+	if task.ProjectID != current.ProjectID || task.Assignee != current.Assignee {
+		if err := s.checkAssignee(ctx, task); err != nil {
+			return err
+		}
+	}
+	if task.ProjectID != current.ProjectID || task.ParentID != current.ParentID {
+		if err := s.checkParent(ctx, task); err != nil {
+			return err
+		}
+	}
+	// Completed tasks stay in their sprint once it closes.
+	if task.ProjectID != current.ProjectID || task.SprintID != current.SprintID {
+		if err := s.sprints.checkTaskSprint(ctx, task); err != nil {
+			return err
+		}
+	}
+	if task.ProjectID != current.ProjectID || task.MilestoneID != current.MilestoneID {
+		if err := s.milestones.checkTaskMilestone(ctx, task); err != nil {
+			return err
+		}
+	}
+	// Tasks keep their dates when their project's dates change later.
+	if task.ProjectID != current.ProjectID || !task.StartDate.Equal(current.StartDate) ||
+		!task.DueDate.Equal(current.DueDate) {
+		if err := s.checkDates(ctx, task); err != nil {
+			return err
+		}
+	}
+	if task.Labels == nil && task.ProjectID == current.ProjectID {
+		task.Labels = current.Labels
+	}
+	if task.Rank == "" && task.ProjectID == current.ProjectID {
+		task.Rank = current.Rank
+	}
+	if err := checkRank(task); err != nil {
+		return err
+	}
+	if task.Labels, err = s.labels.checkLabels(ctx, task.ProjectID, task.Labels); err != nil {
+		return err
+	}
+	var subtasks []domain.Task
+	if task.ProjectID != current.ProjectID || task.ParentID != uuid.Nil && task.ParentID != current.ParentID {
+		if subtasks, err = s.tasks.Subtasks(ctx, task.ID); err != nil {
+			return fromStore(err, "task", task.ID)
+		}
+	}
+	for _, subtask := range subtasks {
+		if subtask.ID == task.ParentID {
+			return domain.Validation("invalid_parent", "task %s is a subtask of task %s", task.ParentID, task.ID)
+		}
+	}
+	workflow, err := s.workflows.forProject(ctx, task.ProjectID)
+	if err != nil {
+		return err
+	}
+	if task.ProjectID != current.ProjectID {
+		if err := s.checkMovedSubtasks(ctx, workflow, task.ProjectID, subtasks); err != nil {
+			return err
+		}
+	}
+
+	if task.Priority == "" {
+		task.Priority = current.Priority
+	} else if err := normalizePriority(task); err != nil {
+		return err
+	}
+	if err := checkEstimates(task); err != nil {
+		return err
 	}
 
-	return tasks, nil
+	if task.State == "" {
+		task.State = current.State
+	}
+	switch {
+	case task.ProjectID != current.ProjectID:
+		// The task is moving to a workflow that may not know its old state.
+		if !workflow.HasState(task.State) {
+			return unknownState(workflow, task.State)
+		}
+	case task.State != current.State:
+		if err := checkTransition(workflow, current.State, task.State); err != nil {
+			return err
+		}
+	}
+	var limits []repository.WIPLimit
+	if task.State != current.State || task.ProjectID != current.ProjectID {
+		if err := s.checkCompletable(ctx, workflow, current, task.State); err != nil {
+			return err
+		}
+		if task.ProjectID != current.ProjectID {
+			// The subtasks enter the project's board along with the task.
+			states := []string{task.State}
+			for _, subtask := range subtasks {
+				states = append(states, subtask.State)
+			}
+			limits, err = s.boards.checkWIPEntering(ctx, task.ProjectID, states)
+		} else {
+			_, limits, err = s.boards.checkWIP(ctx, task.ProjectID, task.State, current.State)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	task.CreatedAt = current.CreatedAt
+	task.CompletedAt = completedAt(workflow, current, task.State)
+	return s.save(ctx, task, ranks, limits)
+}
+
+// save writes a task with the ranks of other tasks, checking the WIP limits
+// of the columns it enters again in the same transaction: the counts taken
+// before may be stale by then.
+func (s *TaskService) save(ctx context.Context, task *domain.Task, ranks map[uuid.UUID]string,
+	limits []repository.WIPLimit) error {
+	err := s.tasks.Move(ctx, task, ranks, limits)
+	if errors.Is(err, repository.ErrLimitReached) {
+		return wipExceeded(limits)
+	}
+	return fromStore(err, "task", task.ID)
 }
 
-func CreateTask(task *domain.Task) error {
-	_, err := config.DB.Exec(
-		"INSERT INTO tasks (id, title, description, priority, state, assignee, project_id, created_at, completed_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)",
-		task.ID, task.Title, task.Description, task.Priority, task.State, task.Assignee, task.ProjectID, task.CreatedAt, task.CompletedAt,
-	)
-	return err
+// Transition moves the task to another state of its project's workflow. A
+// task cannot enter a terminal state while its blockers are open, nor a
+// board column that is full and rejects more.
+func (s *TaskService) Transition(ctx context.Context, id uuid.UUID, to string) (*domain.Task, error) {
+	task, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, auth.TasksUpdate, task); err != nil {
+		return nil, err
+	}
+	workflow, err := s.workflows.forProject(ctx, task.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkTransition(workflow, task.State, to); err != nil {
+		return nil, err
+	}
+	if err := s.checkCompletable(ctx, workflow, task, to); err != nil {
+		return nil, err
+	}
+	_, limits, err := s.boards.checkWIP(ctx, task.ProjectID, to, task.State)
+	if err != nil {
+		return nil, err
+	}
+
+	task.CompletedAt = completedAt(workflow, task, to)
+	task.State = to
+	if err := s.save(ctx, task, nil, limits); err != nil {
+		return nil, err
+	}
+	return task, nil
 }
 
-func GetTask(id uuid.UUID) (*domain.Task, error) {
-	var task domain.Task
-	err := config.DB.QueryRow(
-		"SELECT id, title, description, priority, state, assignee, project_id, created_at, completed_at FROM tasks WHERE id = $1", id,
-	).Scan(&task.ID, &task.Title, &task.Description, &task.Priority, &task.State, &task.Assignee, &task.ProjectID, &task.CreatedAt, &task.CompletedAt)
+// Move puts a task in a column of its project's board, right before or
+// after another task in it, or else at its top. Entering another column
+// moves the task to the first of the column's states that its workflow
+// allows, and its state and rank change together, along with the ranks of
+// the column's other tasks when it is ranked anew. A column at its WIP limit
+// refuses the task when its policy is "reject"; with "warn", the move is
+// made and reported with a warning.
+func (s *TaskService) Move(ctx context.Context, id uuid.UUID, column string, before, after uuid.UUID) (*domain.TaskMove, error) {
+	task, err := s.Get(ctx, id)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
+		return nil, err
+	}
+	if err := s.authorize(ctx, auth.TasksUpdate, task); err != nil {
+		return nil, err
+	}
+	switch {
+	case before != uuid.Nil && after != uuid.Nil:
+		return nil, domain.Validation("invalid_move", "before and after cannot be combined")
+	case before == id || after == id:
+		return nil, domain.Validation("invalid_move", "task %s cannot be moved next to itself", id)
+	}
+	workflow, err := s.workflows.forProject(ctx, task.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	board, err := s.boards.forProject(ctx, task.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	target := board.Column(column)
+	if target == nil {
+		names := make([]string, len(board.Columns))
+		for i, c := range board.Columns {
+			names[i] = c.Name
 		}
+		return nil, domain.Validation("unknown_column", "the board of project %s has no column %q",
+			task.ProjectID, column).With("allowed", names)
+	}
+
+	moved := *task
+	if moved.State, err = columnState(workflow, target, task.State); err != nil {
+		return nil, err
+	}
+	warning, _, err := s.boards.checkWIP(ctx, task.ProjectID, moved.State, task.State)
+	if err != nil {
 		return nil, err
 	}
-	return &task, nil
+	var ranks map[uuid.UUID]string
+	if moved.Rank, ranks, err = s.boards.place(ctx, &moved, target, before, after); err != nil {
+		return nil, err
+	}
+	if err := s.update(ctx, &moved, ranks); err != nil {
+		return nil, err
+	}
+
+	result := &domain.TaskMove{Task: moved, Warnings: []domain.WIPWarning{}}
+	if warning != nil {
+		result.Warnings = append(result.Warnings, *warning)
+	}
+	return result, nil
 }
 
-func UpdateTask(task *domain.Task) error {
-	_, err := config.DB.Exec(
-		"UPDATE tasks SET title = $1, description = $2, priority = $3, state = $4, assignee = $5, project_id = $6, created_at = $7, completed_at = $8 WHERE id = $9",
-		task.Title, task.Description, task.Priority, task.State, task.Assignee, task.ProjectID, task.CreatedAt, task.CompletedAt, task.ID,
-	)
-	return err
+// columnState returns the state a task in state from takes on in a board
+// column: from itself when the column maps it, or else the first state of
+// the column that the workflow lets the task move to.
+func columnState(workflow *domain.Workflow, column *domain.BoardColumn, from string) (string, error) {
+	if column.Maps(from) {
+		return from, nil
+	}
+	for _, state := range column.States {
+		if workflow.CanTransition(from, state) {
+			return state, nil
+		}
+	}
+	return "", checkTransition(workflow, from, column.States[0])
 }
 
-func DeleteTask(id uuid.UUID) error {
-	_, err := config.DB.Exec("DELETE FROM tasks WHERE id = $1", id)
-	return err
+// AddLabel puts a label of the task's project on it, unless it has it
+// already.
+func (s *TaskService) AddLabel(ctx context.Context, id, labelID uuid.UUID) (*domain.Task, error) {
+	task, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, auth.TasksUpdate, task); err != nil {
+		return nil, err
+	}
+	if labelID == uuid.Nil {
+		return nil, domain.Validation("missing_label", "label_id is required")
+	}
+	if slices.Contains(task.Labels, labelID) {
+		return task, nil
+	}
+	if task.Labels, err = s.labels.checkLabels(ctx, task.ProjectID, append(task.Labels, labelID)); err != nil {
+		return nil, err
+	}
+	if err := s.tasks.Update(ctx, task); err != nil {
+		return nil, fromStore(err, "task", id)
+	}
+	return task, nil
+}
+
+// RemoveLabel takes a label off the task.
+func (s *TaskService) RemoveLabel(ctx context.Context, id, labelID uuid.UUID) (*domain.Task, error) {
+	task, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, auth.TasksUpdate, task); err != nil {
+		return nil, err
+	}
+	if !slices.Contains(task.Labels, labelID) {
+		return nil, domain.NotFound("label_not_found", "task %s does not have label %s", id, labelID)
+	}
+	task.Labels = slices.DeleteFunc(task.Labels, func(l uuid.UUID) bool { return l == labelID })
+	if err := s.tasks.Update(ctx, task); err != nil {
+		return nil, fromStore(err, "task", id)
+	}
+	return task, nil
+}
+
+// Delete removes the task. Its subtasks are deleted with it when cascade is
+// set; otherwise a task that still has subtasks is refused with a conflict
+// counting them.
+func (s *TaskService) Delete(ctx context.Context, id uuid.UUID, cascade bool) error {
+	task, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.authorize(ctx, auth.TasksDelete, task); err != nil {
+		return err
+	}
+	subtasks, err := s.tasks.Subtasks(ctx, id)
+	if err != nil {
+		return fromStore(err, "task", id)
+	}
+	if len(subtasks) > 0 && !cascade {
+		open := 0
+		for _, subtask := range subtasks {
+			if subtask.CompletedAt.IsZero() {
+				open++
+			}
+		}
+		return domain.Conflict("task_has_subtasks",
+			"task still has subtasks, pass cascade=subtasks to delete them too").
+			With("dependents", map[string]int{"subtasks": len(subtasks), "open_subtasks": open})
+	}
+
+	attachments, err := s.attachments.forTask(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, subtask := range subtasks {
+		more, err := s.attachments.forTask(ctx, subtask.ID)
+		if err != nil {
+			return err
+		}
+		attachments = append(attachments, more...)
+	}
+	if err := s.tasks.Delete(ctx, id); err != nil {
+		return fromStore(err, "task", id)
+	}
+	s.attachments.discard(ctx, attachments)
+	return nil
+}
+
+// subtaskProblem tells why a subtask cannot follow its parent to another
+// project.
+type subtaskProblem struct {
+	ID     uuid.UUID `json:"id"`
+	Code   string    `json:"code"`
+	Detail string    `json:"detail"`
+}
+
+// checkMovedSubtasks checks the subtasks that follow a task to another
+// project against that project, reporting every one that does not fit it.
+func (s *TaskService) checkMovedSubtasks(ctx context.Context, workflow *domain.Workflow, projectID uuid.UUID,
+	subtasks []domain.Task) error {
+	var problems []subtaskProblem
+	for _, subtask := range subtasks {
+		subtask.ProjectID = projectID
+		var err error
+		if !workflow.HasState(subtask.State) {
+			err = unknownState(workflow, subtask.State)
+		}
+		if err == nil {
+			err = s.checkAssignee(ctx, &subtask)
+		}
+		if err == nil {
+			err = s.checkDates(ctx, &subtask)
+		}
+		var problem *domain.Error
+		switch {
+		case errors.As(err, &problem) && errors.Is(err, domain.ErrValidation):
+			problems = append(problems, subtaskProblem{ID: subtask.ID, Code: problem.Code, Detail: problem.Message})
+		case err != nil:
+			return err
+		}
+	}
+	if len(problems) > 0 {
+		return domain.Validation("invalid_subtasks", "%d subtasks cannot move to project %s along with their parent",
+			len(problems), projectID).With("subtasks", problems)
+	}
+	return nil
+}
+
+// checkCompletable reports an open task that would be completed by moving
+// to state while it still waits on open blockers.
+func (s *TaskService) checkCompletable(ctx context.Context, workflow *domain.Workflow, current *domain.Task, state string) error {
+	if !workflow.IsTerminal(state) || !current.CompletedAt.IsZero() {
+		return nil
+	}
+	return s.dependencies.checkUnblocked(ctx, current.ID)
+}
+
+func normalizePriority(task *domain.Task) error {
+	priority, err := domain.ParsePriority(string(task.Priority))
+	if err != nil {
+		return domain.Validation("invalid_priority", "%v", err).With("allowed", domain.Priorities())
+	}
+	task.Priority = priority
+	return nil
+}
+
+func checkEstimates(task *domain.Task) error {
+	if task.OriginalEstimate < 0 || task.RemainingEstimate < 0 {
+		return domain.Validation("invalid_estimate", "estimates must not be negative")
+	}
+	return nil
+}
+
+func checkRank(task *domain.Task) error {
+	if task.Rank != "" && !domain.ValidRank(task.Rank) {
+		return domain.Validation("invalid_rank", "rank %q is not a board rank", task.Rank)
+	}
+	return nil
+}
+
+func checkTransition(workflow *domain.Workflow, from, to string) error {
+	if !workflow.HasState(to) {
+		return unknownState(workflow, to)
+	}
+	if !workflow.CanTransition(from, to) {
+		allowed := workflow.NextStates(from)
+		if len(allowed) == 0 {
+			return domain.Conflict("illegal_transition", "no transitions are allowed from %q", from).
+				With("allowed", []string{})
+		}
+		return domain.Conflict("illegal_transition", "cannot move from %q to %q, allowed: %s",
+			from, to, strings.Join(allowed, ", ")).With("allowed", allowed)
+	}
+	return nil
+}
+
+func unknownState(workflow *domain.Workflow, state string) error {
+	names := stateNames(workflow)
+	return domain.Validation("unknown_state", "unknown task state %q, expected one of: %s",
+		state, strings.Join(names, ", ")).With("allowed", names)
+}
+
+func stateNames(workflow *domain.Workflow) []string {
+	names := make([]string, len(workflow.States))
+	for i, s := range workflow.States {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// completedAt keeps CompletedAt in step with the workflow: it is set when a
+// task enters a terminal state and cleared when it leaves one.
+func completedAt(workflow *domain.Workflow, current *domain.Task, state string) time.Time {
+	if !workflow.IsTerminal(state) {
+		return time.Time{}
+	}
+	if workflow.IsTerminal(current.State) && !current.CompletedAt.IsZero() {
+		return current.CompletedAt
+	}
+	return time.Now().UTC()
 }
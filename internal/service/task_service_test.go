@@ -0,0 +1,241 @@
+package service_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+)
+
+// TestMoveWithSubtasks checks that a task only moves to another project
+// when all of its subtasks fit there too.
+func TestMoveWithSubtasks(t *testing.T) {
+	s := newServices(t)
+	admin, alice := s.addOrganization(t, "alice@example.com", "secret123")
+	bob := s.addUser(t, admin, "bob@example.com", domain.RoleMember)
+
+	source := &domain.Entity{ID: uuid.New(), Title: "Source", ManagerID: alice.ID}
+	must(t, s.projects.Create(admin, source))
+	target := &domain.Entity{ID: uuid.New(), Title: "Target", ManagerID: alice.ID,
+		StartDate: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)}
+	must(t, s.projects.Create(admin, target))
+	must(t, s.members.Add(admin, &domain.Member{ProjectID: source.ID, UserID: bob.ID,
+		Role: domain.ProjectContributor, JoinedAt: time.Now().UTC()}))
+
+	parent := &domain.Task{ID: uuid.New(), Title: "Parent", ProjectID: source.ID}
+	must(t, s.tasks.Create(admin, parent))
+	assigned := &domain.Task{ID: uuid.New(), Title: "Assigned", ProjectID: source.ID, ParentID: parent.ID,
+		Assignee: bob.ID}
+	must(t, s.tasks.Create(admin, assigned))
+	late := &domain.Task{ID: uuid.New(), Title: "Late", ProjectID: source.ID, ParentID: assigned.ID,
+		DueDate: time.Date(2027, 6, 1, 0, 0, 0, 0, time.UTC)}
+	must(t, s.tasks.Create(admin, late))
+	fine := &domain.Task{ID: uuid.New(), Title: "Fine", ProjectID: source.ID, ParentID: parent.ID}
+	must(t, s.tasks.Create(admin, fine))
+
+	move := func() error {
+		t.Helper()
+		task, err := s.tasks.Get(admin, parent.ID)
+		must(t, err)
+		task.ProjectID = target.ID
+		return s.tasks.Update(admin, task)
+	}
+
+	var problem *domain.Error
+	err := move()
+	if !errors.As(err, &problem) || problem.Code != "invalid_subtasks" {
+		t.Fatalf("want invalid_subtasks, got %v", err)
+	}
+	// The offenders are reported the way clients see them.
+	var offenders []struct {
+		ID   uuid.UUID `json:"id"`
+		Code string    `json:"code"`
+	}
+	data, err := json.Marshal(problem.Fields["subtasks"])
+	must(t, err)
+	must(t, json.Unmarshal(data, &offenders))
+	codes := make(map[uuid.UUID]string)
+	for _, o := range offenders {
+		codes[o.ID] = o.Code
+	}
+	want := map[uuid.UUID]string{assigned.ID: "assignee_not_member", late.ID: "date_outside_project"}
+	if !maps.Equal(codes, want) {
+		t.Errorf("offenders: want %v, got %v", want, codes)
+	}
+	if task, err := s.tasks.Get(admin, fine.ID); err != nil || task.ProjectID != source.ID {
+		t.Errorf("subtask moved although its parent did not: %v", err)
+	}
+
+	for _, fix := range []*domain.Task{assigned, late} {
+		task, err := s.tasks.Get(admin, fix.ID)
+		must(t, err)
+		task.Assignee, task.DueDate = uuid.Nil, time.Time{}
+		must(t, s.tasks.Update(admin, task))
+	}
+
+	// The four tasks all enter the target's "To do" column.
+	board := &domain.Board{ProjectID: target.ID, Columns: []domain.BoardColumn{
+		{Name: "To do", States: []string{"todo"}, WIPLimit: 3, WIPPolicy: domain.WIPReject},
+	}}
+	must(t, s.boards.Update(admin, board))
+	if err := move(); !errors.As(err, &problem) || problem.Code != "wip_limit_exceeded" {
+		t.Fatalf("want wip_limit_exceeded, got %v", err)
+	}
+	board.Columns[0].WIPLimit = 4
+	must(t, s.boards.Update(admin, board))
+	must(t, move())
+	for _, id := range []uuid.UUID{parent.ID, assigned.ID, late.ID, fine.ID} {
+		task, err := s.tasks.Get(admin, id)
+		must(t, err)
+		if task.ProjectID != target.ID {
+			t.Errorf("task %s stayed in project %s", task.Title, task.ProjectID)
+		}
+	}
+}
+
+// TestConcurrentMoves checks that tasks moved at the same time cannot take
+// a board column past a WIP limit that rejects more.
+func TestConcurrentMoves(t *testing.T) {
+	s := newServices(t)
+	admin, alice := s.addOrganization(t, "alice@example.com", "secret123")
+	project := &domain.Entity{ID: uuid.New(), Title: "Apollo", ManagerID: alice.ID}
+	must(t, s.projects.Create(admin, project))
+	must(t, s.boards.Update(admin, &domain.Board{ProjectID: project.ID, Columns: []domain.BoardColumn{
+		{Name: "To do", States: []string{"todo"}},
+		{Name: "Doing", States: []string{"in_progress"}, WIPLimit: 2, WIPPolicy: domain.WIPReject},
+	}}))
+
+	tasks := make([]uuid.UUID, 20)
+	for i := range tasks {
+		task := &domain.Task{ID: uuid.New(), Title: "Task", ProjectID: project.ID}
+		must(t, s.tasks.Create(admin, task))
+		tasks[i] = task.ID
+	}
+
+	// Half of the tasks go through a transition, the others across the board.
+	errs := make([]error, len(tasks))
+	var wg sync.WaitGroup
+	for i, id := range tasks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if i%2 == 0 {
+				_, errs[i] = s.tasks.Transition(admin, id, "in_progress")
+			} else {
+				_, errs[i] = s.tasks.Move(admin, id, "Doing", uuid.Nil, uuid.Nil)
+			}
+		}()
+	}
+	wg.Wait()
+
+	moved := 0
+	for _, err := range errs {
+		var problem *domain.Error
+		switch {
+		case err == nil:
+			moved++
+		case !errors.As(err, &problem) || problem.Code != "wip_limit_exceeded":
+			t.Errorf("want wip_limit_exceeded, got %v", err)
+		}
+	}
+	view, err := s.boards.Get(admin, project.ID)
+	must(t, err)
+	if doing := view.Columns[1].Count; moved != 2 || doing != 2 {
+		t.Errorf("want 2 tasks moved into a column limited to 2, got %d moved and %d there", moved, doing)
+	}
+}
+
+// TestCreateByProjectRole checks that only the manager, owners and
+// contributors of a project can create tasks in it.
+func TestCreateByProjectRole(t *testing.T) {
+	s := newServices(t)
+	admin, _ := s.addOrganization(t, "alice@example.com", "secret123")
+	manager := s.addUser(t, admin, "carol@example.com", domain.RoleManager)
+	project := &domain.Entity{ID: uuid.New(), Title: "Apollo", ManagerID: manager.ID}
+	must(t, s.projects.Create(admin, project))
+
+	join := func(email string, userRole domain.Role, projectRole domain.ProjectRole) *domain.User {
+		t.Helper()
+		user := s.addUser(t, admin, email, userRole)
+		if projectRole != "" {
+			must(t, s.members.Add(admin, &domain.Member{ProjectID: project.ID, UserID: user.ID,
+				Role: projectRole, JoinedAt: time.Now().UTC()}))
+		}
+		return user
+	}
+	create := func(user *domain.User) error {
+		t.Helper()
+		return s.tasks.Create(as(admin, user), &domain.Task{ID: uuid.New(), Title: "Task", ProjectID: project.ID})
+	}
+
+	for _, user := range []*domain.User{
+		manager,
+		join("dave@example.com", domain.RoleManager, domain.ProjectOwner),
+		join("erin@example.com", domain.RoleMember, domain.ProjectContributor),
+	} {
+		if err := create(user); err != nil {
+			t.Errorf("%s: %v", user.Email, err)
+		}
+	}
+	for _, user := range []*domain.User{
+		join("frank@example.com", domain.RoleManager, ""),
+		join("grace@example.com", domain.RoleMember, ""),
+		join("heidi@example.com", domain.RoleMember, domain.ProjectObserver),
+		join("ivan@example.com", domain.RoleViewer, domain.ProjectContributor),
+	} {
+		forbidden(t, user.Email, "forbidden", create(user))
+	}
+}
+
+// TestTransitions checks that tasks only move along their workflow, and
+// that completed_at follows them into and out of terminal states.
+func TestTransitions(t *testing.T) {
+	s := newServices(t)
+	admin, alice := s.addOrganization(t, "alice@example.com", "secret123")
+	project := &domain.Entity{ID: uuid.New(), Title: "Apollo", ManagerID: alice.ID}
+	must(t, s.projects.Create(admin, project))
+	task := &domain.Task{ID: uuid.New(), Title: "Launch", ProjectID: project.ID}
+	must(t, s.tasks.Create(admin, task))
+	if task.State != "todo" || !task.CompletedAt.IsZero() {
+		t.Fatalf("new task: want todo and not completed, got %s at %v", task.State, task.CompletedAt)
+	}
+
+	_, err := s.tasks.Transition(admin, task.ID, "done")
+	problem := expectProblem(t, "skip to done", "illegal_transition", err)
+	if got := fmt.Sprint(problem.Fields["allowed"]); got != "[in_progress]" {
+		t.Errorf("allowed: want [in_progress], got %s", got)
+	}
+	_, err = s.tasks.Transition(admin, task.ID, "shipped")
+	expectProblem(t, "enter an unknown state", "unknown_state", err)
+
+	for _, state := range []string{"in_progress", "review"} {
+		moved, err := s.tasks.Transition(admin, task.ID, state)
+		must(t, err)
+		if moved.State != state || !moved.CompletedAt.IsZero() {
+			t.Fatalf("%s: got %s, completed at %v", state, moved.State, moved.CompletedAt)
+		}
+	}
+	before := time.Now().UTC()
+	done, err := s.tasks.Transition(admin, task.ID, "done")
+	must(t, err)
+	if done.CompletedAt.Before(before) || done.CompletedAt.After(time.Now().UTC()) {
+		t.Fatalf("done: completed_at %v is not the time of the transition", done.CompletedAt)
+	}
+	stored, err := s.tasks.Get(admin, task.ID)
+	must(t, err)
+	if !stored.CompletedAt.Equal(done.CompletedAt) {
+		t.Errorf("stored completed_at %v, want %v", stored.CompletedAt, done.CompletedAt)
+	}
+
+	reopened, err := s.tasks.Transition(admin, task.ID, "in_progress")
+	must(t, err)
+	if !reopened.CompletedAt.IsZero() {
+		t.Errorf("reopened task is still completed at %v", reopened.CompletedAt)
+	}
+}
@@ -0,0 +1,359 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/auth"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+// Every organization's data is kept apart by the repositories below, which
+// confine users, projects and tasks to the organization the caller acts in.
+// Services are built on them rather than on the repositories they are given,
+// so that no query can reach another organization. Records of other
+// organizations are reported as missing, so that their IDs cannot even be
+// probed. Project members and workflows are only reached through their
+// project, which is looked up here first.
+
+// organizationOf returns the organization the caller acts in.
+func organizationOf(ctx context.Context) (uuid.UUID, error) {
+	principal := auth.PrincipalFrom(ctx)
+	switch {
+	case principal == nil:
+		return uuid.Nil, domain.Unauthorized("missing_token", "authentication is required")
+	case principal.OrganizationID == uuid.Nil:
+		return uuid.Nil, domain.Forbidden("no_organization", "you do not belong to an organization, create one or ask to be added")
+	}
+	return principal.OrganizationID, nil
+}
+
+// inOrganization reports a record of another organization than the
+// caller's as missing.
+func inOrganization(ctx context.Context, organizationID uuid.UUID) error {
+	current, err := organizationOf(ctx)
+	if err != nil {
+		return err
+	}
+	if organizationID != current {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+type tenantProjects struct {
+	projects repository.ProjectRepository
+}
+
+var _ repository.ProjectRepository = tenantProjects{}
+
+func (r tenantProjects) GetAll(ctx context.Context, query repository.ProjectQuery) (*repository.Page[domain.Entity], error) {
+	organizationID, err := organizationOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query.OrganizationID = organizationID
+	return r.projects.GetAll(ctx, query)
+}
+
+func (r tenantProjects) Get(ctx context.Context, id uuid.UUID) (*domain.Entity, error) {
+	project, err := r.projects.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := inOrganization(ctx, project.OrganizationID); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+func (r tenantProjects) Create(ctx context.Context, project *domain.Entity) error {
+	organizationID, err := organizationOf(ctx)
+	if err != nil {
+		return err
+	}
+	project.OrganizationID = organizationID
+	return r.projects.Create(ctx, project)
+}
+
+func (r tenantProjects) Update(ctx context.Context, project *domain.Entity) error {
+	current, err := r.Get(ctx, project.ID)
+	if err != nil {
+		return err
+	}
+	project.OrganizationID = current.OrganizationID
+	return r.projects.Update(ctx, project)
+}
+
+func (r tenantProjects) Delete(ctx context.Context, id uuid.UUID) error {
+	if _, err := r.Get(ctx, id); err != nil {
+		return err
+	}
+	return r.projects.Delete(ctx, id)
+}
+
+func (r tenantProjects) DeleteWithTasks(ctx context.Context, id uuid.UUID) error {
+	if _, err := r.Get(ctx, id); err != nil {
+		return err
+	}
+	return r.projects.DeleteWithTasks(ctx, id)
+}
+
+type tenantTasks struct {
+	tasks repository.TaskRepository
+}
+
+var _ repository.TaskRepository = tenantTasks{}
+
+func (r tenantTasks) GetAll(ctx context.Context, query repository.TaskQuery) (*repository.Page[domain.Task], error) {
+	organizationID, err := organizationOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query.OrganizationID = organizationID
+	return r.tasks.GetAll(ctx, query)
+}
+
+func (r tenantTasks) Get(ctx context.Context, id uuid.UUID) (*domain.Task, error) {
+	task, err := r.tasks.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := inOrganization(ctx, task.OrganizationID); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+func (r tenantTasks) Count(ctx context.Context, query repository.TaskQuery) (int, error) {
+	organizationID, err := organizationOf(ctx)
+	if err != nil {
+		return 0, err
+	}
+	query.OrganizationID = organizationID
+	return r.tasks.Count(ctx, query)
+}
+
+func (r tenantTasks) Subtasks(ctx context.Context, id uuid.UUID) ([]domain.Task, error) {
+	if _, err := r.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	return r.tasks.Subtasks(ctx, id)
+}
+
+func (r tenantTasks) Create(ctx context.Context, task *domain.Task) error {
+	organizationID, err := organizationOf(ctx)
+	if err != nil {
+		return err
+	}
+	task.OrganizationID = organizationID
+	return r.tasks.Create(ctx, task)
+}
+
+func (r tenantTasks) CreateWithin(ctx context.Context, task *domain.Task, limits []repository.WIPLimit) error {
+	organizationID, err := organizationOf(ctx)
+	if err != nil {
+		return err
+	}
+	task.OrganizationID = organizationID
+	return r.tasks.CreateWithin(ctx, task, limits)
+}
+
+func (r tenantTasks) Update(ctx context.Context, task *domain.Task) error {
+	current, err := r.Get(ctx, task.ID)
+	if err != nil {
+		return err
+	}
+	task.OrganizationID = current.OrganizationID
+	return r.tasks.Update(ctx, task)
+}
+
+func (r tenantTasks) Move(ctx context.Context, task *domain.Task, ranks map[uuid.UUID]string,
+	limits []repository.WIPLimit) error {
+	current, err := r.Get(ctx, task.ID)
+	if err != nil {
+		return err
+	}
+	for id := range ranks {
+		if _, err := r.Get(ctx, id); err != nil {
+			return err
+		}
+	}
+	task.OrganizationID = current.OrganizationID
+	return r.tasks.Move(ctx, task, ranks, limits)
+}
+
+func (r tenantTasks) Delete(ctx context.Context, id uuid.UUID) error {
+	if _, err := r.Get(ctx, id); err != nil {
+		return err
+	}
+	return r.tasks.Delete(ctx, id)
+}
+
+// tenantUsers presents the members of the caller's organization as users,
+// whose Role is their role in it. Users are created into the organization,
+// and saving a user saves its role there.
+type tenantUsers struct {
+	users         repository.UserRepository
+	organizations repository.OrganizationRepository
+}
+
+var _ repository.UserRepository = tenantUsers{}
+
+func (r tenantUsers) GetAll(ctx context.Context, query repository.UserQuery) (*repository.Page[domain.User], error) {
+	organizationID, err := organizationOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query.OrganizationID = organizationID
+	return r.users.GetAll(ctx, query)
+}
+
+func (r tenantUsers) Get(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	user, err := r.users.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return r.withRole(ctx, user)
+}
+
+func (r tenantUsers) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	user, err := r.users.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	return r.withRole(ctx, user)
+}
+
+func (r tenantUsers) GetByHandle(ctx context.Context, handle string) (*domain.User, error) {
+	user, err := r.users.GetByHandle(ctx, handle)
+	if err != nil {
+		return nil, err
+	}
+	return r.withRole(ctx, user)
+}
+
+// withRole fills in the user's role in the caller's organization, reporting
+// users outside of it as missing.
+func (r tenantUsers) withRole(ctx context.Context, user *domain.User) (*domain.User, error) {
+	organizationID, err := organizationOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+	member, err := r.organizations.GetMember(ctx, organizationID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	user.Role = member.Role
+	return user, nil
+}
+
+func (r tenantUsers) Create(ctx context.Context, user *domain.User) error {
+	return r.CreateWithMembership(ctx, user, &domain.OrganizationMember{
+		UserID:   user.ID,
+		Role:     user.Role,
+		JoinedAt: time.Now().UTC(),
+	})
+}
+
+func (r tenantUsers) CreateWithMembership(ctx context.Context, user *domain.User, member *domain.OrganizationMember) error {
+	organizationID, err := organizationOf(ctx)
+	if err != nil {
+		return err
+	}
+	member.OrganizationID = organizationID
+	return r.users.CreateWithMembership(ctx, user, member)
+}
+
+func (r tenantUsers) Update(ctx context.Context, user *domain.User) error {
+	current, err := r.Get(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if err := r.users.Update(ctx, user); err != nil {
+		return err
+	}
+	if user.Role == current.Role {
+		return nil
+	}
+	return r.organizations.UpdateMember(ctx, &domain.OrganizationMember{
+		OrganizationID: auth.PrincipalFrom(ctx).OrganizationID,
+		UserID:         user.ID,
+		Role:           user.Role,
+	})
+}
+
+func (r tenantUsers) Delete(ctx context.Context, id uuid.UUID) error {
+	if _, err := r.Get(ctx, id); err != nil {
+		return err
+	}
+	return r.users.Delete(ctx, id)
+}
+
+func (r tenantUsers) DeleteReassigning(ctx context.Context, id, assignee uuid.UUID) error {
+	if _, err := r.Get(ctx, id); err != nil {
+		return err
+	}
+	if assignee != uuid.Nil {
+		if _, err := r.Get(ctx, assignee); err != nil {
+			return errors.Join(repository.ErrReferenced, err)
+		}
+	}
+	return r.users.DeleteReassigning(ctx, id, assignee)
+}
+
+// tenantNotifications confines notifications to those about the tasks of
+// the caller's organization. tasks is not scoped, so that reminders can be
+// sent outside of any request.
+type tenantNotifications struct {
+	notifications repository.NotificationRepository
+	tasks         repository.TaskRepository
+}
+
+var _ repository.NotificationRepository = tenantNotifications{}
+
+func (r tenantNotifications) List(ctx context.Context, _, userID uuid.UUID,
+	unread bool) ([]domain.Notification, error) {
+	organizationID, err := organizationOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.notifications.List(ctx, organizationID, userID, unread)
+}
+
+func (r tenantNotifications) Get(ctx context.Context, id uuid.UUID) (*domain.Notification, error) {
+	notification, err := r.notifications.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	task, err := r.tasks.Get(ctx, notification.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := inOrganization(ctx, task.OrganizationID); err != nil {
+		return nil, err
+	}
+	return notification, nil
+}
+
+func (r tenantNotifications) Create(ctx context.Context, notification *domain.Notification) error {
+	return r.notifications.Create(ctx, notification)
+}
+
+func (r tenantNotifications) MarkRead(ctx context.Context, id uuid.UUID, at time.Time) error {
+	if _, err := r.Get(ctx, id); err != nil {
+		return err
+	}
+	return r.notifications.MarkRead(ctx, id, at)
+}
+
+func (r tenantNotifications) MarkAllRead(ctx context.Context, _, userID uuid.UUID, at time.Time) error {
+	organizationID, err := organizationOf(ctx)
+	if err != nil {
+		return err
+	}
+	return r.notifications.MarkAllRead(ctx, organizationID, userID, at)
+}
@@ -0,0 +1,150 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/auth"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+	"github.com/yelnar0112/project-management/internal/service"
+)
+
+// TestTenantIsolation checks that the admin of one organization can neither
+// see nor change anything of another.
+func TestTenantIsolation(t *testing.T) {
+	s := newServices(t)
+	mine, me := s.addOrganization(t, "alice@example.com", "secret123")
+	theirs, _ := s.addOrganization(t, "bob@example.com", "secret123")
+
+	project := &domain.Entity{ID: uuid.New(), Title: "Apollo", ManagerID: me.ID}
+	must(t, s.projects.Create(mine, project))
+	task := &domain.Task{ID: uuid.New(), Title: "Launch", ProjectID: project.ID, Assignee: me.ID}
+	must(t, s.tasks.Create(mine, task))
+	comment := &domain.Comment{ID: uuid.New(), TaskID: task.ID, Body: "Ready"}
+	must(t, s.comments.Create(mine, comment))
+	content := "hello"
+	attachment := &domain.Attachment{ID: uuid.New(), TaskID: task.ID, Filename: "notes.txt",
+		ContentType: "text/plain", Size: int64(len(content))}
+	must(t, s.attachments.Create(mine, attachment, strings.NewReader(content)))
+	owner := attachment.Owner()
+
+	t.Run("Projects", func(t *testing.T) {
+		_, err := s.projects.Get(theirs, project.ID)
+		notFound(t, "get", err)
+		page, err := s.projects.GetAll(theirs, repository.ProjectQuery{})
+		must(t, err)
+		if len(page.Items) != 0 {
+			t.Errorf("list: want no projects, got %v", page.Items)
+		}
+		err = s.projects.Update(theirs, &domain.Entity{ID: project.ID, Title: "Hijacked", ManagerID: me.ID})
+		notFound(t, "update", err)
+		notFound(t, "delete", s.projects.Delete(theirs, project.ID, true))
+	})
+
+	t.Run("Tasks", func(t *testing.T) {
+		_, err := s.tasks.Get(theirs, task.ID)
+		notFound(t, "get", err)
+		page, err := s.tasks.GetAll(theirs, repository.TaskQuery{})
+		must(t, err)
+		if len(page.Items) != 0 {
+			t.Errorf("list: want no tasks, got %v", page.Items)
+		}
+		_, err = s.tasks.GetForProject(theirs, project.ID, repository.TaskQuery{})
+		notFound(t, "list in project", err)
+		err = s.tasks.Update(theirs, &domain.Task{ID: task.ID, Title: "Hijacked", ProjectID: project.ID})
+		notFound(t, "update", err)
+		notFound(t, "delete", s.tasks.Delete(theirs, task.ID, true))
+	})
+
+	t.Run("Users", func(t *testing.T) {
+		_, err := s.userService.Get(theirs, me.ID)
+		notFound(t, "get", err)
+		page, err := s.userService.GetAll(theirs, repository.UserQuery{})
+		must(t, err)
+		for _, user := range page.Items {
+			if user.ID == me.ID {
+				t.Errorf("list: got %s of another organization", user.Email)
+			}
+		}
+		err = s.userService.Update(theirs, &domain.User{ID: me.ID, FullName: "Hijacked", Email: me.Email}, "")
+		notFound(t, "update", err)
+		notFound(t, "delete", s.userService.Delete(theirs, me.ID, service.UserDeletion{Unassign: true}))
+	})
+
+	t.Run("Comments", func(t *testing.T) {
+		_, err := s.comments.Get(theirs, task.ID, comment.ID)
+		notFound(t, "get", err)
+		_, err = s.comments.List(theirs, task.ID)
+		notFound(t, "list", err)
+		_, err = s.comments.Update(theirs, task.ID, comment.ID, "Hijacked")
+		notFound(t, "update", err)
+		notFound(t, "delete", s.comments.Delete(theirs, task.ID, comment.ID))
+	})
+
+	t.Run("Attachments", func(t *testing.T) {
+		_, err := s.attachments.Get(theirs, owner, attachment.ID)
+		notFound(t, "get", err)
+		_, err = s.attachments.List(theirs, owner)
+		notFound(t, "list", err)
+		_, _, err = s.attachments.Open(theirs, owner, attachment.ID)
+		notFound(t, "open", err)
+		notFound(t, "delete", s.attachments.Delete(theirs, owner, attachment.ID))
+	})
+
+	// Nothing of the first organization was changed along the way.
+	got, err := s.projects.Get(mine, project.ID)
+	must(t, err)
+	if got.Title != project.Title {
+		t.Errorf("project title changed to %q", got.Title)
+	}
+	gotTask, err := s.tasks.Get(mine, task.ID)
+	must(t, err)
+	if gotTask.Title != task.Title {
+		t.Errorf("task title changed to %q", gotTask.Title)
+	}
+	user, err := s.userService.Get(mine, me.ID)
+	must(t, err)
+	if user.FullName != me.FullName {
+		t.Errorf("user name changed to %q", user.FullName)
+	}
+	gotComment, err := s.comments.Get(mine, task.ID, comment.ID)
+	must(t, err)
+	if gotComment.Body != comment.Body {
+		t.Errorf("comment changed to %q", gotComment.Body)
+	}
+	_, err = s.attachments.Get(mine, owner, attachment.ID)
+	must(t, err)
+}
+
+// TestOrganizationSwitch checks that a caller can only act in an
+// organization it belongs to, whichever X-Organization-ID it sends.
+func TestOrganizationSwitch(t *testing.T) {
+	s := newServices(t)
+	mine, _ := s.addOrganization(t, "alice@example.com", "secret123")
+	theirs, bob := s.addOrganization(t, "bob@example.com", "secret123")
+	organizationA := auth.PrincipalFrom(mine).OrganizationID
+	organizationB := auth.PrincipalFrom(theirs).OrganizationID
+
+	ctx := context.Background()
+	pair, err := s.auth.Login(ctx, bob.Email, "secret123")
+	must(t, err)
+
+	principal, err := s.auth.Authenticate(ctx, pair.AccessToken, uuid.Nil)
+	must(t, err)
+	if principal.OrganizationID != organizationB {
+		t.Errorf("default organization: want %s, got %s", organizationB, principal.OrganizationID)
+	}
+	principal, err = s.auth.Authenticate(ctx, pair.AccessToken, organizationB)
+	must(t, err)
+	if principal.OrganizationID != organizationB || principal.User.Role != domain.RoleAdmin {
+		t.Errorf("own organization: got %s as %s", principal.OrganizationID, principal.User.Role)
+	}
+
+	_, err = s.auth.Authenticate(ctx, pair.AccessToken, organizationA)
+	notFound(t, "switch to another organization", err)
+	_, err = s.auth.Authenticate(ctx, pair.AccessToken, uuid.New())
+	notFound(t, "switch to an unknown organization", err)
+}
@@ -1,55 +1,288 @@
 package service
 
 import (
-	"database/sql"
+	"context"
+	"errors"
+	"time"
 
 	"github.com/google/uuid"
-	"github.com/yelnar0112/project-management/internal/config"
+	"github.com/yelnar0112/project-management/internal/auth"
 	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
 )
 
-func GetAllUsers() ([]domain.User, error) {
-	rows, err := config.DB.Query("SELECT id, full_name, email, registration, role FROM users")
+// UserService manages the members of the caller's organization. Users are
+// shared between the organizations they belong to, so a user who is also in
+// another organization can only be edited by itself and cannot be deleted,
+// only removed from the organization.
+type UserService struct {
+	users         repository.UserRepository
+	accounts      repository.UserRepository
+	organizations repository.OrganizationRepository
+	tasks         repository.TaskRepository
+	sessions      repository.SessionRepository
+}
+
+func NewUserService(users repository.UserRepository, organizations repository.OrganizationRepository,
+	tasks repository.TaskRepository, sessions repository.SessionRepository) *UserService {
+	return &UserService{
+		users:         tenantUsers{users: users, organizations: organizations},
+		accounts:      users,
+		organizations: organizations,
+		tasks:         tenantTasks{tasks},
+		sessions:      sessions,
+	}
+}
+
+// UserDeletion says what happens to the open tasks of a deleted user: they
+// are either handed to ReassignTo or left unassigned.
+type UserDeletion struct {
+	ReassignTo uuid.UUID
+	Unassign   bool
+}
+
+func (s *UserService) GetAll(ctx context.Context, query repository.UserQuery) (*repository.Page[domain.User], error) {
+	users, err := s.users.GetAll(ctx, query)
+	return users, fromStore(err, "user", uuid.Nil)
+}
+
+// Create stores a new user in the caller's organization. A user created
+// without a password cannot log in until one is set, and one created
+// without a handle gets the default handle of its email when it is free.
+// Users who already have an account are added with
+// OrganizationService.AddMember instead.
+func (s *UserService) Create(ctx context.Context, user *domain.User, password string) error {
+	if err := auth.Authorize(ctx, auth.UsersCreate, auth.Target{UserID: user.ID}); err != nil {
+		return err
+	}
+	var err error
+	if user.Role, err = parseRole(user.Role); err != nil {
+		return err
+	}
+	if err := s.setHandle(ctx, user); err != nil {
+		return err
+	}
+	user.PasswordHash = ""
+	if password != "" {
+		if err := setPassword(user, password); err != nil {
+			return err
+		}
+	}
+	return s.userError(ctx, s.users.Create(ctx, user), user)
+}
+
+func (s *UserService) Get(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	user, err := s.users.Get(ctx, id)
+	return user, fromStore(err, "user", id)
+}
+
+// Update replaces the user's fields. The handle is kept unless a new one is
+// given, and the password too unless a new one is given, in which case
+// every session of the user is signed out. Users who may only update
+// themselves cannot change their role, and the role of the organization's
+// last admin cannot be changed.
+func (s *UserService) Update(ctx context.Context, user *domain.User, password string) error {
+	current, err := s.Get(ctx, user.ID)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if err := auth.Authorize(ctx, auth.UsersUpdate, auth.Target{UserID: user.ID}); err != nil {
+		return err
 	}
-	defer rows.Close()
 
-	var users []domain.User
-	for rows.Next() {
-		var user domain.User
-		if err := rows.Scan(&user.ID, &user.FullName, &user.Email, &user.Registration, &user.Role); err != nil {
-			return nil, err
+	if user.Role == "" {
+		user.Role = current.Role
+	} else if user.Role, err = parseRole(user.Role); err != nil {
+		return err
+	}
+	if user.Role != current.Role {
+		// Only a grant on every user covers the empty target.
+		if err := auth.Authorize(ctx, auth.UsersUpdate, auth.Target{}); err != nil {
+			return domain.Forbidden("role_change_forbidden", "you cannot change the role of this user").Wrap(err)
+		}
+		if current.Role == domain.RoleAdmin {
+			if err := checkLastAdmin(ctx, s.organizations, auth.PrincipalFrom(ctx).OrganizationID, user.ID); err != nil {
+				return err
+			}
+		}
+	}
+	if user.Handle == "" {
+		user.Handle = current.Handle
+	} else if err := user.NormalizeHandle(); err != nil {
+		return domain.Validation("invalid_handle", "%v", err)
+	}
+	if password != "" || user.FullName != current.FullName || user.Email != current.Email ||
+		user.Handle != current.Handle {
+		if err := s.checkSharedAccount(ctx, user.ID); err != nil {
+			return err
 		}
-		users = append(users, user)
 	}
 
-	return users, nil
+	user.PasswordHash = current.PasswordHash
+	if password != "" {
+		if err := setPassword(user, password); err != nil {
+			return err
+		}
+	}
+	if err := s.userError(ctx, s.users.Update(ctx, user), user); err != nil {
+		return err
+	}
+
+	if password != "" {
+		return s.sessions.RevokeUser(ctx, user.ID, time.Now().UTC())
+	}
+	return nil
 }
 
-func CreateUser(user *domain.User) error {
-	_, err := config.DB.Exec("INSERT INTO users (id, full_name, email, registration, role) VALUES ($1, $2, $3, $4, $5)",
-		user.ID, user.FullName, user.Email, user.Registration, user.Role)
-	return err
+// Bootstrap creates a user with the given credentials unless one with that
+// email exists, and makes it the admin of a new organization unless it
+// belongs to one, so that a fresh installation can be logged into.
+func (s *UserService) Bootstrap(ctx context.Context, email, password string) error {
+	admin, err := s.accounts.GetByEmail(ctx, email)
+	if errors.Is(err, repository.ErrNotFound) {
+		admin = &domain.User{
+			ID:           uuid.New(),
+			FullName:     "Administrator",
+			Email:        email,
+			Registration: time.Now().UTC(),
+		}
+		if err := s.setHandle(ctx, admin); err != nil {
+			return err
+		}
+		if err := setPassword(admin, password); err != nil {
+			return err
+		}
+		err = s.userError(ctx, s.accounts.Create(ctx, admin), admin)
+	}
+	if err != nil {
+		return err
+	}
+
+	organizations, err := s.organizations.ListForUser(ctx, admin.ID)
+	if err != nil || len(organizations) > 0 {
+		return err
+	}
+	now := time.Now().UTC()
+	organization := &domain.Organization{ID: uuid.New(), Name: "Default", CreatedAt: now}
+	return s.organizations.Create(ctx, organization, &domain.OrganizationMember{
+		OrganizationID: organization.ID,
+		UserID:         admin.ID,
+		Role:           domain.RoleAdmin,
+		JoinedAt:       now,
+	})
 }
 
-func GetUser(id uuid.UUID) (*domain.User, error) {
-	var user domain.User
-	err := config.DB.QueryRow("SELECT id, full_name, email, registration, role FROM users WHERE id = $1", id).
-		Scan(&user.ID, &user.FullName, &user.Email, &user.Registration, &user.Role)
-	if err == sql.ErrNoRows {
-		return nil, err
+// checkSharedAccount refuses changes to the account of another user who
+// also belongs to other organizations than the caller's, whose admins have
+// no say over them there.
+func (s *UserService) checkSharedAccount(ctx context.Context, userID uuid.UUID) error {
+	if auth.PrincipalFrom(ctx).User.ID == userID {
+		return nil
+	}
+	organizations, err := s.organizations.ListForUser(ctx, userID)
+	if err != nil {
+		return err
 	}
-	return &user, err
+	if len(organizations) > 1 {
+		return domain.Forbidden("user_in_other_organizations",
+			"user %s also belongs to other organizations, only they can change their account", userID)
+	}
+	return nil
 }
 
-func UpdateUser(user *domain.User) error {
-	_, err := config.DB.Exec("UPDATE users SET full_name = $1, email = $2, registration = $3, role = $4 WHERE id = $5",
-		user.FullName, user.Email, user.Registration, user.Role, user.ID)
-	return err
+func setPassword(user *domain.User, password string) error {
+	hash, err := auth.HashPassword(password)
+	if errors.Is(err, auth.ErrPasswordLength) {
+		return domain.Validation("invalid_password", "%v", err)
+	}
+	if err != nil {
+		return err
+	}
+	user.PasswordHash = hash
+	return nil
 }
 
-func DeleteUser(id uuid.UUID) error {
-	_, err := config.DB.Exec("DELETE FROM users WHERE id = $1", id)
+// setHandle checks the handle a new user picked. Users who pick none get
+// the default handle of their email, unless another user has it already.
+func (s *UserService) setHandle(ctx context.Context, user *domain.User) error {
+	if err := user.NormalizeHandle(); err != nil {
+		return domain.Validation("invalid_handle", "%v", err)
+	}
+	handle := domain.DefaultHandle(user.Email)
+	if user.Handle != "" || handle == "" {
+		return nil
+	}
+	_, err := s.accounts.GetByHandle(ctx, handle)
+	if errors.Is(err, repository.ErrNotFound) {
+		user.Handle = handle
+		return nil
+	}
 	return err
 }
+
+// userError reports the unique email and handle indexes as a taken email or
+// handle rather than a duplicate user.
+func (s *UserService) userError(ctx context.Context, err error, user *domain.User) error {
+	if !errors.Is(err, repository.ErrDuplicate) {
+		return fromStore(err, "user", user.ID)
+	}
+	if user.Handle != "" {
+		other, lookupErr := s.accounts.GetByHandle(ctx, user.Handle)
+		if lookupErr == nil && other.ID != user.ID {
+			return domain.Conflict("handle_taken", "handle %q is already in use", user.Handle).Wrap(err)
+		}
+	}
+	return domain.Conflict("email_taken", "email %q is already in use", user.Email).Wrap(err)
+}
+
+// Delete removes the user, dealing with its open tasks as deletion says. A
+// user with open tasks can only be deleted with one of the two policies.
+// Completed tasks keep no assignee and managed projects lose their manager.
+// Users who also belong to other organizations, and the organization's last
+// admin, cannot be deleted.
+func (s *UserService) Delete(ctx context.Context, id uuid.UUID, deletion UserDeletion) error {
+	user, err := s.users.Get(ctx, id)
+	if err != nil {
+		return fromStore(err, "user", id)
+	}
+	if err := auth.Authorize(ctx, auth.UsersDelete, auth.Target{UserID: id}); err != nil {
+		return err
+	}
+	organizations, err := s.organizations.ListForUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(organizations) > 1 {
+		return domain.Conflict("user_in_other_organizations",
+			"user %s also belongs to other organizations, remove them from this one instead", id).
+			With("organizations", len(organizations))
+	}
+	if user.Role == domain.RoleAdmin {
+		if err := checkLastAdmin(ctx, s.organizations, auth.PrincipalFrom(ctx).OrganizationID, id); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case deletion.ReassignTo != uuid.Nil && deletion.Unassign:
+		return domain.Validation("invalid_reassignment", "choose either reassignment or unassignment")
+	case deletion.ReassignTo == id:
+		return domain.Validation("invalid_reassignment", "cannot reassign tasks to the user being deleted")
+	case deletion.ReassignTo != uuid.Nil:
+		if err := checkExists(ctx, s.users.Get, deletion.ReassignTo, "invalid_reassignment", "user"); err != nil {
+			return err
+		}
+	case !deletion.Unassign:
+		open, err := s.tasks.Count(ctx, repository.TaskQuery{Assignee: id, Open: true})
+		if err != nil {
+			return err
+		}
+		if open > 0 {
+			return domain.Conflict("user_has_open_tasks",
+				"user still has open tasks, pass reassign_to or unassign=true").
+				With("dependents", map[string]int{"open_tasks": open})
+		}
+	}
+
+	return fromStore(s.users.DeleteReassigning(ctx, id, deletion.ReassignTo), "user", id)
+}
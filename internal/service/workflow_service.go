@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/auth"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+type WorkflowService struct {
+	workflows repository.WorkflowRepository
+	boards    repository.BoardRepository
+	tasks     repository.TaskRepository
+	projects  repository.ProjectRepository
+	members   repository.MemberRepository
+}
+
+func NewWorkflowService(workflows repository.WorkflowRepository, boards repository.BoardRepository,
+	tasks repository.TaskRepository, projects repository.ProjectRepository,
+	members repository.MemberRepository) *WorkflowService {
+	return &WorkflowService{
+		workflows: workflows,
+		boards:    boards,
+		tasks:     tenantTasks{tasks},
+		projects:  tenantProjects{projects},
+		members:   members,
+	}
+}
+
+// Get returns the workflow of an existing project.
+func (s *WorkflowService) Get(ctx context.Context, projectID uuid.UUID) (*domain.Workflow, error) {
+	if _, err := s.projects.Get(ctx, projectID); err != nil {
+		return nil, fromStore(err, "project", projectID)
+	}
+	return s.forProject(ctx, projectID)
+}
+
+// Update replaces the workflow of a project. States that tasks of the
+// project are in, or that a column of its board maps, cannot be removed,
+// and states that tasks are in cannot be made terminal or not, which would
+// leave their completed_at wrong: those tasks and columns have to be moved
+// off them first.
+func (s *WorkflowService) Update(ctx context.Context, workflow *domain.Workflow) error {
+	project, err := s.projects.Get(ctx, workflow.ProjectID)
+	if err != nil {
+		return fromStore(err, "project", workflow.ProjectID)
+	}
+	if err := authorizeProject(ctx, s.members, auth.WorkflowsUpdate, project, uuid.Nil); err != nil {
+		return err
+	}
+	if err := workflow.Validate(); err != nil {
+		return domain.Validation("invalid_workflow", "%v", err)
+	}
+	if err := s.checkChangedStates(ctx, workflow); err != nil {
+		return err
+	}
+	return s.workflows.Save(ctx, workflow)
+}
+
+// checkChangedStates reports the states that the new workflow drops while
+// tasks are still in them or a column of the saved board still maps them,
+// and those it makes terminal or not while tasks are still in them.
+func (s *WorkflowService) checkChangedStates(ctx context.Context, workflow *domain.Workflow) error {
+	current, err := s.forProject(ctx, workflow.ProjectID)
+	if err != nil {
+		return err
+	}
+	var removed, flipped []string
+	for _, state := range current.States {
+		switch {
+		case !workflow.HasState(state.Name):
+			removed = append(removed, state.Name)
+		case workflow.IsTerminal(state.Name) != state.Terminal:
+			flipped = append(flipped, state.Name)
+		}
+	}
+
+	used, err := s.countTasks(ctx, workflow.ProjectID, removed)
+	if err != nil {
+		return err
+	}
+	if len(used) > 0 {
+		return domain.Conflict("state_in_use", "workflow removes states that tasks are still in").
+			With("tasks_by_state", used)
+	}
+	if used, err = s.countTasks(ctx, workflow.ProjectID, flipped); err != nil {
+		return err
+	}
+	if len(used) > 0 {
+		return domain.Conflict("state_in_use", "workflow changes whether states that tasks are still in are terminal").
+			With("tasks_by_state", used)
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+
+	board, err := s.boards.Get(ctx, workflow.ProjectID)
+	if errors.Is(err, repository.ErrNotFound) {
+		// The default board follows the workflow.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	columns := map[string]string{}
+	for _, state := range removed {
+		if column := board.ColumnOf(state); column != nil {
+			columns[state] = column.Name
+		}
+	}
+	if len(columns) > 0 {
+		return domain.Conflict("state_in_use", "workflow removes states that board columns still map").
+			With("columns_by_state", columns)
+	}
+	return nil
+}
+
+// countTasks counts the tasks of a project in each of states, leaving out
+// the states no task is in.
+func (s *WorkflowService) countTasks(ctx context.Context, projectID uuid.UUID, states []string) (map[string]int, error) {
+	used := map[string]int{}
+	for _, state := range states {
+		count, err := s.tasks.Count(ctx, repository.TaskQuery{ProjectID: projectID, States: []string{state}})
+		if err != nil {
+			return nil, fromStore(err, "task", uuid.Nil)
+		}
+		if count > 0 {
+			used[state] = count
+		}
+	}
+	return used, nil
+}
+
+// forProject falls back to the default workflow for projects that have not
+// defined their own.
+func (s *WorkflowService) forProject(ctx context.Context, projectID uuid.UUID) (*domain.Workflow, error) {
+	workflow, err := s.workflows.Get(ctx, projectID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return domain.DefaultWorkflow(projectID), nil
+	}
+	return workflow, err
+}
@@ -0,0 +1,59 @@
+package service_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+)
+
+// TestWorkflowStatesInUse checks that a workflow cannot drop the states
+// tasks are in, nor make them terminal or not, which would leave the tasks'
+// completed_at wrong.
+func TestWorkflowStatesInUse(t *testing.T) {
+	s := newServices(t)
+	admin, alice := s.addOrganization(t, "alice@example.com", "secret123")
+	project := &domain.Entity{ID: uuid.New(), Title: "Apollo", ManagerID: alice.ID}
+	must(t, s.projects.Create(admin, project))
+	task := &domain.Task{ID: uuid.New(), Title: "Launch", ProjectID: project.ID}
+	must(t, s.tasks.Create(admin, task))
+
+	workflow := func(terminal ...string) *domain.Workflow {
+		w := domain.DefaultWorkflow(project.ID)
+		for i := range w.States {
+			w.States[i].Terminal = false
+			for _, name := range terminal {
+				if w.States[i].Name == name {
+					w.States[i].Terminal = true
+				}
+			}
+		}
+		return w
+	}
+	inUse := func(what, want string, err error) {
+		t.Helper()
+		problem := expectProblem(t, what, "state_in_use", err)
+		if got := fmt.Sprint(problem.Fields["tasks_by_state"]); got != want {
+			t.Errorf("%s: want %s tasks by state, got %s", what, want, got)
+		}
+	}
+
+	inUse("make the task's state terminal", "map[todo:1]", s.workflows.Update(admin, workflow("done", "todo")))
+	removed := workflow("done")
+	removed.States = removed.States[1:]
+	removed.InitialState = "in_progress"
+	removed.Transitions = removed.Transitions[2:]
+	inUse("remove the task's state", "map[todo:1]", s.workflows.Update(admin, removed))
+
+	// States no task is in can change freely.
+	must(t, s.workflows.Update(admin, workflow("done", "review")))
+	_, err := s.tasks.Transition(admin, task.ID, "in_progress")
+	must(t, err)
+	moved, err := s.tasks.Transition(admin, task.ID, "review")
+	must(t, err)
+	if moved.CompletedAt.IsZero() {
+		t.Fatal("entering a state made terminal should complete the task")
+	}
+	inUse("make the task's state not terminal", "map[review:1]", s.workflows.Update(admin, workflow("done")))
+}
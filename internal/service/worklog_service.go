@@ -0,0 +1,352 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/auth"
+	"github.com/yelnar0112/project-management/internal/domain"
+	"github.com/yelnar0112/project-management/internal/repository"
+)
+
+const (
+	// defaultTimesheetDays is the range of timesheets asked for without
+	// one, ending with the current day.
+	defaultTimesheetDays = 7
+	// maxTimesheetRange is the longest range a timesheet may cover.
+	maxTimesheetRange = 366 * 24 * time.Hour
+)
+
+// WorklogService tracks the time spent on tasks, logged by hand or with
+// timers, and sums it up in timesheets. Anyone who can read a task can read
+// its worklogs; logging time takes the worklogs:* permissions, scoped to
+// the user who logged it and the task's project.
+type WorklogService struct {
+	worklogs repository.WorklogRepository
+	tasks    repository.TaskRepository
+	projects repository.ProjectRepository
+	users    repository.UserRepository
+	members  repository.MemberRepository
+}
+
+func NewWorklogService(worklogs repository.WorklogRepository, tasks repository.TaskRepository,
+	projects repository.ProjectRepository, users repository.UserRepository,
+	organizations repository.OrganizationRepository, members repository.MemberRepository) *WorklogService {
+	return &WorklogService{
+		worklogs: worklogs,
+		tasks:    tenantTasks{tasks},
+		projects: tenantProjects{projects},
+		users:    tenantUsers{users: users, organizations: organizations},
+		members:  members,
+	}
+}
+
+// List returns the worklogs of a task, ordered by when they started.
+func (s *WorklogService) List(ctx context.Context, taskID uuid.UUID) ([]domain.Worklog, error) {
+	if _, err := s.tasks.Get(ctx, taskID); err != nil {
+		return nil, fromStore(err, "task", taskID)
+	}
+	return s.worklogs.List(ctx, repository.WorklogQuery{TaskID: taskID})
+}
+
+func (s *WorklogService) Get(ctx context.Context, taskID, id uuid.UUID) (*domain.Worklog, error) {
+	if _, err := s.tasks.Get(ctx, taskID); err != nil {
+		return nil, fromStore(err, "task", taskID)
+	}
+	return s.get(ctx, taskID, id)
+}
+
+// Create logs time the caller spent on a task, given by its start and end,
+// its start and duration, or its duration alone, taken to end now.
+func (s *WorklogService) Create(ctx context.Context, worklog *domain.Worklog) error {
+	task, err := s.tasks.Get(ctx, worklog.TaskID)
+	if err != nil {
+		return fromStore(err, "task", worklog.TaskID)
+	}
+	if err := s.authorize(ctx, auth.WorklogsCreate, task, uuid.Nil); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	if err := worklog.Normalize(now); err != nil {
+		return domain.Validation("invalid_worklog", "%v", err)
+	}
+
+	worklog.UserID = auth.PrincipalFrom(ctx).User.ID
+	worklog.CreatedAt = now
+	return fromStore(s.worklogs.Create(ctx, worklog, logWork), "worklog", worklog.ID)
+}
+
+// Update replaces the times, duration and note of a worklog, like Create
+// sets them. Running timers are stopped rather than edited.
+func (s *WorklogService) Update(ctx context.Context, worklog *domain.Worklog) error {
+	task, err := s.tasks.Get(ctx, worklog.TaskID)
+	if err != nil {
+		return fromStore(err, "task", worklog.TaskID)
+	}
+	current, err := s.get(ctx, task.ID, worklog.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.authorize(ctx, auth.WorklogsUpdate, task, current.UserID); err != nil {
+		return err
+	}
+	if current.Running() {
+		return domain.Conflict("timer_running", "worklog %s is a running timer, stop it first", current.ID)
+	}
+	if err := worklog.Normalize(time.Now().UTC()); err != nil {
+		return domain.Validation("invalid_worklog", "%v", err)
+	}
+
+	worklog.UserID = current.UserID
+	worklog.CreatedAt = current.CreatedAt
+	return fromStore(s.worklogs.Update(ctx, worklog, logWork), "worklog", worklog.ID)
+}
+
+// Delete removes a worklog. Deleting a running timer discards it.
+func (s *WorklogService) Delete(ctx context.Context, taskID, id uuid.UUID) error {
+	task, err := s.tasks.Get(ctx, taskID)
+	if err != nil {
+		return fromStore(err, "task", taskID)
+	}
+	worklog, err := s.get(ctx, taskID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.authorize(ctx, auth.WorklogsDelete, task, worklog.UserID); err != nil {
+		return err
+	}
+	return fromStore(s.worklogs.Delete(ctx, id, logWork), "worklog", id)
+}
+
+// Timer returns the timer the caller is running.
+func (s *WorklogService) Timer(ctx context.Context) (*domain.Worklog, error) {
+	principal := auth.PrincipalFrom(ctx)
+	if principal == nil {
+		return nil, domain.Unauthorized("missing_token", "authentication is required")
+	}
+	worklog, err := s.worklogs.Running(ctx, principal.User.ID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, domain.NotFound("timer_not_running", "you are not running a timer").Wrap(err)
+	}
+	return worklog, err
+}
+
+// StartTimer starts logging the caller's time on a task. Users run at most
+// one timer at a time.
+func (s *WorklogService) StartTimer(ctx context.Context, taskID uuid.UUID, note string) (*domain.Worklog, error) {
+	task, err := s.tasks.Get(ctx, taskID)
+	if err != nil {
+		return nil, fromStore(err, "task", taskID)
+	}
+	if err := s.authorize(ctx, auth.WorklogsCreate, task, uuid.Nil); err != nil {
+		return nil, err
+	}
+	note = strings.TrimSpace(note)
+	if utf8.RuneCountInString(note) > domain.MaxWorklogNoteLength {
+		return nil, domain.Validation("invalid_worklog", "note must be at most %d characters",
+			domain.MaxWorklogNoteLength)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	worklog := &domain.Worklog{
+		ID:        uuid.New(),
+		TaskID:    task.ID,
+		UserID:    auth.PrincipalFrom(ctx).User.ID,
+		StartedAt: now,
+		Note:      note,
+		CreatedAt: now,
+	}
+	err = s.worklogs.Create(ctx, worklog, logWork)
+	if errors.Is(err, repository.ErrDuplicate) {
+		running, runningErr := s.worklogs.Running(ctx, worklog.UserID)
+		if runningErr != nil {
+			return nil, runningErr
+		}
+		return nil, domain.Conflict("timer_running", "you are already running a timer on task %s, stop it first",
+			running.TaskID).With("worklog", running)
+	}
+	if err != nil {
+		return nil, fromStore(err, "worklog", worklog.ID)
+	}
+	return worklog, nil
+}
+
+// StopTimer stops the timer the caller is running on a task and logs the
+// time it ran.
+func (s *WorklogService) StopTimer(ctx context.Context, taskID uuid.UUID) (*domain.Worklog, error) {
+	task, err := s.tasks.Get(ctx, taskID)
+	if err != nil {
+		return nil, fromStore(err, "task", taskID)
+	}
+	worklog, err := s.Timer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if worklog.TaskID != task.ID {
+		return nil, domain.NotFound("timer_not_running", "you are not running a timer on task %s", task.ID).
+			With("worklog", worklog)
+	}
+	if err := s.authorize(ctx, auth.WorklogsUpdate, task, worklog.UserID); err != nil {
+		return nil, err
+	}
+
+	worklog.EndedAt = time.Now().UTC().Truncate(time.Second)
+	if !worklog.EndedAt.After(worklog.StartedAt) {
+		// Timers stopped within their first second log one.
+		worklog.EndedAt = worklog.StartedAt.Add(time.Second)
+	}
+	worklog.Seconds = int64(worklog.EndedAt.Sub(worklog.StartedAt) / time.Second)
+	if err := s.worklogs.Update(ctx, worklog, logWork); err != nil {
+		return nil, fromStore(err, "worklog", worklog.ID)
+	}
+	return worklog, nil
+}
+
+// ProjectTimesheet sums up the time logged on the tasks of a project from
+// from until to.
+func (s *WorklogService) ProjectTimesheet(ctx context.Context, projectID uuid.UUID, from, to time.Time) (*domain.Timesheet, error) {
+	if _, err := s.projects.Get(ctx, projectID); err != nil {
+		return nil, fromStore(err, "project", projectID)
+	}
+	return s.timesheet(ctx, repository.WorklogQuery{ProjectID: projectID}, from, to)
+}
+
+// UserTimesheet sums up the time a user logged from from until to, on the
+// tasks of the caller's organization.
+func (s *WorklogService) UserTimesheet(ctx context.Context, userID uuid.UUID, from, to time.Time) (*domain.Timesheet, error) {
+	if _, err := s.users.Get(ctx, userID); err != nil {
+		return nil, fromStore(err, "user", userID)
+	}
+	return s.timesheet(ctx, repository.WorklogQuery{UserID: userID}, from, to)
+}
+
+// timesheet sums up the finished worklogs matching query that started from
+// from until to. Without a range, it covers the last defaultTimesheetDays
+// days, today included.
+func (s *WorklogService) timesheet(ctx context.Context, query repository.WorklogQuery, from, to time.Time) (*domain.Timesheet, error) {
+	organizationID, err := organizationOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if to.IsZero() {
+		to = time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+	}
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -defaultTimesheetDays)
+	}
+	from, to = from.UTC(), to.UTC()
+	switch {
+	case !from.Before(to):
+		return nil, domain.Validation("invalid_range", "from must be before to")
+	case to.Sub(from) > maxTimesheetRange:
+		return nil, domain.Validation("invalid_range", "timesheets cover at most %d days", maxTimesheetRange/(24*time.Hour))
+	}
+
+	query.OrganizationID = organizationID
+	query.StartedAfter = from
+	query.StartedBefore = to
+	query.Finished = true
+	worklogs, err := s.worklogs.List(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	timesheet := &domain.Timesheet{
+		From:     from,
+		To:       to,
+		Users:    make(map[uuid.UUID]int64),
+		Projects: make(map[uuid.UUID]int64),
+		Tasks:    make(map[uuid.UUID]int64),
+		Rows:     []domain.TimesheetRow{},
+	}
+	projects := make(map[uuid.UUID]uuid.UUID)
+	rows := make(map[domain.TimesheetRow]int)
+	for _, worklog := range worklogs {
+		projectID, ok := projects[worklog.TaskID]
+		if !ok {
+			task, err := s.tasks.Get(ctx, worklog.TaskID)
+			if err != nil {
+				return nil, fromStore(err, "task", worklog.TaskID)
+			}
+			projectID = task.ProjectID
+			projects[worklog.TaskID] = projectID
+		}
+
+		timesheet.TotalSeconds += worklog.Seconds
+		timesheet.Users[worklog.UserID] += worklog.Seconds
+		timesheet.Projects[projectID] += worklog.Seconds
+		timesheet.Tasks[worklog.TaskID] += worklog.Seconds
+		key := domain.TimesheetRow{
+			Date:      worklog.StartedAt.UTC().Format(time.DateOnly),
+			UserID:    worklog.UserID,
+			ProjectID: projectID,
+			TaskID:    worklog.TaskID,
+		}
+		i, ok := rows[key]
+		if !ok {
+			i = len(timesheet.Rows)
+			rows[key] = i
+			timesheet.Rows = append(timesheet.Rows, key)
+		}
+		timesheet.Rows[i].Seconds += worklog.Seconds
+	}
+
+	sort.Slice(timesheet.Rows, func(i, j int) bool {
+		a, b := timesheet.Rows[i], timesheet.Rows[j]
+		switch {
+		case a.Date != b.Date:
+			return a.Date < b.Date
+		case a.UserID != b.UserID:
+			return a.UserID.String() < b.UserID.String()
+		}
+		return a.TaskID.String() < b.TaskID.String()
+	})
+	return timesheet, nil
+}
+
+// logWork takes seconds of work logged on a task off its remaining
+// estimate, which does not go below 0. Negative seconds, for work no longer
+// logged, give the time back to tasks that were estimated, up to their
+// original estimate. The worklog repository calls it in the transaction
+// that changes the work logged.
+func logWork(task *domain.Task, seconds int64) {
+	switch {
+	case seconds > 0:
+		task.RemainingEstimate = max(task.RemainingEstimate-seconds, 0)
+	case seconds < 0 && task.OriginalEstimate > 0:
+		task.RemainingEstimate = min(task.RemainingEstimate-seconds, task.OriginalEstimate)
+	}
+}
+
+// get returns a worklog of the task, reporting worklogs of other tasks as
+// not found.
+func (s *WorklogService) get(ctx context.Context, taskID, id uuid.UUID) (*domain.Worklog, error) {
+	worklog, err := s.worklogs.Get(ctx, id)
+	if err == nil && worklog.TaskID != taskID {
+		err = repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, fromStore(err, "worklog", id)
+	}
+	return worklog, nil
+}
+
+// authorize checks that the caller may take permission on a worklog by user
+// on the task.
+func (s *WorklogService) authorize(ctx context.Context, permission auth.Permission, task *domain.Task, user uuid.UUID) error {
+	project, err := s.projects.Get(ctx, task.ProjectID)
+	if err != nil {
+		return fromStore(err, "project", task.ProjectID)
+	}
+	target, err := projectTarget(ctx, s.members, project)
+	if err != nil {
+		return err
+	}
+	target.UserID = user
+	return auth.Authorize(ctx, permission, target)
+}
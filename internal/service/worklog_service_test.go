@@ -0,0 +1,198 @@
+package service_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yelnar0112/project-management/internal/domain"
+)
+
+// TestLoggedWork checks that logging, editing and deleting work moves the
+// remaining estimate of the task, which stays between 0 and the original
+// estimate.
+func TestLoggedWork(t *testing.T) {
+	s := newServices(t)
+	admin, alice := s.addOrganization(t, "alice@example.com", "secret123")
+	project := &domain.Entity{ID: uuid.New(), Title: "Apollo", ManagerID: alice.ID}
+	must(t, s.projects.Create(admin, project))
+	estimated := &domain.Task{ID: uuid.New(), Title: "Launch", ProjectID: project.ID, OriginalEstimate: 3600}
+	unestimated := &domain.Task{ID: uuid.New(), Title: "Land", ProjectID: project.ID}
+	for _, task := range []*domain.Task{estimated, unestimated} {
+		must(t, s.tasks.Create(admin, task))
+	}
+	remaining := func(step string, task *domain.Task, want int64) {
+		t.Helper()
+		got, err := s.tasks.Get(admin, task.ID)
+		must(t, err)
+		if got.RemainingEstimate != want {
+			t.Fatalf("%s: remaining estimate is %d, want %d", step, got.RemainingEstimate, want)
+		}
+	}
+	log := func(task *domain.Task, seconds int64) *domain.Worklog {
+		t.Helper()
+		worklog := &domain.Worklog{ID: uuid.New(), TaskID: task.ID, Seconds: seconds}
+		must(t, s.worklogs.Create(admin, worklog))
+		return worklog
+	}
+	remaining("create the task", estimated, 3600)
+
+	logged := log(estimated, 1800)
+	remaining("log", estimated, 1800)
+	logged.StartedAt, logged.EndedAt, logged.Seconds = time.Time{}, time.Time{}, 2400
+	must(t, s.worklogs.Update(admin, logged))
+	remaining("edit", estimated, 1200)
+	overrun := log(estimated, 5000)
+	remaining("overrun", estimated, 0)
+	must(t, s.worklogs.Delete(admin, estimated.ID, logged.ID))
+	remaining("delete", estimated, 2400)
+	must(t, s.worklogs.Delete(admin, estimated.ID, overrun.ID))
+	remaining("delete the overrun", estimated, 3600)
+
+	// Tasks without an estimate are not given one.
+	unlogged := log(unestimated, 600)
+	remaining("log without an estimate", unestimated, 0)
+	must(t, s.worklogs.Delete(admin, unestimated.ID, unlogged.ID))
+	remaining("delete without an estimate", unestimated, 0)
+}
+
+// TestTimers checks that users run one timer at a time, which logs the time
+// it ran when stopped.
+func TestTimers(t *testing.T) {
+	s := newServices(t)
+	admin, alice := s.addOrganization(t, "alice@example.com", "secret123")
+	project := &domain.Entity{ID: uuid.New(), Title: "Apollo", ManagerID: alice.ID}
+	must(t, s.projects.Create(admin, project))
+	task := &domain.Task{ID: uuid.New(), Title: "Launch", ProjectID: project.ID, OriginalEstimate: 3600}
+	other := &domain.Task{ID: uuid.New(), Title: "Land", ProjectID: project.ID}
+	for _, task := range []*domain.Task{task, other} {
+		must(t, s.tasks.Create(admin, task))
+	}
+
+	_, err := s.worklogs.Timer(admin)
+	notFound(t, "timer before starting one", err)
+	_, err = s.worklogs.StopTimer(admin, task.ID)
+	notFound(t, "stop a timer before starting one", err)
+
+	timer, err := s.worklogs.StartTimer(admin, task.ID, " countdown ")
+	must(t, err)
+	if !timer.Running() || timer.Note != "countdown" || timer.UserID != alice.ID {
+		t.Fatalf("started timer is %+v", timer)
+	}
+	running, err := s.worklogs.Timer(admin)
+	must(t, err)
+	if running.ID != timer.ID {
+		t.Fatalf("running timer is %+v, want %+v", running, timer)
+	}
+	_, err = s.worklogs.StartTimer(admin, other.ID, "")
+	expectProblem(t, "start a second timer", "timer_running", err)
+	err = s.worklogs.Update(admin, &domain.Worklog{ID: timer.ID, TaskID: task.ID, Seconds: 60})
+	expectProblem(t, "edit a running timer", "timer_running", err)
+	_, err = s.worklogs.StopTimer(admin, other.ID)
+	notFound(t, "stop the timer on another task", err)
+	got, err := s.tasks.Get(admin, task.ID)
+	must(t, err)
+	if got.RemainingEstimate != 3600 {
+		t.Fatalf("a running timer took %d seconds off the estimate", 3600-got.RemainingEstimate)
+	}
+
+	stopped, err := s.worklogs.StopTimer(admin, task.ID)
+	must(t, err)
+	if stopped.Running() || stopped.Seconds < 1 ||
+		stopped.Seconds != int64(stopped.EndedAt.Sub(stopped.StartedAt)/time.Second) {
+		t.Fatalf("stopped timer is %+v", stopped)
+	}
+	got, err = s.tasks.Get(admin, task.ID)
+	must(t, err)
+	if got.RemainingEstimate != 3600-stopped.Seconds {
+		t.Fatalf("remaining estimate after stopping the timer is %d, want %d", got.RemainingEstimate,
+			3600-stopped.Seconds)
+	}
+	_, err = s.worklogs.Timer(admin)
+	notFound(t, "timer after stopping it", err)
+	_, err = s.worklogs.StartTimer(admin, other.ID, "")
+	must(t, err)
+}
+
+// TestTimesheets checks that timesheets total the finished work started in
+// their range by user, project, task and day.
+func TestTimesheets(t *testing.T) {
+	s := newServices(t)
+	admin, alice := s.addOrganization(t, "alice@example.com", "secret123")
+	carol := s.addUser(t, admin, "carol@example.com", domain.RoleMember)
+	apollo := &domain.Entity{ID: uuid.New(), Title: "Apollo", ManagerID: alice.ID}
+	gemini := &domain.Entity{ID: uuid.New(), Title: "Gemini", ManagerID: alice.ID}
+	for _, project := range []*domain.Entity{apollo, gemini} {
+		must(t, s.projects.Create(admin, project))
+	}
+	must(t, s.members.Add(admin, &domain.Member{ProjectID: apollo.ID, UserID: carol.ID,
+		Role: domain.ProjectContributor, JoinedAt: time.Now().UTC()}))
+	launch := &domain.Task{ID: uuid.New(), Title: "Launch", ProjectID: apollo.ID}
+	land := &domain.Task{ID: uuid.New(), Title: "Land", ProjectID: apollo.ID}
+	orbit := &domain.Task{ID: uuid.New(), Title: "Orbit", ProjectID: gemini.ID}
+	for _, task := range []*domain.Task{launch, land, orbit} {
+		must(t, s.tasks.Create(admin, task))
+	}
+
+	day := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -2)
+	asCarol := as(admin, carol)
+	for _, worklog := range []struct {
+		task    *domain.Task
+		as      *domain.User
+		started time.Duration
+		seconds int64
+	}{
+		{launch, alice, 9 * time.Hour, 3600},
+		{launch, alice, 14 * time.Hour, 600},
+		{land, carol, 10 * time.Hour, 1800},
+		{launch, alice, 33 * time.Hour, 1200},
+		{orbit, alice, 34 * time.Hour, 900},
+		// Outside of the range asked for.
+		{launch, alice, -time.Hour, 300},
+	} {
+		ctx := admin
+		if worklog.as == carol {
+			ctx = asCarol
+		}
+		must(t, s.worklogs.Create(ctx, &domain.Worklog{ID: uuid.New(), TaskID: worklog.task.ID,
+			StartedAt: day.Add(worklog.started), Seconds: worklog.seconds}))
+	}
+	// Running timers are left out.
+	_, err := s.worklogs.StartTimer(asCarol, launch.ID, "")
+	must(t, err)
+
+	from, to := day, day.AddDate(0, 0, 2)
+	project, err := s.worklogs.ProjectTimesheet(admin, apollo.ID, from, to)
+	must(t, err)
+	date := func(days int) string { return day.AddDate(0, 0, days).Format(time.DateOnly) }
+	rows := []domain.TimesheetRow{
+		{Date: date(0), UserID: alice.ID, ProjectID: apollo.ID, TaskID: launch.ID, Seconds: 4200},
+		{Date: date(0), UserID: carol.ID, ProjectID: apollo.ID, TaskID: land.ID, Seconds: 1800},
+		{Date: date(1), UserID: alice.ID, ProjectID: apollo.ID, TaskID: launch.ID, Seconds: 1200},
+	}
+	if alice.ID.String() > carol.ID.String() {
+		rows[0], rows[1] = rows[1], rows[0]
+	}
+	switch {
+	case project.TotalSeconds != 7200:
+		t.Errorf("project total is %d, want 7200", project.TotalSeconds)
+	case project.Users[alice.ID] != 5400 || project.Users[carol.ID] != 1800:
+		t.Errorf("project totals by user are %v", project.Users)
+	case project.Tasks[launch.ID] != 5400 || project.Tasks[land.ID] != 1800 || len(project.Tasks) != 2:
+		t.Errorf("project totals by task are %v", project.Tasks)
+	case fmt.Sprint(project.Rows) != fmt.Sprint(rows):
+		t.Errorf("project rows are %+v, want %+v", project.Rows, rows)
+	}
+
+	user, err := s.worklogs.UserTimesheet(admin, alice.ID, from, to)
+	must(t, err)
+	if user.TotalSeconds != 6300 || user.Projects[apollo.ID] != 5400 || user.Projects[gemini.ID] != 900 {
+		t.Errorf("user timesheet totals %d seconds, by project %v", user.TotalSeconds, user.Projects)
+	}
+
+	_, err = s.worklogs.ProjectTimesheet(admin, apollo.ID, to, from)
+	expectProblem(t, "timesheet ending before it starts", "invalid_range", err)
+	_, err = s.worklogs.UserTimesheet(admin, alice.ID, from, from.AddDate(2, 0, 0))
+	expectProblem(t, "timesheet over two years", "invalid_range", err)
+}
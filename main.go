@@ -3,13 +3,17 @@ package main
 import (
 	_ "github.com/yelnar0112/project-management/docs"
 
+	"context"
 	"log"
+	"os"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files" // swagger embed files
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"github.com/yelnar0112/project-management/internal/auth"
 	"github.com/yelnar0112/project-management/internal/config"
 	"github.com/yelnar0112/project-management/internal/handler"
+	"github.com/yelnar0112/project-management/internal/service"
 )
 
 // @title Project Management API
@@ -19,40 +23,209 @@ import (
 
 // @host localhost:8080
 // @BasePath /
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Access token from /auth/login or API token from /me/tokens, sent as "Bearer <token>". Requests act in the organization named by the X-Organization-ID header, or else in the first one the caller joined.
 func main() {
-	config.LoadConfig()
+	cfg := config.LoadConfig()
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		db := config.ConnectDB(cfg)
+		defer db.Close()
+		migrateCommand(db, cfg.DBDriver, os.Args[2:])
+		return
+	}
 
-	config.ConnectDB()
+	repos, closeStorage := openStorage(cfg)
+	defer closeStorage()
+	blobs := openBlobs(cfg)
+
+	workflowService := service.NewWorkflowService(repos.workflows, repos.boards, repos.tasks, repos.projects,
+		repos.members)
+	attachmentService := service.NewAttachmentService(repos.attachments, blobs, repos.tasks, repos.projects,
+		repos.members, service.AttachmentLimits{MaxSize: cfg.AttachmentMaxSize, Types: cfg.AttachmentTypes})
+	dependencyService := service.NewDependencyService(repos.dependencies, repos.tasks, repos.projects, repos.members,
+		workflowService)
+	labelService := service.NewLabelService(repos.labels, repos.projects, repos.members)
+	sprintService := service.NewSprintService(repos.sprints, repos.tasks, repos.projects, repos.members)
+	milestoneService := service.NewMilestoneService(repos.milestones, repos.tasks, repos.projects, repos.members)
+	boardService := service.NewBoardService(repos.boards, repos.tasks, repos.projects, repos.members, workflowService)
+	notificationService := service.NewNotificationService(repos.notifications, repos.tasks, repos.projects,
+		cfg.ReminderLead)
+	userService := service.NewUserService(repos.users, repos.organizations, repos.tasks, repos.sessions)
+	authService := service.NewAuthService(repos.users, repos.sessions, repos.apiTokens, repos.organizations,
+		auth.NewTokens(cfg.JWTSecret, cfg.AccessTokenTTL), cfg.RefreshTokenTTL)
+
+	if cfg.AdminEmail != "" {
+		if err := userService.Bootstrap(context.Background(), cfg.AdminEmail, cfg.AdminPassword); err != nil {
+			log.Fatalf("could not create the admin user: %v", err)
+		}
+	}
+
+	go notificationService.RunReminders(context.Background(), cfg.ReminderInterval)
+
+	authHandler := handler.NewAuthHandler(authService)
+	apiTokenHandler := handler.NewAPITokenHandler(service.NewAPITokenService(repos.apiTokens))
+	organizationHandler := handler.NewOrganizationHandler(
+		service.NewOrganizationService(repos.organizations, repos.users, repos.projects, repos.tasks))
+	userHandler := handler.NewUserHandler(userService)
+	taskHandler := handler.NewTaskHandler(service.NewTaskService(repos.tasks, repos.projects, repos.users,
+		repos.organizations, repos.members, workflowService, attachmentService, dependencyService, labelService,
+		sprintService, milestoneService, boardService))
+	projectHandler := handler.NewProjectHandler(service.NewProjectService(repos.projects, repos.users,
+		repos.organizations, repos.tasks, repos.members, attachmentService))
+	memberHandler := handler.NewMemberHandler(service.NewMemberService(repos.members, repos.projects, repos.users,
+		repos.organizations, repos.tasks))
+	workflowHandler := handler.NewWorkflowHandler(workflowService)
+	boardHandler := handler.NewBoardHandler(boardService)
+	commentHandler := handler.NewCommentHandler(service.NewCommentService(repos.comments, repos.tasks, repos.projects,
+		repos.users, repos.organizations, repos.members))
+	attachmentHandler := handler.NewAttachmentHandler(attachmentService)
+	dependencyHandler := handler.NewDependencyHandler(dependencyService)
+	labelHandler := handler.NewLabelHandler(labelService)
+	sprintHandler := handler.NewSprintHandler(sprintService)
+	milestoneHandler := handler.NewMilestoneHandler(milestoneService)
+	notificationHandler := handler.NewNotificationHandler(notificationService)
+	worklogHandler := handler.NewWorklogHandler(service.NewWorklogService(repos.worklogs, repos.tasks, repos.projects,
+		repos.users, repos.organizations, repos.members))
 
 	router := gin.Default()
+	router.Use(handler.Errors())
+	router.NoRoute(handler.NoRoute)
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	userGroup := router.Group("/user")
+	requireAuth := handler.RequireAuth(authService)
+	can := handler.RequirePermission
+
+	authGroup := router.Group("/auth")
+	{
+		authGroup.POST("/login", authHandler.Login)
+		authGroup.POST("/refresh", authHandler.Refresh)
+		authGroup.POST("/logout", requireAuth, authHandler.Logout)
+	}
+
+	meGroup := router.Group("/me", requireAuth)
+	{
+		meGroup.GET("/permissions", authHandler.Permissions)
+		meGroup.GET("/tokens", apiTokenHandler.GetAPITokens)
+		meGroup.POST("/tokens", apiTokenHandler.CreateAPIToken)
+		meGroup.DELETE("/tokens/:id", apiTokenHandler.RevokeAPIToken)
+		meGroup.GET("/notifications", notificationHandler.GetNotifications)
+		meGroup.POST("/notifications/read", notificationHandler.ReadAllNotifications)
+		meGroup.POST("/notifications/:id/read", notificationHandler.ReadNotification)
+		meGroup.GET("/timer", worklogHandler.GetTimer)
+		meGroup.GET("/milestones", milestoneHandler.GetUpcomingMilestones)
+	}
+
+	// Organizations are authorized by the role the caller has in the one a
+	// request is about, not in the one it acts in.
+	organizationGroup := router.Group("/organizations", requireAuth)
+	{
+		organizationGroup.GET("/", organizationHandler.GetOrganizations)
+		organizationGroup.POST("/", organizationHandler.CreateOrganization)
+		organizationGroup.GET("/:id", organizationHandler.GetOrganization)
+		organizationGroup.PUT("/:id", organizationHandler.UpdateOrganization)
+		organizationGroup.GET("/:id/members", organizationHandler.GetOrganizationMembers)
+		organizationGroup.POST("/:id/members", organizationHandler.AddOrganizationMember)
+		organizationGroup.PUT("/:id/members/:user_id", organizationHandler.UpdateOrganizationMember)
+		organizationGroup.DELETE("/:id/members/:user_id", organizationHandler.RemoveOrganizationMember)
+	}
+
+	userGroup := router.Group("/user", requireAuth)
 	{
-		userGroup.GET("/", handler.GetUsers)
-		userGroup.POST("/", handler.CreateUser)
-		userGroup.GET("/:id", handler.GetUser)
-		userGroup.PUT("/:id", handler.UpdateUser)
-		userGroup.DELETE("/:id", handler.DeleteUser)
+		userGroup.GET("/", can(auth.UsersRead), userHandler.GetUsers)
+		userGroup.POST("/", can(auth.UsersCreate), userHandler.CreateUser)
+		userGroup.GET("/:id", can(auth.UsersRead), userHandler.GetUser)
+		userGroup.PUT("/:id", can(auth.UsersUpdate), userHandler.UpdateUser)
+		userGroup.DELETE("/:id", can(auth.UsersDelete), userHandler.DeleteUser)
+		userGroup.GET("/:id/tasks", can(auth.TasksRead), taskHandler.GetUserTasks)
+		userGroup.GET("/:id/projects", can(auth.ProjectsRead), projectHandler.GetUserProjects)
+		userGroup.GET("/:id/timesheet", can(auth.UsersRead), worklogHandler.GetUserTimesheet)
 	}
 
-	taskGroup := router.Group("/tasks")
+	taskGroup := router.Group("/tasks", requireAuth)
 	{
-		taskGroup.GET("/", handler.GetTasks)
-		taskGroup.POST("/", handler.CreateTask)
-		taskGroup.GET("/:id", handler.GetTask)
-		taskGroup.PUT("/:id", handler.UpdateTask)
-		taskGroup.DELETE("/:id", handler.DeleteTask)
+		taskGroup.GET("/", can(auth.TasksRead), taskHandler.GetTasks)
+		taskGroup.POST("/", can(auth.TasksCreate), taskHandler.CreateTask)
+		taskGroup.GET("/:id", can(auth.TasksRead), taskHandler.GetTask)
+		taskGroup.PUT("/:id", can(auth.TasksUpdate), taskHandler.UpdateTask)
+		taskGroup.DELETE("/:id", can(auth.TasksDelete), taskHandler.DeleteTask)
+		taskGroup.POST("/:id/transitions", can(auth.TasksUpdate), taskHandler.TransitionTask)
+		taskGroup.POST("/:id/move", can(auth.TasksUpdate), taskHandler.MoveTask)
+		taskGroup.GET("/:id/subtasks", can(auth.TasksRead), taskHandler.GetSubtasks)
+		taskGroup.POST("/:id/subtasks", can(auth.TasksCreate), taskHandler.CreateSubtask)
+		taskGroup.GET("/:id/tree", can(auth.TasksRead), taskHandler.GetTaskTree)
+		taskGroup.GET("/:id/comments", can(auth.TasksRead), commentHandler.GetComments)
+		taskGroup.POST("/:id/comments", can(auth.CommentsCreate), commentHandler.CreateComment)
+		taskGroup.GET("/:id/comments/:comment_id", can(auth.TasksRead), commentHandler.GetComment)
+		taskGroup.PUT("/:id/comments/:comment_id", can(auth.CommentsUpdate), commentHandler.UpdateComment)
+		taskGroup.DELETE("/:id/comments/:comment_id", can(auth.CommentsDelete), commentHandler.DeleteComment)
+		taskGroup.GET("/:id/comments/:comment_id/revisions", can(auth.TasksRead), commentHandler.GetCommentRevisions)
+		taskGroup.GET("/:id/attachments", can(auth.TasksRead), attachmentHandler.GetTaskAttachments)
+		taskGroup.POST("/:id/attachments", can(auth.AttachmentsCreate), attachmentHandler.UploadTaskAttachment)
+		taskGroup.GET("/:id/attachments/:attachment_id", can(auth.TasksRead), attachmentHandler.GetTaskAttachment)
+		taskGroup.GET("/:id/attachments/:attachment_id/download", can(auth.TasksRead), attachmentHandler.DownloadTaskAttachment)
+		taskGroup.DELETE("/:id/attachments/:attachment_id", can(auth.AttachmentsDelete), attachmentHandler.DeleteTaskAttachment)
+		taskGroup.GET("/:id/dependencies", can(auth.TasksRead), dependencyHandler.GetDependencies)
+		taskGroup.POST("/:id/dependencies", can(auth.TasksUpdate), dependencyHandler.AddDependency)
+		taskGroup.DELETE("/:id/dependencies/:task_id", can(auth.TasksUpdate), dependencyHandler.RemoveDependency)
+		taskGroup.POST("/:id/labels", can(auth.TasksUpdate), taskHandler.AddTaskLabel)
+		taskGroup.DELETE("/:id/labels/:label_id", can(auth.TasksUpdate), taskHandler.RemoveTaskLabel)
+		taskGroup.GET("/:id/worklogs", can(auth.TasksRead), worklogHandler.GetWorklogs)
+		taskGroup.POST("/:id/worklogs", can(auth.WorklogsCreate), worklogHandler.CreateWorklog)
+		taskGroup.GET("/:id/worklogs/:worklog_id", can(auth.TasksRead), worklogHandler.GetWorklog)
+		taskGroup.PUT("/:id/worklogs/:worklog_id", can(auth.WorklogsUpdate), worklogHandler.UpdateWorklog)
+		taskGroup.DELETE("/:id/worklogs/:worklog_id", can(auth.WorklogsDelete), worklogHandler.DeleteWorklog)
+		taskGroup.POST("/:id/timer/start", can(auth.WorklogsCreate), worklogHandler.StartTimer)
+		taskGroup.POST("/:id/timer/stop", can(auth.WorklogsUpdate), worklogHandler.StopTimer)
 	}
 
-	projectGroup := router.Group("/projects")
+	projectGroup := router.Group("/projects", requireAuth)
 	{
-		projectGroup.GET("/", handler.GetProjects)
-		projectGroup.POST("/", handler.CreateProject)
-		projectGroup.GET("/:id", handler.GetProject)
-		projectGroup.PUT("/:id", handler.UpdateProject)
-		projectGroup.DELETE("/:id", handler.DeleteProject)
+		projectGroup.GET("/", can(auth.ProjectsRead), projectHandler.GetProjects)
+		projectGroup.POST("/", can(auth.ProjectsCreate), projectHandler.CreateProject)
+		projectGroup.GET("/:id", can(auth.ProjectsRead), projectHandler.GetProject)
+		projectGroup.PUT("/:id", can(auth.ProjectsUpdate), projectHandler.UpdateProject)
+		projectGroup.DELETE("/:id", can(auth.ProjectsDelete), projectHandler.DeleteProject)
+		projectGroup.GET("/:id/workflow", can(auth.WorkflowsRead), workflowHandler.GetWorkflow)
+		projectGroup.PUT("/:id/workflow", can(auth.WorkflowsUpdate), workflowHandler.UpdateWorkflow)
+		projectGroup.GET("/:id/board", can(auth.WorkflowsRead), boardHandler.GetBoard)
+		projectGroup.PUT("/:id/board", can(auth.WorkflowsUpdate), boardHandler.UpdateBoard)
+		projectGroup.GET("/:id/tasks", can(auth.TasksRead), taskHandler.GetProjectTasks)
+		projectGroup.POST("/:id/tasks", can(auth.TasksCreate), taskHandler.CreateProjectTask)
+		projectGroup.GET("/:id/members", can(auth.ProjectsRead), memberHandler.GetMembers)
+		projectGroup.POST("/:id/members", can(auth.ProjectsUpdate), memberHandler.AddMember)
+		projectGroup.GET("/:id/members/:user_id", can(auth.ProjectsRead), memberHandler.GetMember)
+		projectGroup.PUT("/:id/members/:user_id", can(auth.ProjectsUpdate), memberHandler.UpdateMember)
+		projectGroup.DELETE("/:id/members/:user_id", can(auth.ProjectsUpdate), memberHandler.RemoveMember)
+		projectGroup.GET("/:id/attachments", can(auth.ProjectsRead), attachmentHandler.GetProjectAttachments)
+		projectGroup.POST("/:id/attachments", can(auth.AttachmentsCreate), attachmentHandler.UploadProjectAttachment)
+		projectGroup.GET("/:id/attachments/:attachment_id", can(auth.ProjectsRead), attachmentHandler.GetProjectAttachment)
+		projectGroup.GET("/:id/attachments/:attachment_id/download", can(auth.ProjectsRead), attachmentHandler.DownloadProjectAttachment)
+		projectGroup.DELETE("/:id/attachments/:attachment_id", can(auth.AttachmentsDelete), attachmentHandler.DeleteProjectAttachment)
+		projectGroup.GET("/:id/labels", can(auth.ProjectsRead), labelHandler.GetLabels)
+		projectGroup.POST("/:id/labels", can(auth.ProjectsUpdate), labelHandler.CreateLabel)
+		projectGroup.GET("/:id/labels/:label_id", can(auth.ProjectsRead), labelHandler.GetLabel)
+		projectGroup.PUT("/:id/labels/:label_id", can(auth.ProjectsUpdate), labelHandler.UpdateLabel)
+		projectGroup.DELETE("/:id/labels/:label_id", can(auth.ProjectsUpdate), labelHandler.DeleteLabel)
+		projectGroup.POST("/:id/labels/:label_id/merge", can(auth.ProjectsUpdate), labelHandler.MergeLabel)
+		projectGroup.GET("/:id/timesheet", can(auth.ProjectsRead), worklogHandler.GetProjectTimesheet)
+		projectGroup.GET("/:id/sprints", can(auth.ProjectsRead), sprintHandler.GetSprints)
+		projectGroup.POST("/:id/sprints", can(auth.ProjectsUpdate), sprintHandler.CreateSprint)
+		projectGroup.GET("/:id/sprints/:sprint_id", can(auth.ProjectsRead), sprintHandler.GetSprint)
+		projectGroup.PUT("/:id/sprints/:sprint_id", can(auth.ProjectsUpdate), sprintHandler.UpdateSprint)
+		projectGroup.DELETE("/:id/sprints/:sprint_id", can(auth.ProjectsUpdate), sprintHandler.DeleteSprint)
+		projectGroup.POST("/:id/sprints/:sprint_id/start", can(auth.ProjectsUpdate), sprintHandler.StartSprint)
+		projectGroup.POST("/:id/sprints/:sprint_id/close", can(auth.ProjectsUpdate), sprintHandler.CloseSprint)
+		projectGroup.GET("/:id/sprints/:sprint_id/report", can(auth.ProjectsRead), sprintHandler.GetSprintReport)
+		projectGroup.GET("/:id/milestones", can(auth.ProjectsRead), milestoneHandler.GetMilestones)
+		projectGroup.POST("/:id/milestones", can(auth.ProjectsUpdate), milestoneHandler.CreateMilestone)
+		projectGroup.GET("/:id/milestones/:milestone_id", can(auth.ProjectsRead), milestoneHandler.GetMilestone)
+		projectGroup.PUT("/:id/milestones/:milestone_id", can(auth.ProjectsUpdate), milestoneHandler.UpdateMilestone)
+		projectGroup.DELETE("/:id/milestones/:milestone_id", can(auth.ProjectsUpdate), milestoneHandler.DeleteMilestone)
 	}
 
 	log.Println("Server is running on port 8080")
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/yelnar0112/project-management/internal/migrate"
+)
+
+const migrateUsage = "usage: migrate up | down [steps] | status | baseline version"
+
+// runMigrations brings the database schema up to date before the server
+// starts accepting requests.
+func runMigrations(db *sql.DB, driver string) {
+	migrator, err := migrate.New(db, driver)
+	if err != nil {
+		log.Fatalf("Could not load migrations: %v", err)
+	}
+	if err := migrator.Up(context.Background()); err != nil {
+		log.Fatalf("Could not apply migrations: %v", err)
+	}
+}
+
+// migrateCommand implements the `migrate up|down|status|baseline` subcommand.
+func migrateCommand(db *sql.DB, driver string, args []string) {
+	if len(args) == 0 {
+		log.Fatal(migrateUsage)
+	}
+
+	migrator, err := migrate.New(db, driver)
+	if err != nil {
+		log.Fatalf("Could not load migrations: %v", err)
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		err = migrator.Up(ctx)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				log.Fatalf("invalid number of steps %q", args[1])
+			}
+		}
+		err = migrator.Down(ctx, steps)
+	case "baseline":
+		if len(args) != 2 {
+			log.Fatal(migrateUsage)
+		}
+		var version int64
+		if version, err = strconv.ParseInt(args[1], 10, 64); err != nil || version < 1 {
+			log.Fatalf("invalid version %q", args[1])
+		}
+		err = migrator.Baseline(ctx, version)
+	case "status":
+		var statuses []migrate.Status
+		statuses, err = migrator.Status(ctx)
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if status.Modified {
+				state += " (modified)"
+			}
+			fmt.Fprintf(os.Stdout, "%04d_%-30s %s\n", status.Version, status.Name, state)
+		}
+	default:
+		log.Fatal(migrateUsage)
+	}
+
+	if err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+}
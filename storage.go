@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/yelnar0112/project-management/internal/blob"
+	"github.com/yelnar0112/project-management/internal/config"
+	"github.com/yelnar0112/project-management/internal/repository"
+	"github.com/yelnar0112/project-management/internal/repository/memory"
+	"github.com/yelnar0112/project-management/internal/repository/sqlstore"
+)
+
+type repositories struct {
+	users         repository.UserRepository
+	tasks         repository.TaskRepository
+	projects      repository.ProjectRepository
+	workflows     repository.WorkflowRepository
+	boards        repository.BoardRepository
+	sessions      repository.SessionRepository
+	members       repository.MemberRepository
+	apiTokens     repository.APITokenRepository
+	organizations repository.OrganizationRepository
+	comments      repository.CommentRepository
+	attachments   repository.AttachmentRepository
+	dependencies  repository.DependencyRepository
+	labels        repository.LabelRepository
+	notifications repository.NotificationRepository
+	worklogs      repository.WorklogRepository
+	sprints       repository.SprintRepository
+	milestones    repository.MilestoneRepository
+}
+
+// openStorage builds the repositories for the configured storage backend.
+// The returned function releases any resources held by the backend.
+func openStorage(cfg *config.Config) (*repositories, func()) {
+	if cfg.Storage == config.StorageMemory {
+		store := memory.NewStore()
+		return &repositories{
+			users:         memory.NewUserRepository(store),
+			tasks:         memory.NewTaskRepository(store),
+			projects:      memory.NewProjectRepository(store),
+			workflows:     memory.NewWorkflowRepository(store),
+			boards:        memory.NewBoardRepository(store),
+			sessions:      memory.NewSessionRepository(store),
+			members:       memory.NewMemberRepository(store),
+			apiTokens:     memory.NewAPITokenRepository(store),
+			organizations: memory.NewOrganizationRepository(store),
+			comments:      memory.NewCommentRepository(store),
+			attachments:   memory.NewAttachmentRepository(store),
+			dependencies:  memory.NewDependencyRepository(store),
+			labels:        memory.NewLabelRepository(store),
+			notifications: memory.NewNotificationRepository(store),
+			worklogs:      memory.NewWorklogRepository(store),
+			sprints:       memory.NewSprintRepository(store),
+			milestones:    memory.NewMilestoneRepository(store),
+		}, func() {}
+	}
+
+	db := config.ConnectDB(cfg)
+	runMigrations(db, cfg.DBDriver)
+
+	dialect := sqlstore.Postgres
+	if cfg.DBDriver == config.DriverSQLite {
+		dialect = sqlstore.SQLite
+	}
+	store := sqlstore.New(db, dialect)
+
+	return &repositories{
+		users:         sqlstore.NewUserRepository(store),
+		tasks:         sqlstore.NewTaskRepository(store),
+		projects:      sqlstore.NewProjectRepository(store),
+		workflows:     sqlstore.NewWorkflowRepository(store),
+		boards:        sqlstore.NewBoardRepository(store),
+		sessions:      sqlstore.NewSessionRepository(store),
+		members:       sqlstore.NewMemberRepository(store),
+		apiTokens:     sqlstore.NewAPITokenRepository(store),
+		organizations: sqlstore.NewOrganizationRepository(store),
+		comments:      sqlstore.NewCommentRepository(store),
+		attachments:   sqlstore.NewAttachmentRepository(store),
+		dependencies:  sqlstore.NewDependencyRepository(store),
+		labels:        sqlstore.NewLabelRepository(store),
+		notifications: sqlstore.NewNotificationRepository(store),
+		worklogs:      sqlstore.NewWorklogRepository(store),
+		sprints:       sqlstore.NewSprintRepository(store),
+		milestones:    sqlstore.NewMilestoneRepository(store),
+	}, func() { db.Close() }
+}
+
+// openBlobs builds the blob store for the configured backend, which keeps
+// the contents of attachments.
+func openBlobs(cfg *config.Config) blob.Store {
+	if cfg.BlobStorage == config.BlobS3 {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		store, err := blob.NewS3(ctx, blob.S3Config{
+			Endpoint:        cfg.S3Endpoint,
+			Region:          cfg.S3Region,
+			Bucket:          cfg.S3Bucket,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+			Insecure:        cfg.S3Insecure,
+		})
+		if err != nil {
+			log.Fatalf("Could not open the S3 bucket: %v", err)
+		}
+		return store
+	}
+
+	store, err := blob.NewLocal(cfg.BlobDir)
+	if err != nil {
+		log.Fatalf("Could not open the blob directory: %v", err)
+	}
+	return store
+}